@@ -171,6 +171,9 @@ func (f DefaultFunction) IsAvailableIn(version PlutusVersion) bool {
 // available in all language versions. DropList is also activated at PV11.
 //
 // For protocol versions < 11, the original version-based gating applies.
+//
+// This is the only place that gates builtins by protocol version; the policy
+// package and through it the cek package ask it.
 func (f DefaultFunction) IsAvailableInWithProto(
 	version PlutusVersion,
 	protoMajor uint,
@@ -160,13 +160,7 @@ func bigIntExMem(i *big.Int) func() ExMem {
 }
 
 func bigIntExMemValue(i *big.Int) ExMem {
-	if i.Sign() == 0 {
-		return ExMem(1)
-	}
-	if i.IsInt64() || i.IsUint64() {
-		return ExMem(1)
-	}
-	return ExMem((i.BitLen()-1)/64 + 1)
+	return ExMem(data.IntegerExMemSize(i))
 }
 
 func byteArrayExMem(b []byte) func() ExMem {
@@ -176,11 +170,7 @@ func byteArrayExMem(b []byte) func() ExMem {
 }
 
 func byteArrayExMemValue(b []byte) ExMem {
-	length := len(b)
-	if length == 0 {
-		return ExMem(1)
-	}
-	return ExMem(((length - 1) / 8) + 1)
+	return ExMem(data.BytesExMemSize(b))
 }
 
 // Haskell uses T.length which returns the number of Unicode codepoints (characters),
@@ -376,23 +366,9 @@ func valueMaxCountExMem(l []syn.IConstant) func() ExMem {
 // Used by unValueData which costs based on node count.
 func dataNodeCountExMem(d data.PlutusData) func() ExMem {
 	return func() ExMem {
-		count := 0
-		stack := []data.PlutusData{d}
-		for len(stack) > 0 {
-			node := stack[0]
-			stack = stack[1:]
-			count++
-			switch n := node.(type) {
-			case *data.Constr:
-				stack = append(stack, n.Fields...)
-			case *data.List:
-				stack = append(stack, n.Items...)
-			case *data.Map:
-				for _, pair := range n.Pairs {
-					stack = append(stack, pair[0], pair[1])
-				}
-				// Integer and ByteString are leaf nodes, just counted
-			}
+		count, err := data.NodeCount(d)
+		if err != nil {
+			panic(fmt.Sprintf("invalid data: %v", err))
 		}
 		return ExMem(count)
 	}
@@ -400,35 +376,13 @@ func dataNodeCountExMem(d data.PlutusData) func() ExMem {
 
 func dataExMem(x data.PlutusData) func() ExMem {
 	return func() ExMem {
-		var acc ExMem
-		costStack := []data.PlutusData{
-			x,
+		// Constants are forced when the machine evaluates them, so costing
+		// only sees the eager variants.
+		size, err := data.ExMemSize(x)
+		if err != nil {
+			panic(fmt.Sprintf("invalid data: %v", err))
 		}
-
-		for len(costStack) != 0 {
-			d := costStack[0]
-			costStack = costStack[1:]
-			// Cost 4 per item switch
-			acc += DataCost
-			switch dat := d.(type) {
-			case *data.Constr:
-				costStack = append(costStack, dat.Fields...)
-			case *data.List:
-				costStack = append(costStack, dat.Items...)
-			case *data.Map:
-				for _, pair := range dat.Pairs {
-					costStack = append(costStack, pair[0], pair[1])
-				}
-			case *data.Integer:
-				acc += bigIntExMem(dat.Inner)()
-			case *data.ByteString:
-				acc += byteArrayExMem(dat.Inner)()
-			default:
-				panic("Unreachable")
-			}
-		}
-
-		return acc
+		return ExMem(size)
 	}
 }
 
@@ -437,72 +391,16 @@ func dataExMem(x data.PlutusData) func() ExMem {
 // this is possible via having one super large object equals data with a tiny object
 // like script context vs a Data of bytearray of 0 bytes. In this case the cpu ExBudget would far underestimate
 // the cost for calculating the ExMem for the entire script context thus causing a lot of free work to be done
-// by the node
+// by the node. data.MinExMemSize stops walking once the smaller object is sized.
 func equalsDataExMem(
 	x data.PlutusData,
 	y data.PlutusData,
 ) (func() ExMem, func() ExMem) {
-	var xAcc ExMem
-	var yAcc ExMem
-	var minAcc ExMem
-	costStackX := []data.PlutusData{
-		x,
-	}
-
-	costStackY := []data.PlutusData{
-		y,
-	}
-
-	for xLen, yLen := true, true; (xLen || xAcc > yAcc) && (yLen || yAcc > xAcc); xLen,
-		yLen = len(costStackX) != 0, len(costStackY) != 0 {
-		if xLen {
-			// Cost 4 per item switch
-			xAcc += DataCost
-			d := costStackX[0]
-			costStackX = costStackX[1:]
-			switch dat := d.(type) {
-			case *data.Constr:
-				costStackX = append(costStackX, dat.Fields...)
-			case *data.List:
-				costStackX = append(costStackX, dat.Items...)
-			case *data.Map:
-				for _, pair := range dat.Pairs {
-					costStackX = append(costStackX, pair[0], pair[1])
-				}
-			case *data.Integer:
-				xAcc += bigIntExMem(dat.Inner)()
-			case *data.ByteString:
-				xAcc += byteArrayExMem(dat.Inner)()
-			default:
-				panic("Unreachable")
-			}
-		}
-
-		if yLen {
-			// Cost 4 per item switch
-			yAcc += DataCost
-			d := costStackY[0]
-			costStackY = costStackY[1:]
-			switch dat := d.(type) {
-			case *data.Constr:
-				costStackY = append(costStackY, dat.Fields...)
-			case *data.List:
-				costStackY = append(costStackY, dat.Items...)
-			case *data.Map:
-				for _, pair := range dat.Pairs {
-					costStackY = append(costStackY, pair[0], pair[1])
-				}
-			case *data.Integer:
-				yAcc += bigIntExMem(dat.Inner)()
-			case *data.ByteString:
-				yAcc += byteArrayExMem(dat.Inner)()
-			default:
-				panic("Unreachable")
-			}
-		}
+	size, err := data.MinExMemSize(x, y)
+	if err != nil {
+		panic(fmt.Sprintf("invalid data: %v", err))
 	}
-
-	minAcc = min(xAcc, yAcc)
+	minAcc := ExMem(size)
 
 	final_func := func() ExMem {
 		return minAcc
@@ -0,0 +1,170 @@
+package cek
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// termConstantValue is the value of a constant term. The data.Lazy values
+// in the constant are forced as it enters the machine, so that builtins and
+// their costing only see the eager PlutusData variants. A Lazy value keeps
+// what it decodes, so evaluating the constant again does not decode again.
+// The constant itself is left unchanged.
+func termConstantValue[T syn.Eval](m *Machine[T], constant syn.IConstant) (Value[T], error) {
+	switch constant.(type) {
+	case *syn.Data, *syn.ProtoList, *syn.ProtoPair:
+		forced, err := forceLazyConstant(constant)
+		if err != nil {
+			return nil, err
+		}
+		return machineConstantValue(m, forced), nil
+	default:
+		return machineConstantValue(m, constant), nil
+	}
+}
+
+// forceLazyConstant returns con with its Lazy values forced, copying the
+// nodes on the path to them, or con itself when it holds none.
+func forceLazyConstant(con syn.IConstant) (syn.IConstant, error) {
+	switch c := con.(type) {
+	case *syn.Data:
+		inner, err := forceLazyPlutusData(c.Inner)
+		if err != nil || inner == c.Inner {
+			return c, err
+		}
+		return &syn.Data{Inner: inner}, nil
+	case *syn.ProtoList:
+		if !typeHasData(c.LTyp) {
+			return c, nil
+		}
+		var items []syn.IConstant
+		for i, item := range c.List {
+			forced, err := forceLazyConstant(item)
+			if err != nil {
+				return nil, err
+			}
+			if forced != item && items == nil {
+				items = make([]syn.IConstant, len(c.List))
+				copy(items, c.List[:i])
+			}
+			if items != nil {
+				items[i] = forced
+			}
+		}
+		if items == nil {
+			return c, nil
+		}
+		return &syn.ProtoList{LTyp: c.LTyp, List: items}, nil
+	case *syn.ProtoPair:
+		if !typeHasData(c.FstType) && !typeHasData(c.SndType) {
+			return c, nil
+		}
+		first, err := forceLazyConstant(c.First)
+		if err != nil {
+			return nil, err
+		}
+		second, err := forceLazyConstant(c.Second)
+		if err != nil {
+			return nil, err
+		}
+		if first == c.First && second == c.Second {
+			return c, nil
+		}
+		return &syn.ProtoPair{FstType: c.FstType, SndType: c.SndType, First: first, Second: second}, nil
+	default:
+		return con, nil
+	}
+}
+
+func forceLazyPlutusData(pd data.PlutusData) (data.PlutusData, error) {
+	switch d := pd.(type) {
+	case *data.Lazy:
+		// A forced value holds no Lazy nodes.
+		forced, err := d.Force()
+		if err != nil {
+			return nil, &ScriptError{
+				Code:    ErrCodeDecodeFailure,
+				Message: fmt.Sprintf("force lazy data constant: %v", err),
+			}
+		}
+		return forced, nil
+	case *data.Constr:
+		fields, err := forceLazyItems(d.Fields)
+		if err != nil || fields == nil {
+			return d, err
+		}
+		forced := *d
+		forced.Fields = fields
+		return &forced, nil
+	case *data.List:
+		items, err := forceLazyItems(d.Items)
+		if err != nil || items == nil {
+			return d, err
+		}
+		forced := *d
+		forced.Items = items
+		return &forced, nil
+	case *data.Map:
+		var pairs [][2]data.PlutusData
+		for i, pair := range d.Pairs {
+			items, err := forceLazyItems(pair[:])
+			if err != nil {
+				return nil, err
+			}
+			if items != nil && pairs == nil {
+				pairs = make([][2]data.PlutusData, len(d.Pairs))
+				copy(pairs, d.Pairs[:i])
+			}
+			if pairs != nil {
+				pairs[i] = pair
+				if items != nil {
+					pairs[i] = [2]data.PlutusData{items[0], items[1]}
+				}
+			}
+		}
+		if pairs == nil {
+			return d, nil
+		}
+		forced := *d
+		forced.Pairs = pairs
+		return &forced, nil
+	default:
+		return pd, nil
+	}
+}
+
+// forceLazyItems forces the Lazy values in items, and returns nil when there
+// are none.
+func forceLazyItems(items []data.PlutusData) ([]data.PlutusData, error) {
+	var forced []data.PlutusData
+	for i, item := range items {
+		f, err := forceLazyPlutusData(item)
+		if err != nil {
+			return nil, err
+		}
+		if f != item && forced == nil {
+			forced = make([]data.PlutusData, len(items))
+			copy(forced, items[:i])
+		}
+		if forced != nil {
+			forced[i] = f
+		}
+	}
+	return forced, nil
+}
+
+// typeHasData reports whether constants of typ can hold Data.
+func typeHasData(typ syn.Typ) bool {
+	switch t := typ.(type) {
+	case *syn.TData:
+		return true
+	case *syn.TList:
+		return typeHasData(t.Typ)
+	case *syn.TPair:
+		return typeHasData(t.First) || typeHasData(t.Second)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,166 @@
+package cek
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// lazyDataConstant decodes hex CBOR into a Lazy Data constant.
+func lazyDataConstant(t *testing.T, s string) *syn.Constant {
+	t.Helper()
+	lazy, err := data.DecodeLazy(hexDecode(t, s))
+	if err != nil {
+		t.Fatalf("DecodeLazy(%s) failed: %v", s, err)
+	}
+	return &syn.Constant{Con: &syn.Data{Inner: lazy}}
+}
+
+func applyBuiltin(fn builtin.DefaultFunction, forces int, args ...syn.Term[syn.DeBruijn]) syn.Term[syn.DeBruijn] {
+	var term syn.Term[syn.DeBruijn] = &syn.Builtin{DefaultFunction: fn}
+	for range forces {
+		term = &syn.Force[syn.DeBruijn]{Term: term}
+	}
+	for _, arg := range args {
+		term = &syn.Apply[syn.DeBruijn]{Function: term, Argument: arg}
+	}
+	return term
+}
+
+func runLazyDataTerm(t *testing.T, term syn.Term[syn.DeBruijn]) (syn.IConstant, ExBudget) {
+	t.Helper()
+	m := NewMachine[syn.DeBruijn](lang.LanguageVersionV3, 0, nil)
+	out, err := m.Run(term)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	constant, ok := out.(*syn.Constant)
+	if !ok {
+		t.Fatalf("Run returned %T, want *syn.Constant", out)
+	}
+	return constant.Con, DefaultExBudget.Sub(&m.ExBudget)
+}
+
+func TestEqualsDataLazy(t *testing.T) {
+	// Constr 0 [Integer 7 with a non-canonical width, List [B #ab]]
+	const encoded = "d8799f18078141abff"
+	eager := &syn.Constant{Con: &syn.Data{Inner: data.NewConstr(0,
+		data.NewInteger(big.NewInt(7)),
+		data.NewList(data.NewByteString([]byte{0xab})),
+	)}}
+	nested := &syn.Constant{Con: &syn.Data{Inner: data.NewConstr(0,
+		lazyDataConstant(t, "07").Con.(*syn.Data).Inner,
+		lazyDataConstant(t, "8141ab").Con.(*syn.Data).Inner,
+	)}}
+
+	_, eagerBudget := runLazyDataTerm(t, applyBuiltin(builtin.EqualsData, 0, eager, eager))
+	tests := []struct {
+		name string
+		x, y syn.Term[syn.DeBruijn]
+	}{
+		{name: "lazy and eager", x: lazyDataConstant(t, encoded), y: eager},
+		{name: "eager and lazy", x: eager, y: lazyDataConstant(t, encoded)},
+		{name: "both lazy", x: lazyDataConstant(t, encoded), y: lazyDataConstant(t, encoded)},
+		{name: "nested lazy", x: nested, y: eager},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, budget := runLazyDataTerm(t, applyBuiltin(builtin.EqualsData, 0, test.x, test.y))
+			if b, ok := result.(*syn.Bool); !ok || !b.Inner {
+				t.Fatalf("equalsData = %v, want True", result)
+			}
+			if budget != eagerBudget {
+				t.Fatalf("budget = %+v, want %+v as for eager data", budget, eagerBudget)
+			}
+		})
+	}
+}
+
+func TestChooseDataLazy(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    string
+	}{
+		{encoded: "d87980", want: "constr"},
+		{encoded: "a0", want: "map"},
+		{encoded: "80", want: "list"},
+		{encoded: "1807", want: "integer"},
+		{encoded: "41ab", want: "bytes"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			branches := make([]syn.Term[syn.DeBruijn], 0, 6)
+			branches = append(branches, lazyDataConstant(t, test.encoded))
+			for _, branch := range []string{"constr", "map", "list", "integer", "bytes"} {
+				branches = append(branches, &syn.Constant{Con: &syn.String{Inner: branch}})
+			}
+			result, _ := runLazyDataTerm(t, applyBuiltin(builtin.ChooseData, 1, branches...))
+			if s, ok := result.(*syn.String); !ok || s.Inner != test.want {
+				t.Fatalf("chooseData = %v, want %q", result, test.want)
+			}
+		})
+	}
+}
+
+func TestSerialiseDataLazy(t *testing.T) {
+	constant := lazyDataConstant(t, "d8799f1807ff")
+	result, _ := runLazyDataTerm(t, applyBuiltin(builtin.SerialiseData, 0, constant))
+	bs, ok := result.(*syn.ByteString)
+	if !ok {
+		t.Fatalf("serialiseData returned %T, want *syn.ByteString", result)
+	}
+	// Like the node, serialiseData encodes canonically.
+	if got := hex.EncodeToString(bs.Inner); got != "d8799f07ff" {
+		t.Fatalf("serialiseData = %s, want d8799f07ff", got)
+	}
+	lazy, ok := constant.Con.(*syn.Data).Inner.(*data.Lazy)
+	if !ok || !bytes.Equal(lazy.RawCBOR(), hexDecode(t, "d8799f1807ff")) {
+		t.Fatalf("Run changed its input constant to %v", constant.Con)
+	}
+}
+
+func TestRunLazyDataDecodeFailure(t *testing.T) {
+	// A constructor field with an unsupported CBOR tag passes DecodeLazy's
+	// structural scan and fails when forced.
+	term := applyBuiltin(builtin.SerialiseData, 0, lazyDataConstant(t, "d8799fc100ff"))
+	m := NewMachine[syn.DeBruijn](lang.LanguageVersionV3, 0, nil)
+	_, err := m.Run(term)
+	if err == nil {
+		t.Fatal("Run succeeded, want a decode error")
+	}
+	if code, ok := GetErrorCode(err); !ok || code != ErrCodeDecodeFailure {
+		t.Fatalf("Run error = %v, want code %d", err, ErrCodeDecodeFailure)
+	}
+}
+
+func TestRunLazyDataConcurrently(t *testing.T) {
+	// Machines running one term share its Lazy constant, which each forces.
+	constant := lazyDataConstant(t, "d8799f1807ff")
+	term := applyBuiltin(builtin.SerialiseData, 0, constant)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			m := NewMachine[syn.DeBruijn](lang.LanguageVersionV3, 0, nil)
+			out, err := m.Run(term)
+			if err != nil {
+				t.Errorf("Run returned error: %v", err)
+				return
+			}
+			bs, ok := out.(*syn.Constant).Con.(*syn.ByteString)
+			if !ok || hex.EncodeToString(bs.Inner) != "d8799f07ff" {
+				t.Errorf("serialiseData = %v, want d8799f07ff", out)
+			}
+		})
+	}
+	wg.Wait()
+	if _, ok := constant.Con.(*syn.Data).Inner.(*data.Lazy); !ok {
+		t.Fatal("Run changed its input constant")
+	}
+}
@@ -1146,7 +1146,11 @@ func (m *Machine[T]) compute(
 			return nil, err
 		}
 
-		state, err = m.returnValueState(context, machineConstantValue(m, t.Con))
+		value, err := termConstantValue(m, t.Con)
+		if err != nil {
+			return nil, err
+		}
+		state, err = m.returnValueState(context, value)
 		if err != nil {
 			return nil, err
 		}
@@ -1311,7 +1315,8 @@ func (m *Machine[T]) computeImmediateValue(
 		if err := m.stepAndMaybeSpend(ExConstant); err != nil {
 			return nil, true, err
 		}
-		return machineConstantValue(m, t.Con), true, nil
+		value, err := termConstantValue(m, t.Con)
+		return value, true, err
 	case *syn.Error:
 		return nil, true, &ScriptError{Code: ErrCodeExplicitError, Message: "error explicitly called"}
 	case *syn.Builtin:
@@ -8,6 +8,7 @@ import (
 	"github.com/blinklabs-io/plutigo/builtin"
 	"github.com/blinklabs-io/plutigo/data"
 	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/policy"
 	"github.com/blinklabs-io/plutigo/syn"
 	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
 )
@@ -35,7 +36,7 @@ func buildAvailableBuiltins(
 	available := new([builtin.TotalBuiltinCount]bool)
 	for i := 0; i < int(builtin.TotalBuiltinCount); i++ {
 		fn := builtin.DefaultFunction(i)
-		available[i] = fn.IsAvailableInWithProto(plutusVersion, protoMajor)
+		available[i] = policy.BuiltinAvailable(fn, plutusVersion, protoMajor)
 	}
 	return available
 }
@@ -1,22 +1,21 @@
 package cek
 
 import (
+	"github.com/blinklabs-io/plutigo/builtin"
 	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/policy"
 )
 
-type SemanticsVariant int
+// SemanticsVariant is defined by the policy package, which maps ledger
+// languages and protocol versions to variants.
+type SemanticsVariant = policy.SemanticsVariant
 
 const (
-	SemanticsVariantA SemanticsVariant = 1
-	SemanticsVariantB SemanticsVariant = 2
-	SemanticsVariantC SemanticsVariant = 3
-	SemanticsVariantD SemanticsVariant = 4
-	SemanticsVariantE SemanticsVariant = 5
-)
-
-const (
-	changProtoMajorVersion     = 9
-	vanRossemProtoMajorVersion = 11
+	SemanticsVariantA = policy.SemanticsVariantA
+	SemanticsVariantB = policy.SemanticsVariantB
+	SemanticsVariantC = policy.SemanticsVariantC
+	SemanticsVariantD = policy.SemanticsVariantD
+	SemanticsVariantE = policy.SemanticsVariantE
 )
 
 type ProtoVersion struct {
@@ -24,23 +23,21 @@ type ProtoVersion struct {
 	Minor uint
 }
 
+// GetSemantics returns the semantics variant of a language version at a
+// protocol version, as given by the policy table. Unrecognized versions
+// follow PlutusV3.
 func GetSemantics(
 	version lang.LanguageVersion,
 	protoVersion ProtoVersion,
 ) SemanticsVariant {
+	language := builtin.PlutusV3
 	switch version {
-	case lang.LanguageVersionV1, lang.LanguageVersionV2:
-		if protoVersion.Major < changProtoMajorVersion {
-			return SemanticsVariantA
-		}
-		if protoVersion.Major < vanRossemProtoMajorVersion {
-			return SemanticsVariantB
-		}
-		return SemanticsVariantD
-	default:
-		if protoVersion.Major >= vanRossemProtoMajorVersion {
-			return SemanticsVariantE
-		}
-		return SemanticsVariantC
+	case lang.LanguageVersionV1:
+		language = builtin.PlutusV1
+	case lang.LanguageVersionV2:
+		language = builtin.PlutusV2
+	case lang.LanguageVersionV4:
+		language = builtin.PlutusV4
 	}
+	return policy.Semantics(language, protoVersion.Major)
 }
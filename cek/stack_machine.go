@@ -144,7 +144,7 @@ func (m *Machine[T]) computeKnownImmediateValue(
 		if err := m.stepAndMaybeSpend(ExConstant); err != nil {
 			return nil, err
 		}
-		return machineConstantValue(m, t.Con), nil
+		return termConstantValue(m, t.Con)
 	case *syn.Error:
 		return nil, &ScriptError{Code: ErrCodeExplicitError, Message: "error explicitly called"}
 	case *syn.Builtin:
@@ -218,7 +218,7 @@ func (m *Machine[T]) computeKnownImmediateValueNoSlippage(
 		if !m.spendStepNoSlippage(ExConstant) {
 			return nil, m.budgetErrorForStep(ExConstant)
 		}
-		return machineConstantValue(m, t.Con), nil
+		return termConstantValue(m, t.Con)
 	case *syn.Error:
 		return nil, &ScriptError{Code: ErrCodeExplicitError, Message: "error explicitly called"}
 	case *syn.Builtin:
@@ -341,7 +341,11 @@ func (m *Machine[T]) runStackNoSlippage(term syn.Term[T]) (syn.Term[T], error) {
 					return nil, m.budgetErrorForStep(ExConstant)
 				}
 
-				currentValue = machineConstantValue(m, t.Con)
+				value, err := termConstantValue(m, t.Con)
+				if err != nil {
+					return nil, err
+				}
+				currentValue = value
 				returning = true
 			case *syn.Force[T]:
 				if !m.spendStepNoSlippage(ExForce) {
@@ -652,7 +656,11 @@ func (m *Machine[T]) runStack(term syn.Term[T]) (syn.Term[T], error) {
 					return nil, err
 				}
 
-				currentValue = machineConstantValue(m, t.Con)
+				value, err := termConstantValue(m, t.Con)
+				if err != nil {
+					return nil, err
+				}
+				currentValue = value
 				returning = true
 			case *syn.Force[T]:
 				if err := m.stepAndMaybeSpend(ExForce); err != nil {
@@ -173,7 +173,7 @@ func computeKnownImmediateValueNoSlippageDeBruijn(
 		if !m.spendStepNoSlippage(ExConstant) {
 			return nil, m.budgetErrorForStep(ExConstant)
 		}
-		return machineConstantValue(m, t.Con), nil
+		return termConstantValue(m, t.Con)
 	case *syn.Error:
 		return nil, &ScriptError{Code: ErrCodeExplicitError, Message: "error explicitly called"}
 	case *syn.Builtin:
@@ -327,7 +327,11 @@ func runStackNoSlippageDeBruijn(
 					return nil, m.budgetErrorForStep(ExConstant)
 				}
 
-				currentValue = machineConstantValue(m, t.Con)
+				value, err := termConstantValue(m, t.Con)
+				if err != nil {
+					return nil, err
+				}
+				currentValue = value
 				returning = true
 			case *syn.Force[syn.DeBruijn]:
 				if !m.spendStepNoSlippage(ExForce) {
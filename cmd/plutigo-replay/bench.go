@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// runBench implements "plutigo-replay bench". It exits with 1 when a case
+// fails its expectations or is slower than -baseline allows.
+func runBench(
+	ctx context.Context,
+	args []string,
+	stdout, stderr io.Writer,
+) int {
+	flags := flag.NewFlagSet("plutigo-replay bench", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo-replay bench -corpus FILE [flags]")
+		flags.PrintDefaults()
+	}
+	var corpusPath, baselinePath string
+	var pretty bool
+	var opts replay.BenchOptions
+	var maxMean, maxCase float64
+	flags.StringVar(&corpusPath, "corpus", "", "path to a replay corpus: a JSON or JSON Lines file, or a directory of them")
+	flags.IntVar(&opts.Warmup, "warmup", 3, "unmeasured evaluations of each case before measuring")
+	flags.IntVar(&opts.Repetitions, "count", 10, "measured evaluations of each case")
+	flags.StringVar(&baselinePath, "baseline", "", "path to an earlier benchmark report to compare against")
+	flags.Float64Var(&maxMean, "max-mean-increase", 0, "with -baseline, fail when the summed mean of the shared cases grows by more than this percentage (0 disables)")
+	flags.Float64Var(&maxCase, "max-case-increase", 0, "with -baseline, fail when a case's mean grows by more than this percentage (0 disables)")
+	flags.BoolVar(&pretty, "pretty", false, "indent the JSON report")
+	var selection replay.Selection
+	selecting := selectionFlags(flags, &selection)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if corpusPath == "" {
+		fmt.Fprintln(stderr, "plutigo-replay: -corpus is required")
+		return 2
+	}
+	if opts.Warmup < 0 || opts.Repetitions < 1 {
+		fmt.Fprintln(stderr, "plutigo-replay: -warmup must not be negative and -count must be at least 1")
+		return 2
+	}
+	if maxMean < 0 || maxCase < 0 {
+		fmt.Fprintln(stderr, "plutigo-replay: latency limits must not be negative")
+		return 2
+	}
+	if (maxMean > 0 || maxCase > 0) && baselinePath == "" {
+		fmt.Fprintln(stderr, "plutigo-replay: latency limits need -baseline")
+		return 2
+	}
+	var baseline *replay.BenchReport
+	if baselinePath != "" {
+		var err error
+		if baseline, err = replay.LoadBenchReportFile(baselinePath); err != nil {
+			fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+			return 2
+		}
+	}
+
+	source, err := replay.Open(corpusPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	defer source.Close()
+	if selecting() {
+		source = replay.Select(source, selection)
+	}
+	report, err := replay.Bench(ctx, source, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if baseline != nil {
+		if report.Comparison, err = replay.CompareBench(baseline, report); err != nil {
+			fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+			return 2
+		}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: encode benchmark report: %v\n", err)
+		return 2
+	}
+
+	code := 0
+	if report.Summary.Failed > 0 {
+		fmt.Fprintf(stderr, "plutigo-replay: failed cases: %d\n", report.Summary.Failed)
+		code = 1
+	}
+	if comparison := report.Comparison; comparison != nil {
+		if maxMean > 0 && comparison.Mean.ChangePercent > maxMean {
+			fmt.Fprintf(
+				stderr,
+				"plutigo-replay: mean latency grew by %.1f%% (limit %.1f%%)\n",
+				comparison.Mean.ChangePercent,
+				maxMean,
+			)
+			code = 1
+		}
+		if maxCase > 0 {
+			if slower := comparison.Slower(maxCase); len(slower) > 0 {
+				fmt.Fprintf(
+					stderr,
+					"plutigo-replay: cases slower by more than %.1f%%: %d\n",
+					maxCase,
+					len(slower),
+				)
+				code = 1
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+func TestRunBench(t *testing.T) {
+	replayCase := commandReplayCase(t)
+	replayCase.Expected.ExUnits = replay.RunCase(&replayCase).Actual.ExUnits
+	corpus := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         []replay.Case{replayCase},
+	})
+
+	// A baseline that no evaluation can match.
+	fast, err := json.Marshal(replay.BenchReport{
+		SchemaVersion: replay.SchemaVersion,
+		Cases:         []replay.BenchCase{{ID: replayCase.ID, MeanNS: 1}},
+	})
+	if err != nil {
+		t.Fatalf("encode baseline: %v", err)
+	}
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(baseline, fast, 0o600); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"measure", []string{"-corpus", corpus, "-warmup", "1", "-count", "3"}, 0, ""},
+		{"compare without limits", []string{"-corpus", corpus, "-count", "2", "-baseline", baseline}, 0, ""},
+		{"mean beyond limit", []string{"-corpus", corpus, "-count", "2", "-baseline", baseline, "-max-mean-increase", "10"}, 1, "mean latency grew by"},
+		{"case beyond limit", []string{"-corpus", corpus, "-count", "2", "-baseline", baseline, "-max-case-increase", "10"}, 1, "cases slower by more than 10.0%: 1"},
+		{"limit without baseline", []string{"-corpus", corpus, "-max-case-increase", "10"}, 2, "latency limits need -baseline"},
+		{"no repetitions", []string{"-corpus", corpus, "-count", "0"}, 2, "-count must be at least 1"},
+		{"missing baseline", []string{"-corpus", corpus, "-baseline", filepath.Join(t.TempDir(), "none.json")}, 2, "open benchmark report"},
+		{"missing corpus", []string{"-count", "2"}, 2, "-corpus is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"bench"}, tt.args...)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+			if tt.code == 2 {
+				return
+			}
+			report, err := replay.LoadBenchReport(&stdout)
+			if err != nil {
+				t.Fatalf("decode benchmark report: %v", err)
+			}
+			if len(report.Cases) != 1 || !report.Cases[0].Passed ||
+				(report.Comparison != nil) != strings.Contains(strings.Join(tt.args, " "), "-baseline") {
+				t.Errorf("benchmark report = %+v", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// runCollect implements "plutigo-replay collect". It builds the cases of a
+// transaction from files, adds them to the corpus at -out, creating it if
+// needed, and prints their IDs.
+func runCollect(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo-replay collect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo-replay collect -tx FILE -utxos FILE -params FILE -out FILE [flags]")
+		flags.PrintDefaults()
+	}
+	var txPath, utxosPath, paramsPath, costsPath, outPath string
+	var network, block string
+	var slot uint64
+	var reference replay.Reference
+	flags.StringVar(&txPath, "tx", "", "path to the transaction: a cardano-cli text envelope, hex or raw CBOR")
+	flags.StringVar(&utxosPath, "utxos", "", "path to the UTxOs the transaction spends and references: Ogmios or cardano-cli query utxo JSON")
+	flags.StringVar(&paramsPath, "params", "", "path to the protocol parameters JSON file (cardano-cli, Ogmios or Blockfrost)")
+	flags.StringVar(&costsPath, "costs", "", "path to the output of cardano-cli transaction calculate-plutus-script-cost, the reference ExUnits")
+	flags.StringVar(&outPath, "out", "", "path of the corpus to write, as JSON Lines if it ends in .jsonl; cases are added to an existing corpus")
+	flags.StringVar(&network, "network", "mainnet", "network of the transaction and its slot configuration: mainnet, preprod or preview")
+	flags.Uint64Var(&slot, "slot", 0, "slot of the block holding the transaction, if known")
+	flags.StringVar(&block, "block", "", "hash of the block holding the transaction, if known")
+	flags.StringVar(&reference.Implementation, "reference", "", `reference implementation of a new corpus (default "cardano-node" with -costs, otherwise "plutigo")`)
+	flags.StringVar(&reference.Version, "reference-version", "", "reference version of a new corpus, such as the cardano-node version; required with -costs")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if txPath == "" || utxosPath == "" || paramsPath == "" || outPath == "" {
+		fmt.Fprintln(stderr, "plutigo-replay: collect needs -tx, -utxos, -params and -out")
+		return 2
+	}
+	slots, ok := map[string]ledger.SlotConfig{
+		"mainnet": ledger.MainnetSlotConfig,
+		"preprod": ledger.PreprodSlotConfig,
+		"preview": ledger.PreviewSlotConfig,
+	}[network]
+	if !ok {
+		fmt.Fprintf(stderr, "plutigo-replay: unknown network %q\n", network)
+		return 2
+	}
+	if reference.Implementation == "" {
+		reference.Implementation = "plutigo"
+		if costsPath != "" {
+			reference.Implementation = "cardano-node"
+		}
+	}
+	if reference.Version == "" {
+		if costsPath != "" {
+			fmt.Fprintln(stderr, "plutigo-replay: -costs needs -reference-version")
+			return 2
+		}
+		reference.Version = "(devel)"
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+			reference.Version = info.Main.Version
+		}
+	}
+
+	bundle := &replay.Bundle{Slots: slots, Slot: slot, Block: block}
+	var err error
+	if bundle.Transaction, err = readTransaction(txPath); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if bundle.UTxOs, err = ledger.LoadUTxOFile(utxosPath); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if bundle.Parameters, err = ledger.LoadParametersFile(paramsPath); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if costsPath != "" {
+		if bundle.Costs, err = replay.LoadScriptCostsFile(costsPath); err != nil {
+			fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+			return 2
+		}
+	}
+	cases, err := replay.Collect(bundle)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+
+	corpus, err := loadOrCreateCorpus(outPath, network, reference)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	corpus.Cases = append(corpus.Cases, cases...)
+	if err := corpus.Validate(); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if err := replay.WriteFile(outPath, corpus); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	for _, c := range cases {
+		fmt.Fprintln(stdout, c.ID)
+	}
+	return 0
+}
+
+// readTransaction reads a transaction file as cardano-cli writes it, a
+// text envelope with a cborHex field, or as hex or raw CBOR.
+func readTransaction(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read transaction: %w", err)
+	}
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var envelope struct {
+			CBORHex string `json:"cborHex"`
+		}
+		if err := json.Unmarshal(trimmed, &envelope); err != nil {
+			return nil, fmt.Errorf("decode transaction envelope: %w", err)
+		}
+		trimmed = []byte(envelope.CBORHex)
+	}
+	if decoded, err := hex.DecodeString(string(trimmed)); err == nil && len(decoded) > 0 {
+		return decoded, nil
+	}
+	return b, nil
+}
+
+// loadOrCreateCorpus loads the corpus at path, which must be of network and
+// reference implementation, or returns an empty one if there is none yet.
+func loadOrCreateCorpus(path, network string, reference replay.Reference) (*replay.Corpus, error) {
+	source, err := replay.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &replay.Corpus{
+			SchemaVersion: replay.SchemaVersion,
+			Network:       network,
+			Reference:     reference,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	header := source.Header()
+	if header.Network != network {
+		return nil, fmt.Errorf("corpus %q is for %s, not %s", path, header.Network, network)
+	}
+	// Cases that expect plutigo's outcome do not belong with cardano-node
+	// references, and the other way around.
+	if header.Reference.Implementation != reference.Implementation {
+		return nil, fmt.Errorf(
+			"corpus %q has reference %q, not %q",
+			path,
+			header.Reference.Implementation,
+			reference.Implementation,
+		)
+	}
+	corpus := &replay.Corpus{
+		SchemaVersion: header.SchemaVersion,
+		Network:       header.Network,
+		Reference:     header.Reference,
+	}
+	for {
+		c, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return corpus, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		corpus.Cases = append(corpus.Cases, *c)
+	}
+}
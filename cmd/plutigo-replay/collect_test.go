@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/blinklabs-io/plutigo/replay"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// writeBundle writes a transaction that mints with an always succeeding
+// PlutusV3 policy, as a cardano-cli text envelope, and the cardano-cli
+// UTxO JSON of its input. It returns their paths and the policy's hash.
+func writeBundle(t *testing.T) (string, string, string) {
+	t.Helper()
+	program, err := syn.Parse(`(program 1.1.0 (lam ctx (con unit ())))`)
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("convert policy: %v", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		t.Fatalf("encode policy: %v", err)
+	}
+	script, err := cbor.Marshal(flat)
+	if err != nil {
+		t.Fatalf("wrap policy: %v", err)
+	}
+	h, _ := blake2b.New(28, nil)
+	h.Write([]byte{3})
+	h.Write(script)
+	policy := h.Sum(nil)
+
+	txID := bytes.Repeat([]byte{0x01}, 32)
+	tx, err := cbor.Marshal([]any{
+		map[uint64]any{
+			0: []any{[]any{txID, 0}},
+			1: []any{},
+			2: 0,
+			9: map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(policy): {"a": 1}},
+		},
+		map[uint64]any{
+			5: []any{[]any{1, 0, 0, []any{0, 0}}},
+			7: []any{script},
+		},
+		true,
+		nil,
+	})
+	if err != nil {
+		t.Fatalf("encode transaction: %v", err)
+	}
+	dir := t.TempDir()
+	txPath := filepath.Join(dir, "tx.json")
+	envelope := `{"type": "Tx ConwayEra", "description": "", "cborHex": "` + hex.EncodeToString(tx) + `"}`
+	if err := os.WriteFile(txPath, []byte(envelope), 0o600); err != nil {
+		t.Fatalf("write transaction: %v", err)
+	}
+	utxosPath := filepath.Join(dir, "utxos.json")
+	utxos := `{"` + hex.EncodeToString(txID) + `#0": {
+		"address": "addr1vypsxqcrqvpsxqcrqvpsxqcrqvpsxqcrqvpsxqcrqvpsxqc9rrquz",
+		"value": {"lovelace": 2000000}
+	}}`
+	if err := os.WriteFile(utxosPath, []byte(utxos), 0o600); err != nil {
+		t.Fatalf("write UTxOs: %v", err)
+	}
+	return txPath, utxosPath, hex.EncodeToString(policy)
+}
+
+func TestRunCollect(t *testing.T) {
+	txPath, utxosPath, policy := writeBundle(t)
+	params := writeParams(t, 10_000_000_000)
+	dir := t.TempDir()
+	costs := filepath.Join(dir, "costs.json")
+	encoded, err := json.Marshal([]map[string]any{{
+		"executionUnits": map[string]any{"memory": 100, "steps": 16_100},
+		"lovelaceCost":   1,
+		"scriptHash":     policy,
+	}})
+	if err != nil {
+		t.Fatalf("encode costs: %v", err)
+	}
+	if err := os.WriteFile(costs, encoded, 0o600); err != nil {
+		t.Fatalf("write costs: %v", err)
+	}
+	bundle := []string{"-tx", txPath, "-utxos", utxosPath, "-params", params}
+	plutigo := filepath.Join(dir, "plutigo.jsonl")
+	node := filepath.Join(dir, "node.json")
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"without costs", append([]string{"-out", plutigo}, bundle...), 0, ""},
+		{"duplicate", append([]string{"-out", plutigo}, bundle...), 2, "duplicate id"},
+		{"with costs", append([]string{"-out", node, "-costs", costs, "-reference-version", "10.5.1"}, bundle...), 0, ""},
+		{"mixed references", append([]string{"-out", plutigo, "-costs", costs, "-reference-version", "10.5.1"}, bundle...), 2, `has reference "plutigo", not "cardano-node"`},
+		{"other network", append([]string{"-out", node, "-network", "preview"}, bundle...), 2, "is for mainnet, not preview"},
+		{"costs without version", append([]string{"-out", node, "-costs", costs}, bundle...), 2, "-costs needs -reference-version"},
+		{"unknown network", append([]string{"-out", node, "-network", "sanchonet"}, bundle...), 2, `unknown network "sanchonet"`},
+		{"missing files", []string{"-out", node, "-tx", txPath}, 2, "collect needs -tx, -utxos, -params and -out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"collect"}, tt.args...)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+			if tt.code == 0 && !strings.HasSuffix(strings.TrimSpace(stdout.String()), "#mint:0") {
+				t.Errorf("run() stdout = %q", stdout.String())
+			}
+		})
+	}
+
+	// The collected corpora replay: the plutigo one passes and the
+	// reference one reports the difference from the costs.
+	for path, code := range map[string]int{plutigo: 0, node: 1} {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		if got := run([]string{"-corpus", path}, &stdout, &stderr); got != code {
+			t.Errorf("run(-corpus %s) code = %d, want %d; stderr=%s", filepath.Base(path), got, code, stderr.String())
+		}
+	}
+	corpus, err := replay.LoadFile(node)
+	if err != nil {
+		t.Fatalf("load collected corpus: %v", err)
+	}
+	if corpus.Reference != (replay.Reference{Implementation: "cardano-node", Version: "10.5.1"}) ||
+		len(corpus.Cases) != 1 || corpus.Cases[0].Expected.ExUnits.Steps != 16_100 {
+		t.Errorf("collected corpus = %+v", corpus)
+	}
+}
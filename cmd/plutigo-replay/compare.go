@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// runCompare implements "plutigo-replay compare OLD NEW". It exits with 1
+// when a case regressed, a case of OLD is missing from NEW without
+// -allow-removed, or a latency percentile grew beyond its limit.
+func runCompare(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo-replay compare", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo-replay compare [flags] OLD.json NEW.json")
+		flags.PrintDefaults()
+	}
+	var pretty, allowRemoved bool
+	var maxMedian, maxP95 float64
+	flags.BoolVar(&pretty, "pretty", false, "indent the JSON comparison")
+	flags.BoolVar(&allowRemoved, "allow-removed", false, "do not fail when cases of the old report are missing from the new one")
+	flags.Float64Var(&maxMedian, "max-median-increase", 0, "fail when median latency grows by more than this percentage (0 disables)")
+	flags.Float64Var(&maxP95, "max-p95-increase", 0, "fail when p95 latency grows by more than this percentage (0 disables)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "plutigo-replay: compare needs an old and a new report")
+		return 2
+	}
+	if maxMedian < 0 || maxP95 < 0 {
+		fmt.Fprintln(stderr, "plutigo-replay: latency limits must not be negative")
+		return 2
+	}
+
+	oldReport, err := replay.LoadReportFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	newReport, err := replay.LoadReportFile(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	comparison, err := replay.CompareReports(oldReport, newReport)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(comparison); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: encode comparison: %v\n", err)
+		return 2
+	}
+
+	code := 0
+	if comparison.Regressed > 0 {
+		fmt.Fprintf(stderr, "plutigo-replay: regressed cases: %d\n", comparison.Regressed)
+		code = 1
+	}
+	if len(comparison.Removed) > 0 && !allowRemoved {
+		fmt.Fprintf(stderr, "plutigo-replay: removed cases: %d\n", len(comparison.Removed))
+		code = 1
+	}
+	for _, limit := range []struct {
+		name   string
+		change replay.DurationChange
+		max    float64
+	}{
+		{"median", comparison.Latency.Median, maxMedian},
+		{"p95", comparison.Latency.P95, maxP95},
+	} {
+		if limit.max > 0 && limit.change.ChangePercent > limit.max {
+			fmt.Fprintf(
+				stderr,
+				"plutigo-replay: %s latency grew by %.1f%% (limit %.1f%%)\n",
+				limit.name,
+				limit.change.ChangePercent,
+				limit.max,
+			)
+			code = 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+func writeReport(t *testing.T, name, id string, p95 int64, passed bool) string {
+	t.Helper()
+	encoded, err := json.Marshal(replay.Report{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases: []replay.CaseResult{{
+			ID:     id,
+			Passed: passed,
+		}},
+		Summary: replay.Summary{P95DurationNS: p95},
+	})
+	if err != nil {
+		t.Fatalf("encode report: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	return path
+}
+
+func TestRunCompare(t *testing.T) {
+	const id = "tx-command#spend:0"
+	baseline := writeReport(t, "old.json", id, 1000, true)
+	slower := writeReport(t, "slower.json", id, 1200, true)
+	regressed := writeReport(t, "regressed.json", id, 1000, false)
+	replaced := writeReport(t, "replaced.json", "tx-command#spend:1", 1000, true)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"unchanged", []string{baseline, baseline}, 0, ""},
+		{"slower without limit", []string{baseline, slower}, 0, ""},
+		{"slower within limit", []string{"-max-p95-increase", "25", baseline, slower}, 0, ""},
+		{"slower beyond limit", []string{"-max-p95-increase", "10", baseline, slower}, 1, "p95 latency grew by 20.0%"},
+		{"regressed", []string{baseline, regressed}, 1, "regressed cases: 1"},
+		{"removed", []string{baseline, replaced}, 1, "removed cases: 1"},
+		{"removed allowed", []string{"-allow-removed", baseline, replaced}, 0, ""},
+		{"one report", []string{baseline}, 2, "needs an old and a new report"},
+		{"missing report", []string{baseline, filepath.Join(t.TempDir(), "none.json")}, 2, "open replay report"},
+		{"negative limit", []string{"-max-p95-increase", "-1", baseline, slower}, 2, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"compare"}, tt.args...)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+			if tt.code == 2 {
+				return
+			}
+			var comparison replay.Comparison
+			if err := json.Unmarshal(stdout.Bytes(), &comparison); err != nil {
+				t.Fatalf("decode comparison: %v\n%s", err, stdout.String())
+			}
+		})
+	}
+}
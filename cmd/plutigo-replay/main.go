@@ -1,3 +1,10 @@
+// Command plutigo-replay runs a replay corpus and prints a JSON report of
+// each case's outcome, ExUnits and latency. "plutigo-replay compare" diffs
+// two such reports, "plutigo-replay whatif" re-runs a corpus under proposed
+// protocol parameters, "plutigo-replay minimize" cuts a corpus down to the
+// cases that cover distinct code paths, "plutigo-replay bench" measures
+// repeated evaluations of each case, and "plutigo-replay collect" builds the
+// cases of a transaction from offline files.
 package main
 
 import (
@@ -34,12 +41,43 @@ func runContext(
 	args []string,
 	stdout, stderr io.Writer,
 ) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "compare":
+			return runCompare(args[1:], stdout, stderr)
+		case "whatif":
+			return runWhatIf(ctx, args[1:], stdout, stderr)
+		case "minimize":
+			return runMinimize(ctx, args[1:], stdout, stderr)
+		case "bench":
+			return runBench(ctx, args[1:], stdout, stderr)
+		case "collect":
+			return runCollect(args[1:], stdout, stderr)
+		}
+	}
+
 	flags := flag.NewFlagSet("plutigo-replay", flag.ContinueOnError)
 	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage of plutigo-replay:")
+		fmt.Fprintln(stderr, "  plutigo-replay -corpus FILE [flags]")
+		fmt.Fprintln(stderr, "  plutigo-replay compare [flags] OLD.json NEW.json")
+		fmt.Fprintln(stderr, "  plutigo-replay whatif -corpus FILE -params FILE [flags]")
+		fmt.Fprintln(stderr, "  plutigo-replay minimize -corpus FILE -out FILE [flags]")
+		fmt.Fprintln(stderr, "  plutigo-replay bench -corpus FILE [flags]")
+		fmt.Fprintln(stderr, "  plutigo-replay collect -tx FILE -utxos FILE -params FILE -out FILE [flags]")
+		flags.PrintDefaults()
+	}
 	var corpusPath string
 	var pretty bool
-	flags.StringVar(&corpusPath, "corpus", "", "path to a replay corpus JSON file")
+	var format string
+	var jobs int
+	flags.StringVar(&corpusPath, "corpus", "", "path to a replay corpus: a JSON or JSON Lines file, or a directory of them")
 	flags.BoolVar(&pretty, "pretty", false, "indent the JSON report")
+	flags.StringVar(&format, "format", "json", "report format: json, junit, markdown or csv")
+	flags.IntVar(&jobs, "jobs", 1, "number of cases to evaluate concurrently")
+	var selection replay.Selection
+	selecting := selectionFlags(flags, &selection)
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return 0
@@ -51,24 +89,57 @@ func runContext(
 		fmt.Fprintln(stderr, "plutigo-replay: -corpus is required")
 		return 2
 	}
+	writeReport, ok := map[string]func(io.Writer, *replay.Report) error{
+		"json": func(w io.Writer, report *replay.Report) error {
+			encoder := json.NewEncoder(w)
+			if pretty {
+				encoder.SetIndent("", "  ")
+			}
+			return encoder.Encode(report)
+		},
+		"junit":    replay.WriteJUnit,
+		"markdown": replay.WriteMarkdown,
+		"csv": func(w io.Writer, report *replay.Report) error {
+			// Read the corpus again for the script hashes rather than keep
+			// every case of the run.
+			source, err := replay.Open(corpusPath)
+			if err != nil {
+				return err
+			}
+			defer source.Close()
+			return replay.WriteCSV(w, report, source)
+		},
+	}[format]
+	if !ok {
+		fmt.Fprintf(stderr, "plutigo-replay: unknown report format %q\n", format)
+		return 2
+	}
+	if jobs < 1 {
+		fmt.Fprintln(stderr, "plutigo-replay: -jobs must be at least 1")
+		return 2
+	}
 
-	corpus, err := replay.LoadFile(corpusPath)
+	source, err := replay.Open(corpusPath)
 	if err != nil {
 		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
 		return 2
 	}
-	report, err := replay.Run(ctx, corpus)
+	defer source.Close()
+	if selecting() {
+		source = replay.Select(source, selection)
+	}
+	report, err := replay.RunSource(
+		ctx,
+		source,
+		replay.Options{Jobs: jobs},
+	)
 	if err != nil {
 		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
 		return 2
 	}
 
-	encoder := json.NewEncoder(stdout)
-	if pretty {
-		encoder.SetIndent("", "  ")
-	}
-	if err := encoder.Encode(report); err != nil {
-		fmt.Fprintf(stderr, "plutigo-replay: encode report: %v\n", err)
+	if err := writeReport(stdout, report); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: write report: %v\n", err)
 		return 2
 	}
 	if report.Summary.Failed > 0 {
@@ -150,6 +150,134 @@ func TestRunReturnsMismatchExitCode(t *testing.T) {
 	}
 }
 
+func TestRunJobs(t *testing.T) {
+	replayCase := commandReplayCase(t)
+	replayCase.Expected.ExUnits = replay.RunCase(&replayCase).Actual.ExUnits
+	mismatch := replayCase
+	mismatch.ID = "tx-command#spend:1"
+	mismatch.Expected.ExUnits = replay.ExUnits{}
+	corpusPath := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         []replay.Case{replayCase, mismatch},
+	})
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := run(
+		[]string{"-corpus", corpusPath, "-jobs", "2"},
+		&stdout,
+		&stderr,
+	); code != 1 {
+		t.Fatalf(
+			"run() code = %d, want 1; stderr=%s",
+			code,
+			stderr.String(),
+		)
+	}
+	var report replay.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, stdout.String())
+	}
+	if len(report.Cases) != 2 ||
+		!report.Cases[0].Passed ||
+		report.Cases[1].Passed {
+		t.Fatalf("report cases = %+v", report.Cases)
+	}
+
+	stderr.Reset()
+	if code := run(
+		[]string{"-corpus", corpusPath, "-jobs", "0"},
+		&stdout,
+		&stderr,
+	); code != 2 {
+		t.Fatalf("run() code = %d, want 2", code)
+	}
+	if got := stderr.String(); !strings.Contains(got, "-jobs must be at least 1") {
+		t.Fatalf("run() stderr = %q, want jobs error", got)
+	}
+}
+
+func TestRunJSONLinesCorpus(t *testing.T) {
+	replayCase := commandReplayCase(t)
+	replayCase.Expected.ExUnits = replay.RunCase(&replayCase).Actual.ExUnits
+	corpus := replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+	}
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, record := range []any{corpus.Header(), replayCase} {
+		if err := encoder.Encode(record); err != nil {
+			t.Fatalf("encode corpus: %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	if err := os.WriteFile(path, lines.Bytes(), 0o600); err != nil {
+		t.Fatalf("write corpus: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := run([]string{"-corpus", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf(
+			"run() code = %d, want 0; stderr=%s",
+			code,
+			stderr.String(),
+		)
+	}
+	var report replay.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, stdout.String())
+	}
+	if report.Summary.Passed != 1 {
+		t.Fatalf("report summary = %+v", report.Summary)
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	corpusPath := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         []replay.Case{commandReplayCase(t)},
+	})
+	tests := []struct {
+		format string
+		code   int
+		want   string
+	}{
+		{"junit", 1, `<testcase name="tx-command#spend:0" classname="tx-command"`},
+		{"markdown", 1, "| tx-command#spend:0 |"},
+		{"csv", 1, "tx-command#spend:0,tx-command,spend:0,9eb420ad4a8eb3d63d7c3d578b97ac23361626ac1b5688aad376e127,false,true,"},
+		{"yaml", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			code := run(
+				[]string{"-corpus", corpusPath, "-format", tt.format},
+				&stdout,
+				&stderr,
+			)
+			if code != tt.code {
+				t.Fatalf(
+					"run() code = %d, want %d; stderr=%s",
+					code,
+					tt.code,
+					stderr.String(),
+				)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("run() stdout = %s, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
 func commandReference() replay.Reference {
 	return replay.Reference{
 		Implementation: "cardano-node",
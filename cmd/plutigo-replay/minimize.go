@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// runMinimize implements "plutigo-replay minimize". It writes the minimized
+// corpus to -out and prints its coverage groups as JSON.
+func runMinimize(
+	ctx context.Context,
+	args []string,
+	stdout, stderr io.Writer,
+) int {
+	flags := flag.NewFlagSet("plutigo-replay minimize", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo-replay minimize -corpus FILE -out FILE [flags]")
+		flags.PrintDefaults()
+	}
+	var corpusPath, outPath string
+	var pretty bool
+	var jobs int
+	flags.StringVar(&corpusPath, "corpus", "", "path to a replay corpus: a JSON or JSON Lines file, or a directory of them")
+	flags.StringVar(&outPath, "out", "", "path to write the minimized corpus to, as JSON Lines if it ends in .jsonl")
+	flags.BoolVar(&pretty, "pretty", false, "indent the JSON coverage groups")
+	flags.IntVar(&jobs, "jobs", 1, "number of cases to evaluate concurrently")
+	var selection replay.Selection
+	selecting := selectionFlags(flags, &selection)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if corpusPath == "" || outPath == "" {
+		fmt.Fprintln(stderr, "plutigo-replay: minimize needs -corpus and -out")
+		return 2
+	}
+	if jobs < 1 {
+		fmt.Fprintln(stderr, "plutigo-replay: -jobs must be at least 1")
+		return 2
+	}
+
+	source, err := replay.Open(corpusPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	defer source.Close()
+	if selecting() {
+		source = replay.Select(source, selection)
+	}
+	minimized, err := replay.Minimize(ctx, source, replay.Options{Jobs: jobs})
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	if err := replay.WriteFile(outPath, minimized.Corpus); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(minimized); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: encode coverage groups: %v\n", err)
+		return 2
+	}
+	return 0
+}
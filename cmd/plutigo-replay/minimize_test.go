@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+func TestRunMinimize(t *testing.T) {
+	first := commandReplayCase(t)
+	second := commandReplayCase(t)
+	second.ID = "tx-command#spend:1"
+	corpus := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         []replay.Case{first, second},
+	})
+	out := filepath.Join(t.TempDir(), "minimized.jsonl")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	args := []string{"minimize", "-corpus", corpus, "-out", out}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() code = %d; stderr=%s", code, stderr.String())
+	}
+	var minimized replay.Minimization
+	if err := json.Unmarshal(stdout.Bytes(), &minimized); err != nil {
+		t.Fatalf("decode coverage groups: %v\n%s", err, stdout.String())
+	}
+	if minimized.Total != 2 || minimized.Kept != 1 || len(minimized.Groups) != 1 {
+		t.Errorf("coverage groups = %+v", minimized)
+	}
+
+	source, err := replay.Open(out)
+	if err != nil {
+		t.Fatalf("Open() minimized corpus failed: %v", err)
+	}
+	defer source.Close()
+	replayCase, err := source.Next()
+	if err != nil || replayCase.ID != first.ID {
+		t.Fatalf("minimized corpus first case = %v, %v", replayCase, err)
+	}
+	if _, err := source.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("minimized corpus has more than one case: %v", err)
+	}
+}
+
+func TestRunMinimizeRequiresOut(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := run([]string{"minimize", "-corpus", "corpus.json"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("run() code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "needs -corpus and -out") {
+		t.Errorf("run() stderr = %q", stderr.String())
+	}
+}
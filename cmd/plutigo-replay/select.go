@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// selectionFlags registers the case selection flags on flags. The returned
+// function reports whether any of them was set.
+func selectionFlags(flags *flag.FlagSet, sel *replay.Selection) func() bool {
+	set := false
+	flags.Func("id", "run only cases whose id matches `regexp`", func(value string) error {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		sel.ID, set = re, true
+		return nil
+	})
+	flags.Func("language", "run only cases of these comma-separated `languages`, such as PlutusV2", func(value string) error {
+		for _, name := range splitList(value) {
+			language := replay.Language(name)
+			if _, err := language.Version(); err != nil {
+				return err
+			}
+			sel.Languages = append(sel.Languages, language)
+		}
+		set = true
+		return nil
+	})
+	flags.Func("protocol-version", "run only cases of these comma-separated protocol `versions`, MAJOR or MAJOR.MINOR", func(value string) error {
+		for _, version := range splitList(value) {
+			major, minor, hasMinor := strings.Cut(version, ".")
+			parsedMajor, err := strconv.ParseUint(major, 10, 0)
+			if err != nil {
+				return fmt.Errorf("invalid protocol version %q", version)
+			}
+			if !hasMinor {
+				sel.ProtocolMajors = append(sel.ProtocolMajors, uint(parsedMajor))
+				continue
+			}
+			parsedMinor, err := strconv.ParseUint(minor, 10, 0)
+			if err != nil {
+				return fmt.Errorf("invalid protocol version %q", version)
+			}
+			sel.ProtocolVersions = append(sel.ProtocolVersions, replay.ProtocolVersion{
+				Major: uint(parsedMajor),
+				Minor: uint(parsedMinor),
+			})
+		}
+		set = true
+		return nil
+	})
+	flags.Func("tx", "run only cases of these comma-separated transaction `ids`", func(value string) error {
+		sel.TransactionIDs = append(sel.TransactionIDs, splitList(value)...)
+		set = true
+		return nil
+	})
+	flags.Func("expect", "run only cases whose expected `outcome` is success or failure", func(value string) error {
+		var success bool
+		switch value {
+		case "success":
+			success = true
+		case "failure":
+		default:
+			return errors.New("want success or failure")
+		}
+		sel.ExpectedSuccess, set = &success, true
+		return nil
+	})
+	flags.Func("shard", "run only shard `i/n` of the corpus, counting from 1", func(value string) error {
+		shard, shards, ok := strings.Cut(value, "/")
+		i, err := strconv.Atoi(shard)
+		if !ok || err != nil {
+			return errors.New("want i/n")
+		}
+		n, err := strconv.Atoi(shards)
+		if err != nil || n < 1 || i < 1 || i > n {
+			return errors.New("want i/n with 1 <= i <= n")
+		}
+		sel.Shard, sel.Shards, set = i, n, true
+		return nil
+	})
+	return func() bool { return set }
+}
+
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+func TestSelectionFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-id", "("}, "missing closing )"},
+		{[]string{"-language", "PlutusV9"}, `unsupported Plutus language "PlutusV9"`},
+		{[]string{"-protocol-version", "10.x"}, `invalid protocol version "10.x"`},
+		{[]string{"-expect", "maybe"}, "want success or failure"},
+		{[]string{"-shard", "3"}, "want i/n"},
+		{[]string{"-shard", "0/2"}, "want i/n with 1 <= i <= n"},
+		{[]string{"-shard", "3/2"}, "want i/n with 1 <= i <= n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			var selection replay.Selection
+			selectionFlags(flags, &selection)
+			err := flags.Parse(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	var selection replay.Selection
+	selecting := selectionFlags(flags, &selection)
+	if selecting() {
+		t.Error("selecting() = true before parsing")
+	}
+	err := flags.Parse([]string{
+		"-language", "PlutusV1, PlutusV3",
+		"-protocol-version", "9,10.2",
+		"-tx", "aa,bb",
+		"-expect", "failure",
+		"-shard", "2/3",
+	})
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if !selecting() ||
+		!slices.Equal(selection.Languages, []replay.Language{replay.PlutusV1, replay.PlutusV3}) ||
+		!slices.Equal(selection.ProtocolMajors, []uint{9}) ||
+		!slices.Equal(selection.ProtocolVersions, []replay.ProtocolVersion{{Major: 10, Minor: 2}}) ||
+		!slices.Equal(selection.TransactionIDs, []string{"aa", "bb"}) ||
+		selection.ExpectedSuccess == nil || *selection.ExpectedSuccess ||
+		selection.Shard != 2 || selection.Shards != 3 {
+		t.Errorf("selection = %+v", selection)
+	}
+}
+
+func TestRunShards(t *testing.T) {
+	var cases []replay.Case
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		replayCase := commandReplayCase(t)
+		replayCase.ID = id
+		cases = append(cases, replayCase)
+	}
+	corpusPath := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         cases,
+	})
+
+	var ids []string
+	for _, shard := range []string{"1/2", "2/2"} {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		code := run([]string{"-corpus", corpusPath, "-shard", shard}, &stdout, &stderr)
+		if code == 2 {
+			t.Fatalf("run(-shard %s) failed: %s", shard, stderr.String())
+		}
+		var report replay.Report
+		if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+			t.Fatalf("decode report: %v\n%s", err, stdout.String())
+		}
+		for _, result := range report.Cases {
+			ids = append(ids, result.ID)
+		}
+	}
+	slices.Sort(ids)
+	if strings.Join(ids, "") != "abcdef" {
+		t.Errorf("shards ran %v, want every case once", ids)
+	}
+}
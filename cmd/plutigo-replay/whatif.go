@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// runWhatIf implements "plutigo-replay whatif". It exits with 1 when the
+// scenario makes a case fail or exceed a limit it met before.
+func runWhatIf(
+	ctx context.Context,
+	args []string,
+	stdout, stderr io.Writer,
+) int {
+	flags := flag.NewFlagSet("plutigo-replay whatif", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo-replay whatif -corpus FILE -params FILE [flags]")
+		flags.PrintDefaults()
+	}
+	var corpusPath, paramsPath, substitute string
+	var pretty bool
+	var jobs int
+	flags.StringVar(&corpusPath, "corpus", "", "path to a replay corpus: a JSON or JSON Lines file, or a directory of them")
+	flags.StringVar(&paramsPath, "params", "", "path to the proposed protocol parameters JSON file (cardano-cli, Ogmios or Blockfrost)")
+	flags.StringVar(&substitute, "substitute", "cost-models,protocol-version", "comma-separated parameters to substitute: cost-models, protocol-version")
+	flags.BoolVar(&pretty, "pretty", false, "indent the JSON report")
+	flags.IntVar(&jobs, "jobs", 1, "number of cases to evaluate concurrently")
+	var selection replay.Selection
+	selecting := selectionFlags(flags, &selection)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if corpusPath == "" || paramsPath == "" {
+		fmt.Fprintln(stderr, "plutigo-replay: whatif needs -corpus and -params")
+		return 2
+	}
+	if jobs < 1 {
+		fmt.Fprintln(stderr, "plutigo-replay: -jobs must be at least 1")
+		return 2
+	}
+	params, err := ledger.LoadParametersFile(paramsPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	scenario, err := whatIfScenario(params, splitList(substitute))
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+
+	source, err := replay.Open(corpusPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+	defer source.Close()
+	if selecting() {
+		source = replay.Select(source, selection)
+	}
+	report, err := replay.WhatIf(ctx, source, scenario, replay.Options{Jobs: jobs})
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: %v\n", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(stderr, "plutigo-replay: encode what-if report: %v\n", err)
+		return 2
+	}
+	if report.Regressed() {
+		fmt.Fprintf(
+			stderr,
+			"plutigo-replay: newly failing cases: %d, over budget limit: %d, transactions over max tx ExUnits: %d\n",
+			report.Summary.NewlyFailing,
+			report.Summary.ExceedBudgetLimit,
+			report.Summary.ExceedMaxTxExUnits,
+		)
+		return 1
+	}
+	return 0
+}
+
+// whatIfScenario builds the scenario that substitutes the named parameters
+// of params. The per-transaction maximum is always checked when params sets
+// it.
+func whatIfScenario(
+	params *ledger.Parameters,
+	substitute []string,
+) (replay.Scenario, error) {
+	var scenario replay.Scenario
+	if len(substitute) == 0 {
+		return scenario, errors.New("-substitute must name at least one parameter")
+	}
+	for _, name := range substitute {
+		switch name {
+		case "cost-models":
+			if len(params.CostModels) == 0 {
+				return scenario, errors.New("protocol parameters have no cost models")
+			}
+			scenario.CostModels = make(map[replay.Language][]int64, len(params.CostModels))
+			for version, model := range params.CostModels {
+				language := replay.Language("PlutusV" + strconv.Itoa(int(version)))
+				scenario.CostModels[language] = model
+			}
+		case "protocol-version":
+			scenario.ProtocolVersion = &replay.ProtocolVersion{
+				Major: params.ProtocolVersion.Major,
+				Minor: params.ProtocolVersion.Minor,
+			}
+		default:
+			return scenario, fmt.Errorf("unknown -substitute parameter %q", name)
+		}
+	}
+	if params.MaxTxExUnits != (ledger.ExUnits{}) {
+		scenario.MaxTxExUnits = &replay.ExUnits{
+			Steps:  params.MaxTxExUnits.Steps,
+			Memory: params.MaxTxExUnits.Memory,
+		}
+	}
+	return scenario, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/replay"
+)
+
+// writeParams writes cardano-cli protocol parameters whose PlutusV1 machine
+// steps cost twice the default.
+func writeParams(t *testing.T, maxTxSteps int64) string {
+	t.Helper()
+	names := lang.GetParamNamesForVersion(lang.LanguageVersionV1)
+	model := make([]int64, len(names))
+	for i, name := range names {
+		switch {
+		case strings.HasSuffix(name, "-exBudgetCPU"):
+			model[i] = 32_000
+		case strings.HasSuffix(name, "-exBudgetMemory"):
+			model[i] = 200
+		default:
+			model[i] = 1
+		}
+	}
+	encoded, err := json.Marshal(map[string]any{
+		"costModels":             map[string]any{"PlutusV1": model},
+		"protocolVersion":        map[string]any{"major": 10, "minor": 0},
+		"maxTxExecutionUnits":    map[string]any{"memory": 14_000_000, "steps": maxTxSteps},
+		"maxBlockExecutionUnits": map[string]any{"memory": 62_000_000, "steps": 20_000_000_000},
+	})
+	if err != nil {
+		t.Fatalf("encode parameters: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "params.json")
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		t.Fatalf("write parameters: %v", err)
+	}
+	return path
+}
+
+func TestRunWhatIf(t *testing.T) {
+	corpus := writeCorpus(t, replay.Corpus{
+		SchemaVersion: replay.SchemaVersion,
+		Network:       "mainnet",
+		Reference:     commandReference(),
+		Cases:         []replay.Case{commandReplayCase(t)},
+	})
+	params := writeParams(t, 10_000_000_000)
+	// The case takes 64,100 steps with the default cost model.
+	tight := writeParams(t, 100_000)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"within limits", []string{"-corpus", corpus, "-params", params}, 0, ""},
+		{"over max tx", []string{"-corpus", corpus, "-params", tight}, 1, "transactions over max tx ExUnits: 1"},
+		{"protocol version only", []string{"-corpus", corpus, "-params", tight, "-substitute", "protocol-version"}, 0, ""},
+		{"missing params", []string{"-corpus", corpus}, 2, "needs -corpus and -params"},
+		{"unknown parameter", []string{"-corpus", corpus, "-params", params, "-substitute", "fees"}, 2, `unknown -substitute parameter "fees"`},
+		{"unreadable params", []string{"-corpus", corpus, "-params", corpus}, 2, "protocol parameters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"whatif"}, tt.args...)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+			if tt.code == 2 {
+				return
+			}
+			var report replay.WhatIfReport
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("decode what-if report: %v\n%s", err, stdout.String())
+			}
+			changed := tt.name != "protocol version only"
+			if len(report.Cases) != 1 || (report.Cases[0].Delta.Steps > 0) != changed {
+				t.Errorf("what-if report = %+v", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Command plutigo-server is a local stand-in for the evaluateTransaction
+// method of Ogmios. It serves Ogmios v6 JSON-RPC over HTTP (POST /) and
+// WebSocket (/) and evaluates transactions with plutigo against protocol
+// parameters and UTxOs read from files, without a node. WebSocket upgrades
+// from web pages of another origin are refused unless -allow-cross-origin
+// is given.
+//
+// Requests take the transaction as {"transaction": {"cbor": ...}} and may
+// add UTxOs in additionalUtxo. Successful responses list the budget of each
+// validator. Failures use the Ogmios error codes:
+//
+//   - 3002: an additional UTxO overlaps the -utxos set
+//   - 3010: some redeemers failed; data lists them with code 3012 for
+//     scripts that ran and failed, with their traces, and 3011 for redeemers
+//     whose script could not be run, such as a missing script or datum
+//   - 3117: inputs that no UTxO resolves
+//   - -32602: a malformed transaction or request
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/blinklabs-io/plutigo/ledger"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	code := runContext(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	return runContext(context.Background(), args, stdout, stderr)
+}
+
+func runContext(
+	ctx context.Context,
+	args []string,
+	stdout, stderr io.Writer,
+) int {
+	flags := flag.NewFlagSet("plutigo-server", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var listen, paramsPath, utxosPath, network string
+	var allowCrossOrigin bool
+	flags.StringVar(&listen, "listen", "127.0.0.1:1337", "address to listen on")
+	flags.StringVar(&paramsPath, "params", "", "path to a protocol parameters JSON file")
+	flags.StringVar(&utxosPath, "utxos", "", "path to a UTxO JSON file: an array of Ogmios UTxOs or cardano-cli query utxo output")
+	flags.StringVar(&network, "network", "mainnet", "slot configuration: mainnet, preprod or preview")
+	flags.BoolVar(&allowCrossOrigin, "allow-cross-origin", false, "accept WebSocket connections from web pages of other origins")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if paramsPath == "" {
+		fmt.Fprintln(stderr, "plutigo-server: -params is required")
+		return 2
+	}
+	slots, ok := map[string]ledger.SlotConfig{
+		"mainnet": ledger.MainnetSlotConfig,
+		"preprod": ledger.PreprodSlotConfig,
+		"preview": ledger.PreviewSlotConfig,
+	}[network]
+	if !ok {
+		fmt.Fprintf(stderr, "plutigo-server: unknown network %q\n", network)
+		return 2
+	}
+	params, err := ledger.LoadParametersFile(paramsPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-server: %v\n", err)
+		return 2
+	}
+	var utxos []ledger.UTxO
+	if utxosPath != "" {
+		if utxos, err = ledger.LoadUTxOFile(utxosPath); err != nil {
+			fmt.Fprintf(stderr, "plutigo-server: %v\n", err)
+			return 2
+		}
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo-server: %v\n", err)
+		return 2
+	}
+	s := newServer(params, utxos, slots)
+	s.allowCrossOrigin = allowCrossOrigin
+	srv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(s.closeWebSockets)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(stdout, "plutigo-server: listening on %s\n", listener.Addr())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "plutigo-server: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	params := filepath.Join(dir, "params.json")
+	err := os.WriteFile(params, []byte(`{"protocolVersion": {"major": 10, "minor": 0},
+		"maxTxExecutionUnits": {"memory": 14000000, "steps": 10000000000},
+		"maxBlockExecutionUnits": {"memory": 62000000, "steps": 20000000000},
+		"costModels": {}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := filepath.Join(dir, "utxos.json")
+	if err := os.WriteFile(utxos, []byte(`[{}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing params", nil, "-params is required"},
+		{"unknown network", []string{"-params", params, "-network", "sanchonet"}, `unknown network "sanchonet"`},
+		{"bad params", []string{"-params", filepath.Join(dir, "missing.json")}, "open protocol parameters"},
+		{"bad utxos", []string{"-params", params, "-utxos", utxos}, "UTxO 0: invalid transaction id"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(test.args, &stdout, &stderr); code != 2 {
+				t.Fatalf("run() code = %d, want 2", code)
+			}
+			if !strings.Contains(stderr.String(), test.want) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestRunHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-h"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() code = %d, want 0", code)
+	}
+	if !strings.Contains(stderr.String(), "Usage of plutigo-server:") {
+		t.Errorf("run() stderr = %q, want usage", stderr.String())
+	}
+}
+
+func TestRunShutdown(t *testing.T) {
+	params := filepath.Join(t.TempDir(), "params.json")
+	err := os.WriteFile(params, []byte(`{"protocol_major_ver": 10,
+		"max_tx_ex_mem": "1", "max_tx_ex_steps": "1",
+		"max_block_ex_mem": "1", "max_block_ex_steps": "1",
+		"cost_models_raw": {}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var stdout, stderr bytes.Buffer
+	code := runContext(ctx, []string{"-params", params, "-listen", "127.0.0.1:0"}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "listening on 127.0.0.1:") {
+		t.Errorf("runContext() = %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+}
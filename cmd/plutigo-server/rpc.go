@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+// maxMessageSize bounds HTTP request bodies and WebSocket messages.
+const maxMessageSize = 16 << 20
+
+// JSON-RPC and Ogmios error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+
+	codeOverlappingUTxO  = 3002
+	codeScriptFailures   = 3010
+	codeCannotEvaluate   = 3011
+	codeValidationFailed = 3012
+	codeUnknownInputs    = 3117
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type validator struct {
+	Purpose string `json:"purpose"`
+	Index   uint64 `json:"index"`
+}
+
+type budget struct {
+	Memory int64 `json:"memory"`
+	CPU    int64 `json:"cpu"`
+}
+
+type evaluation struct {
+	Validator validator `json:"validator"`
+	Budget    budget    `json:"budget"`
+}
+
+type validatorError struct {
+	Validator validator `json:"validator"`
+	Error     rpcError  `json:"error"`
+}
+
+type outRef struct {
+	Transaction struct {
+		ID string `json:"id"`
+	} `json:"transaction"`
+	Index uint64 `json:"index"`
+}
+
+func newOutRef(ref scriptcontext.TxOutRef) outRef {
+	var ret outRef
+	ret.Transaction.ID = hex.EncodeToString(ref.TxID)
+	ret.Index = ref.Index
+	return ret
+}
+
+// server evaluates transactions against fixed protocol parameters and a
+// local UTxO set, which requests can extend with additionalUtxo.
+type server struct {
+	params *ledger.Parameters
+	utxos  []ledger.UTxO
+	known  map[string]bool
+	slots  ledger.SlotConfig
+
+	// allowCrossOrigin accepts WebSocket upgrades from browser pages served
+	// by other origins.
+	allowCrossOrigin bool
+
+	mu    sync.Mutex
+	conns map[*wsConn]struct{}
+}
+
+func newServer(params *ledger.Parameters, utxos []ledger.UTxO, slots ledger.SlotConfig) *server {
+	s := &server{
+		params: params,
+		utxos:  utxos,
+		known:  make(map[string]bool),
+		slots:  slots,
+		conns:  make(map[*wsConn]struct{}),
+	}
+	for _, utxo := range utxos {
+		s.known[outRefKey(utxo.Input)] = true
+	}
+	return s
+}
+
+func outRefKey(ref scriptcontext.TxOutRef) string {
+	return fmt.Sprintf("%x#%d", ref.TxID, ref.Index)
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"connectionStatus":"connected","currentEra":"conway"}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketUpgrade(r) {
+			s.serveWebSocket(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "use POST or a WebSocket", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.handle(body))
+	})
+	return mux
+}
+
+func (s *server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.allowCrossOrigin && !sameOrigin(r) {
+		http.Error(w, "cross-origin WebSocket upgrade refused", http.StatusForbidden)
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	if !s.track(conn) {
+		conn.Close()
+		return
+	}
+	defer s.untrack(conn)
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(s.handle(message)); err != nil {
+			return
+		}
+	}
+}
+
+// track records a live WebSocket connection, so that closeWebSockets can
+// close it. It reports false once the server has shut down.
+func (s *server) track(conn *wsConn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conns == nil {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *server) untrack(conn *wsConn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// closeWebSockets closes the live WebSocket connections, which
+// http.Server.Shutdown does not track once they are hijacked, and refuses
+// new ones.
+func (s *server) closeWebSockets() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// handle answers one JSON-RPC request.
+func (s *server) handle(message []byte) []byte {
+	var req request
+	var resp response
+	if err := json.Unmarshal(message, &req); err != nil {
+		resp.Error = &rpcError{Code: codeParseError, Message: fmt.Sprintf("Invalid JSON: %v", err)}
+	} else {
+		resp.Method, resp.ID = req.Method, req.ID
+		switch {
+		case req.JSONRPC != "2.0":
+			resp.Error = &rpcError{Code: codeInvalidRequest, Message: `Invalid request: jsonrpc must be "2.0".`}
+		case req.Method != "evaluateTransaction":
+			resp.Error = &rpcError{
+				Code:    codeMethodNotFound,
+				Message: fmt.Sprintf("Unknown method %q; only evaluateTransaction is supported.", req.Method),
+			}
+		default:
+			resp.Result, resp.Error = s.evaluate(req.Params)
+		}
+	}
+	resp.JSONRPC = "2.0"
+	b, err := json.Marshal(resp)
+	if err != nil {
+		// Every field is plain data, so this cannot happen.
+		panic(err)
+	}
+	return b
+}
+
+func (s *server) evaluate(raw json.RawMessage) (any, *rpcError) {
+	var params struct {
+		Transaction struct {
+			CBOR string `json:"cbor"`
+		} `json:"transaction"`
+		AdditionalUTxO []json.RawMessage `json:"additionalUtxo"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("Invalid params: %v", err)}
+	}
+	txCBOR, err := hex.DecodeString(params.Transaction.CBOR)
+	if err != nil || len(txCBOR) == 0 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "Invalid transaction; expected hex-encoded CBOR."}
+	}
+
+	utxos := s.utxos
+	var overlapping []outRef
+	if len(params.AdditionalUTxO) > 0 {
+		utxos = append([]ledger.UTxO(nil), s.utxos...)
+		for i, item := range params.AdditionalUTxO {
+			utxo, err := ledger.DecodeOgmiosUTxO(item)
+			if err != nil {
+				return nil, &rpcError{
+					Code:    codeInvalidParams,
+					Message: fmt.Sprintf("Invalid additionalUtxo %d: %v", i, err),
+				}
+			}
+			if s.known[outRefKey(utxo.Input)] {
+				overlapping = append(overlapping, newOutRef(utxo.Input))
+			}
+			utxos = append(utxos, utxo)
+		}
+	}
+	if len(overlapping) > 0 {
+		return nil, &rpcError{
+			Code:    codeOverlappingUTxO,
+			Message: "Some user-provided additional UTxO entries overlap with those that exist in the ledger.",
+			Data:    map[string]any{"overlappingOutputReferences": overlapping},
+		}
+	}
+
+	results, err := ledger.EvaluateTransaction(txCBOR, utxos, s.params, s.slots)
+	var unknown *ledger.UnknownInputsError
+	switch {
+	case errors.As(err, &unknown):
+		refs := make([]outRef, 0, len(unknown.Inputs))
+		for _, ref := range unknown.Inputs {
+			refs = append(refs, newOutRef(ref))
+		}
+		return nil, &rpcError{
+			Code:    codeUnknownInputs,
+			Message: "The transaction contains unknown UTxO references as inputs.",
+			Data:    map[string]any{"unknownOutputReferences": refs},
+		}
+	case err != nil:
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("Invalid transaction; %v", err)}
+	}
+
+	evaluations := make([]evaluation, 0, len(results))
+	var failures []validatorError
+	for _, result := range results {
+		v := validator{Purpose: result.Redeemer.Tag.String(), Index: result.Redeemer.Index}
+		if result.Err == nil {
+			evaluations = append(evaluations, evaluation{
+				Validator: v,
+				Budget:    budget{Memory: result.ExUnits.Memory, CPU: result.ExUnits.Steps},
+			})
+			continue
+		}
+		failures = append(failures, validatorError{Validator: v, Error: redeemerError(result)})
+	}
+	if len(failures) > 0 {
+		return nil, &rpcError{
+			Code:    codeScriptFailures,
+			Message: "Some scripts of the transactions terminated with error(s).",
+			Data:    failures,
+		}
+	}
+	return evaluations, nil
+}
+
+// redeemerError describes a failed redeemer: scripts that ran and failed
+// carry their traces, other problems prevented the script from running.
+func redeemerError(result ledger.Result) rpcError {
+	var scriptErr *ledger.ScriptError
+	if errors.As(result.Err, &scriptErr) {
+		traces := result.Logs
+		if traces == nil {
+			traces = []string{}
+		}
+		return rpcError{
+			Code:    codeValidationFailed,
+			Message: "Some of the scripts failed to evaluate to a positive outcome.",
+			Data: map[string]any{
+				"validationError": scriptErr.Err.Error(),
+				"traces":          traces,
+			},
+		}
+	}
+	return rpcError{Code: codeCannotEvaluate, Message: sentence(result.Err.Error())}
+}
+
+// sentence capitalizes message and ends it with a full stop.
+func sentence(message string) string {
+	first, size := utf8.DecodeRuneInString(message)
+	if size == 0 {
+		return "Cannot evaluate the script."
+	}
+	return string(unicode.ToUpper(first)) + message[size:] + "."
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+var testParams = &ledger.Parameters{
+	ProtocolVersion: cek.ProtoVersion{Major: 10},
+	MaxTxExUnits:    ledger.ExUnits{Steps: 10_000_000_000, Memory: 14_000_000},
+}
+
+// encodeBech32 encodes test addresses for the UTxO JSON that ledger decodes.
+func encodeBech32(hrp string, b []byte) string {
+	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	var values []byte
+	acc, bits := uint32(0), 0
+	for _, c := range b {
+		acc = acc<<8 | uint32(c)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	var expanded []byte
+	for i := range len(hrp) {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := range len(hrp) {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	chk := uint32(1)
+	for _, v := range append(append(expanded, values...), 0, 0, 0, 0, 0, 0) {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3} {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	chk ^= 1
+	for i := range 6 {
+		values = append(values, byte(chk>>(5*(5-i)))&31)
+	}
+	var sb strings.Builder
+	sb.WriteString(hrp + "1")
+	for _, v := range values {
+		sb.WriteByte(charset[v])
+	}
+	return sb.String()
+}
+
+// plutusV3 returns the witness set bytes and hash of a PlutusV3 script.
+func plutusV3(t *testing.T, src string) ([]byte, []byte) {
+	t.Helper()
+	program, err := syn.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("NameToDeBruijn() failed: %v", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	script := mustMarshal(t, flat)
+	h, _ := blake2b.New(28, nil)
+	h.Write([]byte{3})
+	h.Write(script)
+	return script, h.Sum(nil)
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := cbor.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return b
+}
+
+type fixture struct {
+	tx   string
+	utxo map[string]any
+}
+
+// newFixture builds a transaction spending an output locked by a PlutusV3
+// script that is supplied in the witness set.
+func newFixture(t *testing.T, src string) fixture {
+	script, hash := plutusV3(t, src)
+	// Each script locks an output of its own.
+	txID := append(bytes.Repeat([]byte{0x01}, 4), hash...)
+	body := map[uint64]any{0: []any{[]any{txID, 0}}, 1: []any{}, 2: 0}
+	witnesses := map[uint64]any{
+		5: []any{[]any{0, 0, 0, []any{0, 0}}},
+		7: []any{script},
+	}
+	return fixture{
+		tx: hex.EncodeToString(mustMarshal(t, []any{body, witnesses, true, nil})),
+		utxo: map[string]any{
+			"transaction": map[string]any{"id": hex.EncodeToString(txID)},
+			"index":       0,
+			"address":     encodeBech32("addr", append([]byte{0x71}, hash...)),
+			"value":       map[string]any{"ada": map[string]any{"lovelace": 2_000_000}},
+		},
+	}
+}
+
+func evaluateRequest(t *testing.T, f fixture, additional ...map[string]any) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "evaluateTransaction",
+		"params": map[string]any{
+			"transaction":    map[string]any{"cbor": f.tx},
+			"additionalUtxo": additional,
+		},
+		"id": "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func decodeResponse(t *testing.T, b []byte) map[string]any {
+	t.Helper()
+	var resp map[string]any
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatalf("decode response %s: %v", b, err)
+	}
+	return resp
+}
+
+func errorCode(resp map[string]any) float64 {
+	if e, ok := resp["error"].(map[string]any); ok {
+		code, _ := e["code"].(float64)
+		return code
+	}
+	return 0
+}
+
+func TestEvaluateHTTP(t *testing.T) {
+	f := newFixture(t, `(program 1.1.0 (lam ctx (con unit ())))`)
+	srv := httptest.NewServer(newServer(testParams, nil, ledger.MainnetSlotConfig).handler())
+	defer srv.Close()
+
+	res, err := http.Post(srv.URL, "application/json", bytes.NewReader(evaluateRequest(t, f, f.utxo)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := decodeResponse(t, body)
+	if resp["id"] != "test" || resp["method"] != "evaluateTransaction" || resp["error"] != nil {
+		t.Fatalf("response = %s", body)
+	}
+	result, _ := resp["result"].([]any)
+	if len(result) != 1 {
+		t.Fatalf("result = %v", resp["result"])
+	}
+	item := result[0].(map[string]any)
+	validator := item["validator"].(map[string]any)
+	budget := item["budget"].(map[string]any)
+	if validator["purpose"] != "spend" || validator["index"] != 0.0 ||
+		budget["cpu"].(float64) <= 0 || budget["memory"].(float64) <= 0 {
+		t.Errorf("result = %v", item)
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	ok := newFixture(t, `(program 1.1.0 (lam ctx (con unit ())))`)
+	fail := newFixture(t, `(program 1.1.0 (lam ctx [(lam u (error)) [(force (builtin trace)) (con string "nope") (con unit ())]]))`)
+	local, err := ledger.DecodeOgmiosUTxO(mustJSON(t, ok.utxo))
+	if err != nil {
+		t.Fatalf("DecodeOgmiosUTxO() failed: %v", err)
+	}
+	s := newServer(testParams, []ledger.UTxO{local}, ledger.MainnetSlotConfig)
+
+	// The failing script reports its traces.
+	resp := decodeResponse(t, s.handle(evaluateRequest(t, fail, fail.utxo)))
+	if errorCode(resp) != codeScriptFailures {
+		t.Fatalf("failing script: response = %v", resp)
+	}
+	failures := resp["error"].(map[string]any)["data"].([]any)
+	failure := failures[0].(map[string]any)["error"].(map[string]any)
+	traces := failure["data"].(map[string]any)["traces"].([]any)
+	if failure["code"] != float64(codeValidationFailed) || len(traces) != 1 || traces[0] != "nope" {
+		t.Errorf("failure = %v", failure)
+	}
+
+	tests := []struct {
+		name    string
+		request []byte
+		code    int
+	}{
+		{"local utxo", evaluateRequest(t, ok), 0},
+		{"overlapping utxo", evaluateRequest(t, ok, ok.utxo), codeOverlappingUTxO},
+		{"unknown input", evaluateRequest(t, fail), codeUnknownInputs},
+		{"invalid transaction", evaluateRequest(t, fixture{tx: "80"}), codeInvalidParams},
+		{"not hex", evaluateRequest(t, fixture{tx: "zz"}), codeInvalidParams},
+		{"unknown method", []byte(`{"jsonrpc": "2.0", "method": "submitTransaction"}`), codeMethodNotFound},
+		{"not json-rpc", []byte(`{"method": "evaluateTransaction"}`), codeInvalidRequest},
+		{"invalid json", []byte(`{`), codeParseError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := decodeResponse(t, s.handle(test.request))
+			if errorCode(resp) != float64(test.code) {
+				t.Errorf("response = %v, want code %d", resp, test.code)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func writeClientFrame(t *testing.T, w io.Writer, opcode byte, payload []byte) {
+	t.Helper()
+	frame := []byte{0x80 | opcode}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	var mask [4]byte
+	_, _ = rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := w.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0f, payload
+}
+
+// dialWebSocket opens a WebSocket connection to srv, sending origin in the
+// handshake unless it is empty, and returns the handshake response.
+func dialWebSocket(t *testing.T, srv *httptest.Server, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	handshake := "GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(srv.URL, "http://") +
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: " + key +
+		"\r\nSec-WebSocket-Version: 13\r\n"
+	if origin != "" {
+		handshake += "Origin: " + origin + "\r\n"
+	}
+	if _, err := io.WriteString(conn, handshake+"\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, r, res
+}
+
+func TestEvaluateWebSocket(t *testing.T) {
+	f := newFixture(t, `(program 1.1.0 (lam ctx (con unit ())))`)
+	srv := httptest.NewServer(newServer(testParams, nil, ledger.MainnetSlotConfig).handler())
+	defer srv.Close()
+
+	conn, r, res := dialWebSocket(t, srv, srv.URL)
+	if res.StatusCode != http.StatusSwitchingProtocols ||
+		res.Header.Get("Sec-WebSocket-Accept") != "BACScCJPNqyz+UBoqMH89VmURoA=" {
+		t.Fatalf("handshake response = %v", res)
+	}
+
+	// A ping between requests is answered with a pong.
+	writeClientFrame(t, conn, opPing, []byte("hi"))
+	if opcode, payload := readServerFrame(t, r); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("got opcode %d %q, want pong", opcode, payload)
+	}
+	for range 2 {
+		writeClientFrame(t, conn, opText, evaluateRequest(t, f, f.utxo))
+		opcode, payload := readServerFrame(t, r)
+		resp := decodeResponse(t, payload)
+		if opcode != opText || resp["error"] != nil || len(resp["result"].([]any)) != 1 {
+			t.Errorf("response = %s", payload)
+		}
+	}
+	writeClientFrame(t, conn, opClose, nil)
+	if opcode, _ := readServerFrame(t, r); opcode != opClose {
+		t.Errorf("got opcode %d, want close", opcode)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	s := newServer(testParams, nil, ledger.MainnetSlotConfig)
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	if _, _, res := dialWebSocket(t, srv, "https://example.com"); res.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin handshake status = %d, want %d", res.StatusCode, http.StatusForbidden)
+	}
+	if _, _, res := dialWebSocket(t, srv, ""); res.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("handshake without Origin status = %d, want %d", res.StatusCode, http.StatusSwitchingProtocols)
+	}
+	s.allowCrossOrigin = true
+	if _, _, res := dialWebSocket(t, srv, "https://example.com"); res.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("allowed cross-origin handshake status = %d, want %d", res.StatusCode, http.StatusSwitchingProtocols)
+	}
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	s := newServer(testParams, nil, ledger.MainnetSlotConfig)
+	srv := httptest.NewUnstartedServer(s.handler())
+	srv.Config.RegisterOnShutdown(s.closeWebSockets)
+	srv.Start()
+	defer srv.Close()
+
+	conn, r, res := dialWebSocket(t, srv, "")
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake response = %v", res)
+	}
+	if err := srv.Config.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("read after shutdown = %v, want io.EOF", err)
+	}
+}
+
+func TestRedeemerErrorEmptyMessage(t *testing.T) {
+	err := redeemerError(ledger.Result{Err: errors.New("")})
+	if err.Code != codeCannotEvaluate || err.Message == "" {
+		t.Errorf("redeemerError() = %+v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// formatEnvelope is the cardano-cli text envelope output of "plutigo
+// convert".
+const formatEnvelope = "envelope"
+
+// textEnvelope is a cardano-cli text envelope, with its fields in
+// cardano-cli's order.
+type textEnvelope struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CBORHex     string `json:"cborHex"`
+}
+
+// runConvert implements "plutigo convert".
+func runConvert(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo convert [flags] PROGRAM")
+		fmt.Fprintln(stderr, `PROGRAM is a file, or "-" for standard input.`)
+		flags.PrintDefaults()
+	}
+	var input, output, language string
+	var named, binary, info bool
+	flags.StringVar(&input, "input", formatAuto, "program format: auto, uplc, flat or cbor; flat and cbor may be binary or hex")
+	flags.StringVar(&output, "output", formatText, "output format: uplc, flat, cbor (wrapped once, as on chain) or envelope (a cardano-cli text envelope)")
+	flags.BoolVar(&named, "named", false, "read and write FLAT with named De Bruijn binders instead of plain De Bruijn indices; not for cbor or envelope output")
+	flags.BoolVar(&binary, "binary", false, "write flat and cbor output as binary instead of hex")
+	flags.StringVar(&language, "language", "PlutusV3", "ledger language of the script, for envelope output and -info")
+	flags.BoolVar(&info, "info", false, "print the script hash and sizes instead of the program")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	switch output {
+	case formatText, formatFlat, formatCBOR, formatEnvelope:
+	default:
+		fmt.Fprintf(stderr, "plutigo: unknown output format %q (want uplc, flat, cbor or envelope)\n", output)
+		return 2
+	}
+	// Scripts on chain are plain De Bruijn FLAT, so only bare FLAT output can
+	// carry named binders.
+	if named && !info && (output == formatCBOR || output == formatEnvelope) {
+		fmt.Fprintf(stderr, "plutigo: -named cannot be used with -output %s\n", output)
+		return 2
+	}
+	plutusVersion, err := parseLanguage(language)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+
+	b, err := readInput(flags.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	program, script, err := convertInput(b, input, named)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	var converted []byte
+	if info {
+		converted, err = scriptInfo(program, script, plutusVersion)
+	} else {
+		converted, err = convertOutput(program, output, named, plutusVersion)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	if !binary && !info && (output == formatFlat || output == formatCBOR) {
+		converted = []byte(hex.EncodeToString(converted) + "\n")
+	}
+	if _, err := stdout.Write(converted); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// convertInput reads a program with names, which textual programs keep and
+// FLAT ones get from their binders' levels. For binary input with plain De
+// Bruijn indices it also returns the script that the ledger hashes: the CBOR
+// byte string around the program as given, or the FLAT program wrapped in
+// one.
+func convertInput(b []byte, format string, named bool) (*syn.Program[syn.Name], []byte, error) {
+	text, source, script, err := programSource(b, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if text {
+		program, err := parseProgram(source)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Check the program is closed, as FLAT output needs.
+		if _, err := syn.NameToDeBruijn(program); err != nil {
+			return nil, nil, fmt.Errorf("parse program: %w", err)
+		}
+		return program, nil, nil
+	}
+	if named {
+		program, err := syn.Decode[syn.NamedDeBruijn](source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode FLAT program: %w", err)
+		}
+		converted, err := syn.NamedDeBruijnToName(program)
+		return converted, nil, err
+	}
+	program, err := decodeFlat(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if script == nil {
+		if script, err = cbor.Marshal(source); err != nil {
+			return nil, nil, fmt.Errorf("wrap program: %w", err)
+		}
+	}
+	converted, err := syn.DeBruijnToName(program)
+	return converted, script, err
+}
+
+// scriptInfo describes a script: its hash, language, version and sizes.
+// Without script, it hashes the program's plain De Bruijn encoding, since
+// the ledger only takes those.
+func scriptInfo(
+	program *syn.Program[syn.Name],
+	script []byte,
+	language builtin.PlutusVersion,
+) ([]byte, error) {
+	if script == nil {
+		debruijn, err := syn.NameToDeBruijn(program)
+		if err != nil {
+			return nil, fmt.Errorf("convert program: %w", err)
+		}
+		flat, err := syn.Encode(debruijn)
+		if err != nil {
+			return nil, fmt.Errorf("encode FLAT program: %w", err)
+		}
+		if script, err = cbor.Marshal(flat); err != nil {
+			return nil, fmt.Errorf("wrap program: %w", err)
+		}
+	}
+	var flat []byte
+	if err := cbor.Unmarshal(script, &flat); err != nil {
+		return nil, fmt.Errorf("decode script: %w", err)
+	}
+	return fmt.Appendf(nil,
+		"Script hash: %x\nLanguage: PlutusV%d\nVersion: %d.%d.%d\nFLAT size: %d bytes\nCBOR size: %d bytes\n",
+		ledger.ScriptHash(language, script), language,
+		program.Version[0], program.Version[1], program.Version[2],
+		len(flat), len(script),
+	), nil
+}
+
+// convertOutput writes a program in an output format. Binary formats are
+// returned as bytes. Named binders are only written as bare FLAT.
+func convertOutput(
+	program *syn.Program[syn.Name],
+	format string,
+	named bool,
+	language builtin.PlutusVersion,
+) ([]byte, error) {
+	if format == formatText {
+		return []byte(syn.Format(program, syn.DefaultLayout)), nil
+	}
+	if named {
+		namedDeBruijn, err := syn.NameToNamedDeBruijn(program)
+		if err != nil {
+			return nil, fmt.Errorf("convert program: %w", err)
+		}
+		flat, err := syn.Encode(namedDeBruijn)
+		if err != nil {
+			return nil, fmt.Errorf("encode FLAT program: %w", err)
+		}
+		return flat, nil
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		return nil, fmt.Errorf("convert program: %w", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		return nil, fmt.Errorf("encode FLAT program: %w", err)
+	}
+	wrapped, err := cbor.Marshal(flat)
+	if err != nil {
+		return nil, fmt.Errorf("wrap program: %w", err)
+	}
+	switch format {
+	case formatFlat:
+		return flat, nil
+	case formatCBOR:
+		return wrapped, nil
+	default:
+		doubled, err := cbor.Marshal(wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("wrap program: %w", err)
+		}
+		encoded, err := json.MarshalIndent(textEnvelope{
+			Type:    "PlutusScriptV" + strconv.Itoa(int(language)),
+			CBORHex: hex.EncodeToString(doubled),
+		}, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("encode text envelope: %w", err)
+		}
+		return append(encoded, '\n'), nil
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestRunConvert(t *testing.T) {
+	const program = `(program 1.1.0 (lam x (lam y [x y])))`
+	flat := encodeProgram(t, program)
+	wrapped, err := cbor.Marshal(flat)
+	if err != nil {
+		t.Fatalf("wrap program: %v", err)
+	}
+	hash := hex.EncodeToString(ledger.ScriptHash(builtin.PlutusV2, wrapped))
+	// A byte string header with a length that fits in the initial byte, which
+	// re-encoding would shorten. The ledger hashes scripts as they are given.
+	loose := append([]byte{0x58, byte(len(flat))}, flat...)
+	looseHash := hex.EncodeToString(ledger.ScriptHash(builtin.PlutusV2, loose))
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"text to text", nil, "(program 1.1.0\n  (lam x (lam y [x y])))", 0, "(program 1.1.0 (lam x (lam y [x y])))\n", ""},
+		{"text to flat", []string{"-output", "flat"}, program, 0, hex.EncodeToString(flat) + "\n", ""},
+		{"text to binary flat", []string{"-output", "flat", "-binary"}, program, 0, string(flat), ""},
+		{"text to cbor", []string{"-output", "cbor"}, program, 0, hex.EncodeToString(wrapped) + "\n", ""},
+		{"flat to text", nil, hex.EncodeToString(flat), 0, "(program 1.1.0 (lam i_0 (lam i_1 [i_0 i_1])))\n", ""},
+		{"cbor to flat", []string{"-output", "flat"}, string(wrapped), 0, hex.EncodeToString(flat) + "\n", ""},
+		{"envelope", []string{"-output", "envelope", "-language", "PlutusV2"}, program, 0, `"type": "PlutusScriptV2",`, ""},
+		{"info", []string{"-info", "-language", "PlutusV2"}, hex.EncodeToString(wrapped), 0, "Script hash: " + hash + "\nLanguage: PlutusV2\nVersion: 1.1.0\nFLAT size: 8 bytes\nCBOR size: 9 bytes\n", ""},
+		{"info loose cbor", []string{"-info", "-language", "PlutusV2"}, hex.EncodeToString(loose), 0, "Script hash: " + looseHash + "\nLanguage: PlutusV2\nVersion: 1.1.0\nFLAT size: 8 bytes\nCBOR size: 10 bytes\n", ""},
+		{"info text", []string{"-info", "-language", "PlutusV2"}, program, 0, "Script hash: " + hash + "\n", ""},
+		{"free variable", []string{"-output", "flat"}, `(program 1.0.0 x)`, 2, "", "parse program"},
+		{"bad flat", []string{"-input", "flat"}, "ff", 2, "", "decode FLAT program"},
+		{"bad output", []string{"-output", "json"}, program, 2, "", `unknown output format "json"`},
+		{"named cbor", []string{"-output", "cbor", "-named"}, program, 2, "", "-named cannot be used with -output cbor"},
+		{"named envelope", []string{"-output", "envelope", "-named"}, program, 2, "", "-named cannot be used with -output envelope"},
+		{"bad language", []string{"-language", "Plutus"}, program, 2, "", `unknown language "Plutus"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append(append([]string{"convert"}, tt.args...), "-")
+			if code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("run() stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+
+	// Every output converts back to the program; named FLAT keeps the
+	// binders' names.
+	for _, output := range []string{"uplc", "flat", "cbor", "envelope"} {
+		for _, named := range []bool{false, true} {
+			if named && (output == "cbor" || output == "envelope") {
+				continue
+			}
+			args := []string{"convert", "-output", output}
+			if named {
+				args = append(args, "-named")
+			}
+			var converted, stdout, stderr bytes.Buffer
+			if code := run(append(args, "-"), strings.NewReader(program), &converted, &stderr); code != 0 {
+				t.Fatalf("convert -output %s: %s", output, stderr.String())
+			}
+			back := []string{"convert", "-output", "flat"}
+			if named {
+				back = append(back, "-named")
+			}
+			if code := run(append(back, "-"), &converted, &stdout, &stderr); code != 0 {
+				t.Fatalf("convert back from %s: %s", output, stderr.String())
+			}
+			var decoded bytes.Buffer
+			if code := run([]string{"convert", "-named=" + strconv.FormatBool(named), "-"}, &stdout, &decoded, &stderr); code != 0 {
+				t.Fatalf("convert to text from %s: %s", output, stderr.String())
+			}
+			want := "(program 1.1.0 (lam i_0 (lam i_1 [i_0 i_1])))\n"
+			if named {
+				want = "(program 1.1.0 (lam x (lam y [x y])))\n"
+			}
+			if decoded.String() != want {
+				t.Errorf("round trip through %s (named %v) = %q, want %q", output, named, decoded.String(), want)
+			}
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/policy"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// evalOutcome is the JSON output of "plutigo eval".
+type evalOutcome struct {
+	Success   bool           `json:"success"`
+	Result    string         `json:"result,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	ErrorCode *cek.ErrorCode `json:"error_code,omitempty"`
+	ExUnits   ledger.ExUnits `json:"ex_units"`
+	Remaining ledger.ExUnits `json:"remaining"`
+	Traces    []string       `json:"traces"`
+}
+
+// runEval implements "plutigo eval". It exits with 1 when the program
+// fails.
+func runEval(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo eval", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo eval [flags] PROGRAM")
+		fmt.Fprintln(stderr, `PROGRAM is a file, or "-" for standard input.`)
+		flags.PrintDefaults()
+	}
+	var input, language, protocol, costModelPath, budgetText, format string
+	var arguments dataArgs
+	flags.StringVar(&input, "input", formatAuto, "program format: auto, uplc, flat or cbor; flat and cbor may be binary or hex")
+	flags.Var(&arguments, "arg", "Data argument to apply the program to, as CBOR hex or detailed-schema JSON; repeat for more")
+	flags.StringVar(&language, "language", "PlutusV3", "ledger language: PlutusV1, PlutusV2, PlutusV3 or PlutusV4")
+	flags.StringVar(&protocol, "protocol-version", "", "protocol version as MAJOR or MAJOR.MINOR (default: the latest hard fork)")
+	flags.StringVar(&costModelPath, "cost-model", "", "path to a JSON array of cost model parameters, or to protocol parameters (cardano-cli, Ogmios or Blockfrost) holding the language's model (default: plutigo's model)")
+	flags.StringVar(&budgetText, "budget", "10000000000,14000000", "budget as STEPS,MEMORY")
+	flags.StringVar(&format, "format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(stderr, "plutigo: unknown output format %q\n", format)
+		return 2
+	}
+
+	plutusVersion, err := parseLanguage(language)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	protoVersion, err := parseProtocolVersion(protocol)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	budget, err := parseBudget(budgetText)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	evalContext, err := loadEvalContext(costModelPath, plutusVersion, protoVersion)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	b, err := readInput(flags.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	program, err := decodeProgram(b, input)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	if err := checkProgramVersion(program.Version, plutusVersion, protoVersion); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+
+	term := program.Term
+	for _, argument := range arguments {
+		term = &syn.Apply[syn.DeBruijn]{
+			Function: term,
+			Argument: &syn.Constant{Con: &syn.Data{Inner: argument}},
+		}
+	}
+	version, _ := policy.LanguageVersion(plutusVersion)
+	outcome, _ := evaluate(version, evalContext, budget, term)
+
+	if format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(outcome); err != nil {
+			fmt.Fprintf(stderr, "plutigo: encode result: %v\n", err)
+			return 2
+		}
+	} else {
+		writeOutcome(stdout, outcome)
+	}
+	if !outcome.Success {
+		return 1
+	}
+	return 0
+}
+
+// evaluate runs term with budget and reports the outcome, including the
+// budget spent by a failing run, and the result.
+func evaluate(
+	version lang.LanguageVersion,
+	evalContext *cek.EvalContext,
+	budget cek.ExBudget,
+	term syn.Term[syn.DeBruijn],
+) (evalOutcome, syn.Term[syn.DeBruijn]) {
+	machine := cek.NewMachine[syn.DeBruijn](version, 0, evalContext)
+	machine.ExBudget = budget
+	out, err := runMachine(machine, term)
+	// A run that goes over budget spent the whole budget and no more,
+	// whether the machine deducted the cost that failed, leaving its budget
+	// negative, or refused it, as the startup cost does.
+	consumed := budget.Sub(&machine.ExBudget)
+	if outOfBudget(err) {
+		consumed = budget
+	}
+	outcome := evalOutcome{
+		Success:   err == nil,
+		ExUnits:   ledger.ExUnits{Steps: consumed.Cpu, Memory: consumed.Mem},
+		Remaining: ledger.ExUnits{Steps: budget.Cpu - consumed.Cpu, Memory: budget.Mem - consumed.Mem},
+		Traces:    machine.Logs,
+	}
+	if outcome.Traces == nil {
+		outcome.Traces = []string{}
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+		if code, ok := cek.GetErrorCode(err); ok {
+			outcome.ErrorCode = &code
+		}
+		return outcome, nil
+	}
+	// The result is closed, so it converts to names, and is printed as
+	// convert and the REPL print terms.
+	named, err := syn.DeBruijnToName(&syn.Program[syn.DeBruijn]{Term: out})
+	if err != nil {
+		outcome.Success = false
+		outcome.Error = fmt.Sprintf("convert result: %v", err)
+		return outcome, nil
+	}
+	outcome.Result = syn.FormatTerm[syn.Name](named.Term, syn.DefaultLayout)
+	return outcome, out
+}
+
+// outOfBudget reports whether err ended a run that ran out of budget.
+func outOfBudget(err error) bool {
+	code, ok := cek.GetErrorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case cek.ErrCodeBudgetExhausted,
+		cek.ErrCodeMemoryExhausted,
+		cek.ErrCodeCPUExhausted:
+		return true
+	}
+	return false
+}
+
+func runMachine(
+	machine *cek.Machine[syn.DeBruijn],
+	term syn.Term[syn.DeBruijn],
+) (out syn.Term[syn.DeBruijn], err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic during evaluation: %v", recovered)
+		}
+	}()
+	return machine.Run(term)
+}
+
+func writeOutcome(w io.Writer, outcome evalOutcome) {
+	if outcome.Success {
+		fmt.Fprintf(w, "Result: %s\n", outcome.Result)
+	} else {
+		fmt.Fprintf(w, "Error: %s\n", outcome.Error)
+	}
+	fmt.Fprintf(w, "Budget: cpu %d, mem %d\n", outcome.ExUnits.Steps, outcome.ExUnits.Memory)
+	fmt.Fprintf(w, "Remaining: cpu %d, mem %d\n", outcome.Remaining.Steps, outcome.Remaining.Memory)
+	if len(outcome.Traces) > 0 {
+		fmt.Fprintln(w, "Traces:")
+		for _, trace := range outcome.Traces {
+			fmt.Fprintf(w, "  %s\n", trace)
+		}
+	}
+}
+
+// parseLanguage parses a ledger language such as PlutusV3.
+func parseLanguage(s string) (builtin.PlutusVersion, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "PlutusV"))
+	if err != nil || !strings.HasPrefix(s, "PlutusV") || n < 1 || n > 4 {
+		return 0, fmt.Errorf("unknown language %q (want PlutusV1, PlutusV2, PlutusV3 or PlutusV4)", s)
+	}
+	return builtin.PlutusVersion(n), nil
+}
+
+// checkProgramVersion reports an error unless the language accepts the
+// program version at the protocol version.
+func checkProgramVersion(version lang.LanguageVersion, language builtin.PlutusVersion, protoVersion cek.ProtoVersion) error {
+	if slices.Contains(policy.ProgramVersions(language, protoVersion.Major), version) {
+		return nil
+	}
+	return fmt.Errorf(
+		"program version %d.%d.%d is not allowed for PlutusV%d at protocol version %d",
+		version[0],
+		version[1],
+		version[2],
+		language,
+		protoVersion.Major,
+	)
+}
+
+// parseProtocolVersion parses MAJOR or MAJOR.MINOR. The empty string is the
+// latest hard fork.
+func parseProtocolVersion(s string) (cek.ProtoVersion, error) {
+	if s == "" {
+		forks := policy.HardForks()
+		return cek.ProtoVersion{Major: forks[len(forks)-1].ProtoMajor}, nil
+	}
+	majorText, minorText, hasMinor := strings.Cut(s, ".")
+	major, err := strconv.ParseUint(majorText, 10, 32)
+	if err != nil || major == 0 {
+		return cek.ProtoVersion{}, fmt.Errorf("invalid protocol version %q", s)
+	}
+	var minor uint64
+	if hasMinor {
+		if minor, err = strconv.ParseUint(minorText, 10, 32); err != nil {
+			return cek.ProtoVersion{}, fmt.Errorf("invalid protocol version %q", s)
+		}
+	}
+	return cek.ProtoVersion{Major: uint(major), Minor: uint(minor)}, nil
+}
+
+// parseBudget parses STEPS,MEMORY.
+func parseBudget(s string) (cek.ExBudget, error) {
+	stepsText, memoryText, ok := strings.Cut(s, ",")
+	steps, stepsErr := strconv.ParseInt(strings.TrimSpace(stepsText), 10, 64)
+	memory, memoryErr := strconv.ParseInt(strings.TrimSpace(memoryText), 10, 64)
+	if !ok || stepsErr != nil || memoryErr != nil || steps <= 0 || memory <= 0 {
+		return cek.ExBudget{}, fmt.Errorf("invalid budget %q (want positive STEPS,MEMORY)", s)
+	}
+	return cek.ExBudget{Cpu: steps, Mem: memory}, nil
+}
+
+// loadEvalContext builds the evaluation context of a language from a cost
+// model file, or from plutigo's model without one.
+func loadEvalContext(
+	path string,
+	language builtin.PlutusVersion,
+	protoVersion cek.ProtoVersion,
+) (*cek.EvalContext, error) {
+	version, err := policy.LanguageVersion(language)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return cek.NewDefaultEvalContext(version, protoVersion), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cost model: %w", err)
+	}
+	var model []int64
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &model); err != nil {
+			return nil, fmt.Errorf("decode cost model: %w", err)
+		}
+	} else {
+		params, err := ledger.LoadParameters(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if model, ok = params.CostModels[language]; !ok {
+			return nil, fmt.Errorf("protocol parameters have no PlutusV%d cost model", language)
+		}
+	}
+	evalContext, err := cek.NewEvalContext(version, protoVersion, model)
+	if err != nil {
+		return nil, fmt.Errorf("build evaluation context: %w", err)
+	}
+	return evalContext, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/lang"
+)
+
+// writeCostModel writes a PlutusV3 cost model whose machine steps, startup
+// included, cost 32,000 CPU and 100 memory, as a JSON array and as
+// cardano-cli protocol parameters.
+func writeCostModel(t *testing.T) (string, string) {
+	t.Helper()
+	names := lang.GetParamNamesForVersion(lang.LanguageVersionV3)
+	model := make([]int64, len(names))
+	for i, name := range names {
+		switch {
+		case strings.HasSuffix(name, "-exBudgetCPU"):
+			model[i] = 32_000
+		case strings.HasSuffix(name, "-exBudgetMemory"):
+			model[i] = 100
+		default:
+			model[i] = 1
+		}
+	}
+	dir := t.TempDir()
+	write := func(name string, v any) string {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("encode %s: %v", name, err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, encoded, 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	params := write("params.json", map[string]any{
+		"costModels":             map[string]any{"PlutusV3": model},
+		"protocolVersion":        map[string]any{"major": 10, "minor": 0},
+		"maxTxExecutionUnits":    map[string]any{"memory": 14_000_000, "steps": 10_000_000_000},
+		"maxBlockExecutionUnits": map[string]any{"memory": 62_000_000, "steps": 20_000_000_000},
+	})
+	return write("model.json", model), params
+}
+
+func TestRunEval(t *testing.T) {
+	const identity = `(program 1.1.0 (lam d [(force (builtin trace)) (con string "hi") d]))`
+	dir := t.TempDir()
+	program := filepath.Join(dir, "identity.uplc")
+	if err := os.WriteFile(program, []byte(identity), 0o600); err != nil {
+		t.Fatalf("write program: %v", err)
+	}
+	model, params := writeCostModel(t)
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"text", []string{"-arg", "05", program}, "", 0, "Result: (con data (I 5))\nBudget: cpu ", ""},
+		{"traces", []string{"-arg", "05", program}, "", 0, "Traces:\n  hi\n", ""},
+		{"stdin", []string{"-"}, `(program 1.0.0 (con integer 1))`, 0, "Result: (con integer 1)", ""},
+		{"json", []string{"-format", "json", "-arg", `{"int": 5}`, program}, "", 0, `"traces": [
+    "hi"
+  ]`, ""},
+		{"cost model array", []string{"-cost-model", model, "-"}, `(program 1.1.0 (con unit ()))`, 0, "Budget: cpu 64000, mem 200", ""},
+		{"cost model parameters", []string{"-cost-model", params, "-"}, `(program 1.1.0 (con unit ()))`, 0, "Budget: cpu 64000, mem 200", ""},
+		{"named result", []string{"-"}, `(program 1.1.0 (lam x (lam y [x y])))`, 0, "Result: (lam i_0 (lam i_1 [i_0 i_1]))\n", ""},
+		{"failure", []string{"-"}, `(program 1.1.0 (error))`, 1, "Error: ", ""},
+		{"over budget", []string{"-budget", "100,100", "-"}, `(program 1.1.0 (con unit ()))`, 1, "Budget: cpu 100, mem 100\nRemaining: cpu 0, mem 0\n", ""},
+		{"over budget at startup", []string{"-budget", "1,1", "-"}, `(program 1.1.0 (con unit ()))`, 1, "Budget: cpu 1, mem 1\nRemaining: cpu 0, mem 0\n", ""},
+		{"json over budget", []string{"-format", "json", "-budget", "100,100", "-"}, `(program 1.1.0 (con unit ()))`, 1, `"remaining": {
+    "steps": 0,
+    "memory": 0
+  }`, ""},
+		{"json failure", []string{"-format", "json", "-"}, `(program 1.1.0 (error))`, 1, `"success": false`, ""},
+		{"missing program", nil, "", 2, "", "Usage: plutigo eval"},
+		{"bad program", []string{"-"}, `(program`, 2, "", "parse program"},
+		{"bad argument", []string{"-arg", "zz", program}, "", 2, "", "decode Data CBOR hex"},
+		{"bad language", []string{"-language", "PlutusV9", program}, "", 2, "", `unknown language "PlutusV9"`},
+		{"bad protocol version", []string{"-protocol-version", "ten", program}, "", 2, "", `invalid protocol version "ten"`},
+		{"program version", []string{"-language", "PlutusV1", "-protocol-version", "10", "-"}, `(program 1.1.0 (con unit ()))`, 2, "", "program version 1.1.0 is not allowed for PlutusV1 at protocol version 10"},
+		{"bad budget", []string{"-budget", "100", program}, "", 2, "", `invalid budget "100"`},
+		{"bad format", []string{"-format", "yaml", program}, "", 2, "", `unknown output format "yaml"`},
+		{"missing cost model", []string{"-language", "PlutusV1", "-cost-model", params, program}, "", 2, "", "no PlutusV1 cost model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"eval"}, tt.args...)
+			if code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("run() stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, 2},
+		{"help", []string{"help"}, 0},
+		{"unknown command", []string{"run"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(""), &stdout, &stderr); code != tt.code {
+				t.Errorf("run() code = %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(stdout.String()+stderr.String(), "plutigo eval") {
+				t.Errorf("run() printed no usage: %q", stdout.String()+stderr.String())
+			}
+		})
+	}
+}
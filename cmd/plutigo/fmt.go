@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// runFmt implements "plutigo fmt". It formats standard input to standard
+// output when given no paths, and rewrites files otherwise. With -check it
+// lists the files that are not formatted and exits with 1 if there are any.
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo fmt [flags] [PATH...]")
+		fmt.Fprintln(stderr, "Formats .uplc files, and the .uplc files in directories, in place.")
+		fmt.Fprintln(stderr, `With no PATH, or "-", it formats standard input to standard output.`)
+		flags.PrintDefaults()
+	}
+	layout := syn.DefaultLayout
+	var check bool
+	var constants, dataLayout string
+	flags.BoolVar(&check, "check", false, "list files whose formatting differs instead of rewriting them, and exit with 1 if there are any")
+	flags.IntVar(&layout.Width, "width", layout.Width, "maximum line width")
+	flags.IntVar(&layout.IndentSize, "indent", layout.IndentSize, "spaces per indentation level")
+	flags.BoolVar(&layout.Compact, "compact", layout.Compact, "print subterms that fit in the width on one line")
+	flags.StringVar(&constants, "constants", "fit", "layout of list constants: fit, one-line or expanded")
+	flags.StringVar(&dataLayout, "data", "fit", "layout of Data lists, maps and constructors: fit, one-line or expanded")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	var err error
+	if layout.Constants, err = parseCollectionLayout(constants); err != nil {
+		fmt.Fprintf(stderr, "plutigo: -constants: %v\n", err)
+		return 2
+	}
+	if layout.Data, err = parseCollectionLayout(dataLayout); err != nil {
+		fmt.Fprintf(stderr, "plutigo: -data: %v\n", err)
+		return 2
+	}
+	if layout.Width <= 0 || layout.IndentSize <= 0 {
+		fmt.Fprintln(stderr, "plutigo: -width and -indent must be positive")
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	code := 0
+	for _, root := range paths {
+		if root == "-" {
+			code = max(code, formatStdin(layout, check, stdin, stdout, stderr))
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Named files are formatted whatever their extension.
+			if d.IsDir() || (path != root && filepath.Ext(path) != ".uplc") {
+				return nil
+			}
+			code = max(code, formatFile(layout, check, path, stdout, stderr))
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "plutigo: %v\n", err)
+			code = 2
+		}
+	}
+	return code
+}
+
+func formatStdin(layout syn.Layout, check bool, stdin io.Reader, stdout, stderr io.Writer) int {
+	src, err := readInput("-", stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	formatted, err := formatSource(src, layout)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: <standard input>: %v\n", err)
+		return 2
+	}
+	if check {
+		if !bytes.Equal(src, formatted) {
+			fmt.Fprintln(stdout, "<standard input>")
+			return 1
+		}
+		return 0
+	}
+	if _, err := stdout.Write(formatted); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+func formatFile(layout syn.Layout, check bool, path string, stdout, stderr io.Writer) int {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	formatted, err := formatSource(src, layout)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %s: %v\n", path, err)
+		return 2
+	}
+	if bytes.Equal(src, formatted) {
+		return 0
+	}
+	if check {
+		fmt.Fprintln(stdout, path)
+		return 1
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// formatSource formats a textual program. Comments go on their own lines,
+// before the terms that follow them.
+func formatSource(src []byte, layout syn.Layout) ([]byte, error) {
+	program, comments, err := syn.ParseComments(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("parse program: %w", err)
+	}
+	return []byte(syn.FormatComments(program, comments, layout)), nil
+}
+
+func parseCollectionLayout(s string) (syn.CollectionLayout, error) {
+	switch s {
+	case "fit":
+		return syn.CollectionFit, nil
+	case "one-line":
+		return syn.CollectionOneLine, nil
+	case "expanded":
+		return syn.CollectionExpanded, nil
+	default:
+		return 0, fmt.Errorf("unknown layout %q (want fit, one-line or expanded)", s)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunFmt(t *testing.T) {
+	const (
+		messy     = "(program 1.1.0\n(lam x   [(builtin addInteger) x (con integer 1)]))"
+		formatted = "(program 1.1.0 (lam x [[(builtin addInteger) x] (con integer 1)]))\n"
+	)
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"stdin", nil, messy, 0, formatted, ""},
+		{"check formatted stdin", []string{"-check", "-"}, formatted, 0, "", ""},
+		{"check messy stdin", []string{"-check"}, messy, 1, "<standard input>\n", ""},
+		{"layout flags", []string{"-compact=false", "-indent", "1"}, "(program 1.0.0 (delay (error)))", 0, "(program 1.0.0\n (delay\n  (error)\n )\n)\n", ""},
+		{"comments", nil, "-- fixture\n(program 1.1.0 -- adds one\n(lam x   [(builtin addInteger) x (con integer 1)]))", 0, "-- fixture\n(program 1.1.0\n  -- adds one\n  (lam x [[(builtin addInteger) x] (con integer 1)])\n)\n", ""},
+		{"check commented stdin", []string{"-check"}, "-- fixture\n" + formatted, 0, "", ""},
+		{"parse error", nil, "(program", 2, "", "parse program"},
+		{"bad layout", []string{"-data", "tight"}, formatted, 2, "", `-data: unknown layout "tight"`},
+		{"bad width", []string{"-width", "0"}, formatted, 2, "", "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"fmt"}, tt.args...)
+			if code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() code = %d, want %d; stderr=%s", code, tt.code, stderr.String())
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("run() stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+
+	// Directories are walked for .uplc files, which are rewritten in place.
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "nested", "add.uplc")
+	other := filepath.Join(dir, "nested", "notes.txt")
+	if err := os.MkdirAll(filepath.Dir(fixture), 0o700); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+	for _, path := range []string{fixture, other} {
+		if err := os.WriteFile(path, []byte(messy), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if code := run([]string{"fmt", "-check", dir}, strings.NewReader(""), &stdout, &stderr); code != 1 || stdout.String() != fixture+"\n" {
+		t.Fatalf("fmt -check = %d, %q; stderr=%s", code, stdout.String(), stderr.String())
+	}
+	if code := run([]string{"fmt", dir}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("fmt = %d; stderr=%s", code, stderr.String())
+	}
+	for path, want := range map[string]string{fixture: formatted, other: messy} {
+		if got, err := os.ReadFile(path); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", filepath.Base(path), got, err, want)
+		}
+	}
+	stdout.Reset()
+	if code := run([]string{"fmt", "-check", fixture}, strings.NewReader(""), &stdout, &stderr); code != 0 || stdout.Len() != 0 {
+		t.Errorf("fmt -check after fmt = %d, %q", code, stdout.String())
+	}
+}
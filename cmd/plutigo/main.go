@@ -0,0 +1,61 @@
+// Command plutigo works with Plutus programs from the command line.
+//
+// "plutigo eval" evaluates a program, given as UPLC text, FLAT or a
+// CBOR-wrapped script, applied to optional Data arguments, and prints its
+// result, the budget it consumed and its traces.
+//
+// "plutigo fmt" formats UPLC text files in place, or reports with -check
+// the files that are not formatted, as gofmt does.
+//
+// "plutigo convert" translates a program between UPLC text, FLAT and
+// CBOR-wrapped scripts, and with -info prints its script hash and size.
+//
+// "plutigo repl" is an interactive session that evaluates terms, with
+// let-style definitions, files loaded with :load, and commands to print
+// result types and switch the budget, language and protocol version.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run dispatches to a command. Commands exit with 2 for usage and input
+// errors.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "eval":
+		return runEval(args[1:], stdin, stdout, stderr)
+	case "fmt":
+		return runFmt(args[1:], stdin, stdout, stderr)
+	case "convert":
+		return runConvert(args[1:], stdin, stdout, stderr)
+	case "repl":
+		return runRepl(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "plutigo: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage of plutigo:")
+	fmt.Fprintln(w, "  plutigo eval [flags] PROGRAM")
+	fmt.Fprintln(w, "  plutigo fmt [flags] [PATH...]")
+	fmt.Fprintln(w, "  plutigo convert [flags] PROGRAM")
+	fmt.Fprintln(w, "  plutigo repl [flags]")
+	fmt.Fprintln(w, `Run "plutigo COMMAND -h" for the flags of a command.`)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// Program input formats. auto tells them apart by their content.
+const (
+	formatAuto = "auto"
+	formatText = "uplc"
+	formatFlat = "flat"
+	formatCBOR = "cbor"
+)
+
+// readInput reads a file, or standard input for "-".
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read standard input: %w", err)
+		}
+		return b, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read program: %w", err)
+	}
+	return b, nil
+}
+
+// decodeProgram decodes a program in one of the input formats. Textual
+// programs are converted to De Bruijn indices.
+func decodeProgram(b []byte, format string) (*syn.Program[syn.DeBruijn], error) {
+	text, flat, _, err := programSource(b, format)
+	if err != nil {
+		return nil, err
+	}
+	if !text {
+		return decodeFlat(flat)
+	}
+	program, err := parseProgram(flat)
+	if err != nil {
+		return nil, err
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		return nil, fmt.Errorf("parse program: %w", err)
+	}
+	return debruijn, nil
+}
+
+// skipComments drops the whitespace and "--" line comments that may come
+// before a textual program.
+func skipComments(b []byte) []byte {
+	for {
+		b = bytes.TrimLeftFunc(b, unicode.IsSpace)
+		if !bytes.HasPrefix(b, []byte("--")) {
+			return b
+		}
+		_, rest, found := bytes.Cut(b, []byte("\n"))
+		if !found {
+			return nil
+		}
+		b = rest
+	}
+}
+
+// programSource reads a program in one of the input formats:
+//
+//   - uplc: the textual syntax
+//   - flat: FLAT, as binary or hex
+//   - cbor: FLAT wrapped in one or two CBOR byte strings, as binary or hex,
+//     or a cardano-cli text envelope whose cborHex holds it
+//
+// It returns the source of a textual program, with text set, or the FLAT
+// bytes of a binary one. For CBOR input, script is the CBOR byte string
+// around the FLAT bytes, as given.
+func programSource(b []byte, format string) (text bool, source, script []byte, err error) {
+	trimmed := bytes.TrimSpace(b)
+	if format == formatAuto {
+		switch {
+		case bytes.HasPrefix(skipComments(trimmed), []byte("(")):
+			format = formatText
+		case bytes.HasPrefix(trimmed, []byte("{")):
+			format = formatCBOR
+		default:
+			// FLAT programs start with their version's major number, which
+			// is no CBOR byte string header.
+			if raw := binaryInput(trimmed, b); len(raw) > 0 && raw[0]>>5 == 2 {
+				format = formatCBOR
+			} else {
+				format = formatFlat
+			}
+		}
+	}
+
+	switch format {
+	case formatText:
+		return true, b, nil, nil
+	case formatFlat:
+		return false, binaryInput(trimmed, b), nil, nil
+	case formatCBOR:
+		raw := binaryInput(trimmed, b)
+		if bytes.HasPrefix(trimmed, []byte("{")) {
+			var envelope textEnvelope
+			if err := json.Unmarshal(trimmed, &envelope); err != nil {
+				return false, nil, nil, fmt.Errorf("decode text envelope: %w", err)
+			}
+			if raw, err = hex.DecodeString(envelope.CBORHex); err != nil {
+				return false, nil, nil, fmt.Errorf("decode text envelope cborHex: %w", err)
+			}
+		}
+		// Scripts are wrapped once on chain and twice in text envelopes.
+		for range 2 {
+			var inner []byte
+			if err := cbor.Unmarshal(raw, &inner); err != nil {
+				break
+			}
+			script, raw = raw, inner
+		}
+		return false, raw, script, nil
+	default:
+		return false, nil, nil, fmt.Errorf("unknown program format %q (want auto, uplc, flat or cbor)", format)
+	}
+}
+
+func parseProgram(source []byte) (*syn.Program[syn.Name], error) {
+	program, err := syn.Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("parse program: %w", err)
+	}
+	return program, nil
+}
+
+// binaryInput returns the bytes of a binary input given as hex or as is.
+func binaryInput(trimmed, b []byte) []byte {
+	text := strings.TrimPrefix(string(trimmed), "0x")
+	if decoded, err := hex.DecodeString(text); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return b
+}
+
+func decodeFlat(b []byte) (*syn.Program[syn.DeBruijn], error) {
+	program, err := syn.Decode[syn.DeBruijn](b)
+	if err != nil {
+		return nil, fmt.Errorf("decode FLAT program: %w", err)
+	}
+	return program, nil
+}
+
+// decodeData decodes a Data argument given as CBOR hex or as JSON in the
+// detailed schema.
+func decodeData(arg string) (data.PlutusData, error) {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "{") {
+		d, err := data.DecodeJSON([]byte(arg))
+		if err != nil {
+			return nil, fmt.Errorf("decode Data JSON: %w", err)
+		}
+		return d, nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(arg, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("decode Data CBOR hex: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty Data argument")
+	}
+	d, err := data.Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("decode Data CBOR: %w", err)
+	}
+	return d, nil
+}
+
+// dataArgs collects repeated -arg flags.
+type dataArgs []data.PlutusData
+
+func (a *dataArgs) String() string {
+	return strconv.Itoa(len(*a)) + " arguments"
+}
+
+func (a *dataArgs) Set(value string) error {
+	d, err := decodeData(value)
+	if err != nil {
+		return err
+	}
+	*a = append(*a, d)
+	return nil
+}
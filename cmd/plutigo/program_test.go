@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// encodeProgram returns the FLAT encoding of a textual program.
+func encodeProgram(t *testing.T, text string) []byte {
+	t.Helper()
+	program, err := syn.Parse(text)
+	if err != nil {
+		t.Fatalf("parse program: %v", err)
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("convert program: %v", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		t.Fatalf("encode program: %v", err)
+	}
+	return flat
+}
+
+func TestDecodeProgram(t *testing.T) {
+	const text = `(program 1.1.0 (lam x x))`
+	flat := encodeProgram(t, text)
+	single, err := cbor.Marshal(flat)
+	if err != nil {
+		t.Fatalf("wrap program: %v", err)
+	}
+	double, err := cbor.Marshal(single)
+	if err != nil {
+		t.Fatalf("wrap program: %v", err)
+	}
+	envelope := `{"type": "PlutusScriptV3", "description": "", "cborHex": "` + hex.EncodeToString(double) + `"}`
+
+	tests := []struct {
+		name   string
+		input  []byte
+		format string
+		error  string
+	}{
+		{"text", []byte(text), formatAuto, ""},
+		{"text with comments", []byte("-- identity\n\n  -- on PlutusV3\n" + text + " -- done\n"), formatAuto, ""},
+		{"flat binary", flat, formatAuto, ""},
+		{"flat hex", []byte(hex.EncodeToString(flat) + "\n"), formatAuto, ""},
+		{"cbor hex", []byte("0x" + hex.EncodeToString(single)), formatAuto, ""},
+		{"double cbor binary", double, formatAuto, ""},
+		{"text envelope", []byte(envelope), formatAuto, ""},
+		{"explicit flat", []byte(hex.EncodeToString(flat)), formatFlat, ""},
+		{"explicit cbor", single, formatCBOR, ""},
+		{"text as flat", []byte(text), formatFlat, "decode FLAT program"},
+		{"bad text", []byte(`(program 1.1.0 (lam x`), formatAuto, "parse program"},
+		{"bad envelope", []byte(`{"cborHex": "zz"}`), formatAuto, "decode text envelope cborHex"},
+		{"unknown format", []byte(text), "json", `unknown program format "json"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := decodeProgram(tt.input, tt.format)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("decodeProgram() error = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeProgram() failed: %v", err)
+			}
+			if got, err := syn.Encode(program); err != nil || !bytes.Equal(got, flat) {
+				t.Errorf("decodeProgram() = %s", syn.Pretty[syn.DeBruijn](program))
+			}
+		})
+	}
+}
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name  string
+		arg   string
+		want  data.PlutusData
+		error string
+	}{
+		{"cbor hex", "05", data.NewInteger(big.NewInt(5)), ""},
+		{"prefixed cbor hex", "0x4141", data.NewByteString([]byte("A")), ""},
+		{"json", `{"constructor": 1, "fields": [{"int": 2}]}`, data.NewConstr(1, data.NewInteger(big.NewInt(2))), ""},
+		{"bad json", `{"int": "x"}`, nil, "decode Data JSON"},
+		{"bad hex", "xyz", nil, "decode Data CBOR hex"},
+		{"empty", "", nil, "empty Data argument"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeData(tt.arg)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("decodeData() error = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeData() failed: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("decodeData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
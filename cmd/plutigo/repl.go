@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/policy"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+const replHelp = `Enter a term to evaluate it, or one of:
+  let NAME = TERM              define NAME as TERM in later terms
+  :load FILE [NAME]            define NAME, by default the file's base name, as a program's term
+  :type TERM                   evaluate TERM and print the type of its result
+  :budget [STEPS,MEMORY]       print or set the budget of each evaluation
+  :version [LANGUAGE] [MAJOR[.MINOR]]
+                               print or set the language and protocol version
+  :env                         list the definitions
+  :help                        print this help
+  :quit                        leave
+Terms may span lines until their brackets close.`
+
+// session is the state of "plutigo repl".
+type session struct {
+	language      builtin.PlutusVersion
+	protoVersion  cek.ProtoVersion
+	costModelPath string
+	evalContext   *cek.EvalContext
+	budget        cek.ExBudget
+	// definitions holds closed terms, with earlier definitions substituted.
+	definitions map[string]syn.Term[syn.Name]
+	// versions holds the program version each definition needs: the
+	// version a loaded program declares, or the oldest that has the
+	// syntax a let term uses.
+	versions map[string]lang.LanguageVersion
+	names    []string
+	stdout   io.Writer
+}
+
+// runRepl implements "plutigo repl". It reads commands until the end of
+// its input or :quit.
+func runRepl(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("plutigo repl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: plutigo repl [flags]")
+		flags.PrintDefaults()
+	}
+	var language, protocol, budgetText string
+	s := &session{
+		definitions: make(map[string]syn.Term[syn.Name]),
+		versions:    make(map[string]lang.LanguageVersion),
+		stdout:      stdout,
+	}
+	flags.StringVar(&language, "language", "PlutusV3", "ledger language: PlutusV1, PlutusV2, PlutusV3 or PlutusV4")
+	flags.StringVar(&protocol, "protocol-version", "", "protocol version as MAJOR or MAJOR.MINOR (default: the latest hard fork)")
+	flags.StringVar(&s.costModelPath, "cost-model", "", "path to a JSON array of cost model parameters, or to protocol parameters holding the language's model (default: plutigo's model)")
+	flags.StringVar(&budgetText, "budget", "10000000000,14000000", "budget of each evaluation as STEPS,MEMORY")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	plutusVersion, err := parseLanguage(language)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	protoVersion, err := parseProtocolVersion(protocol)
+	if err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	if s.budget, err = parseBudget(budgetText); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+	if err := s.setVersion(plutusVersion, protoVersion); err != nil {
+		fmt.Fprintf(stderr, "plutigo: %v\n", err)
+		return 2
+	}
+
+	fmt.Fprintf(stdout, "plutigo repl: %s. Type :help for help.\n", s.versionText())
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var input strings.Builder
+	for {
+		if input.Len() == 0 {
+			fmt.Fprint(stdout, "> ")
+		} else {
+			fmt.Fprint(stdout, "| ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+		input.WriteString(scanner.Text())
+		input.WriteString("\n")
+		if openBrackets(input.String()) > 0 {
+			continue
+		}
+		command := strings.TrimSpace(input.String())
+		input.Reset()
+		if command == ":quit" || command == ":q" {
+			return 0
+		}
+		if err := s.run(command); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+		}
+	}
+	fmt.Fprintln(stdout)
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "plutigo: read input: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// run runs one command or evaluates one term.
+func (s *session) run(command string) error {
+	word, rest, _ := strings.Cut(command, " ")
+	rest = strings.TrimSpace(rest)
+	switch word {
+	case "":
+		return nil
+	case ":help", ":h":
+		fmt.Fprintln(s.stdout, replHelp)
+		return nil
+	case ":load", ":l":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 2 {
+			return errors.New("usage: :load FILE [NAME]")
+		}
+		name := strings.TrimSuffix(filepath.Base(fields[0]), filepath.Ext(fields[0]))
+		if len(fields) == 2 {
+			name = fields[1]
+		}
+		return s.load(fields[0], name)
+	case ":type", ":t":
+		term, err := s.term(rest)
+		if err != nil {
+			return err
+		}
+		outcome, result, err := s.evaluate(term)
+		if err != nil {
+			return err
+		}
+		if !outcome.Success {
+			return errors.New(outcome.Error)
+		}
+		fmt.Fprintln(s.stdout, resultType(result))
+		return nil
+	case ":budget", ":b":
+		if rest != "" {
+			budget, err := parseBudget(rest)
+			if err != nil {
+				return err
+			}
+			s.budget = budget
+		}
+		fmt.Fprintf(s.stdout, "Budget: cpu %d, mem %d\n", s.budget.Cpu, s.budget.Mem)
+		return nil
+	case ":version", ":v":
+		language, protoVersion := s.language, s.protoVersion
+		for _, field := range strings.Fields(rest) {
+			var err error
+			if strings.HasPrefix(field, "PlutusV") {
+				language, err = parseLanguage(field)
+			} else {
+				protoVersion, err = parseProtocolVersion(field)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.setVersion(language, protoVersion); err != nil {
+			return err
+		}
+		fmt.Fprintln(s.stdout, s.versionText())
+		return nil
+	case ":env", ":e":
+		for _, name := range s.names {
+			fmt.Fprintf(s.stdout, "%s = %s\n", name, syn.FormatTerm[syn.Name](s.definitions[name], syn.DefaultLayout))
+		}
+		return nil
+	case "let":
+		name, definition, ok := strings.Cut(rest, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isIdentifier(name) {
+			return errors.New("usage: let NAME = TERM")
+		}
+		term, err := s.term(definition)
+		if err != nil {
+			return err
+		}
+		s.define(name, term, termVersion(term))
+		return nil
+	}
+	if strings.HasPrefix(word, ":") {
+		return fmt.Errorf("unknown command %s; type :help for help", word)
+	}
+
+	term, err := s.term(command)
+	if err != nil {
+		return err
+	}
+	outcome, _, err := s.evaluate(term)
+	if err != nil {
+		return err
+	}
+	writeOutcome(s.stdout, outcome)
+	return nil
+}
+
+// setVersion switches the language and protocol version, and the cost
+// model that goes with them. Definitions whose program version the new
+// language does not accept are dropped.
+func (s *session) setVersion(language builtin.PlutusVersion, protoVersion cek.ProtoVersion) error {
+	evalContext, err := loadEvalContext(s.costModelPath, language, protoVersion)
+	if err != nil {
+		return err
+	}
+	s.language, s.protoVersion, s.evalContext = language, protoVersion, evalContext
+	names := s.names[:0]
+	for _, name := range s.names {
+		if err := checkProgramVersion(s.versions[name], language, protoVersion); err != nil {
+			fmt.Fprintf(s.stdout, "Dropped %s: %v\n", name, err)
+			delete(s.definitions, name)
+			delete(s.versions, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	s.names = names
+	return nil
+}
+
+func (s *session) versionText() string {
+	version := s.programVersion()
+	return fmt.Sprintf("PlutusV%d, protocol version %d.%d, program version %d.%d.%d",
+		s.language, s.protoVersion.Major, s.protoVersion.Minor, version[0], version[1], version[2])
+}
+
+// programVersion is the latest program version the language accepts at the
+// protocol version, which decides the syntax terms may use.
+func (s *session) programVersion() lang.LanguageVersion {
+	versions := policy.ProgramVersions(s.language, s.protoVersion.Major)
+	return versions[len(versions)-1]
+}
+
+// term parses a term and substitutes the definitions of its free
+// variables.
+func (s *session) term(input string) (syn.Term[syn.Name], error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, errors.New("missing term")
+	}
+	term, err := syn.ParseTerm(input, s.programVersion())
+	if err != nil {
+		return nil, fmt.Errorf("parse term: %w", err)
+	}
+	return s.resolve(term, nil)
+}
+
+func (s *session) define(name string, term syn.Term[syn.Name], version lang.LanguageVersion) {
+	if _, ok := s.definitions[name]; !ok {
+		s.names = append(s.names, name)
+	}
+	s.definitions[name] = term
+	s.versions[name] = version
+}
+
+func (s *session) load(path, name string) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("%q is not a name; use :load FILE NAME", name)
+	}
+	// Standard input holds the session's commands.
+	if path == "-" {
+		return errors.New("cannot load a program from standard input")
+	}
+	b, err := readInput(path, nil)
+	if err != nil {
+		return err
+	}
+	program, _, err := convertInput(b, formatAuto, false)
+	if err != nil {
+		return err
+	}
+	if err := checkProgramVersion(program.Version, s.language, s.protoVersion); err != nil {
+		return err
+	}
+	s.define(name, program.Term, program.Version)
+	fmt.Fprintf(s.stdout, "Loaded %s (program %d.%d.%d)\n", name, program.Version[0], program.Version[1], program.Version[2])
+	return nil
+}
+
+// evaluate runs a closed term with the session's budget.
+func (s *session) evaluate(term syn.Term[syn.Name]) (evalOutcome, syn.Term[syn.DeBruijn], error) {
+	program, err := syn.NameToDeBruijn(&syn.Program[syn.Name]{Version: s.programVersion(), Term: term})
+	if err != nil {
+		return evalOutcome{}, nil, fmt.Errorf("convert term: %w", err)
+	}
+	version, _ := policy.LanguageVersion(s.language)
+	outcome, result := evaluate(version, s.evalContext, s.budget, program.Term)
+	return outcome, result, nil
+}
+
+// resolve replaces the free variables of term, outside the names bound
+// around it, with their definitions. Definitions are closed, so they
+// capture no variables.
+func (s *session) resolve(term syn.Term[syn.Name], bound []string) (syn.Term[syn.Name], error) {
+	switch t := term.(type) {
+	case *syn.Var[syn.Name]:
+		if slices.Contains(bound, t.Name.Text) {
+			return t, nil
+		}
+		definition, ok := s.definitions[t.Name.Text]
+		if !ok {
+			return nil, fmt.Errorf("unbound variable %s", t.Name.Text)
+		}
+		return definition, nil
+	case *syn.Lambda[syn.Name]:
+		body, err := s.resolve(t.Body, append(bound[:len(bound):len(bound)], t.ParameterName.Text))
+		if err != nil {
+			return nil, err
+		}
+		return &syn.Lambda[syn.Name]{ParameterName: t.ParameterName, Body: body}, nil
+	case *syn.Delay[syn.Name]:
+		inner, err := s.resolve(t.Term, bound)
+		if err != nil {
+			return nil, err
+		}
+		return &syn.Delay[syn.Name]{Term: inner}, nil
+	case *syn.Force[syn.Name]:
+		inner, err := s.resolve(t.Term, bound)
+		if err != nil {
+			return nil, err
+		}
+		return &syn.Force[syn.Name]{Term: inner}, nil
+	case *syn.Apply[syn.Name]:
+		function, err := s.resolve(t.Function, bound)
+		if err != nil {
+			return nil, err
+		}
+		argument, err := s.resolve(t.Argument, bound)
+		if err != nil {
+			return nil, err
+		}
+		return &syn.Apply[syn.Name]{Function: function, Argument: argument}, nil
+	case *syn.Constr[syn.Name]:
+		fields := make([]syn.Term[syn.Name], len(t.Fields))
+		for i, field := range t.Fields {
+			resolved, err := s.resolve(field, bound)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = resolved
+		}
+		return &syn.Constr[syn.Name]{Tag: t.Tag, Fields: fields}, nil
+	case *syn.Case[syn.Name]:
+		constr, err := s.resolve(t.Constr, bound)
+		if err != nil {
+			return nil, err
+		}
+		branches := make([]syn.Term[syn.Name], len(t.Branches))
+		for i, branch := range t.Branches {
+			resolved, err := s.resolve(branch, bound)
+			if err != nil {
+				return nil, err
+			}
+			branches[i] = resolved
+		}
+		return &syn.Case[syn.Name]{Constr: constr, Branches: branches}, nil
+	default:
+		return term, nil
+	}
+}
+
+// termVersion is the oldest program version with the syntax term uses:
+// constr and case need 1.1.0.
+func termVersion(term syn.Term[syn.Name]) lang.LanguageVersion {
+	switch t := term.(type) {
+	case *syn.Constr[syn.Name], *syn.Case[syn.Name]:
+		return policy.ProgramVersion110
+	case *syn.Lambda[syn.Name]:
+		return termVersion(t.Body)
+	case *syn.Delay[syn.Name]:
+		return termVersion(t.Term)
+	case *syn.Force[syn.Name]:
+		return termVersion(t.Term)
+	case *syn.Apply[syn.Name]:
+		if termVersion(t.Function) == policy.ProgramVersion110 {
+			return policy.ProgramVersion110
+		}
+		return termVersion(t.Argument)
+	default:
+		return policy.ProgramVersion100
+	}
+}
+
+// resultType describes the result of an evaluation: the type of a
+// constant, or what kind of value it is otherwise.
+func resultType(result syn.Term[syn.DeBruijn]) string {
+	switch t := result.(type) {
+	case *syn.Constant:
+		return syn.FormatType(t.Con.Typ())
+	case *syn.Lambda[syn.DeBruijn], *syn.Builtin, *syn.Apply[syn.DeBruijn]:
+		return "function"
+	case *syn.Delay[syn.DeBruijn], *syn.Force[syn.DeBruijn]:
+		return "delayed"
+	case *syn.Constr[syn.DeBruijn]:
+		return fmt.Sprintf("constr %d with %d fields", t.Tag, len(t.Fields))
+	default:
+		return fmt.Sprintf("%T", result)
+	}
+}
+
+// isIdentifier reports whether name lexes as a variable.
+func isIdentifier(name string) bool {
+	term, err := syn.ParseTerm(name, lang.LanguageVersionV1)
+	if err != nil {
+		return false
+	}
+	v, ok := term.(*syn.Var[syn.Name])
+	return ok && v.Name.Text == name
+}
+
+// openBrackets counts the brackets in input left open, outside strings and
+// comments.
+func openBrackets(input string) int {
+	open := 0
+	inString := false
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '-' && i+1 < len(input) && input[i+1] == '-':
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		case c == '(' || c == '[':
+			open++
+		case c == ')' || c == ']':
+			open--
+		}
+	}
+	return open
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRepl(t *testing.T) {
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "double.uplc")
+	if err := os.WriteFile(fixture, []byte(`(program 1.0.0 (lam x [(builtin multiplyInteger) x (con integer 2)]))`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	caseFixture := filepath.Join(dir, "seven.uplc")
+	if err := os.WriteFile(caseFixture, []byte(`(program 1.1.0 (case (constr 0) (con integer 7)))`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		input  []string
+		stdout []string
+		stderr string
+	}{
+		{"evaluate", []string{"(con integer 1)"}, []string{"Result: (con integer 1)\nBudget: cpu 16100, mem 200\n"}, ""},
+		{"traces", []string{`[(force (builtin trace)) (con string "hi") (con unit ())]`}, []string{"Traces:\n  hi\n"}, ""},
+		{
+			"definitions",
+			[]string{"let add = (lam a (lam b [(builtin addInteger) a b]))", "let three = [add (con integer 1) (con integer 2)]", "[add three three]", ":env"},
+			[]string{"Result: (con integer 6)", "add = (lam a (lam b [[(builtin addInteger) a] b]))\nthree = "},
+			"",
+		},
+		{"shadowed definition", []string{"let x = (con integer 1)", "[(lam x x) (con integer 2)]"}, []string{"Result: (con integer 2)"}, ""},
+		{"multi-line term", []string{"[(lam x", "  x)", "  (con bool True)]"}, []string{"| | Result: (con bool True)"}, ""},
+		{"load", []string{":load " + fixture, "[double (con integer 21)]", ":load " + fixture + " twice", ":type twice"}, []string{"Loaded double (program 1.0.0)", "Result: (con integer 42)", "Loaded twice", "> function\n"}, ""},
+		{"type", []string{":type (con (list (pair integer data)) [])", ":type (delay (error))", ":type (constr 1 (con unit ()))"}, []string{"(list (pair integer data))\n", "delayed\n", "constr 1 with 1 fields\n"}, ""},
+		{"budget", []string{":budget 1000,1000", "(con integer 1)", ":budget"}, []string{"Error: out of budget", "Budget: cpu 1000, mem 1000\n"}, ""},
+		{"version", []string{":version PlutusV1 8", "(case (constr 0) (con unit ()))"}, []string{"PlutusV1, protocol version 8.0, program version 1.0.0"}, "case can't be used before 1.1.0"},
+		{"load version", []string{":version PlutusV1 7", ":load " + caseFixture, "seven"}, []string{}, "program version 1.1.0 is not allowed for PlutusV1 at protocol version 7"},
+		{
+			"version drops definitions",
+			[]string{":load " + caseFixture, "let one = (con integer 1)", "let two = (constr 2)", ":version PlutusV1 7", ":env"},
+			[]string{"Dropped seven: program version 1.1.0 is not allowed", "Dropped two:", "\n> one = (con integer 1)\n> \n"},
+			"",
+		},
+		{"quit", []string{":quit", "(con integer 1)"}, []string{}, ""},
+		{"unbound variable", []string{"[f (con integer 1)]"}, []string{}, "unbound variable f"},
+		{"bad definition", []string{"let 1 = (con integer 1)"}, []string{}, "usage: let NAME = TERM"},
+		{"bad load", []string{":load " + filepath.Join(dir, "missing.uplc")}, []string{}, "read program"},
+		{"load standard input", []string{":load - foo", "(con integer 1)"}, []string{"Result: (con integer 1)"}, "cannot load a program from standard input"},
+		{"bad version", []string{":version PlutusV9"}, []string{}, `unknown language "PlutusV9"`},
+		{"unknown command", []string{":bogus"}, []string{}, "unknown command :bogus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			stdin := strings.NewReader(strings.Join(tt.input, "\n") + "\n")
+			if code := run([]string{"repl"}, stdin, &stdout, &stderr); code != 0 {
+				t.Fatalf("run() code = %d; stderr=%s", code, stderr.String())
+			}
+			for _, want := range tt.stdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("run() stdout = %q, want %q", stdout.String(), want)
+				}
+			}
+			if tt.name == "quit" && strings.Contains(stdout.String(), "Result") {
+				t.Errorf("run() went on after :quit: %q", stdout.String())
+			}
+			if tt.stderr == "" && stderr.Len() > 0 || !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestOpenBrackets(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"(con integer 1)", 0},
+		{"[(lam x", 2},
+		{`(con string "(")`, 0},
+		{`(con string "\"(")`, 0},
+		{"(lam x -- (\n", 1},
+	}
+	for _, tt := range tests {
+		if got := openBrackets(tt.input); got != tt.want {
+			t.Errorf("openBrackets(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
@@ -56,6 +56,7 @@ type Constr struct {
 	Tag      uint
 	Fields   []PlutusData
 	useIndef *bool
+	cbor     *preserved
 }
 
 func (Constr) isPlutusData() {}
@@ -75,6 +76,13 @@ func (c *Constr) UnmarshalCBOR(data []byte) error {
 }
 
 func (c Constr) MarshalCBOR() ([]byte, error) {
+	return c.encodeCBOR(new(encodeState))
+}
+
+func (c *Constr) encodeCBOR(state *encodeState) ([]byte, error) {
+	if raw := state.rawCBOR(c); raw != nil {
+		return raw, nil
+	}
 	// Determine whether to use indefinite-length encoding for fields.
 	// If useIndef is explicitly set, honor it; otherwise default to
 	// Haskell's cborg behavior: indefinite for non-empty, definite for empty.
@@ -83,7 +91,7 @@ func (c Constr) MarshalCBOR() ([]byte, error) {
 		useIndef = *c.useIndef
 	}
 
-	fields, err := encodeCBORArray(c.Fields, useIndef, "Constr field")
+	fields, err := encodeCBORArray(state, c.Fields, useIndef, "Constr field")
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +130,7 @@ func (c Constr) Clone() PlutusData {
 func (c Constr) Equal(pd PlutusData) bool {
 	pdConstr, ok := pd.(*Constr)
 	if !ok {
-		return false
+		return equalLazy(&c, pd)
 	}
 	if c.Tag != pdConstr.Tag {
 		return false
@@ -165,6 +173,7 @@ func NewConstrDefIndef(
 // is used. Empty slices always use definite-length encoding regardless of
 // useIndef, matching Haskell's cborg behavior.
 func encodeCBORArray(
+	state *encodeState,
 	items []PlutusData,
 	useIndef bool,
 	desc string,
@@ -176,7 +185,7 @@ func encodeCBORArray(
 		var buf bytes.Buffer
 		buf.WriteByte(0x9F) // Start indefinite-length array
 		for i, item := range items {
-			encoded, err := cborMarshal(item)
+			encoded, err := state.encode(item)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"failed to encode %s %d: %w",
@@ -192,7 +201,7 @@ func encodeCBORArray(
 	}
 	encoded := make([]cbor.RawMessage, len(items))
 	for i, item := range items {
-		raw, err := cborMarshal(item)
+		raw, err := state.encode(item)
 		if err != nil {
 			return nil, fmt.Errorf(
 				"failed to encode %s %d: %w",
@@ -211,6 +220,7 @@ func encodeCBORArray(
 type Map struct {
 	Pairs    [][2]PlutusData // Each pair is [key, value]
 	useIndef *bool
+	cbor     *preserved
 }
 
 func (Map) isPlutusData() {}
@@ -229,6 +239,13 @@ func (m *Map) UnmarshalCBOR(data []byte) error {
 }
 
 func (m Map) MarshalCBOR() ([]byte, error) {
+	return m.encodeCBOR(new(encodeState))
+}
+
+func (m *Map) encodeCBOR(state *encodeState) ([]byte, error) {
+	if raw := state.rawCBOR(m); raw != nil {
+		return raw, nil
+	}
 	// The below is a hack to work around our CBOR library not supporting encoding a map
 	// with a specific key order. We pre-encode each key/value pair, build a dummy list to
 	// steal and modify its header, and build our own output from pieces. This avoids
@@ -243,11 +260,11 @@ func (m Map) MarshalCBOR() ([]byte, error) {
 	// Build encoded pairs into buffer directly to avoid allocations
 	var pairsBuf bytes.Buffer
 	for _, pair := range m.Pairs {
-		keyRaw, err := cborMarshal(pair[0])
+		keyRaw, err := state.encode(pair[0])
 		if err != nil {
 			return nil, fmt.Errorf("encode map key: %w", err)
 		}
-		valueRaw, err := cborMarshal(pair[1])
+		valueRaw, err := state.encode(pair[1])
 		if err != nil {
 			return nil, fmt.Errorf("encode map value: %w", err)
 		}
@@ -293,7 +310,7 @@ func (m Map) Clone() PlutusData {
 func (m Map) Equal(pd PlutusData) bool {
 	pdMap, ok := pd.(*Map)
 	if !ok {
-		return false
+		return equalLazy(&m, pd)
 	}
 	if len(m.Pairs) != len(pdMap.Pairs) {
 		return false
@@ -331,6 +348,7 @@ func NewMapDefIndef(useIndef bool, pairs [][2]PlutusData) PlutusData {
 
 type Integer struct {
 	Inner *big.Int
+	cbor  *preserved
 }
 
 func (Integer) isPlutusData() {}
@@ -340,18 +358,21 @@ func (i *Integer) UnmarshalCBOR(data []byte) error {
 }
 
 func (i Integer) MarshalCBOR() ([]byte, error) {
+	if raw := preservedCBOR(&i); raw != nil {
+		return raw, nil
+	}
 	return cborMarshal(i.Inner)
 }
 
 func (i Integer) Clone() PlutusData {
 	tmpVal := new(big.Int).Set(i.Inner)
-	return &Integer{tmpVal}
+	return &Integer{Inner: tmpVal}
 }
 
 func (i Integer) Equal(pd PlutusData) bool {
 	pdInt, ok := pd.(*Integer)
 	if !ok {
-		return false
+		return equalLazy(&i, pd)
 	}
 	if i.Inner.Cmp(pdInt.Inner) != 0 {
 		return false
@@ -366,13 +387,14 @@ func (i Integer) String() string {
 // NewInteger creates a new Integer variant.
 func NewInteger(value *big.Int) PlutusData {
 	tmpVal := new(big.Int).Set(value)
-	return &Integer{tmpVal}
+	return &Integer{Inner: tmpVal}
 }
 
 // ByteString
 
 type ByteString struct {
 	Inner []byte
+	cbor  *preserved
 }
 
 func (ByteString) isPlutusData() {}
@@ -385,6 +407,9 @@ func (b *ByteString) UnmarshalCBOR(data []byte) error {
 }
 
 func (b ByteString) MarshalCBOR() ([]byte, error) {
+	if raw := preservedCBOR(&b); raw != nil {
+		return raw, nil
+	}
 	// Haskell's Plutus encodes ByteStrings <= 64 bytes as definite-length,
 	// and ByteStrings > 64 bytes as indefinite-length with 64-byte chunks.
 	if len(b.Inner) <= 64 {
@@ -414,13 +439,13 @@ func (b ByteString) MarshalCBOR() ([]byte, error) {
 func (b ByteString) Clone() PlutusData {
 	tmpVal := make([]byte, len(b.Inner))
 	copy(tmpVal, b.Inner)
-	return &ByteString{tmpVal}
+	return &ByteString{Inner: tmpVal}
 }
 
 func (b ByteString) Equal(pd PlutusData) bool {
 	pdByteString, ok := pd.(*ByteString)
 	if !ok {
-		return false
+		return equalLazy(&b, pd)
 	}
 	if !bytes.Equal(b.Inner, pdByteString.Inner) {
 		return false
@@ -436,7 +461,7 @@ func (b ByteString) String() string {
 func NewByteString(value []byte) PlutusData {
 	tmpVal := make([]byte, len(value))
 	copy(tmpVal, value)
-	return &ByteString{tmpVal}
+	return &ByteString{Inner: tmpVal}
 }
 
 // List
@@ -444,6 +469,7 @@ func NewByteString(value []byte) PlutusData {
 type List struct {
 	Items    []PlutusData
 	useIndef *bool
+	cbor     *preserved
 }
 
 func (List) isPlutusData() {}
@@ -741,6 +767,13 @@ func decodeListItemsDefiniteEntered(
 }
 
 func (l List) MarshalCBOR() ([]byte, error) {
+	return l.encodeCBOR(new(encodeState))
+}
+
+func (l *List) encodeCBOR(state *encodeState) ([]byte, error) {
+	if raw := state.rawCBOR(l); raw != nil {
+		return raw, nil
+	}
 	// Determine whether to use indefinite-length encoding.
 	// If useIndef is explicitly set, honor it; otherwise default to
 	// Haskell's cborg behavior: indefinite for non-empty, definite for empty.
@@ -749,7 +782,7 @@ func (l List) MarshalCBOR() ([]byte, error) {
 		useIndef = *l.useIndef
 	}
 
-	result, err := encodeCBORArray(l.Items, useIndef, "list item")
+	result, err := encodeCBORArray(state, l.Items, useIndef, "list item")
 	if err != nil {
 		return nil, err
 	}
@@ -768,7 +801,7 @@ func (l List) Clone() PlutusData {
 func (l List) Equal(pd PlutusData) bool {
 	pdList, ok := pd.(*List)
 	if !ok {
-		return false
+		return equalLazy(&l, pd)
 	}
 	if len(l.Items) != len(pdList.Items) {
 		return false
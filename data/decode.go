@@ -52,9 +52,10 @@ type decodeLimits struct {
 }
 
 type decodeState struct {
-	limits decodeLimits
-	depth  int
-	nodes  int
+	limits   decodeLimits
+	depth    int
+	nodes    int
+	preserve bool
 }
 
 func newDecodeState() *decodeState {
@@ -333,6 +334,20 @@ func decodeNextPlutusDataWithState(
 	}
 	defer state.leaveValue()
 
+	v, rest, err := decodeNextPlutusDataEntered(data, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.preserve {
+		setRawCBOR(v, data[:len(data)-len(rest)])
+	}
+	return v, rest, nil
+}
+
+func decodeNextPlutusDataEntered(
+	data []byte,
+	state *decodeState,
+) (PlutusData, []byte, error) {
 	switch data[0] & CborTypeMask {
 	case CborTypeArray:
 		tmpList, rest, err := decodeListNextEntered(data, state)
@@ -19,6 +19,43 @@
 //	// Encode PlutusData to CBOR bytes
 //	cborBytes, err := data.Encode(plutusData)
 //
+// # Byte-Exact Round Trips and Datum Hashes
+//
+// [Decode] normalizes its input, so re-encoding may not reproduce the
+// original bytes. [DecodePreserving] retains each node's original CBOR so
+// that [Encode] and [Hash] match the on-chain serialization exactly:
+//
+//	datum, err := data.DecodePreserving(cborBytes)
+//	hash, err := data.Hash(datum) // blake2b-256 over the original bytes
+//
+// # Lazy Decoding
+//
+// [DecodeLazy] returns a [Lazy] view over large values that decodes
+// constructor fields, list items and map entries only when accessed:
+//
+//	datum, err := data.DecodeLazy(cborBytes)
+//	owner, err := datum.Index(0)
+//
+// # Streaming
+//
+// [NewStreamDecoder] reads a sequence of CBOR items from an io.Reader,
+// reusing one arena between items. Each value is valid until the next call:
+//
+//	dec := data.NewStreamDecoder(r)
+//	for {
+//		datum, err := dec.Decode()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//	}
+//
+// # Sizes and Costs
+//
+// [ExMemSize] and [NodeCount] return the sizes the cost model charges for
+// data builtins, and [SerializedSize] the encoded length counted against
+// datum and transaction size limits.
+//
 // # Constructor Tags
 //
 // Constr uses special CBOR tags for efficient encoding:
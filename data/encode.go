@@ -25,6 +25,20 @@ func Encode(pd PlutusData) ([]byte, error) {
 	return cborMarshal(pd)
 }
 
+// encode encodes a node inside a container being encoded with state.
+func (s *encodeState) encode(pd PlutusData) ([]byte, error) {
+	switch v := pd.(type) {
+	case *Constr:
+		return v.encodeCBOR(s)
+	case *Map:
+		return v.encodeCBOR(s)
+	case *List:
+		return v.encodeCBOR(s)
+	default:
+		return cborMarshal(pd)
+	}
+}
+
 // cborMarshal acts like cbor.Marshal but allows us to set our own encoder options
 func cborMarshal(data any) ([]byte, error) {
 	em := encMode
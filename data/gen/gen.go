@@ -0,0 +1,204 @@
+// Package gen generates random PlutusData values for property-based tests.
+//
+// A [Generator] produces values within the limits of a [Config] and biases
+// its choices toward encoding edge cases: byte strings around the 64-byte
+// chunk boundary, integers at the int64/uint64 boundaries, bigints that need
+// CBOR tags 2 and 3, constructor tags in each of the three CBOR tag ranges,
+// and both definite- and indefinite-length containers.
+//
+//	g := gen.New(rand.New(rand.NewSource(1)), gen.DefaultConfig())
+//	pd := g.Data()
+//
+// [Value] implements [testing/quick.Generator], so it can be used directly as
+// a quick.Check argument:
+//
+//	quick.Check(func(v gen.Value) bool { ... }, nil)
+package gen
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// Config bounds the values produced by a Generator.
+type Config struct {
+	// MaxDepth is the maximum nesting depth; 1 produces only Integer and
+	// ByteString leaves.
+	MaxDepth int
+	// MaxLength bounds the number of constructor fields, list items and map
+	// pairs.
+	MaxLength int
+	// MaxIntegerBits bounds the bit length of generated integers.
+	MaxIntegerBits int
+	// MaxBytesLength bounds the length of generated byte strings.
+	MaxBytesLength int
+}
+
+// DefaultConfig returns limits suitable for fast round-trip tests while still
+// covering bigints and chunked byte strings.
+func DefaultConfig() Config {
+	return Config{
+		MaxDepth:       5,
+		MaxLength:      6,
+		MaxIntegerBits: 256,
+		MaxBytesLength: 130,
+	}
+}
+
+// Generator produces random PlutusData values. It is not safe for concurrent
+// use.
+type Generator struct {
+	rand   *rand.Rand
+	config Config
+}
+
+// New returns a Generator drawing from r within the given limits.
+func New(r *rand.Rand, config Config) *Generator {
+	return &Generator{rand: r, config: config}
+}
+
+// Data returns a random PlutusData value.
+func (g *Generator) Data() data.PlutusData {
+	return g.data(g.config.MaxDepth)
+}
+
+// Integer returns a random integer, favoring encoding boundaries.
+func (g *Generator) Integer() *big.Int {
+	maxBits := max(g.config.MaxIntegerBits, 1)
+	var v *big.Int
+	switch g.rand.Intn(4) {
+	case 0:
+		v = big.NewInt(int64(g.rand.Intn(49) - 24))
+	case 1:
+		edges := integerEdges()
+		v = new(big.Int).Set(edges[g.rand.Intn(len(edges))])
+	default:
+		bits := 1 + g.rand.Intn(maxBits)
+		v = new(big.Int).Rand(g.rand, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		if g.rand.Intn(2) == 0 {
+			v.Neg(v)
+		}
+	}
+	// Keep edge values inside the configured magnitude.
+	if v.BitLen() > maxBits {
+		v.Rsh(v, uint(v.BitLen()-maxBits))
+	}
+	return v
+}
+
+// Bytes returns a random byte string, favoring lengths around the 64-byte
+// chunk boundary used by the canonical encoding.
+func (g *Generator) Bytes() []byte {
+	maxLen := max(g.config.MaxBytesLength, 0)
+	var n int
+	if g.rand.Intn(2) == 0 {
+		edges := []int{0, 1, 32, 63, 64, 65, 127, 128, 129}
+		n = edges[g.rand.Intn(len(edges))]
+	} else {
+		n = g.rand.Intn(maxLen + 1)
+	}
+	n = min(n, maxLen)
+	b := make([]byte, n)
+	_, _ = g.rand.Read(b)
+	return b
+}
+
+func (g *Generator) data(depth int) data.PlutusData {
+	if depth <= 1 {
+		return g.leaf()
+	}
+	switch g.rand.Intn(5) {
+	case 0:
+		return g.constr(depth)
+	case 1:
+		return g.list(depth)
+	case 2:
+		return g.dataMap(depth)
+	default:
+		return g.leaf()
+	}
+}
+
+func (g *Generator) leaf() data.PlutusData {
+	if g.rand.Intn(2) == 0 {
+		return data.NewInteger(g.Integer())
+	}
+	return data.NewByteString(g.Bytes())
+}
+
+func (g *Generator) constr(depth int) data.PlutusData {
+	var tag uint
+	switch g.rand.Intn(4) {
+	case 0:
+		tag = uint(g.rand.Intn(7)) // CBOR tags 121-127
+	case 1:
+		tag = 7 + uint(g.rand.Intn(121)) // CBOR tags 1280-1400
+	case 2:
+		edges := []uint{0, 6, 7, 127, 128, 1 << 16, math.MaxUint32}
+		tag = edges[g.rand.Intn(len(edges))]
+	default:
+		tag = 128 + uint(g.rand.Uint32()) // CBOR tag 102
+	}
+	return data.NewConstrDefIndef(g.rand.Intn(2) == 0, tag, g.items(depth)...)
+}
+
+func (g *Generator) list(depth int) data.PlutusData {
+	return data.NewListDefIndef(g.rand.Intn(2) == 0, g.items(depth)...)
+}
+
+func (g *Generator) dataMap(depth int) data.PlutusData {
+	pairs := make([][2]data.PlutusData, g.length())
+	for i := range pairs {
+		pairs[i] = [2]data.PlutusData{g.data(depth - 1), g.data(depth - 1)}
+	}
+	return data.NewMapDefIndef(g.rand.Intn(2) == 0, pairs)
+}
+
+func (g *Generator) items(depth int) []data.PlutusData {
+	items := make([]data.PlutusData, g.length())
+	for i := range items {
+		items[i] = g.data(depth - 1)
+	}
+	return items
+}
+
+func (g *Generator) length() int {
+	return g.rand.Intn(max(g.config.MaxLength, 0) + 1)
+}
+
+func integerEdges() []*big.Int {
+	one := big.NewInt(1)
+	twoTo64 := new(big.Int).Lsh(one, 64)
+	return []*big.Int{
+		big.NewInt(0),
+		big.NewInt(23),
+		big.NewInt(24),
+		big.NewInt(-24),
+		big.NewInt(-25),
+		big.NewInt(1<<63 - 1),
+		big.NewInt(-1 << 63),
+		new(big.Int).Sub(twoTo64, one),
+		twoTo64,
+		new(big.Int).Neg(twoTo64),
+		new(big.Int).Sub(new(big.Int).Neg(twoTo64), one),
+		new(big.Int).Lsh(one, 512),
+	}
+}
+
+// Value wraps a random PlutusData value generated with DefaultConfig. It
+// implements testing/quick.Generator.
+type Value struct {
+	Data data.PlutusData
+}
+
+// Generate implements testing/quick.Generator. The quick size hint scales
+// the maximum container length.
+func (Value) Generate(r *rand.Rand, size int) reflect.Value {
+	config := DefaultConfig()
+	config.MaxLength = min(max(size/10, 1), config.MaxLength)
+	return reflect.ValueOf(Value{Data: New(r, config).Data()})
+}
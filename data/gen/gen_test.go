@@ -0,0 +1,94 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	first := New(rand.New(rand.NewSource(42)), DefaultConfig())
+	second := New(rand.New(rand.NewSource(42)), DefaultConfig())
+	for range 50 {
+		a, b := first.Data(), second.Data()
+		if !a.Equal(b) {
+			t.Fatalf("same seed produced %v and %v", a, b)
+		}
+	}
+}
+
+func TestGeneratorRespectsLimits(t *testing.T) {
+	config := Config{
+		MaxDepth:       3,
+		MaxLength:      4,
+		MaxIntegerBits: 70,
+		MaxBytesLength: 65,
+	}
+	g := New(rand.New(rand.NewSource(7)), config)
+	for range 500 {
+		checkLimits(t, g.Data(), config, 1)
+	}
+}
+
+func checkLimits(t *testing.T, pd data.PlutusData, config Config, depth int) {
+	t.Helper()
+	if depth > config.MaxDepth {
+		t.Fatalf("depth %d exceeds MaxDepth %d", depth, config.MaxDepth)
+	}
+	switch v := pd.(type) {
+	case *data.Integer:
+		if v.Inner.BitLen() > config.MaxIntegerBits {
+			t.Fatalf("integer %s exceeds %d bits", v.Inner, config.MaxIntegerBits)
+		}
+	case *data.ByteString:
+		if len(v.Inner) > config.MaxBytesLength {
+			t.Fatalf("byte string length %d exceeds %d", len(v.Inner), config.MaxBytesLength)
+		}
+	case *data.Constr:
+		checkLength(t, len(v.Fields), config)
+		for _, field := range v.Fields {
+			checkLimits(t, field, config, depth+1)
+		}
+	case *data.List:
+		checkLength(t, len(v.Items), config)
+		for _, item := range v.Items {
+			checkLimits(t, item, config, depth+1)
+		}
+	case *data.Map:
+		checkLength(t, len(v.Pairs), config)
+		for _, pair := range v.Pairs {
+			checkLimits(t, pair[0], config, depth+1)
+			checkLimits(t, pair[1], config, depth+1)
+		}
+	default:
+		t.Fatalf("unexpected PlutusData type %T", pd)
+	}
+}
+
+func checkLength(t *testing.T, n int, config Config) {
+	t.Helper()
+	if n > config.MaxLength {
+		t.Fatalf("container length %d exceeds MaxLength %d", n, config.MaxLength)
+	}
+}
+
+func TestQuickCBORRoundTrip(t *testing.T) {
+	roundTrip := func(v Value) bool {
+		encoded, err := data.Encode(v.Data)
+		if err != nil {
+			t.Logf("Encode(%v) failed: %v", v.Data, err)
+			return false
+		}
+		decoded, err := data.Decode(encoded)
+		if err != nil {
+			t.Logf("Decode(%x) failed: %v", encoded, err)
+			return false
+		}
+		return decoded.Equal(v.Data)
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 300}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,28 @@
+package data
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// HashSize is the size in bytes of a datum hash.
+const HashSize = blake2b.Size256
+
+// Hash returns the blake2b-256 datum hash of pd.
+//
+// The hash is computed over the value's CBOR encoding. Values decoded with
+// DecodePreserving hash their original bytes, matching the datum hash
+// recorded on chain even when the input was not canonically encoded.
+func Hash(pd PlutusData) ([HashSize]byte, error) {
+	encoded, err := Encode(pd)
+	if err != nil {
+		return [HashSize]byte{}, fmt.Errorf("encode datum for hashing: %w", err)
+	}
+	return HashBytes(encoded), nil
+}
+
+// HashBytes returns the blake2b-256 datum hash of already-serialized CBOR.
+func HashBytes(cborBytes []byte) [HashSize]byte {
+	return blake2b.Sum256(cborBytes)
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashUnitDatum(t *testing.T) {
+	// The well-known hash of the unit datum, Constr 0 [].
+	const want = "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+	got, err := Hash(NewConstr(0))
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	if hex.EncodeToString(got[:]) != want {
+		t.Fatalf("Hash() = %x, want %s", got, want)
+	}
+}
+
+func TestHashUsesPreservedBytes(t *testing.T) {
+	input, _ := hex.DecodeString("1807")
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	got, err := Hash(preserved)
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	if want := HashBytes(input); got != want {
+		t.Fatalf("Hash() = %x, want %x", got, want)
+	}
+
+	canonical, err := Hash(preserved.Clone())
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	if canonical == got {
+		t.Fatal("Hash() of canonical clone matched non-canonical input hash")
+	}
+}
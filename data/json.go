@@ -255,6 +255,12 @@ func marshalPlutusDataJSON(pd PlutusData) (json.RawMessage, error) {
 		return json.Marshal(v)
 	case Constr:
 		return json.Marshal(v)
+	case *Lazy:
+		forced, err := v.Force()
+		if err != nil {
+			return nil, err
+		}
+		return marshalPlutusDataJSON(forced)
 	default:
 		return nil, fmt.Errorf("unknown PlutusData type: %T", pd)
 	}
@@ -0,0 +1,434 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+)
+
+// LazyKind identifies the PlutusData variant encoded by a Lazy value.
+type LazyKind uint8
+
+const (
+	LazyConstr LazyKind = iota + 1
+	LazyMap
+	LazyList
+	LazyInteger
+	LazyByteString
+)
+
+func (k LazyKind) String() string {
+	switch k {
+	case LazyConstr:
+		return "Constr"
+	case LazyMap:
+		return "Map"
+	case LazyList:
+		return "List"
+	case LazyInteger:
+		return "Integer"
+	case LazyByteString:
+		return "ByteString"
+	default:
+		return fmt.Sprintf("LazyKind(%d)", uint8(k))
+	}
+}
+
+// Lazy is a PlutusData value backed by its original CBOR bytes.
+//
+// Constructor fields, list items and map entries are located the first time
+// they are accessed, and each child is itself a Lazy value, so reading a few
+// fields of a large datum only scans the bytes on the path to those fields.
+// Force materializes a value (and its whole subtree) as ordinary Constr, Map,
+// List, Integer and ByteString nodes using an arena-backed Decoder shared by
+// every Lazy value derived from the same DecodeLazy call.
+//
+// Encoding a Lazy value reproduces the original bytes exactly. The cek
+// machine forces the Lazy values in a constant as the constant enters it;
+// other code that type-switches on the concrete PlutusData types needs the
+// result of Force rather than the Lazy value itself.
+//
+// Lazy values are safe for concurrent use. Each value is indexed and forced
+// at most once.
+type Lazy struct {
+	raw       []byte
+	kind      LazyKind
+	dec       *lazyDecoder
+	indexOnce sync.Once
+	indexErr  error
+	tag       uint
+	children  []*Lazy // Map children alternate key, value
+	forceOnce sync.Once
+	value     PlutusData
+	forceErr  error
+}
+
+// lazyDecoder is the Decoder shared by the Lazy values of one DecodeLazy
+// call, which can be forced from several goroutines.
+type lazyDecoder struct {
+	mu  sync.Mutex
+	dec *Decoder
+}
+
+func newLazyDecoder() *lazyDecoder {
+	return &lazyDecoder{dec: NewDecoder()}
+}
+
+func (*Lazy) isPlutusData() {}
+
+// DecodeLazy validates CBOR-encoded PlutusData and returns a Lazy view of it.
+//
+// The input is scanned once to enforce the same nesting and node-count
+// limits as Decode, but no values are materialized. Semantic errors inside
+// a subtree, such as an unsupported CBOR tag, are reported when that
+// subtree is accessed.
+func DecodeLazy(b []byte) (*Lazy, error) {
+	raw := bytes.Clone(b)
+	rest, err := skipCBORItem(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CBOR: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf(
+			"failed to decode CBOR: unexpected %d trailing bytes",
+			len(rest),
+		)
+	}
+	kind, err := lazyKindOf(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CBOR: %w", err)
+	}
+	return &Lazy{raw: raw, kind: kind, dec: newLazyDecoder()}, nil
+}
+
+// Kind returns the PlutusData variant encoded by l.
+func (l *Lazy) Kind() LazyKind {
+	return l.kind
+}
+
+// Tag returns the constructor tag of a Constr value.
+func (l *Lazy) Tag() (uint, error) {
+	if l.kind != LazyConstr {
+		return 0, fmt.Errorf("lazy %s has no constructor tag", l.kind)
+	}
+	if err := l.index(); err != nil {
+		return 0, err
+	}
+	return l.tag, nil
+}
+
+// Len returns the number of constructor fields, list items or map pairs.
+func (l *Lazy) Len() (int, error) {
+	if err := l.index(); err != nil {
+		return 0, err
+	}
+	if l.kind == LazyMap {
+		return len(l.children) / 2, nil
+	}
+	return len(l.children), nil
+}
+
+// Index returns the i-th field of a Constr or the i-th item of a List.
+func (l *Lazy) Index(i int) (*Lazy, error) {
+	if l.kind != LazyConstr && l.kind != LazyList {
+		return nil, fmt.Errorf("lazy %s is not indexable", l.kind)
+	}
+	if err := l.index(); err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(l.children) {
+		return nil, fmt.Errorf(
+			"index %d out of range for lazy %s of length %d",
+			i,
+			l.kind,
+			len(l.children),
+		)
+	}
+	return l.children[i], nil
+}
+
+// Pair returns the key and value of the i-th entry of a Map.
+func (l *Lazy) Pair(i int) (*Lazy, *Lazy, error) {
+	if l.kind != LazyMap {
+		return nil, nil, fmt.Errorf("lazy %s has no map entries", l.kind)
+	}
+	if err := l.index(); err != nil {
+		return nil, nil, err
+	}
+	if i < 0 || i >= len(l.children)/2 {
+		return nil, nil, fmt.Errorf(
+			"pair %d out of range for lazy Map of length %d",
+			i,
+			len(l.children)/2,
+		)
+	}
+	return l.children[2*i], l.children[2*i+1], nil
+}
+
+// Integer returns the value of an Integer.
+func (l *Lazy) Integer() (*big.Int, error) {
+	if l.kind != LazyInteger {
+		return nil, fmt.Errorf("lazy %s is not an Integer", l.kind)
+	}
+	v, err := l.Force()
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Integer).Inner, nil
+}
+
+// Bytes returns the contents of a ByteString.
+func (l *Lazy) Bytes() ([]byte, error) {
+	if l.kind != LazyByteString {
+		return nil, fmt.Errorf("lazy %s is not a ByteString", l.kind)
+	}
+	v, err := l.Force()
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ByteString).Inner, nil
+}
+
+// Force decodes l and its entire subtree into ordinary PlutusData values.
+// The result is cached and stays valid for the lifetime of l.
+func (l *Lazy) Force() (PlutusData, error) {
+	l.forceOnce.Do(func() {
+		if l.dec == nil {
+			l.dec = newLazyDecoder()
+		}
+		l.dec.mu.Lock()
+		v, err := l.dec.dec.decode(l.raw, newDecodeState())
+		l.dec.mu.Unlock()
+		if err != nil {
+			l.forceErr = fmt.Errorf("failed to decode CBOR: %w", err)
+			return
+		}
+		l.value = v
+	})
+	return l.value, l.forceErr
+}
+
+// RawCBOR returns the CBOR bytes backing l. The returned slice must not be
+// modified.
+func (l *Lazy) RawCBOR() []byte {
+	return l.raw
+}
+
+func (l *Lazy) MarshalCBOR() ([]byte, error) {
+	return l.raw, nil
+}
+
+// Clone returns an independent Lazy value over a copy of the same bytes.
+func (l *Lazy) Clone() PlutusData {
+	return &Lazy{raw: bytes.Clone(l.raw), kind: l.kind, dec: newLazyDecoder()}
+}
+
+// Equal reports whether l and pd encode the same PlutusData value. Both
+// sides are materialized with Force when their bytes differ; a value that
+// fails to decode is not equal to anything.
+func (l *Lazy) Equal(pd PlutusData) bool {
+	if other, ok := pd.(*Lazy); ok {
+		if bytes.Equal(l.raw, other.raw) {
+			_, err := l.Force()
+			return err == nil
+		}
+		otherValue, err := other.Force()
+		if err != nil {
+			return false
+		}
+		pd = otherValue
+	}
+	v, err := l.Force()
+	if err != nil {
+		return false
+	}
+	return v.Equal(pd)
+}
+
+func (l *Lazy) String() string {
+	v, err := l.Force()
+	if err != nil {
+		return fmt.Sprintf("Lazy(invalid: %v)", err)
+	}
+	return v.String()
+}
+
+// equalLazy lets the eager types compare equal to a Lazy value, keeping
+// Equal symmetric.
+func equalLazy(x PlutusData, pd PlutusData) bool {
+	l, ok := pd.(*Lazy)
+	if !ok {
+		return false
+	}
+	return l.Equal(x)
+}
+
+func lazyKindOf(raw []byte) (LazyKind, error) {
+	if len(raw) == 0 {
+		return 0, errors.New("empty data")
+	}
+	switch raw[0] & CborTypeMask {
+	case CborTypeUnsignedInt, CborTypeNegativeInt:
+		return LazyInteger, nil
+	case CborTypeByteString:
+		return LazyByteString, nil
+	case CborTypeArray:
+		return LazyList, nil
+	case CborTypeMap:
+		return LazyMap, nil
+	case CborTypeTag:
+		tagNumber, _, err := decodeCBORTag(raw)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case tagNumber == 102 || (tagNumber >= 121 && tagNumber <= 127) || (tagNumber >= 1280 && tagNumber <= 1400):
+			return LazyConstr, nil
+		case tagNumber == 2 || tagNumber == 3:
+			return LazyInteger, nil
+		default:
+			return 0, fmt.Errorf("unknown CBOR tag for PlutusData: %d", tagNumber)
+		}
+	default:
+		return 0, fmt.Errorf(
+			"unsupported CBOR major type 0x%02x for PlutusData",
+			raw[0]&CborTypeMask,
+		)
+	}
+}
+
+// index locates the direct children of a container without decoding them.
+func (l *Lazy) index() error {
+	l.indexOnce.Do(func() {
+		l.indexErr = l.locateChildren()
+	})
+	return l.indexErr
+}
+
+func (l *Lazy) locateChildren() error {
+	var (
+		spans [][]byte
+		err   error
+	)
+	switch l.kind {
+	case LazyConstr:
+		var body []byte
+		l.tag, body, err = lazyConstrBody(l.raw)
+		if err != nil {
+			return err
+		}
+		count, rest, indefinite, err := decodeCBORArray(body)
+		if err != nil {
+			return err
+		}
+		spans, err = splitCBORSequence(rest, count, 1, indefinite)
+		if err != nil {
+			return err
+		}
+	case LazyList:
+		count, rest, indefinite, err := decodeCBORArray(l.raw)
+		if err != nil {
+			return err
+		}
+		spans, err = splitCBORSequence(rest, count, 1, indefinite)
+		if err != nil {
+			return err
+		}
+	case LazyMap:
+		count, rest, indefinite, err := decodeCBORMap(l.raw)
+		if err != nil {
+			return err
+		}
+		if count > math.MaxInt/2 {
+			return fmt.Errorf("CBOR map too large: %d", count)
+		}
+		spans, err = splitCBORSequence(rest, count*2, 2, indefinite)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("lazy %s has no children", l.kind)
+	}
+
+	children := make([]*Lazy, len(spans))
+	for i, span := range spans {
+		kind, err := lazyKindOf(span)
+		if err != nil {
+			return err
+		}
+		children[i] = &Lazy{raw: span, kind: kind, dec: l.dec}
+	}
+	l.children = children
+	return nil
+}
+
+func lazyConstrBody(raw []byte) (uint, []byte, error) {
+	tagNumber, content, err := decodeCBORTag(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch {
+	case tagNumber >= 121 && tagNumber <= 127:
+		return uint(tagNumber) - 121, content, nil
+	case tagNumber >= 1280 && tagNumber <= 1400:
+		return uint(tagNumber) - 1280 + 7, content, nil
+	case tagNumber == 102:
+		fieldCount, rest, useIndef, err := decodeCBORArray(content)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !useIndef && fieldCount != 2 {
+			return 0, nil, fmt.Errorf("constructor 102 outer array has %d items, want 2", fieldCount)
+		}
+		alternative, rest, err := decodeCBORUint(rest)
+		if err != nil {
+			return 0, nil, err
+		}
+		if alternative > math.MaxUint {
+			return 0, nil, fmt.Errorf("constructor alternative too large: %d", alternative)
+		}
+		return uint(alternative), rest, nil
+	default:
+		return 0, nil, fmt.Errorf(
+			"unknown CBOR tag for PlutusData constructor: %d",
+			tagNumber,
+		)
+	}
+}
+
+// splitCBORSequence returns the byte spans of count consecutive items, or of
+// every item up to the break byte when indefinite is set, in which case
+// items are consumed in groups of stride.
+func splitCBORSequence(
+	rest []byte,
+	count int,
+	stride int,
+	indefinite bool,
+) ([][]byte, error) {
+	if !indefinite && count > len(rest) {
+		return nil, fmt.Errorf("CBOR array claims %d items but only %d bytes remain", count, len(rest))
+	}
+	spans := make([][]byte, 0, count)
+	for i := 0; indefinite || i < count; i += stride {
+		if indefinite {
+			if len(rest) == 0 {
+				return nil, errors.New("unterminated indefinite-length CBOR container")
+			}
+			if rest[0] == 0xff {
+				break
+			}
+		}
+		for range stride {
+			item, next, err := splitCBORItem(rest)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, item[:len(item):len(item)])
+			rest = next
+		}
+	}
+	return spans, nil
+}
@@ -0,0 +1,215 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func lazyTestDatum(t *testing.T) ([]byte, PlutusData) {
+	t.Helper()
+	value := NewConstr(
+		1,
+		NewByteString(bytes.Repeat([]byte{0xab}, 100)),
+		NewList(
+			NewInteger(big.NewInt(1)),
+			NewInteger(new(big.Int).Lsh(big.NewInt(1), 80)),
+		),
+		NewMap([][2]PlutusData{
+			{NewByteString([]byte("k")), NewConstr(0)},
+		}),
+		NewConstr(200, NewInteger(big.NewInt(-5))),
+	)
+	encoded, err := Encode(value)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	return encoded, value
+}
+
+func TestDecodeLazyAccessors(t *testing.T) {
+	encoded, _ := lazyTestDatum(t)
+	lazy, err := DecodeLazy(encoded)
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	if lazy.Kind() != LazyConstr {
+		t.Fatalf("Kind() = %s, want Constr", lazy.Kind())
+	}
+	if tag, err := lazy.Tag(); err != nil || tag != 1 {
+		t.Fatalf("Tag() = %d, %v; want 1", tag, err)
+	}
+	if n, err := lazy.Len(); err != nil || n != 4 {
+		t.Fatalf("Len() = %d, %v; want 4", n, err)
+	}
+
+	list, err := lazy.Index(1)
+	if err != nil {
+		t.Fatalf("Index(1) failed: %v", err)
+	}
+	item, err := list.Index(1)
+	if err != nil {
+		t.Fatalf("list Index(1) failed: %v", err)
+	}
+	got, err := item.Integer()
+	if err != nil {
+		t.Fatalf("Integer() failed: %v", err)
+	}
+	if want := new(big.Int).Lsh(big.NewInt(1), 80); got.Cmp(want) != 0 {
+		t.Fatalf("Integer() = %s, want %s", got, want)
+	}
+
+	field0, err := lazy.Index(0)
+	if err != nil {
+		t.Fatalf("Index(0) failed: %v", err)
+	}
+	if field0.children != nil || field0.value != nil {
+		t.Fatal("sibling field was decoded without being accessed")
+	}
+	b, err := field0.Bytes()
+	if err != nil || len(b) != 100 {
+		t.Fatalf("Bytes() = %d bytes, %v; want 100", len(b), err)
+	}
+
+	m, err := lazy.Index(2)
+	if err != nil {
+		t.Fatalf("Index(2) failed: %v", err)
+	}
+	key, value, err := m.Pair(0)
+	if err != nil {
+		t.Fatalf("Pair(0) failed: %v", err)
+	}
+	if k, _ := key.Bytes(); string(k) != "k" {
+		t.Fatalf("Pair(0) key = %q, want k", k)
+	}
+	if value.Kind() != LazyConstr {
+		t.Fatalf("Pair(0) value kind = %s, want Constr", value.Kind())
+	}
+
+	big102, err := lazy.Index(3)
+	if err != nil {
+		t.Fatalf("Index(3) failed: %v", err)
+	}
+	if tag, err := big102.Tag(); err != nil || tag != 200 {
+		t.Fatalf("Tag() = %d, %v; want 200", tag, err)
+	}
+}
+
+func TestDecodeLazyEqualAndEncode(t *testing.T) {
+	encoded, eager := lazyTestDatum(t)
+	lazy, err := DecodeLazy(encoded)
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	if !lazy.Equal(eager) {
+		t.Fatal("Lazy.Equal(eager) = false")
+	}
+	if !eager.Equal(lazy) {
+		t.Fatal("eager.Equal(Lazy) = false")
+	}
+	other, err := DecodeLazy(encoded)
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	if !lazy.Equal(other) || !lazy.Equal(lazy.Clone()) {
+		t.Fatal("Lazy values over identical bytes are not equal")
+	}
+	if lazy.Equal(NewConstr(1)) {
+		t.Fatal("Lazy.Equal() matched a different value")
+	}
+
+	reencoded, err := Encode(lazy)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if !bytes.Equal(reencoded, encoded) {
+		t.Fatalf("Encode() = %x, want %x", reencoded, encoded)
+	}
+
+	field, err := lazy.Index(1)
+	if err != nil {
+		t.Fatalf("Index(1) failed: %v", err)
+	}
+	wrapped, err := Encode(NewList(field))
+	if err != nil {
+		t.Fatalf("Encode() of eager list with Lazy item failed: %v", err)
+	}
+	want, err := Encode(NewList(eager.(*Constr).Fields[1]))
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if !bytes.Equal(wrapped, want) {
+		t.Fatalf("Encode() = %x, want %x", wrapped, want)
+	}
+
+	if _, err := EncodeJSON(lazy); err != nil {
+		t.Fatalf("EncodeJSON() failed: %v", err)
+	}
+}
+
+func TestDecodeLazyErrors(t *testing.T) {
+	if _, err := DecodeLazy([]byte{0x01, 0x02}); err == nil {
+		t.Fatal("DecodeLazy() accepted trailing bytes")
+	}
+	if _, err := DecodeLazy(nestedListCBOR(MaxDecodeNestingDepth + 1)); err == nil {
+		t.Fatal("DecodeLazy() accepted excessive nesting")
+	} else {
+		var limitErr *DecodeLimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("DecodeLazy() error = %v, want DecodeLimitError", err)
+		}
+	}
+
+	// List [text "a"] is valid CBOR but not valid PlutusData.
+	input, _ := hex.DecodeString("816161")
+	lazy, err := DecodeLazy(input)
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	if _, err := lazy.Index(0); err == nil {
+		t.Fatal("Index() accepted a text string item")
+	}
+	if lazy.Equal(lazy) {
+		t.Fatal("invalid Lazy value compared equal to itself")
+	}
+
+	integer, err := DecodeLazy([]byte{0x07})
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	if _, err := integer.Index(0); err == nil {
+		t.Fatal("Index() succeeded on an Integer")
+	}
+	if _, err := integer.Bytes(); err == nil {
+		t.Fatal("Bytes() succeeded on an Integer")
+	}
+}
+
+func TestDecodeLazyConcurrentForce(t *testing.T) {
+	encoded, value := lazyTestDatum(t)
+	lazy, err := DecodeLazy(encoded)
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	results := make([]PlutusData, 8)
+	for i := range results {
+		wg.Go(func() {
+			if i%2 == 0 {
+				if field, err := lazy.Index(0); err == nil {
+					_, _ = field.Force()
+				}
+			}
+			results[i], _ = lazy.Force()
+		})
+	}
+	wg.Wait()
+	for i, got := range results {
+		if got != results[0] || !got.Equal(value) {
+			t.Fatalf("Force() in goroutine %d = %v, want the one cached %v", i, got, value)
+		}
+	}
+}
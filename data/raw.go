@@ -0,0 +1,165 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// DecodePreserving decodes a CBOR-encoded byte slice like Decode, but every
+// returned node also retains the exact CBOR bytes it was decoded from.
+//
+// Encoding a preserved value reproduces the original input byte-for-byte,
+// including non-canonical integer widths, definite/indefinite container
+// choices and byte string chunk boundaries. This is what the ledger hashes,
+// so use DecodePreserving whenever a value must be re-encoded or hashed with
+// Hash after decoding.
+//
+// Each node remembers its contents at decoding time. A node that has been
+// changed since, or that contains a changed node, no longer matches its
+// retained bytes and is encoded canonically instead.
+func DecodePreserving(b []byte) (PlutusData, error) {
+	state := newDecodeState()
+	state.preserve = true
+	// Copy the input once so every retained span shares a single backing
+	// array that the caller cannot mutate afterwards.
+	v, err := decodeWithState(bytes.Clone(b), state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CBOR: %w", err)
+	}
+	return v, nil
+}
+
+// RawCBOR returns the original CBOR bytes retained by DecodePreserving for
+// pd, or nil if pd was not decoded with preservation enabled or has been
+// changed since. The returned slice must not be modified.
+func RawCBOR(pd PlutusData) []byte {
+	return preservedCBOR(pd)
+}
+
+// preserved is the CBOR a node was decoded from, with a shallow snapshot of
+// the node's contents at that time.
+type preserved struct {
+	raw []byte
+	tag uint
+	// items holds the fields of a Constr or the items of a List.
+	items   []PlutusData
+	pairs   [][2]PlutusData
+	integer *big.Int
+	bytes   []byte
+}
+
+// encodeState remembers which preserved containers were found unchanged
+// during one top-level encode, so that each subtree is validated against its
+// snapshot once rather than again by every changed node above it.
+type encodeState struct {
+	checked map[PlutusData][]byte
+}
+
+// preservedCBOR returns the retained bytes of pd if pd and every node in it
+// still hold the contents they were decoded with, and nil otherwise.
+func preservedCBOR(pd PlutusData) []byte {
+	return new(encodeState).rawCBOR(pd)
+}
+
+// rawCBOR is preservedCBOR, reusing the verdicts already reached by s.
+func (s *encodeState) rawCBOR(pd PlutusData) []byte {
+	switch v := pd.(type) {
+	case *Constr:
+		if v.cbor == nil {
+			return nil
+		}
+		return s.remember(pd, v.cbor.raw, func() bool {
+			return v.Tag == v.cbor.tag && s.unchangedItems(v.Fields, v.cbor.items)
+		})
+	case *List:
+		if v.cbor == nil {
+			return nil
+		}
+		return s.remember(pd, v.cbor.raw, func() bool {
+			return s.unchangedItems(v.Items, v.cbor.items)
+		})
+	case *Map:
+		if v.cbor == nil {
+			return nil
+		}
+		return s.remember(pd, v.cbor.raw, func() bool {
+			if len(v.Pairs) != len(v.cbor.pairs) {
+				return false
+			}
+			for i, pair := range v.Pairs {
+				if !s.unchangedItems(pair[:], v.cbor.pairs[i][:]) {
+					return false
+				}
+			}
+			return true
+		})
+	case *Integer:
+		if v.cbor == nil || v.Inner == nil || v.Inner.Cmp(v.cbor.integer) != 0 {
+			return nil
+		}
+		return v.cbor.raw
+	case *ByteString:
+		if v.cbor == nil || !bytes.Equal(v.Inner, v.cbor.bytes) {
+			return nil
+		}
+		return v.cbor.raw
+	default:
+		return nil
+	}
+}
+
+// remember returns raw, the retained bytes of the container pd, if unchanged
+// reports true, calling it at most once per encode.
+func (s *encodeState) remember(pd PlutusData, raw []byte, unchanged func() bool) []byte {
+	if raw, ok := s.checked[pd]; ok {
+		return raw
+	}
+	if !unchanged() {
+		raw = nil
+	}
+	if s.checked == nil {
+		s.checked = make(map[PlutusData][]byte)
+	}
+	s.checked[pd] = raw
+	return raw
+}
+
+// unchangedItems reports whether items are the decoded items, each with its
+// retained bytes still current.
+func (s *encodeState) unchangedItems(items, decoded []PlutusData) bool {
+	if len(items) != len(decoded) {
+		return false
+	}
+	for i, item := range items {
+		if item != decoded[i] || s.rawCBOR(item) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func setRawCBOR(pd PlutusData, raw []byte) {
+	// Cap the span so an append by a careless caller cannot overwrite the
+	// bytes of the sibling that follows it in the shared input buffer.
+	p := &preserved{raw: raw[:len(raw):len(raw)]}
+	switch v := pd.(type) {
+	case *Constr:
+		p.tag = v.Tag
+		p.items = slices.Clone(v.Fields)
+		v.cbor = p
+	case *Map:
+		p.pairs = slices.Clone(v.Pairs)
+		v.cbor = p
+	case *List:
+		p.items = slices.Clone(v.Items)
+		v.cbor = p
+	case *Integer:
+		p.integer = new(big.Int).Set(v.Inner)
+		v.cbor = p
+	case *ByteString:
+		p.bytes = bytes.Clone(v.Inner)
+		v.cbor = p
+	}
+}
@@ -0,0 +1,208 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestDecodePreservingRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		cborHex string
+	}{
+		{name: "non-canonical uint width", cborHex: "1807"},
+		{name: "non-canonical negative width", cborHex: "39007a"},
+		{name: "definite list", cborHex: "820102"},
+		{name: "short byte string chunks", cborHex: "5f4301020343040506ff"},
+		{name: "indefinite map", cborHex: "bf0102ff"},
+		{name: "constr 102 form", cborHex: "d866820080"},
+		{
+			name:    "nested non-canonical fields",
+			cborHex: "d8799f1807d87a80a1183c9f1901f4ffff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := hex.DecodeString(tt.cborHex)
+			if err != nil {
+				t.Fatalf("invalid test hex: %v", err)
+			}
+
+			canonical, err := Decode(input)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			preserved, err := DecodePreserving(input)
+			if err != nil {
+				t.Fatalf("DecodePreserving() failed: %v", err)
+			}
+			if !preserved.Equal(canonical) {
+				t.Fatalf("DecodePreserving() = %v, want %v", preserved, canonical)
+			}
+
+			encoded, err := Encode(preserved)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if !bytes.Equal(encoded, input) {
+				t.Fatalf("Encode() = %x, want %x", encoded, input)
+			}
+			if !bytes.Equal(RawCBOR(preserved), input) {
+				t.Fatalf("RawCBOR() = %x, want %x", RawCBOR(preserved), input)
+			}
+		})
+	}
+}
+
+func TestDecodePreservingNestedSpans(t *testing.T) {
+	// Constr 0 [Integer 7 (two-byte head), List [Integer 500]]
+	input, _ := hex.DecodeString("d8799f1807811901f4ff")
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	constr, ok := preserved.(*Constr)
+	if !ok || len(constr.Fields) != 2 {
+		t.Fatalf("DecodePreserving() = %v, want two-field Constr", preserved)
+	}
+	if got := hex.EncodeToString(RawCBOR(constr.Fields[0])); got != "1807" {
+		t.Fatalf("field 0 RawCBOR() = %s, want 1807", got)
+	}
+	if got := hex.EncodeToString(RawCBOR(constr.Fields[1])); got != "811901f4" {
+		t.Fatalf("field 1 RawCBOR() = %s, want 811901f4", got)
+	}
+}
+
+func TestDecodePreservingCopiesInput(t *testing.T) {
+	input := []byte{0x18, 0x07}
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	input[1] = 0x08
+	if got := hex.EncodeToString(RawCBOR(preserved)); got != "1807" {
+		t.Fatalf("RawCBOR() = %s after mutating input, want 1807", got)
+	}
+}
+
+func TestClonedPreservedValueEncodesCanonically(t *testing.T) {
+	input, _ := hex.DecodeString("1807")
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	clone := preserved.Clone()
+	if RawCBOR(clone) != nil {
+		t.Fatalf("Clone() retained CBOR bytes")
+	}
+	encoded, err := Encode(clone)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if got := hex.EncodeToString(encoded); got != "07" {
+		t.Fatalf("Encode(clone) = %s, want 07", got)
+	}
+}
+
+func TestDecodeDoesNotPreserve(t *testing.T) {
+	input, _ := hex.DecodeString("1807")
+	decoded, err := Decode(input)
+	if err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if RawCBOR(decoded) != nil {
+		t.Fatalf("Decode() retained CBOR bytes")
+	}
+}
+
+func TestModifiedPreservedValueEncodesCanonically(t *testing.T) {
+	// Constr 0 [Integer 7 (non-canonical width), List [Integer 500]]
+	input, _ := hex.DecodeString("d8799f1807811901f4ff")
+	tests := []struct {
+		name   string
+		modify func(*Constr)
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(*Constr) {},
+			want:   "d8799f1807811901f4ff",
+		},
+		{
+			name:   "tag",
+			modify: func(c *Constr) { c.Tag = 1 },
+			want:   "d87a9f1807811901f4ff",
+		},
+		{
+			name:   "replaced field",
+			modify: func(c *Constr) { c.Fields[0] = NewInteger(big.NewInt(8)) },
+			want:   "d8799f08811901f4ff",
+		},
+		{
+			name: "appended field",
+			modify: func(c *Constr) {
+				c.Fields = append(c.Fields, NewByteString([]byte{0xab}))
+			},
+			want: "d8799f1807811901f441abff",
+		},
+		{
+			name:   "integer changed in place",
+			modify: func(c *Constr) { c.Fields[0].(*Integer).Inner.SetInt64(9) },
+			want:   "d8799f09811901f4ff",
+		},
+		{
+			name: "nested item",
+			modify: func(c *Constr) {
+				c.Fields[1].(*List).Items[0] = NewInteger(big.NewInt(1))
+			},
+			want: "d8799f18078101ff",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			preserved, err := DecodePreserving(input)
+			if err != nil {
+				t.Fatalf("DecodePreserving() failed: %v", err)
+			}
+			test.modify(preserved.(*Constr))
+			encoded, err := Encode(preserved)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if got := hex.EncodeToString(encoded); got != test.want {
+				t.Fatalf("Encode() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestModifiedPreservedValueValidatesEachNodeOnce(t *testing.T) {
+	// List [List [... List [Integer 7 (non-canonical width)] ...]]
+	const depth = 100
+	input := append(bytes.Repeat([]byte{0x81}, depth), 0x18, 0x07)
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	innermost := preserved.(*List)
+	for range depth - 1 {
+		innermost = innermost.Items[0].(*List)
+	}
+	innermost.Items[0] = NewInteger(big.NewInt(8))
+
+	state := new(encodeState)
+	encoded, err := state.encode(preserved)
+	if err != nil {
+		t.Fatalf("encode() failed: %v", err)
+	}
+	want := append(bytes.Repeat([]byte{0x81}, depth), 0x08)
+	if !bytes.Equal(encoded, want) {
+		t.Errorf("encode() = %x, want %x", encoded, want)
+	}
+	if len(state.checked) != depth {
+		t.Errorf("validated %d lists, want %d", len(state.checked), depth)
+	}
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// exMemNodeCost is the memory charged for every node of a PlutusData value
+// on top of the size of its integer or byte string payload.
+const exMemNodeCost = 4
+
+// ExMemSize returns the memory usage of pd as measured by the Plutus cost
+// model, in 64-bit words. This is the size used to cost data builtins such
+// as equalsData and serialiseData: 4 words per node plus the word size of
+// every integer and byte string payload.
+//
+// Costing does not decode, so ExMemSize fails on a Lazy value, which must be
+// forced first, and on any other unknown PlutusData implementation.
+func ExMemSize(pd PlutusData) (int64, error) {
+	var acc int64
+	err := walkData(pd, func(node PlutusData) {
+		acc += nodeExMemSize(node)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return acc, nil
+}
+
+// MinExMemSize returns the smaller of ExMemSize(x) and ExMemSize(y), the
+// size used to cost equalsData. It walks both values in step and stops once
+// the smaller one is done, so comparing a large value with a small one
+// costs about as much as sizing the small one. Nodes past that point are
+// not visited and cannot fail.
+func MinExMemSize(x, y PlutusData) (int64, error) {
+	wx := dataWalker{stack: []PlutusData{x}}
+	wy := dataWalker{stack: []PlutusData{y}}
+	var xAcc, yAcc int64
+	for {
+		w, acc := &wx, &xAcc
+		if yAcc < xAcc {
+			w, acc = &wy, &yAcc
+		}
+		node, err := w.next()
+		if err != nil {
+			return 0, err
+		}
+		if node == nil {
+			return *acc, nil
+		}
+		*acc += nodeExMemSize(node)
+	}
+}
+
+// NodeCount returns the number of nodes in pd, counting every constructor,
+// list, map, integer and byte string, including pd itself. This is the size
+// used to cost unValueData. Like ExMemSize, it fails on Lazy values.
+func NodeCount(pd PlutusData) (int64, error) {
+	var count int64
+	err := walkData(pd, func(PlutusData) {
+		count++
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// IntegerExMemSize returns the memory usage of an integer in 64-bit words,
+// with a minimum of one word.
+func IntegerExMemSize(i *big.Int) int64 {
+	if i.Sign() == 0 || i.IsInt64() || i.IsUint64() {
+		return 1
+	}
+	return int64((i.BitLen()-1)/64 + 1)
+}
+
+// BytesExMemSize returns the memory usage of a byte string in 64-bit words,
+// with a minimum of one word.
+func BytesExMemSize(b []byte) int64 {
+	if len(b) == 0 {
+		return 1
+	}
+	return int64((len(b)-1)/8 + 1)
+}
+
+// SerializedSize returns the length in bytes of the CBOR encoding of pd, as
+// produced by Encode and counted against transaction and datum size limits.
+// Values decoded with DecodePreserving report the size of their original
+// bytes.
+func SerializedSize(pd PlutusData) (int, error) {
+	encoded, err := Encode(pd)
+	if err != nil {
+		return 0, fmt.Errorf("encode data for sizing: %w", err)
+	}
+	return len(encoded), nil
+}
+
+// nodeExMemSize is the share of ExMemSize that node adds by itself.
+func nodeExMemSize(node PlutusData) int64 {
+	switch v := node.(type) {
+	case *Integer:
+		return exMemNodeCost + IntegerExMemSize(v.Inner)
+	case *ByteString:
+		return exMemNodeCost + BytesExMemSize(v.Inner)
+	default:
+		return exMemNodeCost
+	}
+}
+
+// walkData calls visit for every node of pd.
+func walkData(pd PlutusData, visit func(PlutusData)) error {
+	w := dataWalker{stack: []PlutusData{pd}}
+	for {
+		node, err := w.next()
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			return nil
+		}
+		visit(node)
+	}
+}
+
+// dataWalker visits the nodes of a PlutusData value one at a time.
+type dataWalker struct {
+	stack []PlutusData
+}
+
+// next returns the next node, or nil once every node has been visited.
+func (w *dataWalker) next() (PlutusData, error) {
+	if len(w.stack) == 0 {
+		return nil, nil
+	}
+	node := w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+	switch v := node.(type) {
+	case *Constr:
+		w.stack = append(w.stack, v.Fields...)
+	case *List:
+		w.stack = append(w.stack, v.Items...)
+	case *Map:
+		for _, pair := range v.Pairs {
+			w.stack = append(w.stack, pair[0], pair[1])
+		}
+	case *Integer, *ByteString:
+	case *Lazy:
+		return nil, errors.New("cannot size lazy data without forcing it")
+	default:
+		return nil, fmt.Errorf("cannot size unknown data variant %T", node)
+	}
+	return node, nil
+}
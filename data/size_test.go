@@ -0,0 +1,158 @@
+package data
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestSizeAccounting(t *testing.T) {
+	tests := []struct {
+		name           string
+		cborHex        string
+		wantExMem      int64
+		wantNodes      int64
+		wantSerialized int
+	}{
+		{name: "zero", cborHex: "00", wantExMem: 5, wantNodes: 1, wantSerialized: 1},
+		{name: "empty bytes", cborHex: "40", wantExMem: 5, wantNodes: 1, wantSerialized: 1},
+		{
+			name:           "list of integer and nine bytes",
+			cborHex:        "820149010203040506070809",
+			wantExMem:      15,
+			wantNodes:      3,
+			wantSerialized: 12,
+		},
+		{
+			name:           "constr with map",
+			cborHex:        "d87981a10140",
+			wantExMem:      18,
+			wantNodes:      4,
+			wantSerialized: 6,
+		},
+		{
+			name:           "two-word bigint",
+			cborHex:        "c249010000000000000000",
+			wantExMem:      6,
+			wantNodes:      1,
+			wantSerialized: 11,
+		},
+		{
+			name:           "uint64 max",
+			cborHex:        "1bffffffffffffffff",
+			wantExMem:      5,
+			wantNodes:      1,
+			wantSerialized: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := hex.DecodeString(tt.cborHex)
+			if err != nil {
+				t.Fatalf("invalid test hex: %v", err)
+			}
+			pd, err := Decode(input)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			lazy, err := DecodeLazy(input)
+			if err != nil {
+				t.Fatalf("DecodeLazy() failed: %v", err)
+			}
+
+			forced, err := lazy.Force()
+			if err != nil {
+				t.Fatalf("Force() failed: %v", err)
+			}
+			if _, err := ExMemSize(lazy); err == nil {
+				t.Errorf("ExMemSize(lazy) succeeded, want an error")
+			}
+			if _, err := NodeCount(lazy); err == nil {
+				t.Errorf("NodeCount(lazy) succeeded, want an error")
+			}
+
+			for _, v := range []PlutusData{pd, forced} {
+				got, err := ExMemSize(v)
+				if err != nil {
+					t.Fatalf("ExMemSize(%T) failed: %v", v, err)
+				}
+				if got != tt.wantExMem {
+					t.Errorf("ExMemSize(%T) = %d, want %d", v, got, tt.wantExMem)
+				}
+				if got, err = NodeCount(v); err != nil {
+					t.Fatalf("NodeCount(%T) failed: %v", v, err)
+				}
+				if got != tt.wantNodes {
+					t.Errorf("NodeCount(%T) = %d, want %d", v, got, tt.wantNodes)
+				}
+			}
+			for _, v := range []PlutusData{pd, lazy} {
+				got, err := SerializedSize(v)
+				if err != nil {
+					t.Fatalf("SerializedSize(%T) failed: %v", v, err)
+				}
+				if got != tt.wantSerialized {
+					t.Errorf("SerializedSize(%T) = %d, want %d", v, got, tt.wantSerialized)
+				}
+			}
+		})
+	}
+}
+
+func TestSerializedSizePreserved(t *testing.T) {
+	input, _ := hex.DecodeString("1807")
+	preserved, err := DecodePreserving(input)
+	if err != nil {
+		t.Fatalf("DecodePreserving() failed: %v", err)
+	}
+	got, err := SerializedSize(preserved)
+	if err != nil {
+		t.Fatalf("SerializedSize() failed: %v", err)
+	}
+	if got != 2 {
+		t.Fatalf("SerializedSize() = %d, want 2", got)
+	}
+	if got, _ := SerializedSize(preserved.Clone()); got != 1 {
+		t.Fatalf("SerializedSize(clone) = %d, want 1", got)
+	}
+}
+
+// unknownData is a PlutusData implementation the size helpers do not know.
+type unknownData struct {
+	PlutusData
+}
+
+func TestSizeUnknownVariant(t *testing.T) {
+	pd := NewList(NewInteger(big.NewInt(1)), unknownData{})
+	if _, err := ExMemSize(pd); err == nil {
+		t.Errorf("ExMemSize() succeeded, want an error")
+	}
+	if _, err := NodeCount(pd); err == nil {
+		t.Errorf("NodeCount() succeeded, want an error")
+	}
+}
+
+func TestMinExMemSize(t *testing.T) {
+	small := NewByteString(nil)
+	large := NewList(NewInteger(big.NewInt(1)), NewByteString(make([]byte, 9)), NewMap(nil))
+	for _, tt := range []struct{ x, y PlutusData }{{small, large}, {large, small}} {
+		got, err := MinExMemSize(tt.x, tt.y)
+		if err != nil || got != 5 {
+			t.Errorf("MinExMemSize(%v, %v) = %d, %v; want 5", tt.x, tt.y, got, err)
+		}
+	}
+	if got, err := MinExMemSize(large, large); err != nil || got != 19 {
+		t.Errorf("MinExMemSize(large, large) = %d, %v; want 19", got, err)
+	}
+
+	// The walk stops before the part of the larger value that cannot be
+	// sized, but not before a failure in the smaller one.
+	broken := NewList(unknownData{}, NewInteger(big.NewInt(1)), NewList(nil))
+	if _, err := MinExMemSize(small, broken); err != nil {
+		t.Errorf("MinExMemSize(small, broken) failed: %v", err)
+	}
+	if _, err := MinExMemSize(broken, broken); err == nil {
+		t.Errorf("MinExMemSize(broken, broken) succeeded, want an error")
+	}
+}
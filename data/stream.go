@@ -0,0 +1,339 @@
+package data
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// streamReadChunk bounds how much buffer is reserved ahead of the bytes
+// actually received, so a forged length prefix cannot force a huge
+// allocation before the data arrives.
+const streamReadChunk = 64 << 10
+
+// streamRetainedBuffer is the largest item buffer a StreamDecoder keeps
+// between items, so one large item does not pin its memory for the rest of
+// the stream.
+const streamRetainedBuffer = 1 << 20
+
+// DefaultStreamMaxItemSize is the largest encoded item, in bytes, that a new
+// StreamDecoder accepts.
+const DefaultStreamMaxItemSize = 16 << 20
+
+// StreamDecodeError reports a failure while decoding an item from a
+// StreamDecoder, along with the byte offset in the stream at which the
+// offending CBOR value starts.
+type StreamDecodeError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *StreamDecodeError) Error() string {
+	return fmt.Sprintf(
+		"failed to decode CBOR at byte offset %d: %v",
+		e.Offset,
+		e.Err,
+	)
+}
+
+func (e *StreamDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// StreamDecoder reads a sequence of CBOR-encoded PlutusData items from an
+// io.Reader, such as the datums of a chain-sync feed.
+//
+// Each item is subject to the same nesting-depth and node-count limits as
+// Decode, and to a limit on its encoded size (see SetMaxItemSize), which are
+// enforced while the item is being read so that an oversized item is
+// rejected without buffering the rest of it.
+//
+// Items are decoded with an arena-backed Decoder that is Reset before every
+// item, so a long-running stream reuses the same memory instead of
+// allocating per item. As a consequence, a value returned by Decode is only
+// valid until the next call to Decode; use Clone to retain it longer.
+//
+// A StreamDecoder is not safe for concurrent use.
+type StreamDecoder struct {
+	r       *bufio.Reader
+	dec     *Decoder
+	buf     []byte
+	maxSize int
+	offset  int64
+	err     error
+}
+
+// NewStreamDecoder returns a StreamDecoder reading from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{
+		r:       bufio.NewReader(r),
+		dec:     NewDecoder(),
+		maxSize: DefaultStreamMaxItemSize,
+	}
+}
+
+// SetMaxItemSize sets the largest encoded item, in bytes, that Decode
+// accepts. A larger item fails with a *DecodeLimitError. Zero or less
+// removes the limit.
+func (s *StreamDecoder) SetMaxItemSize(n int) {
+	s.maxSize = n
+}
+
+// Decode reads and decodes the next item. It returns io.EOF when the stream
+// ends cleanly between items. Any other error is a *StreamDecodeError, and
+// the StreamDecoder returns the same error from every later call.
+func (s *StreamDecoder) Decode() (PlutusData, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if _, err := s.r.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			s.err = io.EOF
+		} else {
+			s.err = &StreamDecodeError{Offset: s.offset, Err: err}
+		}
+		return nil, s.err
+	}
+
+	start := s.offset
+	if cap(s.buf) > streamRetainedBuffer {
+		s.buf = nil
+	}
+	s.buf = s.buf[:0]
+	if err := s.readItem(newDecodeState()); err != nil {
+		var streamErr *StreamDecodeError
+		if !errors.As(err, &streamErr) {
+			streamErr = &StreamDecodeError{Offset: start, Err: err}
+		}
+		s.err = streamErr
+		return nil, s.err
+	}
+
+	s.dec.Reset()
+	v, err := s.dec.decode(s.buf, newDecodeState())
+	if err != nil {
+		s.err = &StreamDecodeError{Offset: start, Err: err}
+		return nil, s.err
+	}
+	return v, nil
+}
+
+// InputOffset returns the number of bytes consumed from the stream so far.
+// Between items this is the offset at which the next item starts.
+func (s *StreamDecoder) InputOffset() int64 {
+	return s.offset
+}
+
+// readItem reads one complete CBOR item into s.buf, mirroring the structure
+// checks of skipCBORItemEntered.
+func (s *StreamDecoder) readItem(state *decodeState) error {
+	itemStart := s.offset
+	fail := func(err error) error {
+		return &StreamDecodeError{Offset: itemStart, Err: err}
+	}
+	if err := state.enterValue(); err != nil {
+		return fail(err)
+	}
+	defer state.leaveValue()
+
+	cborType, value, indefinite, err := s.readHead()
+	if err != nil {
+		return fail(err)
+	}
+
+	switch cborType {
+	case CborTypeUnsignedInt, CborTypeNegativeInt:
+		if indefinite {
+			return fail(errors.New("indefinite CBOR integer is not supported"))
+		}
+		return nil
+	case CborTypeByteString, CborTypeTextString:
+		if err := s.readBytesLike(cborType, value, indefinite); err != nil {
+			return fail(err)
+		}
+		return nil
+	case CborTypeArray:
+		if !indefinite && value > math.MaxInt {
+			return fail(fmt.Errorf("CBOR array too large: %d", value))
+		}
+		return s.readSequence(state, value, 1, indefinite)
+	case CborTypeMap:
+		if !indefinite && value > math.MaxInt/2 {
+			return fail(fmt.Errorf("CBOR map too large: %d", value))
+		}
+		return s.readSequence(state, value*2, 2, indefinite)
+	case CborTypeTag:
+		if indefinite {
+			return fail(errors.New("indefinite CBOR tags are not supported"))
+		}
+		return s.readItem(state)
+	case CborTypeSimple:
+		if indefinite {
+			return fail(errors.New("unexpected CBOR break"))
+		}
+		return nil
+	default:
+		return fail(fmt.Errorf("unsupported CBOR type 0x%02x", cborType))
+	}
+}
+
+// readSequence reads count items, or groups of stride items up to a break
+// byte when indefinite is set.
+func (s *StreamDecoder) readSequence(
+	state *decodeState,
+	count uint64,
+	stride int,
+	indefinite bool,
+) error {
+	if !indefinite {
+		for range count {
+			if err := s.readItem(state); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		done, err := s.readBreak()
+		if err != nil {
+			return &StreamDecodeError{Offset: s.offset, Err: err}
+		}
+		if done {
+			return nil
+		}
+		for range stride {
+			if err := s.readItem(state); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *StreamDecoder) readBytesLike(
+	cborType uint8,
+	value uint64,
+	indefinite bool,
+) error {
+	if !indefinite {
+		return s.readN(value)
+	}
+	for {
+		done, err := s.readBreak()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		chunkType, chunkValue, chunkIndef, err := s.readHead()
+		if err != nil {
+			return err
+		}
+		if chunkIndef || chunkType != cborType {
+			return fmt.Errorf("invalid CBOR chunk type 0x%02x", chunkType)
+		}
+		if err := s.readN(chunkValue); err != nil {
+			return err
+		}
+	}
+}
+
+// readHead reads a CBOR initial byte and its argument.
+func (s *StreamDecoder) readHead() (uint8, uint64, bool, error) {
+	headStart := len(s.buf)
+	initial, err := s.readByte()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	var extra uint64
+	switch initial & 0x1f {
+	case 24:
+		extra = 1
+	case 25:
+		extra = 2
+	case 26:
+		extra = 4
+	case 27:
+		extra = 8
+	}
+	if err := s.readN(extra); err != nil {
+		return 0, 0, false, err
+	}
+	cborType, value, _, indefinite, err := decodeCBORHead(s.buf[headStart:])
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return cborType, value, indefinite, nil
+}
+
+// readBreak consumes a break byte if it is next in the stream.
+func (s *StreamDecoder) readBreak() (bool, error) {
+	next, err := s.r.Peek(1)
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+	if next[0] != 0xff {
+		return false, nil
+	}
+	_, err = s.readByte()
+	return true, err
+}
+
+// reserve checks that n more bytes keep the item within the size limit.
+func (s *StreamDecoder) reserve(n uint64) error {
+	if s.maxSize <= 0 || n <= uint64(s.maxSize-len(s.buf)) {
+		return nil
+	}
+	actual := uint64(len(s.buf)) + n
+	if actual > math.MaxInt || actual < n {
+		actual = math.MaxInt
+	}
+	return &DecodeLimitError{
+		Limit:  "item size",
+		Max:    s.maxSize,
+		Actual: int(actual),
+	}
+}
+
+func (s *StreamDecoder) readByte() (byte, error) {
+	if err := s.reserve(1); err != nil {
+		return 0, err
+	}
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	s.buf = append(s.buf, b)
+	s.offset++
+	return b, nil
+}
+
+// readN appends the next n bytes of the stream to s.buf.
+func (s *StreamDecoder) readN(n uint64) error {
+	if err := s.reserve(n); err != nil {
+		return err
+	}
+	for n > 0 {
+		chunk := int(min(n, streamReadChunk))
+		start := len(s.buf)
+		s.buf = slices.Grow(s.buf, chunk)[:start+chunk]
+		read, err := io.ReadFull(s.r, s.buf[start:])
+		s.buf = s.buf[:start+read]
+		s.offset += int64(read)
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		n -= uint64(chunk)
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamDecoderSequence(t *testing.T) {
+	items := []string{
+		"07",
+		"d8799f1807811901f4ff",
+		"5f4301020343040506ff",
+		"bf0102ff",
+		"c249010000000000000000",
+		"d866820080",
+	}
+	input, err := hex.DecodeString(strings.Join(items, ""))
+	if err != nil {
+		t.Fatalf("invalid test hex: %v", err)
+	}
+
+	readers := map[string]io.Reader{
+		"buffered": bytes.NewReader(input),
+		"one byte": iotest.OneByteReader(bytes.NewReader(input)),
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			dec := NewStreamDecoder(r)
+			var offset int64
+			for i, itemHex := range items {
+				item, _ := hex.DecodeString(itemHex)
+				want, err := Decode(item)
+				if err != nil {
+					t.Fatalf("Decode(%s) failed: %v", itemHex, err)
+				}
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("item %d: Decode() failed: %v", i, err)
+				}
+				if !got.Equal(want) {
+					t.Fatalf("item %d: Decode() = %v, want %v", i, got, want)
+				}
+				offset += int64(len(item))
+				if dec.InputOffset() != offset {
+					t.Fatalf("item %d: InputOffset() = %d, want %d", i, dec.InputOffset(), offset)
+				}
+			}
+			if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+				t.Fatalf("Decode() at end = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		cborHex    string
+		wantOffset int64
+		wantErr    error
+	}{
+		{
+			name:       "truncated second item",
+			cborHex:    "07" + "4401",
+			wantOffset: 1,
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:       "unterminated indefinite list",
+			cborHex:    "9f0102",
+			wantOffset: 3,
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:       "nested text string",
+			cborHex:    "07" + "820161" + "61",
+			wantOffset: 1,
+		},
+		{
+			name:       "indefinite integer",
+			cborHex:    "82" + "01" + "1f",
+			wantOffset: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := hex.DecodeString(tt.cborHex)
+			if err != nil {
+				t.Fatalf("invalid test hex: %v", err)
+			}
+			dec := NewStreamDecoder(bytes.NewReader(input))
+			for {
+				_, err = dec.Decode()
+				if err != nil {
+					break
+				}
+			}
+			var streamErr *StreamDecodeError
+			if !errors.As(err, &streamErr) {
+				t.Fatalf("Decode() error = %v, want *StreamDecodeError", err)
+			}
+			if streamErr.Offset != tt.wantOffset {
+				t.Fatalf("error offset = %d, want %d (%v)", streamErr.Offset, tt.wantOffset, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, again := dec.Decode(); again != err {
+				t.Fatalf("Decode() after error = %v, want sticky %v", again, err)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderEnforcesLimits(t *testing.T) {
+	// One nesting level past the limit, followed by data that is never read.
+	input := append(
+		bytes.Repeat([]byte{0x81}, MaxDecodeNestingDepth),
+		0x00, 0x00, 0x00,
+	)
+	dec := NewStreamDecoder(bytes.NewReader(input))
+	_, err := dec.Decode()
+	var limitErr *DecodeLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Decode() error = %v, want *DecodeLimitError", err)
+	}
+	if limitErr.Limit != "nesting depth" {
+		t.Fatalf("limit = %q, want nesting depth", limitErr.Limit)
+	}
+	var streamErr *StreamDecodeError
+	if !errors.As(err, &streamErr) || streamErr.Offset != MaxDecodeNestingDepth {
+		t.Fatalf("Decode() error = %v, want offset %d", err, MaxDecodeNestingDepth)
+	}
+}
+
+func TestStreamDecoderEmpty(t *testing.T) {
+	dec := NewStreamDecoder(bytes.NewReader(nil))
+	if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Decode() = %v, want io.EOF", err)
+	}
+}
+
+func TestStreamDecoderMaxItemSize(t *testing.T) {
+	// Two byte strings of 5 and 6 encoded bytes.
+	input, err := hex.DecodeString("4401020304" + "450102030405")
+	if err != nil {
+		t.Fatalf("invalid test hex: %v", err)
+	}
+	dec := NewStreamDecoder(bytes.NewReader(input))
+	dec.SetMaxItemSize(5)
+	if _, err := dec.Decode(); err != nil {
+		t.Fatalf("Decode() of an item at the limit failed: %v", err)
+	}
+	_, err = dec.Decode()
+	var limitErr *DecodeLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "item size" ||
+		limitErr.Max != 5 || limitErr.Actual != 6 {
+		t.Fatalf("Decode() error = %v, want item size limit", err)
+	}
+	var streamErr *StreamDecodeError
+	if !errors.As(err, &streamErr) || streamErr.Offset != 5 {
+		t.Fatalf("Decode() error = %v, want offset 5", err)
+	}
+}
+
+func TestStreamDecoderReleasesLargeBuffer(t *testing.T) {
+	large, err := Encode(NewByteString(make([]byte, 2*streamRetainedBuffer)))
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	input := append(large, 0x07)
+	dec := NewStreamDecoder(bytes.NewReader(input))
+	for range 2 {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+	}
+	if cap(dec.buf) > streamRetainedBuffer {
+		t.Fatalf("buffer capacity after a small item = %d, want at most %d", cap(dec.buf), streamRetainedBuffer)
+	}
+}
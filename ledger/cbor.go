@@ -0,0 +1,171 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var decMode cbor.DecMode
+
+func init() {
+	decOptions := cbor.DecOptions{
+		// Native scripts and datums can nest deeper than the default of 32
+		MaxNestedLevels: 256,
+	}
+	var err error
+	decMode, err = decOptions.DecMode()
+	if err != nil {
+		panic("failed to initialize CBOR decoder: " + err.Error())
+	}
+}
+
+// unmarshal decodes raw into v, naming what was being decoded on error.
+// Unregistered tags, such as the 258 set tag, are skipped when decoding
+// into typed values.
+func unmarshal(raw []byte, v any, what string) error {
+	if err := decMode.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("decode %s: %w", what, err)
+	}
+	return nil
+}
+
+// decodeArray splits a CBOR array, optionally wrapped in a set tag, into its
+// raw items.
+func decodeArray(raw []byte, what string) ([]cbor.RawMessage, error) {
+	var items []cbor.RawMessage
+	if err := unmarshal(raw, &items, what); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeMap splits a CBOR map into raw key/value pairs in encoded order.
+// Unlike decoding into a Go map it supports keys that are arrays, such as
+// voters and redeemer pointers.
+func decodeMap(raw []byte, what string) ([][2]cbor.RawMessage, error) {
+	if len(raw) == 0 || raw[0]>>5 != 5 {
+		return nil, fmt.Errorf("decode %s: expected a CBOR map", what)
+	}
+	rest := raw[1:]
+	count := -1
+	switch info := raw[0] & 0x1f; {
+	case info < 24:
+		count = int(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(rest) < size {
+			return nil, fmt.Errorf("decode %s: truncated map header", what)
+		}
+		var n uint64
+		for _, b := range rest[:size] {
+			n = n<<8 | uint64(b)
+		}
+		if n > uint64(len(rest)) {
+			return nil, fmt.Errorf("decode %s: map length %d exceeds input", what, n)
+		}
+		count = int(n)
+		rest = rest[size:]
+	case info == 31:
+		// Indefinite length, terminated by a break byte
+	default:
+		return nil, fmt.Errorf("decode %s: invalid map header", what)
+	}
+
+	var pairs [][2]cbor.RawMessage
+	for i := 0; count < 0 || i < count; i++ {
+		if count < 0 {
+			if len(rest) == 0 {
+				return nil, fmt.Errorf("decode %s: missing map break", what)
+			}
+			if rest[0] == 0xff {
+				rest = rest[1:]
+				break
+			}
+		}
+		var pair [2]cbor.RawMessage
+		for j := range pair {
+			var err error
+			rest, err = decMode.UnmarshalFirst(rest, &pair[j])
+			if err != nil {
+				return nil, fmt.Errorf("decode %s: %w", what, err)
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("decode %s: trailing bytes after map", what)
+	}
+	return pairs, nil
+}
+
+func decodeUint(raw []byte, what string) (uint64, error) {
+	var v uint64
+	err := unmarshal(raw, &v, what)
+	return v, err
+}
+
+func decodeBigInt(raw []byte, what string) (*big.Int, error) {
+	var v big.Int
+	if err := unmarshal(raw, &v, what); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func decodeBytes(raw []byte, what string) ([]byte, error) {
+	var v []byte
+	err := unmarshal(raw, &v, what)
+	return v, err
+}
+
+// decodeHash decodes a byte string of exactly size bytes.
+func decodeHash(raw []byte, size int, what string) ([]byte, error) {
+	v, err := decodeBytes(raw, what)
+	if err != nil {
+		return nil, err
+	}
+	if len(v) != size {
+		return nil, fmt.Errorf("decode %s: got %d bytes, want %d", what, len(v), size)
+	}
+	return v, nil
+}
+
+// isNull reports whether raw is the CBOR null (or undefined) value.
+func isNull(raw []byte) bool {
+	return len(raw) == 1 && (raw[0] == 0xf6 || raw[0] == 0xf7)
+}
+
+// decodeTagged splits a ledger sum type, encoded as an array whose first item
+// is the constructor index, into the index and the remaining fields.
+func decodeTagged(raw []byte, what string) (uint64, []cbor.RawMessage, error) {
+	items, err := decodeArray(raw, what)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(items) == 0 {
+		return 0, nil, fmt.Errorf("decode %s: empty array", what)
+	}
+	tag, err := decodeUint(items[0], what+" tag")
+	if err != nil {
+		return 0, nil, err
+	}
+	return tag, items[1:], nil
+}
+
+// expectFields checks the field count of a decoded sum type constructor.
+func expectFields(fields []cbor.RawMessage, n int, what string) error {
+	if len(fields) != n {
+		return fmt.Errorf(
+			"decode %s: got %d fields, want %d",
+			what,
+			len(fields),
+			n,
+		)
+	}
+	return nil
+}
+
+var errTruncated = errors.New("unexpected end of input")
@@ -0,0 +1,680 @@
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+// certificate is a decoded transaction certificate in both of its Plutus
+// representations.
+type certificate struct {
+	v1 scriptcontext.DCert  // nil when V1/V2 scripts cannot see it
+	v3 scriptcontext.TxCert // nil when V3 scripts cannot see it
+	// credential is the credential that a certifying script witnesses, nil
+	// for pool certificates.
+	credential *scriptcontext.Credential
+	kind       uint64
+}
+
+func decodeCertificate(raw []byte) (certificate, error) {
+	kind, fields, err := decodeTagged(raw, "certificate")
+	if err != nil {
+		return certificate{}, err
+	}
+	arity := map[uint64]int{
+		0: 1, 1: 1, 2: 2, 3: 9, 4: 2, 5: 3, 6: 1,
+		7: 2, 8: 2, 9: 2, 10: 3, 11: 3, 12: 3, 13: 4,
+		14: 2, 15: 2, 16: 3, 17: 2, 18: 2,
+	}
+	n, ok := arity[kind]
+	if !ok {
+		return certificate{}, fmt.Errorf("decode certificate: unknown kind %d", kind)
+	}
+	what := fmt.Sprintf("certificate kind %d", kind)
+	if err := expectFields(fields, n, what); err != nil {
+		return certificate{}, err
+	}
+	cert := certificate{kind: kind}
+
+	// Genesis delegation and MIR certificates carry no credential.
+	switch kind {
+	case 5:
+		cert.v1 = scriptcontext.DCertGenesis{}
+		return cert, nil
+	case 6:
+		cert.v1 = scriptcontext.DCertMir{}
+		return cert, nil
+	case 3, 4:
+		pool, err := decodeHash(fields[0], hash28, what+" pool")
+		if err != nil {
+			return certificate{}, err
+		}
+		if kind == 3 {
+			vrf, err := decodeHash(fields[1], hash32, what+" VRF key hash")
+			if err != nil {
+				return certificate{}, err
+			}
+			cert.v1 = scriptcontext.DCertPoolRegister{Pool: pool, VRF: vrf}
+			cert.v3 = scriptcontext.TxCertPoolRegister{Pool: pool, VRF: vrf}
+			return cert, nil
+		}
+		epoch, err := decodeUint(fields[1], what+" epoch")
+		if err != nil {
+			return certificate{}, err
+		}
+		cert.v1 = scriptcontext.DCertPoolRetire{Pool: pool, Epoch: epoch}
+		cert.v3 = scriptcontext.TxCertPoolRetire{Pool: pool, Epoch: epoch}
+		return cert, nil
+	}
+
+	credential, err := decodeCredential(fields[0])
+	if err != nil {
+		return certificate{}, fmt.Errorf("%s: %w", what, err)
+	}
+	cert.credential = &credential
+	staking := scriptcontext.StakingHash{Credential: credential}
+	// amount decodes the coin in field i.
+	amount := func(i int) (*big.Int, error) {
+		return decodeBigInt(fields[i], what+" coin")
+	}
+	pool := func(i int) ([]byte, error) {
+		return decodeHash(fields[i], hash28, what+" pool")
+	}
+
+	switch kind {
+	case 0:
+		cert.v1 = scriptcontext.DCertDelegRegKey{Credential: staking}
+		cert.v3 = scriptcontext.TxCertRegStaking{Credential: credential}
+	case 1:
+		cert.v1 = scriptcontext.DCertDelegDeRegKey{Credential: staking}
+		cert.v3 = scriptcontext.TxCertUnRegStaking{Credential: credential}
+	case 2:
+		p, err := pool(1)
+		if err != nil {
+			return certificate{}, err
+		}
+		cert.v1 = scriptcontext.DCertDelegDelegate{Credential: staking, Pool: p}
+		cert.v3 = scriptcontext.TxCertDelegStaking{
+			Credential: credential,
+			Delegatee:  scriptcontext.DelegStake{Pool: p},
+		}
+	case 7, 8:
+		coin, err := amount(1)
+		if err != nil {
+			return certificate{}, err
+		}
+		if kind == 7 {
+			cert.v3 = scriptcontext.TxCertRegStaking{Credential: credential, Deposit: coin}
+		} else {
+			cert.v3 = scriptcontext.TxCertUnRegStaking{Credential: credential, Refund: coin}
+		}
+	case 9, 10, 11, 12, 13:
+		delegatee, rest, err := decodeDelegatee(kind, fields[1:], what)
+		if err != nil {
+			return certificate{}, err
+		}
+		if kind <= 10 {
+			cert.v3 = scriptcontext.TxCertDelegStaking{Credential: credential, Delegatee: delegatee}
+			break
+		}
+		coin, err := decodeBigInt(rest, what+" coin")
+		if err != nil {
+			return certificate{}, err
+		}
+		cert.v3 = scriptcontext.TxCertRegDeleg{
+			Credential: credential,
+			Delegatee:  delegatee,
+			Deposit:    coin,
+		}
+	case 14:
+		hot, err := decodeCredential(fields[1])
+		if err != nil {
+			return certificate{}, fmt.Errorf("%s: %w", what, err)
+		}
+		cert.v3 = scriptcontext.TxCertAuthHotCommittee{Cold: credential, Hot: hot}
+	case 15:
+		cert.v3 = scriptcontext.TxCertResignColdCommittee{Cold: credential}
+	case 16, 17:
+		coin, err := amount(1)
+		if err != nil {
+			return certificate{}, err
+		}
+		if kind == 16 {
+			cert.v3 = scriptcontext.TxCertRegDRep{Credential: credential, Deposit: coin}
+		} else {
+			cert.v3 = scriptcontext.TxCertUnRegDRep{Credential: credential, Refund: coin}
+		}
+	case 18:
+		cert.v3 = scriptcontext.TxCertUpdateDRep{Credential: credential}
+	}
+	return cert, nil
+}
+
+// decodeDelegatee decodes the delegation target of certificate kinds 9-13
+// and returns the field following it.
+func decodeDelegatee(
+	kind uint64,
+	fields []cbor.RawMessage,
+	what string,
+) (scriptcontext.Delegatee, cbor.RawMessage, error) {
+	var delegatee scriptcontext.Delegatee
+	next := 1
+	switch kind {
+	case 9, 12:
+		drep, err := decodeDRep(fields[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", what, err)
+		}
+		delegatee = scriptcontext.DelegVote{DRep: drep}
+	case 10, 13:
+		pool, err := decodeHash(fields[0], hash28, what+" pool")
+		if err != nil {
+			return nil, nil, err
+		}
+		drep, err := decodeDRep(fields[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", what, err)
+		}
+		delegatee = scriptcontext.DelegStakeVote{Pool: pool, DRep: drep}
+		next = 2
+	case 11:
+		pool, err := decodeHash(fields[0], hash28, what+" pool")
+		if err != nil {
+			return nil, nil, err
+		}
+		delegatee = scriptcontext.DelegStake{Pool: pool}
+	}
+	if next < len(fields) {
+		return delegatee, fields[next], nil
+	}
+	return delegatee, nil, nil
+}
+
+func decodeDRep(raw []byte) (scriptcontext.DRep, error) {
+	tag, fields, err := decodeTagged(raw, "drep")
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0, 1:
+		if err := expectFields(fields, 1, "drep"); err != nil {
+			return nil, err
+		}
+		hash, err := decodeHash(fields[0], hash28, "drep hash")
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.DRepCredential{
+			Credential: scriptcontext.Credential{Type: credentialType(tag == 1), Hash: hash},
+		}, nil
+	case 2:
+		return scriptcontext.DRepAlwaysAbstain{}, nil
+	case 3:
+		return scriptcontext.DRepAlwaysNoConfidence{}, nil
+	default:
+		return nil, fmt.Errorf("decode drep: unknown kind %d", tag)
+	}
+}
+
+// decodeVoter decodes a voter and returns the credential a voting script
+// witnesses, which is nil for stake pools.
+func decodeVoter(raw []byte) (scriptcontext.Voter, *scriptcontext.Credential, error) {
+	tag, fields, err := decodeTagged(raw, "voter")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := expectFields(fields, 1, "voter"); err != nil {
+		return nil, nil, err
+	}
+	hash, err := decodeHash(fields[0], hash28, "voter hash")
+	if err != nil {
+		return nil, nil, err
+	}
+	credential := scriptcontext.Credential{Type: credentialType(tag%2 == 1), Hash: hash}
+	switch tag {
+	case 0, 1:
+		return scriptcontext.CommitteeVoter{Credential: credential}, &credential, nil
+	case 2, 3:
+		return scriptcontext.DRepVoter{Credential: credential}, &credential, nil
+	case 4:
+		return scriptcontext.StakePoolVoter{Pool: hash}, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("decode voter: unknown kind %d", tag)
+	}
+}
+
+// voterRank orders voter kinds as the ledger does.
+func voterRank(v scriptcontext.Voter) int {
+	switch v.(type) {
+	case scriptcontext.CommitteeVoter:
+		return 0
+	case scriptcontext.DRepVoter:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func compareVoters(a, b scriptcontext.Voter) int {
+	if ra, rb := voterRank(a), voterRank(b); ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case scriptcontext.CommitteeVoter:
+		return compareCredentials(a.Credential, b.(scriptcontext.CommitteeVoter).Credential)
+	case scriptcontext.DRepVoter:
+		return compareCredentials(a.Credential, b.(scriptcontext.DRepVoter).Credential)
+	case scriptcontext.StakePoolVoter:
+		return bytes.Compare(a.Pool, b.(scriptcontext.StakePoolVoter).Pool)
+	}
+	return 0
+}
+
+// voter is a voter in the transaction's voting procedures together with the
+// credential its script witnesses.
+type voter struct {
+	votes      scriptcontext.VoterVotes
+	credential *scriptcontext.Credential
+}
+
+// decodeVotingProcedures decodes voter -> action ID -> [vote, anchor],
+// sorted by voter and action ID.
+func decodeVotingProcedures(raw []byte) ([]voter, error) {
+	pairs, err := decodeMap(raw, "voting procedures")
+	if err != nil {
+		return nil, err
+	}
+	voters := make([]voter, 0, len(pairs))
+	for _, pair := range pairs {
+		v, credential, err := decodeVoter(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		votePairs, err := decodeMap(pair[1], "votes")
+		if err != nil {
+			return nil, err
+		}
+		entry := voter{
+			votes:      scriptcontext.VoterVotes{Voter: v},
+			credential: credential,
+		}
+		for _, votePair := range votePairs {
+			id, err := decodeActionID(votePair[0])
+			if err != nil {
+				return nil, err
+			}
+			var procedure struct {
+				_      struct{} `cbor:",toarray"`
+				Vote   uint64
+				Anchor cbor.RawMessage
+			}
+			if err := unmarshal(votePair[1], &procedure, "voting procedure"); err != nil {
+				return nil, err
+			}
+			if procedure.Vote > uint64(scriptcontext.VoteAbstain) {
+				return nil, fmt.Errorf("decode voting procedure: unknown vote %d", procedure.Vote)
+			}
+			entry.votes.Votes = append(entry.votes.Votes, scriptcontext.GovernanceVote{
+				Action: id,
+				Vote:   scriptcontext.Vote(procedure.Vote),
+			})
+		}
+		slices.SortFunc(entry.votes.Votes, func(a, b scriptcontext.GovernanceVote) int {
+			return compareActionIDs(a.Action, b.Action)
+		})
+		voters = append(voters, entry)
+	}
+	slices.SortFunc(voters, func(a, b voter) int {
+		return compareVoters(a.votes.Voter, b.votes.Voter)
+	})
+	return voters, nil
+}
+
+func decodeActionID(raw []byte) (scriptcontext.GovernanceActionID, error) {
+	var id struct {
+		_     struct{} `cbor:",toarray"`
+		TxID  []byte
+		Index uint64
+	}
+	if err := unmarshal(raw, &id, "governance action id"); err != nil {
+		return scriptcontext.GovernanceActionID{}, err
+	}
+	if len(id.TxID) != hash32 {
+		return scriptcontext.GovernanceActionID{}, fmt.Errorf(
+			"decode governance action id: transaction id is %d bytes",
+			len(id.TxID),
+		)
+	}
+	return scriptcontext.GovernanceActionID{TxID: id.TxID, Index: id.Index}, nil
+}
+
+func decodePrevAction(raw []byte) (*scriptcontext.GovernanceActionID, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	id, err := decodeActionID(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func compareActionIDs(a, b scriptcontext.GovernanceActionID) int {
+	if c := bytes.Compare(a.TxID, b.TxID); c != 0 {
+		return c
+	}
+	switch {
+	case a.Index < b.Index:
+		return -1
+	case a.Index > b.Index:
+		return 1
+	}
+	return 0
+}
+
+func decodeOptionalHash(raw []byte, what string) ([]byte, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	return decodeHash(raw, hash28, what)
+}
+
+// decodeProposal decodes [deposit, reward account, action, anchor].
+func decodeProposal(raw []byte) (scriptcontext.ProposalProcedure, error) {
+	items, err := decodeArray(raw, "proposal procedure")
+	if err != nil {
+		return scriptcontext.ProposalProcedure{}, err
+	}
+	if err := expectFields(items, 4, "proposal procedure"); err != nil {
+		return scriptcontext.ProposalProcedure{}, err
+	}
+	deposit, err := decodeBigInt(items[0], "proposal deposit")
+	if err != nil {
+		return scriptcontext.ProposalProcedure{}, err
+	}
+	returnAddress, err := decodeRewardAccount(items[1])
+	if err != nil {
+		return scriptcontext.ProposalProcedure{}, err
+	}
+	action, err := decodeGovernanceAction(items[2])
+	if err != nil {
+		return scriptcontext.ProposalProcedure{}, err
+	}
+	return scriptcontext.ProposalProcedure{
+		Deposit:       deposit,
+		ReturnAddress: returnAddress,
+		Action:        action,
+	}, nil
+}
+
+func decodeGovernanceAction(raw []byte) (scriptcontext.GovernanceAction, error) {
+	kind, fields, err := decodeTagged(raw, "governance action")
+	if err != nil {
+		return nil, err
+	}
+	arity := []int{3, 2, 2, 1, 4, 2, 0}
+	if kind >= uint64(len(arity)) {
+		return nil, fmt.Errorf("decode governance action: unknown kind %d", kind)
+	}
+	what := fmt.Sprintf("governance action kind %d", kind)
+	if err := expectFields(fields, arity[kind], what); err != nil {
+		return nil, err
+	}
+	if kind == 6 {
+		return scriptcontext.InfoAction{}, nil
+	}
+	if kind == 2 {
+		withdrawals, err := decodeWithdrawals(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		guardrail, err := decodeOptionalHash(fields[1], what+" policy")
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.TreasuryWithdrawals{
+			Withdrawals:     withdrawals,
+			GuardrailScript: guardrail,
+		}, nil
+	}
+
+	prev, err := decodePrevAction(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 0:
+		parameters, err := parameterUpdateData(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		guardrail, err := decodeOptionalHash(fields[2], what+" policy")
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.ParameterChange{
+			PrevAction:      prev,
+			Parameters:      parameters,
+			GuardrailScript: guardrail,
+		}, nil
+	case 1:
+		var version struct {
+			_     struct{} `cbor:",toarray"`
+			Major uint64
+			Minor uint64
+		}
+		if err := unmarshal(fields[1], &version, what+" protocol version"); err != nil {
+			return nil, err
+		}
+		return scriptcontext.HardForkInitiation{
+			PrevAction: prev,
+			Version: scriptcontext.ProtocolVersion{
+				Major: version.Major,
+				Minor: version.Minor,
+			},
+		}, nil
+	case 3:
+		return scriptcontext.NoConfidence{PrevAction: prev}, nil
+	case 4:
+		return decodeUpdateCommittee(prev, fields[1:])
+	default:
+		var constitution struct {
+			_      struct{} `cbor:",toarray"`
+			Anchor cbor.RawMessage
+			Script cbor.RawMessage
+		}
+		if err := unmarshal(fields[1], &constitution, what+" constitution"); err != nil {
+			return nil, err
+		}
+		guardrail, err := decodeOptionalHash(constitution.Script, what+" constitution script")
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.NewConstitution{PrevAction: prev, GuardrailScript: guardrail}, nil
+	}
+}
+
+func decodeUpdateCommittee(
+	prev *scriptcontext.GovernanceActionID,
+	fields []cbor.RawMessage,
+) (scriptcontext.GovernanceAction, error) {
+	removeRaw, err := decodeArray(fields[0], "committee removals")
+	if err != nil {
+		return nil, err
+	}
+	action := scriptcontext.UpdateCommittee{PrevAction: prev}
+	for _, raw := range removeRaw {
+		credential, err := decodeCredential(raw)
+		if err != nil {
+			return nil, err
+		}
+		action.Remove = append(action.Remove, credential)
+	}
+	slices.SortFunc(action.Remove, compareCredentials)
+
+	addPairs, err := decodeMap(fields[1], "committee additions")
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range addPairs {
+		credential, err := decodeCredential(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		expiry, err := decodeUint(pair[1], "committee member expiry")
+		if err != nil {
+			return nil, err
+		}
+		action.Add = append(action.Add, scriptcontext.CommitteeMember{
+			Credential: credential,
+			Expiry:     expiry,
+		})
+	}
+	slices.SortFunc(action.Add, func(a, b scriptcontext.CommitteeMember) int {
+		return compareCredentials(a.Credential, b.Credential)
+	})
+
+	quorum, err := decodeRational(fields[2], "committee quorum")
+	if err != nil {
+		return nil, err
+	}
+	action.Quorum = quorum
+	return action, nil
+}
+
+// decodeRational decodes a #6.30([numerator, denominator]) unit interval.
+func decodeRational(raw []byte, what string) (scriptcontext.Rational, error) {
+	var r struct {
+		_           struct{} `cbor:",toarray"`
+		Numerator   big.Int
+		Denominator big.Int
+	}
+	if err := unmarshal(raw, &r, what); err != nil {
+		return scriptcontext.Rational{}, err
+	}
+	return scriptcontext.Rational{Numerator: &r.Numerator, Denominator: &r.Denominator}, nil
+}
+
+// decodeWithdrawals decodes a reward account -> coin map, sorted by
+// credential.
+func decodeWithdrawals(raw []byte) ([]scriptcontext.Withdrawal, error) {
+	pairs, err := decodeMap(raw, "withdrawals")
+	if err != nil {
+		return nil, err
+	}
+	withdrawals := make([]scriptcontext.Withdrawal, 0, len(pairs))
+	for _, pair := range pairs {
+		credential, err := decodeRewardAccount(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		amount, err := decodeBigInt(pair[1], "withdrawal amount")
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, scriptcontext.Withdrawal{
+			Credential: credential,
+			Amount:     amount,
+		})
+	}
+	slices.SortFunc(withdrawals, func(a, b scriptcontext.Withdrawal) int {
+		return compareCredentials(a.Credential, b.Credential)
+	})
+	return withdrawals, nil
+}
+
+// parameterUpdateData converts a protocol parameter update to the Data the
+// ledger passes to guardrail scripts: a map from parameter index to value,
+// with rationals as two-element lists.
+func parameterUpdateData(raw []byte) (data.PlutusData, error) {
+	entries, err := decodeMap(raw, "protocol parameter update")
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([][2]data.PlutusData, 0, len(entries))
+	for _, entry := range entries {
+		var key, value any
+		if err := unmarshal(entry[0], &key, "protocol parameter index"); err != nil {
+			return nil, err
+		}
+		if err := unmarshal(entry[1], &value, "protocol parameter value"); err != nil {
+			return nil, err
+		}
+		keyData, err := genericData(key)
+		if err != nil {
+			return nil, err
+		}
+		valueData, err := genericData(value)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]data.PlutusData{keyData, valueData})
+	}
+	sortIntegerKeys(pairs)
+	return data.NewMap(pairs), nil
+}
+
+// sortIntegerKeys sorts map entries by their integer keys, as the ledger
+// does for parameter indexes and cost model languages.
+func sortIntegerKeys(pairs [][2]data.PlutusData) {
+	slices.SortStableFunc(pairs, func(a, b [2]data.PlutusData) int {
+		ai, aok := a[0].(*data.Integer)
+		bi, bok := b[0].(*data.Integer)
+		if aok && bok {
+			return ai.Inner.Cmp(bi.Inner)
+		}
+		return 0
+	})
+}
+
+func genericData(v any) (data.PlutusData, error) {
+	switch v := v.(type) {
+	case uint64:
+		return data.NewInteger(new(big.Int).SetUint64(v)), nil
+	case int64:
+		return data.NewInteger(big.NewInt(v)), nil
+	case big.Int:
+		return data.NewInteger(&v), nil
+	case []byte:
+		return data.NewByteString(v), nil
+	case cbor.ByteString:
+		return data.NewByteString([]byte(v)), nil
+	case cbor.Tag:
+		// Rationals (tag 30) and sets (tag 258) are passed as their content
+		return genericData(v.Content)
+	case []any:
+		items := make([]data.PlutusData, 0, len(v))
+		for _, item := range v {
+			d, err := genericData(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, d)
+		}
+		return data.NewList(items...), nil
+	case map[any]any:
+		pairs := make([][2]data.PlutusData, 0, len(v))
+		for key, value := range v {
+			keyData, err := genericData(key)
+			if err != nil {
+				return nil, err
+			}
+			valueData, err := genericData(value)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, [2]data.PlutusData{keyData, valueData})
+		}
+		sortIntegerKeys(pairs)
+		return data.NewMap(pairs), nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol parameter value %T", v)
+	}
+}
@@ -0,0 +1,219 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+func TestDecodeCertificate(t *testing.T) {
+	key := scriptcontext.Credential{Hash: hash(0x01, 28)}
+	script := scriptcontext.Credential{Type: scriptcontext.ScriptCredential, Hash: hash(0x02, 28)}
+	pool := hash(0x03, 28)
+	keyCBOR := []any{0, key.Hash}
+	scriptCBOR := []any{1, script.Hash}
+	tests := []struct {
+		name       string
+		cert       []any
+		v1         scriptcontext.DCert
+		v3         scriptcontext.TxCert
+		credential *scriptcontext.Credential
+	}{
+		{
+			name:       "legacy registration",
+			cert:       []any{0, scriptCBOR},
+			v1:         scriptcontext.DCertDelegRegKey{Credential: scriptcontext.StakingHash{Credential: script}},
+			v3:         scriptcontext.TxCertRegStaking{Credential: script},
+			credential: &script,
+		},
+		{
+			name: "stake delegation",
+			cert: []any{2, keyCBOR, pool},
+			v1: scriptcontext.DCertDelegDelegate{
+				Credential: scriptcontext.StakingHash{Credential: key},
+				Pool:       pool,
+			},
+			v3: scriptcontext.TxCertDelegStaking{
+				Credential: key,
+				Delegatee:  scriptcontext.DelegStake{Pool: pool},
+			},
+			credential: &key,
+		},
+		{
+			name: "pool retirement",
+			cert: []any{4, pool, 300},
+			v1:   scriptcontext.DCertPoolRetire{Pool: pool, Epoch: 300},
+			v3:   scriptcontext.TxCertPoolRetire{Pool: pool, Epoch: 300},
+		},
+		{
+			name:       "registration with deposit",
+			cert:       []any{7, scriptCBOR, 2_000_000},
+			v3:         scriptcontext.TxCertRegStaking{Credential: script, Deposit: big.NewInt(2_000_000)},
+			credential: &script,
+		},
+		{
+			name: "vote registration delegation",
+			cert: []any{12, scriptCBOR, []any{2}, 2_000_000},
+			v3: scriptcontext.TxCertRegDeleg{
+				Credential: script,
+				Delegatee:  scriptcontext.DelegVote{DRep: scriptcontext.DRepAlwaysAbstain{}},
+				Deposit:    big.NewInt(2_000_000),
+			},
+			credential: &script,
+		},
+		{
+			name: "stake vote registration delegation",
+			cert: []any{13, keyCBOR, pool, []any{1, script.Hash}, 1},
+			v3: scriptcontext.TxCertRegDeleg{
+				Credential: key,
+				Delegatee: scriptcontext.DelegStakeVote{
+					Pool: pool,
+					DRep: scriptcontext.DRepCredential{Credential: script},
+				},
+				Deposit: big.NewInt(1),
+			},
+			credential: &key,
+		},
+		{
+			name:       "committee hot key",
+			cert:       []any{14, scriptCBOR, keyCBOR},
+			v3:         scriptcontext.TxCertAuthHotCommittee{Cold: script, Hot: key},
+			credential: &script,
+		},
+		{
+			name:       "drep update",
+			cert:       []any{18, scriptCBOR, nil},
+			v3:         scriptcontext.TxCertUpdateDRep{Credential: script},
+			credential: &script,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cert, err := decodeCertificate(mustMarshal(t, test.cert))
+			if err != nil {
+				t.Fatalf("decodeCertificate() failed: %v", err)
+			}
+			if (cert.v1 == nil) != (test.v1 == nil) ||
+				test.v1 != nil && !cert.v1.ToData().Equal(test.v1.ToData()) {
+				t.Errorf("v1 = %+v, want %+v", cert.v1, test.v1)
+			}
+			if !cert.v3.ToData().Equal(test.v3.ToData()) {
+				t.Errorf("v3 = %+v, want %+v", cert.v3, test.v3)
+			}
+			if (cert.credential == nil) != (test.credential == nil) ||
+				test.credential != nil && compareCredentials(*cert.credential, *test.credential) != 0 {
+				t.Errorf("credential = %+v, want %+v", cert.credential, test.credential)
+			}
+		})
+	}
+
+	for _, bad := range [][]any{{19, keyCBOR}, {0}, {0, []any{2, key.Hash}}} {
+		if _, err := decodeCertificate(mustMarshal(t, bad)); err == nil {
+			t.Errorf("decodeCertificate(%v) succeeded", bad)
+		}
+	}
+}
+
+func TestDecodeVotingProcedures(t *testing.T) {
+	key := hash(0x05, 28)
+	script := hash(0x06, 28)
+	action := func(b byte, index uint64) []any { return []any{hash(b, 32), index} }
+	// Voters and actions are out of ledger order; keys are arrays, which a Go
+	// map cannot hold, so the map is written by hand.
+	raw := []byte{0xa3}
+	for _, pair := range [][2]any{
+		{[]any{4, hash(0x07, 28)}, map[int]any{}},
+		{[]any{2, key}, map[int]any{}},
+		{[]any{3, script}, map[int]any{}},
+	} {
+		raw = append(raw, mustMarshal(t, pair[0])...)
+		votes := []byte{0xa2}
+		votes = append(votes, mustMarshal(t, action(0x09, 1))...)
+		votes = append(votes, mustMarshal(t, []any{1, nil})...)
+		votes = append(votes, mustMarshal(t, action(0x08, 0))...)
+		votes = append(votes, mustMarshal(t, []any{2, nil})...)
+		raw = append(raw, votes...)
+	}
+	voters, err := decodeVotingProcedures(raw)
+	if err != nil {
+		t.Fatalf("decodeVotingProcedures() failed: %v", err)
+	}
+	if len(voters) != 3 {
+		t.Fatalf("got %d voters, want 3", len(voters))
+	}
+	// DRep script credentials sort before DRep key credentials, and stake
+	// pools come last.
+	if v, ok := voters[0].votes.Voter.(scriptcontext.DRepVoter); !ok ||
+		v.Credential.Type != scriptcontext.ScriptCredential {
+		t.Errorf("voter 0 = %+v", voters[0].votes.Voter)
+	}
+	if _, ok := voters[2].votes.Voter.(scriptcontext.StakePoolVoter); !ok || voters[2].credential != nil {
+		t.Errorf("voter 2 = %+v", voters[2])
+	}
+	if votes := voters[1].votes.Votes; votes[0].Vote != scriptcontext.VoteAbstain ||
+		votes[1].Vote != scriptcontext.VoteYes {
+		t.Errorf("votes are not sorted by action: %+v", votes)
+	}
+}
+
+func TestDecodeGovernanceAction(t *testing.T) {
+	guardrail := hash(0x0a, 28)
+	update := map[uint64]any{
+		0:  44,
+		17: []any{cbor.Tag{Number: 30, Content: []any{577, 10000}}},
+	}
+	action, err := decodeGovernanceAction(mustMarshal(t, []any{0, nil, update, guardrail}))
+	if err != nil {
+		t.Fatalf("decodeGovernanceAction() failed: %v", err)
+	}
+	change, ok := action.(scriptcontext.ParameterChange)
+	if !ok {
+		t.Fatalf("got %T, want ParameterChange", action)
+	}
+	want := data.NewMap([][2]data.PlutusData{
+		{data.NewInteger(big.NewInt(0)), data.NewInteger(big.NewInt(44))},
+		{data.NewInteger(big.NewInt(17)), data.NewList(data.NewList(
+			data.NewInteger(big.NewInt(577)),
+			data.NewInteger(big.NewInt(10000)),
+		))},
+	})
+	if !change.Parameters.Equal(want) || change.PrevAction != nil {
+		t.Errorf("ParameterChange = %+v", change)
+	}
+	if string(change.GuardrailScript) != string(guardrail) {
+		t.Errorf("guardrail = %x", change.GuardrailScript)
+	}
+
+	proposal, err := decodeProposal(mustMarshal(t, []any{
+		100_000_000_000,
+		append([]byte{0xe1}, hash(0x0b, 28)...),
+		[]any{6},
+		[]any{"https://example.com", hash(0x0c, 32)},
+	}))
+	if err != nil {
+		t.Fatalf("decodeProposal() failed: %v", err)
+	}
+	if _, ok := proposal.Action.(scriptcontext.InfoAction); !ok || proposal.Deposit.Int64() != 100_000_000_000 {
+		t.Errorf("proposal = %+v", proposal)
+	}
+}
+
+func TestDecodeMapIndefinite(t *testing.T) {
+	raw := []byte{0xbf, 0x01, 0x02, 0x03, 0x04, 0xff}
+	pairs, err := decodeMap(raw, "test")
+	if err != nil {
+		t.Fatalf("decodeMap() failed: %v", err)
+	}
+	if len(pairs) != 2 || pairs[1][0][0] != 0x03 {
+		t.Errorf("decodeMap() = %v", pairs)
+	}
+	for _, bad := range [][]byte{{0xbf, 0x01}, {0xa1, 0x01}, {0x80}} {
+		if _, err := decodeMap(bad, "test"); err == nil {
+			t.Errorf("decodeMap(%x) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package ledger evaluates the Plutus scripts of a Cardano transaction
+// offline, in the manner of the Ogmios evaluateTransaction endpoint.
+//
+// [EvaluateTransaction] takes a Conway-era transaction as CBOR, the UTxOs it
+// spends and references, the protocol [Parameters] and a [SlotConfig]. For
+// every redeemer it locates the script (in the witness set or as a reference
+// script), resolves the datum of spent outputs, builds the script context of
+// the script's ledger language with the scriptcontext package, applies the
+// arguments the ledger would and runs the script through cek:
+//
+//	results, err := ledger.EvaluateTransaction(
+//	    txCBOR,
+//	    utxos,
+//	    &ledger.Parameters{
+//	        ProtocolVersion: cek.ProtoVersion{Major: 10},
+//	        CostModels:      costModels,
+//	        MaxTxExUnits:    ledger.ExUnits{Steps: 10_000_000_000, Memory: 14_000_000},
+//	    },
+//	    ledger.MainnetSlotConfig,
+//	)
+//	for _, result := range results {
+//	    fmt.Println(result.Redeemer, result.ExUnits, result.Err)
+//	}
+//
+// [LoadParameters] and [LoadParametersFile] read the Parameters from the
+// protocol-parameter JSON of cardano-cli, Ogmios or Blockfrost, and
+// [Parameters.EvalContext] builds the cek evaluation context of a language
+// from them:
+//
+//	params, err := ledger.LoadParametersFile("protocol-parameters.json")
+//	...
+//	evalContext, err := params.EvalContext(builtin.PlutusV3)
+//	machine := cek.NewMachine[syn.DeBruijn](lang.LanguageVersionV3, 0, evalContext)
+//	machine.ExBudget = params.MaxTxExUnits.ExBudget()
+//
+// [LoadUTxOs] and [LoadUTxOFile] read the UTxOs from Ogmios or cardano-cli
+// JSON. [TransactionInvocations] resolves the scripts and arguments of every
+// redeemer like EvaluateTransaction, but returns them instead of running
+// them, for tools that evaluate scripts on their own.
+//
+// The package reproduces what scripts observe, not the whole ledger: it does
+// not check signatures, balance, fees, collateral or the declared execution
+// units. Transactions that a language cannot represent, such as a PlutusV1
+// script in a transaction with reference inputs, fail for the redeemers of
+// that language, as they do in the ledger.
+package ledger
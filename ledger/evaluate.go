@@ -0,0 +1,429 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/policy"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// RedeemerTag identifies what a redeemer's index points into.
+type RedeemerTag uint8
+
+const (
+	Spend   RedeemerTag = iota // sorted transaction inputs
+	Mint                       // sorted minting policies
+	Cert                       // certificates
+	Reward                     // sorted withdrawals
+	Vote                       // sorted voters
+	Propose                    // proposal procedures
+)
+
+// String returns the purpose name used by Ogmios.
+func (t RedeemerTag) String() string {
+	switch t {
+	case Spend:
+		return "spend"
+	case Mint:
+		return "mint"
+	case Cert:
+		return "publish"
+	case Reward:
+		return "withdraw"
+	case Vote:
+		return "vote"
+	case Propose:
+		return "propose"
+	default:
+		return fmt.Sprintf("RedeemerTag(%d)", uint8(t))
+	}
+}
+
+// RedeemerPointer identifies a redeemer by its tag and index.
+type RedeemerPointer struct {
+	Tag   RedeemerTag
+	Index uint64
+}
+
+// String formats the pointer as "tag:index", such as "spend:0".
+func (p RedeemerPointer) String() string {
+	return fmt.Sprintf("%s:%d", p.Tag, p.Index)
+}
+
+func (p RedeemerPointer) compare(other RedeemerPointer) int {
+	switch {
+	case p.Tag != other.Tag:
+		return int(p.Tag) - int(other.Tag)
+	case p.Index < other.Index:
+		return -1
+	case p.Index > other.Index:
+		return 1
+	}
+	return 0
+}
+
+// ExUnits uses the cardano-node field names: steps are plutigo's CPU units.
+type ExUnits struct {
+	Steps  int64 `json:"steps"`
+	Memory int64 `json:"memory"`
+}
+
+// ExBudget converts the units to a cek budget.
+func (u ExUnits) ExBudget() cek.ExBudget {
+	return cek.ExBudget{Cpu: u.Steps, Mem: u.Memory}
+}
+
+// UTxO is a resolved transaction output. Output is the output's CBOR as it
+// appears in a transaction body, in either the legacy array or the
+// post-Alonzo map format.
+type UTxO struct {
+	Input  scriptcontext.TxOutRef
+	Output []byte
+}
+
+// SlotConfig converts slots to the POSIX times seen by scripts.
+type SlotConfig struct {
+	ZeroTime   int64  // POSIX time of ZeroSlot in milliseconds
+	ZeroSlot   uint64 // first slot with the current slot length
+	SlotLength uint64 // slot length in milliseconds
+}
+
+var (
+	MainnetSlotConfig = SlotConfig{ZeroTime: 1596059091000, ZeroSlot: 4492800, SlotLength: 1000}
+	PreprodSlotConfig = SlotConfig{ZeroTime: 1655769600000, ZeroSlot: 86400, SlotLength: 1000}
+	PreviewSlotConfig = SlotConfig{ZeroTime: 1666656000000, ZeroSlot: 0, SlotLength: 1000}
+)
+
+// SlotToTime returns the POSIX time of slot in milliseconds.
+func (c SlotConfig) SlotToTime(slot uint64) *big.Int {
+	t := new(big.Int).SetUint64(slot)
+	t.Sub(t, new(big.Int).SetUint64(c.ZeroSlot))
+	t.Mul(t, new(big.Int).SetUint64(c.SlotLength))
+	return t.Add(t, big.NewInt(c.ZeroTime))
+}
+
+// Parameters are the protocol parameters that affect script evaluation.
+type Parameters struct {
+	ProtocolVersion cek.ProtoVersion
+	// CostModels holds the cost model parameters of each ledger language.
+	// Languages without an entry use plutigo's default cost model.
+	CostModels map[builtin.PlutusVersion][]int64
+	// MaxTxExUnits is the budget each script is evaluated with, whatever
+	// its language.
+	MaxTxExUnits ExUnits
+	// MaxBlockExUnits is informational: EvaluateTransaction does not use it.
+	MaxBlockExUnits ExUnits
+}
+
+// EvalContext builds the evaluation context of a ledger language from its
+// cost model and the protocol version.
+func (p *Parameters) EvalContext(language builtin.PlutusVersion) (*cek.EvalContext, error) {
+	version, err := policy.LanguageVersion(language)
+	if err != nil {
+		return nil, err
+	}
+	params, ok := p.CostModels[language]
+	if !ok {
+		return cek.NewDefaultEvalContext(version, p.ProtocolVersion), nil
+	}
+	evalContext, err := cek.NewEvalContext(version, p.ProtocolVersion, params)
+	if err != nil {
+		return nil, fmt.Errorf("build PlutusV%d evaluation context: %w", language, err)
+	}
+	return evalContext, nil
+}
+
+// Result is the outcome of evaluating one redeemer.
+type Result struct {
+	Redeemer RedeemerPointer
+	// Language and ScriptHash are zero when the script could not be found.
+	Language   builtin.PlutusVersion
+	ScriptHash []byte
+	// ExUnits is the budget the script consumed, including when it failed.
+	ExUnits ExUnits
+	Logs    []string
+	// Err is nil when the script succeeded. Script failures are a
+	// *ScriptError wrapping the cek error, so cek.GetErrorCode works on them.
+	Err error
+}
+
+// UnknownInputsError reports inputs and reference inputs that the UTxOs
+// given to EvaluateTransaction do not resolve.
+type UnknownInputsError struct {
+	Inputs []scriptcontext.TxOutRef
+}
+
+func (e *UnknownInputsError) Error() string {
+	refs := make([]string, 0, len(e.Inputs))
+	for _, ref := range e.Inputs {
+		refs = append(refs, outRefKey(ref))
+	}
+	return "unknown transaction input " + strings.Join(refs, ", ")
+}
+
+// ScriptError is the Result error of a script that ran and failed, as
+// opposed to a redeemer whose script could not be run at all.
+type ScriptError struct {
+	ScriptHash []byte
+	Err        error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script %x failed: %v", e.ScriptHash, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// EvaluateTransaction evaluates every redeemer of a Conway-era transaction,
+// like the evaluateTransaction endpoint of Ogmios. utxos must resolve every
+// input and reference input. Each script runs with params.MaxTxExUnits as its
+// budget, regardless of the execution units declared in the transaction.
+//
+// Results are ordered by redeemer tag and index. The returned error reports
+// a malformed transaction or unresolved inputs; problems with a single
+// redeemer, including a missing script or datum, are reported in its Result.
+func EvaluateTransaction(
+	txCBOR []byte,
+	utxos []UTxO,
+	params *Parameters,
+	slots SlotConfig,
+) ([]Result, error) {
+	if params == nil {
+		return nil, errors.New("protocol parameters are required")
+	}
+	if params.ProtocolVersion.Major == 0 {
+		return nil, errors.New("protocol major version must be positive")
+	}
+	if params.MaxTxExUnits.Steps <= 0 || params.MaxTxExUnits.Memory <= 0 {
+		return nil, errors.New("maximum transaction execution units must be positive")
+	}
+	tx, err := decodeTransaction(txCBOR)
+	if err != nil {
+		return nil, err
+	}
+	e, err := newEvaluation(tx, utxos, slots)
+	if err != nil {
+		return nil, err
+	}
+
+	evalContexts := make(map[builtin.PlutusVersion]*cek.EvalContext)
+	results := make([]Result, 0, len(tx.redeemers))
+	for _, r := range tx.redeemers {
+		result := Result{Redeemer: r.pointer}
+		result.Err = e.evaluateRedeemer(r, params, evalContexts, &result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Invocation is a redeemer's script with the arguments the ledger applies
+// it to, as EvaluateTransaction would run it.
+type Invocation struct {
+	Redeemer RedeemerPointer
+	// Language and ScriptHash are zero when the script could not be found.
+	Language   builtin.PlutusVersion
+	ScriptHash []byte
+	// Program is the script's raw FLAT encoding, without its CBOR
+	// bytestring envelope.
+	Program   []byte
+	Arguments []data.PlutusData
+	// Err reports a redeemer whose script or arguments could not be built.
+	Err error
+}
+
+// TransactionInvocations returns the invocation of every redeemer of a
+// Conway-era transaction without evaluating them, ordered like the Results
+// of EvaluateTransaction. The returned error reports a malformed
+// transaction or unresolved inputs.
+func TransactionInvocations(
+	txCBOR []byte,
+	utxos []UTxO,
+	params *Parameters,
+	slots SlotConfig,
+) ([]Invocation, error) {
+	if params == nil {
+		return nil, errors.New("protocol parameters are required")
+	}
+	if params.ProtocolVersion.Major == 0 {
+		return nil, errors.New("protocol major version must be positive")
+	}
+	tx, err := decodeTransaction(txCBOR)
+	if err != nil {
+		return nil, err
+	}
+	e, err := newEvaluation(tx, utxos, slots)
+	if err != nil {
+		return nil, err
+	}
+
+	invocations := make([]Invocation, 0, len(tx.redeemers))
+	for _, r := range tx.redeemers {
+		invocation := Invocation{Redeemer: r.pointer}
+		s, err := e.invocation(r, params, &invocation)
+		if err == nil {
+			invocation.Program, err = decodeBytes(s.bytes, "script bytes")
+		}
+		invocation.Err = err
+		invocations = append(invocations, invocation)
+	}
+	return invocations, nil
+}
+
+// TransactionID returns the ID of a transaction: the hash of its body.
+func TransactionID(txCBOR []byte) ([]byte, error) {
+	tx, err := decodeTransaction(txCBOR)
+	if err != nil {
+		return nil, err
+	}
+	return tx.id, nil
+}
+
+// invocation finds the script of a redeemer and builds its arguments.
+func (e *evaluation) invocation(
+	r redeemer,
+	params *Parameters,
+	invocation *Invocation,
+) (*script, error) {
+	hash, err := e.scriptHash(r.pointer)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := e.scripts[string(hash)]
+	if !ok {
+		return nil, fmt.Errorf("missing script %x", hash)
+	}
+	if s.native {
+		return nil, fmt.Errorf("script %x is a native script", hash)
+	}
+	invocation.Language = s.language
+	invocation.ScriptHash = hash
+	if !policy.LanguageAllowed(s.language, params.ProtocolVersion.Major) {
+		return nil, fmt.Errorf(
+			"PlutusV%d scripts are not allowed at protocol version %d",
+			s.language,
+			params.ProtocolVersion.Major,
+		)
+	}
+
+	var datum data.PlutusData
+	if r.pointer.Tag == Spend {
+		datum, err = e.spentDatum(e.inputs[r.pointer.Index])
+		if err != nil {
+			return nil, err
+		}
+		if datum == nil && s.language < builtin.PlutusV3 {
+			return nil, fmt.Errorf("spending PlutusV%d script %x requires a datum", s.language, hash)
+		}
+	}
+	context, err := e.context(s.language, r, datum)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case s.language >= builtin.PlutusV3:
+		invocation.Arguments = []data.PlutusData{context}
+	case datum != nil:
+		invocation.Arguments = []data.PlutusData{datum, r.data, context}
+	default:
+		invocation.Arguments = []data.PlutusData{r.data, context}
+	}
+	return s, nil
+}
+
+func (e *evaluation) evaluateRedeemer(
+	r redeemer,
+	params *Parameters,
+	evalContexts map[builtin.PlutusVersion]*cek.EvalContext,
+	result *Result,
+) error {
+	var invocation Invocation
+	s, err := e.invocation(r, params, &invocation)
+	result.Language = invocation.Language
+	result.ScriptHash = invocation.ScriptHash
+	if err != nil {
+		return err
+	}
+	hash := invocation.ScriptHash
+	program, err := s.program()
+	if err != nil {
+		return fmt.Errorf("script %x: %w", hash, err)
+	}
+	if !slices.Contains(policy.ProgramVersions(s.language, params.ProtocolVersion.Major), program.Version) {
+		return fmt.Errorf(
+			"script %x: program version %d.%d.%d is not allowed for PlutusV%d at protocol version %d",
+			hash,
+			program.Version[0], program.Version[1], program.Version[2],
+			s.language,
+			params.ProtocolVersion.Major,
+		)
+	}
+	evalContext, ok := evalContexts[s.language]
+	if !ok {
+		if evalContext, err = params.EvalContext(s.language); err != nil {
+			return err
+		}
+		evalContexts[s.language] = evalContext
+	}
+
+	term := program.Term
+	for _, argument := range invocation.Arguments {
+		term = &syn.Apply[syn.DeBruijn]{
+			Function: term,
+			Argument: &syn.Constant{Con: &syn.Data{Inner: argument}},
+		}
+	}
+
+	version, _ := policy.LanguageVersion(s.language)
+	machine := cek.NewMachine[syn.DeBruijn](version, 0, evalContext)
+	budget := params.MaxTxExUnits.ExBudget()
+	machine.ExBudget = budget
+	out, err := runMachine(machine, term)
+	// A run that goes over budget leaves the machine's budget negative, but
+	// it spent the whole budget and no more.
+	consumed := budget.Sub(&machine.ExBudget)
+	if cek.IsBudgetError(err) {
+		consumed = budget
+	}
+	result.ExUnits = ExUnits{Steps: consumed.Cpu, Memory: consumed.Mem}
+	result.Logs = machine.Logs
+	if err != nil {
+		return &ScriptError{ScriptHash: hash, Err: err}
+	}
+	// PlutusV3 scripts must return unit to succeed.
+	if s.language >= builtin.PlutusV3 {
+		if constant, ok := out.(*syn.Constant); !ok || !isUnit(constant.Con) {
+			return &ScriptError{ScriptHash: hash, Err: errors.New("returned a non-unit value")}
+		}
+	}
+	return nil
+}
+
+func isUnit(con syn.IConstant) bool {
+	switch con.(type) {
+	case *syn.Unit, syn.Unit:
+		return true
+	}
+	return false
+}
+
+func runMachine(
+	machine *cek.Machine[syn.DeBruijn],
+	term syn.Term[syn.DeBruijn],
+) (out syn.Term[syn.DeBruijn], err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic during script evaluation: %v", recovered)
+		}
+	}()
+	return machine.Run(term)
+}
@@ -0,0 +1,606 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+var testParams = &Parameters{
+	ProtocolVersion: cek.ProtoVersion{Major: 10},
+	MaxTxExUnits:    ExUnits{Steps: 10_000_000_000, Memory: 14_000_000},
+}
+
+var testSlots = SlotConfig{ZeroTime: 1_000_000, ZeroSlot: 10, SlotLength: 1000}
+
+func hash(b byte, n int) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+// compile returns the CBOR-wrapped FLAT encoding of a UPLC program.
+func compile(t *testing.T, language builtin.PlutusVersion, src string) *script {
+	t.Helper()
+	program, err := syn.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("NameToDeBruijn() failed: %v", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	wrapped, err := cbor.Marshal(flat)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return &script{language: language, bytes: wrapped}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := cbor.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return b
+}
+
+func enterpriseAddress(credential scriptcontext.Credential) []byte {
+	header := byte(0x61)
+	if credential.Type == scriptcontext.ScriptCredential {
+		header = 0x71
+	}
+	return append([]byte{header}, credential.Hash...)
+}
+
+func rewardAccount(credential scriptcontext.Credential) []byte {
+	header := byte(0xe1)
+	if credential.Type == scriptcontext.ScriptCredential {
+		header = 0xf1
+	}
+	return append([]byte{header}, credential.Hash...)
+}
+
+func scriptCredential(s *script) scriptcontext.Credential {
+	return scriptcontext.Credential{Type: scriptcontext.ScriptCredential, Hash: s.hash()}
+}
+
+type v3Fixture struct {
+	tx      []byte
+	utxos   []UTxO
+	trace   *script
+	fail    *script
+	keyRef  scriptcontext.TxOutRef
+	lockRef scriptcontext.TxOutRef
+	datum   data.PlutusData
+}
+
+// newV3Fixture builds a Conway transaction that spends a script output
+// whose validator is supplied as a reference script, mints with a failing
+// policy and withdraws from a script stake credential.
+func newV3Fixture(t *testing.T) v3Fixture {
+	f := v3Fixture{
+		trace: compile(t, builtin.PlutusV3,
+			`(program 1.1.0 (lam ctx [(force (builtin trace)) (con string "ok") (con unit ())]))`),
+		fail:    compile(t, builtin.PlutusV3, `(program 1.1.0 (lam ctx (error)))`),
+		keyRef:  scriptcontext.TxOutRef{TxID: hash(0x01, 32), Index: 0},
+		lockRef: scriptcontext.TxOutRef{TxID: hash(0x02, 32), Index: 3},
+		datum:   data.NewConstr(0, data.NewInteger(big.NewInt(42))),
+	}
+	scriptRef := scriptcontext.TxOutRef{TxID: hash(0x03, 32), Index: 0}
+	key := scriptcontext.Credential{Hash: hash(0xaa, 28)}
+	datumCBOR, err := data.Encode(f.datum)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	f.utxos = []UTxO{
+		{Input: f.keyRef, Output: mustMarshal(t, []any{enterpriseAddress(key), 5_000_000})},
+		{Input: f.lockRef, Output: mustMarshal(t, map[uint64]any{
+			0: enterpriseAddress(scriptCredential(f.trace)),
+			1: 2_000_000,
+			2: []any{1, cbor.Tag{Number: 24, Content: datumCBOR}},
+		})},
+		{Input: scriptRef, Output: mustMarshal(t, map[uint64]any{
+			0: enterpriseAddress(key),
+			1: 10_000_000,
+			3: cbor.Tag{Number: 24, Content: mustMarshal(t, []any{3, f.trace.bytes})},
+		})},
+	}
+	policy := f.fail.hash()
+	body := map[uint64]any{
+		0: cbor.Tag{Number: 258, Content: []any{
+			[]any{f.lockRef.TxID, f.lockRef.Index},
+			[]any{f.keyRef.TxID, f.keyRef.Index},
+		}},
+		1: []any{
+			map[uint64]any{
+				0: enterpriseAddress(key),
+				1: []any{6_800_000, map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(policy): {"b": 1, "a": 2}}},
+			},
+		},
+		2:  200_000,
+		3:  30,
+		5:  map[cbor.ByteString]uint64{cbor.ByteString(rewardAccount(scriptCredential(f.trace))): 0},
+		8:  20,
+		9:  map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(policy): {"b": 1, "a": 2}},
+		14: []any{hash(0xbb, 28)},
+		18: []any{[]any{scriptRef.TxID, scriptRef.Index}},
+	}
+	unit := func(mem, steps int) []any { return []any{mem, steps} }
+	witnesses := map[uint64]any{
+		5: map[any]any{
+			[2]uint64{0, 1}: []any{cbor.RawMessage(mustMarshal(t, 0)), unit(1, 1)},
+			[2]uint64{1, 0}: []any{cbor.RawMessage(mustMarshal(t, 0)), unit(1, 1)},
+			[2]uint64{3, 0}: []any{cbor.RawMessage(mustMarshal(t, 0)), unit(1, 1)},
+		},
+		7: []any{f.fail.bytes},
+	}
+	f.tx = mustMarshal(t, []any{body, witnesses, true, nil})
+	return f
+}
+
+func TestEvaluateTransactionV3(t *testing.T) {
+	f := newV3Fixture(t)
+	results, err := EvaluateTransaction(f.tx, f.utxos, testParams, testSlots)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	want := []string{"spend:1", "mint:0", "withdraw:0"}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if result.Redeemer.String() != want[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.Redeemer, want[i])
+		}
+		if result.Language != builtin.PlutusV3 {
+			t.Errorf("%s: language = %d, want 3", result.Redeemer, result.Language)
+		}
+		if result.ExUnits.Steps <= 0 || result.ExUnits.Memory <= 0 {
+			t.Errorf("%s: no execution units reported: %+v", result.Redeemer, result.ExUnits)
+		}
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil {
+			t.Errorf("%s failed: %v", results[i].Redeemer, results[i].Err)
+		}
+		if len(results[i].Logs) != 1 || results[i].Logs[0] != "ok" {
+			t.Errorf("%s logs = %q", results[i].Redeemer, results[i].Logs)
+		}
+		if !bytes.Equal(results[i].ScriptHash, f.trace.hash()) {
+			t.Errorf("%s ran script %x", results[i].Redeemer, results[i].ScriptHash)
+		}
+	}
+	if results[1].Err == nil {
+		t.Fatal("failing minting policy succeeded")
+	}
+	if _, ok := cek.GetErrorCode(results[1].Err); !ok {
+		t.Errorf("failure does not carry a cek error code: %v", results[1].Err)
+	}
+	var scriptErr *ScriptError
+	if !errors.As(results[1].Err, &scriptErr) || !bytes.Equal(scriptErr.ScriptHash, f.fail.hash()) {
+		t.Errorf("failure is not a ScriptError: %v", results[1].Err)
+	}
+}
+
+func TestEvaluateTransactionOverBudget(t *testing.T) {
+	f := newV3Fixture(t)
+	params := *testParams
+	params.MaxTxExUnits = ExUnits{Steps: 20_000, Memory: 200}
+	results, err := EvaluateTransaction(f.tx, f.utxos, &params, testSlots)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	for _, result := range results {
+		if !cek.IsBudgetError(result.Err) {
+			t.Errorf("%s: got %v, want a budget error", result.Redeemer, result.Err)
+		}
+		if result.ExUnits != params.MaxTxExUnits {
+			t.Errorf("%s: ExUnits = %+v, want the whole budget %+v", result.Redeemer, result.ExUnits, params.MaxTxExUnits)
+		}
+	}
+}
+
+func TestTransactionInvocations(t *testing.T) {
+	f := newV3Fixture(t)
+	invocations, err := TransactionInvocations(f.tx, f.utxos, testParams, testSlots)
+	if err != nil {
+		t.Fatalf("TransactionInvocations() failed: %v", err)
+	}
+	results, err := EvaluateTransaction(f.tx, f.utxos, testParams, testSlots)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	if len(invocations) != len(results) {
+		t.Fatalf("got %d invocations for %d results", len(invocations), len(results))
+	}
+	for i, invocation := range invocations {
+		if invocation.Err != nil {
+			t.Fatalf("%s: %v", invocation.Redeemer, invocation.Err)
+		}
+		if invocation.Redeemer != results[i].Redeemer ||
+			!bytes.Equal(invocation.ScriptHash, results[i].ScriptHash) ||
+			len(invocation.Arguments) != 1 {
+			t.Errorf("invocation %d = %+v", i, invocation)
+		}
+		// Running the invocation spends what the evaluation did.
+		program, err := syn.Decode[syn.DeBruijn](invocation.Program)
+		if err != nil {
+			t.Fatalf("%s: decode program: %v", invocation.Redeemer, err)
+		}
+		term := program.Term
+		for _, argument := range invocation.Arguments {
+			term = &syn.Apply[syn.DeBruijn]{Function: term, Argument: &syn.Constant{Con: &syn.Data{Inner: argument}}}
+		}
+		machine := cek.NewMachine[syn.DeBruijn](program.Version, 0, nil)
+		budget := testParams.MaxTxExUnits.ExBudget()
+		machine.ExBudget = budget
+		_, _ = machine.Run(term)
+		if consumed := budget.Sub(&machine.ExBudget); consumed.Cpu != results[i].ExUnits.Steps {
+			t.Errorf("%s: invocation spent %d steps, evaluation %d", invocation.Redeemer, consumed.Cpu, results[i].ExUnits.Steps)
+		}
+	}
+
+	id, err := TransactionID(f.tx)
+	if err != nil || len(id) != 32 {
+		t.Errorf("TransactionID() = %x, %v", id, err)
+	}
+}
+
+func TestScriptContextV3(t *testing.T) {
+	f := newV3Fixture(t)
+	tx, err := decodeTransaction(f.tx)
+	if err != nil {
+		t.Fatalf("decodeTransaction() failed: %v", err)
+	}
+	e, err := newEvaluation(tx, f.utxos, testSlots)
+	if err != nil {
+		t.Fatalf("newEvaluation() failed: %v", err)
+	}
+	datum, err := e.spentDatum(e.inputs[1])
+	if err != nil {
+		t.Fatalf("spentDatum() failed: %v", err)
+	}
+	pd, err := e.context(builtin.PlutusV3, tx.redeemers[0], datum)
+	if err != nil {
+		t.Fatalf("context() failed: %v", err)
+	}
+	ctx, err := scriptcontext.ScriptContextV3FromData(pd)
+	if err != nil {
+		t.Fatalf("ScriptContextV3FromData() failed: %v", err)
+	}
+
+	info := ctx.TxInfo
+	if len(info.Inputs) != 2 || !bytes.Equal(info.Inputs[0].OutRef.TxID, f.keyRef.TxID) {
+		t.Errorf("inputs are not sorted: %+v", info.Inputs)
+	}
+	if len(info.ReferenceInputs) != 1 || info.ReferenceInputs[0].Resolved.ReferenceScript == nil {
+		t.Errorf("reference input script hash missing: %+v", info.ReferenceInputs)
+	}
+	if lower, upper := info.ValidRange.Lower, info.ValidRange.Upper; lower.Time.Int64() != 1_010_000 ||
+		!lower.Closed || upper.Time.Int64() != 1_020_000 || upper.Closed {
+		t.Errorf("valid range = %+v", info.ValidRange)
+	}
+	if info.Fee.Int64() != 200_000 {
+		t.Errorf("fee = %s", info.Fee)
+	}
+	if len(info.Mint) != 1 || string(info.Mint[0].Assets[0].Name) != "a" {
+		t.Errorf("mint is not sorted or carries ada: %+v", info.Mint)
+	}
+	if len(info.Withdrawals) != 1 || len(info.Signatories) != 1 || len(info.Redeemers) != 3 {
+		t.Errorf("unexpected TxInfo: %+v", info)
+	}
+	if !bytes.Equal(info.ID, tx.id) {
+		t.Errorf("ID = %x, want %x", info.ID, tx.id)
+	}
+	spending, ok := ctx.Info.(scriptcontext.SpendingScript)
+	if !ok || !bytes.Equal(spending.OutRef.TxID, f.lockRef.TxID) || !spending.Datum.Equal(f.datum) {
+		t.Errorf("script info = %+v", ctx.Info)
+	}
+}
+
+func TestScriptArgumentsCanonical(t *testing.T) {
+	// Constr 0 [Integer 7], with the integer in a two-byte head. Scripts
+	// see the value re-encoded canonically, as on the node.
+	raw := []byte{0xd8, 0x79, 0x9f, 0x18, 0x07, 0xff}
+	const canonical = "d8799f07ff"
+
+	option, err := decodeDatumOption(mustMarshal(t, []any{1, cbor.Tag{Number: 24, Content: raw}}))
+	if err != nil {
+		t.Fatalf("decodeDatumOption() failed: %v", err)
+	}
+	tx := &transaction{}
+	if err := tx.decodeDatums(mustMarshal(t, []any{cbor.RawMessage(raw)})); err != nil {
+		t.Fatalf("decodeDatums() failed: %v", err)
+	}
+	if err := tx.addRedeemer(RedeemerPointer{Tag: Spend}, raw, ExUnits{}); err != nil {
+		t.Fatalf("addRedeemer() failed: %v", err)
+	}
+	if want := data.HashBytes(raw); !bytes.Equal(tx.datums[0].Hash, want[:]) {
+		t.Errorf("datum hash = %x, want the hash of the original bytes %x", tx.datums[0].Hash, want)
+	}
+
+	arguments := map[string]data.PlutusData{
+		"inline datum":  option.(scriptcontext.InlineDatum).Datum,
+		"witness datum": tx.datums[0].Datum,
+		"redeemer":      tx.redeemers[0].data,
+	}
+	for name, pd := range arguments {
+		encoded, err := data.Encode(pd)
+		if err != nil {
+			t.Fatalf("%s: Encode() failed: %v", name, err)
+		}
+		if got := hex.EncodeToString(encoded); got != canonical {
+			t.Errorf("%s encodes as %s, want %s", name, got, canonical)
+		}
+	}
+}
+
+func TestEvaluateTransactionLegacy(t *testing.T) {
+	spend := compile(t, builtin.PlutusV2, `(program 1.0.0 (lam d (lam r (lam ctx (con unit ())))))`)
+	mint := compile(t, builtin.PlutusV1, `(program 1.0.0 (lam r (lam ctx (con unit ()))))`)
+	datum := data.NewByteString([]byte("datum"))
+	datumCBOR, err := data.Encode(datum)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	datumHash := data.HashBytes(datumCBOR)
+	ref := scriptcontext.TxOutRef{TxID: hash(0x01, 32), Index: 0}
+	utxos := []UTxO{{
+		Input:  ref,
+		Output: mustMarshal(t, []any{enterpriseAddress(scriptCredential(spend)), 2_000_000, datumHash[:]}),
+	}}
+	body := map[uint64]any{
+		0: []any{[]any{ref.TxID, ref.Index}},
+		1: []any{},
+		2: 100,
+		9: map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(mint.hash()): {"": 1}},
+	}
+	witnesses := map[uint64]any{
+		3: []any{mint.bytes},
+		4: []any{cbor.RawMessage(datumCBOR)},
+		5: []any{
+			[]any{1, 0, 0, []any{1, 1}},
+			[]any{0, 0, 0, []any{1, 1}},
+		},
+		6: []any{spend.bytes},
+	}
+	txCBOR := mustMarshal(t, []any{body, witnesses, true, nil})
+
+	results, err := EvaluateTransaction(txCBOR, utxos, testParams, testSlots)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Redeemer.String() != "spend:0" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%s failed: %v", result.Redeemer, result.Err)
+		}
+	}
+	if results[0].Language != builtin.PlutusV2 || results[1].Language != builtin.PlutusV1 {
+		t.Errorf("languages = %d, %d", results[0].Language, results[1].Language)
+	}
+
+	tx, err := decodeTransaction(txCBOR)
+	if err != nil {
+		t.Fatalf("decodeTransaction() failed: %v", err)
+	}
+	e, err := newEvaluation(tx, utxos, testSlots)
+	if err != nil {
+		t.Fatalf("newEvaluation() failed: %v", err)
+	}
+	pd, err := e.context(builtin.PlutusV1, tx.redeemers[1], nil)
+	if err != nil {
+		t.Fatalf("context() failed: %v", err)
+	}
+	ctx, err := scriptcontext.ScriptContextV1FromData(pd)
+	if err != nil {
+		t.Fatalf("ScriptContextV1FromData() failed: %v", err)
+	}
+	if len(ctx.TxInfo.Mint) != 2 || len(ctx.TxInfo.Mint[0].Policy) != 0 {
+		t.Errorf("V1 mint lacks the zero-ada entry: %+v", ctx.TxInfo.Mint)
+	}
+	if len(ctx.TxInfo.Data) != 1 || !bytes.Equal(ctx.TxInfo.Data[0].Hash, datumHash[:]) {
+		t.Errorf("V1 data = %+v", ctx.TxInfo.Data)
+	}
+	if ctx.TxInfo.ValidRange.Lower.Kind != scriptcontext.NegInf ||
+		ctx.TxInfo.ValidRange.Upper.Kind != scriptcontext.PosInf {
+		t.Errorf("valid range = %+v", ctx.TxInfo.ValidRange)
+	}
+}
+
+func TestEvaluateTransactionProgramVersion(t *testing.T) {
+	// Program version 1.1.0, with case and constr, reaches PlutusV1 and
+	// PlutusV2 at protocol version 11.
+	mint := compile(t, builtin.PlutusV1,
+		`(program 1.1.0 (lam r (lam ctx (case (constr 0) (con unit ())))))`)
+	ref := scriptcontext.TxOutRef{TxID: hash(0x01, 32), Index: 0}
+	key := scriptcontext.Credential{Hash: hash(0xaa, 28)}
+	utxos := []UTxO{{Input: ref, Output: mustMarshal(t, []any{enterpriseAddress(key), 2_000_000})}}
+	body := map[uint64]any{
+		0: []any{[]any{ref.TxID, ref.Index}},
+		1: []any{},
+		2: 100,
+		9: map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(mint.hash()): {"": 1}},
+	}
+	witnesses := map[uint64]any{
+		3: []any{mint.bytes},
+		5: []any{[]any{1, 0, 0, []any{1, 1}}},
+	}
+	txCBOR := mustMarshal(t, []any{body, witnesses, true, nil})
+
+	for _, tt := range []struct {
+		major   uint
+		wantErr string
+	}{
+		{major: 10, wantErr: "program version 1.1.0 is not allowed for PlutusV1 at protocol version 10"},
+		{major: 11},
+	} {
+		params := *testParams
+		params.ProtocolVersion.Major = tt.major
+		results, err := EvaluateTransaction(txCBOR, utxos, &params, testSlots)
+		if err != nil {
+			t.Fatalf("protocol version %d: EvaluateTransaction() failed: %v", tt.major, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("protocol version %d: got %d results, want 1", tt.major, len(results))
+		}
+		switch err := results[0].Err; {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("protocol version %d: %v", tt.major, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("protocol version %d: got %v, want %q", tt.major, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEvaluateTransactionErrors(t *testing.T) {
+	f := newV3Fixture(t)
+	var unknown *UnknownInputsError
+	if _, err := EvaluateTransaction(f.tx, f.utxos[1:], testParams, testSlots); !errors.As(err, &unknown) ||
+		len(unknown.Inputs) != 1 || compareOutRefs(unknown.Inputs[0], f.keyRef) != 0 {
+		t.Errorf("unresolved input: got %v", err)
+	}
+	if _, err := EvaluateTransaction(f.tx, f.utxos, &Parameters{}, testSlots); err == nil {
+		t.Error("empty parameters accepted")
+	}
+	if _, err := EvaluateTransaction([]byte{0x80}, nil, testParams, testSlots); err == nil {
+		t.Error("empty transaction accepted")
+	}
+	// PlutusV3 arrived with Chang, at protocol version 9.
+	babbage := *testParams
+	babbage.ProtocolVersion.Major = 8
+	results, err := EvaluateTransaction(f.tx, f.utxos, &babbage, testSlots)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	for _, result := range results {
+		if result.Err == nil || !strings.Contains(result.Err.Error(), "not allowed at protocol version 8") {
+			t.Errorf("%s: got %v, want a disallowed language error", result.Redeemer, result.Err)
+		}
+	}
+
+	// Without the reference input the spending validator cannot be found.
+	nonUnit := compile(t, builtin.PlutusV3, `(program 1.1.0 (lam ctx (con integer 1)))`)
+	body := map[uint64]any{
+		0: []any{[]any{f.lockRef.TxID, f.lockRef.Index}},
+		1: []any{},
+		2: 0,
+		9: map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(nonUnit.hash()): {"": 1}},
+	}
+	witnesses := map[uint64]any{
+		5: []any{
+			[]any{0, 0, 0, []any{0, 0}},
+			[]any{1, 0, 0, []any{0, 0}},
+		},
+		7: []any{nonUnit.bytes},
+	}
+	results, err = EvaluateTransaction(
+		mustMarshal(t, []any{body, witnesses, true, nil}),
+		f.utxos,
+		testParams,
+		testSlots,
+	)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	for i, want := range []string{"missing script", "non-unit"} {
+		if results[i].Err == nil || !strings.Contains(results[i].Err.Error(), want) {
+			t.Errorf("%s: got %v, want an error containing %q", results[i].Redeemer, results[i].Err, want)
+		}
+	}
+
+	// A dangling redeemer cannot be listed in the V3 redeemer map, so no V3
+	// context can be built.
+	witnesses[5] = append(witnesses[5].([]any), []any{1, 1, 0, []any{0, 0}})
+	results, err = EvaluateTransaction(
+		mustMarshal(t, []any{body, witnesses, true, nil}),
+		f.utxos,
+		testParams,
+		testSlots,
+	)
+	if err != nil {
+		t.Fatalf("EvaluateTransaction() failed: %v", err)
+	}
+	for _, result := range results[1:] {
+		if result.Err == nil || !strings.Contains(result.Err.Error(), "points past") {
+			t.Errorf("%s: got %v, want a dangling redeemer error", result.Redeemer, result.Err)
+		}
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	payment := hash(0x01, 28)
+	stake := hash(0x02, 28)
+	tests := []struct {
+		name    string
+		address []byte
+		want    scriptcontext.Address
+	}{
+		{
+			name:    "base script/key",
+			address: append(append([]byte{0x11}, payment...), stake...),
+			want: scriptcontext.Address{
+				Credential: scriptcontext.Credential{Type: scriptcontext.ScriptCredential, Hash: payment},
+				StakingCredential: scriptcontext.StakingHash{
+					Credential: scriptcontext.Credential{Hash: stake},
+				},
+			},
+		},
+		{
+			name:    "pointer",
+			address: append(append([]byte{0x41}, payment...), 0x81, 0x00, 0x02, 0x03),
+			want: scriptcontext.Address{
+				Credential:        scriptcontext.Credential{Hash: payment},
+				StakingCredential: scriptcontext.StakingPtr{Slot: 128, TxIndex: 2, CertIndex: 3},
+			},
+		},
+		{
+			name:    "enterprise",
+			address: append([]byte{0x70}, payment...),
+			want: scriptcontext.Address{
+				Credential: scriptcontext.Credential{Type: scriptcontext.ScriptCredential, Hash: payment},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := decodeAddress(test.address)
+			if err != nil {
+				t.Fatalf("decodeAddress() failed: %v", err)
+			}
+			if !got.ToData().Equal(test.want.ToData()) {
+				t.Errorf("decodeAddress() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+	if _, err := decodeAddress([]byte{0x82, 0x00}); err != errByronAddress {
+		t.Errorf("Byron address: got %v", err)
+	}
+}
+
+func TestSlotToTime(t *testing.T) {
+	// Slot 4492800 is the start of the Shelley era on mainnet.
+	if got := MainnetSlotConfig.SlotToTime(4492800).Int64(); got != 1596059091000 {
+		t.Errorf("SlotToTime(4492800) = %d", got)
+	}
+	if got := MainnetSlotConfig.SlotToTime(4492801).Int64(); got != 1596059092000 {
+		t.Errorf("SlotToTime(4492801) = %d", got)
+	}
+}
@@ -0,0 +1,394 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+const (
+	// hash28 is the size of key, script and policy hashes.
+	hash28 = 28
+	// hash32 is the size of transaction IDs and datum hashes.
+	hash32 = 32
+)
+
+// errByronAddress is returned when a script context would need to include a
+// Byron bootstrap address, which Plutus cannot represent.
+var errByronAddress = errors.New("byron addresses cannot appear in a script context")
+
+// output is a decoded transaction output.
+type output struct {
+	address         scriptcontext.Address
+	byron           bool
+	value           scriptcontext.Value
+	datum           scriptcontext.OutputDatum
+	referenceScript *script
+}
+
+// decodeOutput decodes a legacy array or post-Alonzo map transaction output.
+func decodeOutput(raw []byte) (output, error) {
+	var out output
+	out.datum = scriptcontext.NoOutputDatum{}
+	var addressRaw, valueRaw cbor.RawMessage
+	switch {
+	case len(raw) > 0 && raw[0]>>5 == 4:
+		items, err := decodeArray(raw, "output")
+		if err != nil {
+			return output{}, err
+		}
+		if len(items) < 2 || len(items) > 3 {
+			return output{}, fmt.Errorf("decode output: got %d fields", len(items))
+		}
+		addressRaw, valueRaw = items[0], items[1]
+		if len(items) == 3 {
+			hash, err := decodeHash(items[2], hash32, "output datum hash")
+			if err != nil {
+				return output{}, err
+			}
+			out.datum = scriptcontext.OutputDatumHash{Hash: hash}
+		}
+	case len(raw) > 0 && raw[0]>>5 == 5:
+		pairs, err := decodeMap(raw, "output")
+		if err != nil {
+			return output{}, err
+		}
+		for _, pair := range pairs {
+			key, err := decodeUint(pair[0], "output key")
+			if err != nil {
+				return output{}, err
+			}
+			switch key {
+			case 0:
+				addressRaw = pair[1]
+			case 1:
+				valueRaw = pair[1]
+			case 2:
+				if out.datum, err = decodeDatumOption(pair[1]); err != nil {
+					return output{}, err
+				}
+			case 3:
+				if out.referenceScript, err = decodeScriptRef(pair[1]); err != nil {
+					return output{}, err
+				}
+			default:
+				return output{}, fmt.Errorf("decode output: unknown key %d", key)
+			}
+		}
+	default:
+		return output{}, errors.New("decode output: expected an array or map")
+	}
+	if addressRaw == nil || valueRaw == nil {
+		return output{}, errors.New("decode output: address and value are required")
+	}
+
+	addressBytes, err := decodeBytes(addressRaw, "output address")
+	if err != nil {
+		return output{}, err
+	}
+	out.address, err = decodeAddress(addressBytes)
+	if errors.Is(err, errByronAddress) {
+		out.byron = true
+	} else if err != nil {
+		return output{}, err
+	}
+	if out.value, err = decodeValue(valueRaw); err != nil {
+		return output{}, err
+	}
+	return out, nil
+}
+
+func decodeDatumOption(raw []byte) (scriptcontext.OutputDatum, error) {
+	tag, fields, err := decodeTagged(raw, "datum option")
+	if err != nil {
+		return nil, err
+	}
+	if err := expectFields(fields, 1, "datum option"); err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		hash, err := decodeHash(fields[0], hash32, "datum hash")
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.OutputDatumHash{Hash: hash}, nil
+	case 1:
+		// #6.24(bytes .cbor data); the embedded-CBOR tag is skipped
+		encoded, err := decodeBytes(fields[0], "inline datum")
+		if err != nil {
+			return nil, err
+		}
+		datum, err := data.Decode(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode inline datum: %w", err)
+		}
+		return scriptcontext.InlineDatum{Datum: datum}, nil
+	default:
+		return nil, fmt.Errorf("decode datum option: unknown kind %d", tag)
+	}
+}
+
+// decodeAddress decodes a Shelley address into its Plutus representation.
+func decodeAddress(b []byte) (scriptcontext.Address, error) {
+	if len(b) == 0 {
+		return scriptcontext.Address{}, errors.New("decode address: empty address")
+	}
+	header := b[0] >> 4
+	payment := scriptcontext.Credential{Type: credentialType(header&1 != 0)}
+	switch header {
+	case 0, 1, 2, 3:
+		if len(b) != 1+2*hash28 {
+			return scriptcontext.Address{}, fmt.Errorf("decode address: base address is %d bytes", len(b))
+		}
+		payment.Hash = b[1 : 1+hash28]
+		stake := scriptcontext.Credential{
+			Type: credentialType(header&2 != 0),
+			Hash: b[1+hash28:],
+		}
+		return scriptcontext.Address{
+			Credential:        payment,
+			StakingCredential: scriptcontext.StakingHash{Credential: stake},
+		}, nil
+	case 4, 5:
+		if len(b) < 1+hash28 {
+			return scriptcontext.Address{}, fmt.Errorf("decode address: pointer address is %d bytes", len(b))
+		}
+		payment.Hash = b[1 : 1+hash28]
+		ptr, err := decodePointer(b[1+hash28:])
+		if err != nil {
+			return scriptcontext.Address{}, fmt.Errorf("decode address pointer: %w", err)
+		}
+		return scriptcontext.Address{Credential: payment, StakingCredential: ptr}, nil
+	case 6, 7:
+		if len(b) != 1+hash28 {
+			return scriptcontext.Address{}, fmt.Errorf("decode address: enterprise address is %d bytes", len(b))
+		}
+		payment.Hash = b[1:]
+		return scriptcontext.Address{Credential: payment}, nil
+	case 8:
+		return scriptcontext.Address{}, errByronAddress
+	default:
+		return scriptcontext.Address{}, fmt.Errorf("decode address: unsupported address type %d", header)
+	}
+}
+
+func decodePointer(b []byte) (scriptcontext.StakingPtr, error) {
+	var values [3]uint64
+	for i := range values {
+		var v uint64
+		for {
+			if len(b) == 0 {
+				return scriptcontext.StakingPtr{}, errTruncated
+			}
+			if v > (1<<64-1)>>7 {
+				return scriptcontext.StakingPtr{}, errors.New("pointer value overflows")
+			}
+			v = v<<7 | uint64(b[0]&0x7f)
+			more := b[0]&0x80 != 0
+			b = b[1:]
+			if !more {
+				break
+			}
+		}
+		values[i] = v
+	}
+	if len(b) != 0 {
+		return scriptcontext.StakingPtr{}, errors.New("trailing bytes after pointer")
+	}
+	return scriptcontext.StakingPtr{
+		Slot:      values[0],
+		TxIndex:   values[1],
+		CertIndex: values[2],
+	}, nil
+}
+
+// decodeRewardAccount decodes a reward address into its stake credential.
+func decodeRewardAccount(raw []byte) (scriptcontext.Credential, error) {
+	b, err := decodeBytes(raw, "reward account")
+	if err != nil {
+		return scriptcontext.Credential{}, err
+	}
+	if len(b) != 1+hash28 || b[0]>>4 != 14 && b[0]>>4 != 15 {
+		return scriptcontext.Credential{}, fmt.Errorf("decode reward account: invalid address %x", b)
+	}
+	return scriptcontext.Credential{
+		Type: credentialType(b[0]&0x10 != 0),
+		Hash: b[1:],
+	}, nil
+}
+
+func credentialType(script bool) scriptcontext.CredentialType {
+	if script {
+		return scriptcontext.ScriptCredential
+	}
+	return scriptcontext.PubKeyCredential
+}
+
+// decodeCredential decodes a ledger credential: [0, key hash] or
+// [1, script hash].
+func decodeCredential(raw []byte) (scriptcontext.Credential, error) {
+	tag, fields, err := decodeTagged(raw, "credential")
+	if err != nil {
+		return scriptcontext.Credential{}, err
+	}
+	if err := expectFields(fields, 1, "credential"); err != nil {
+		return scriptcontext.Credential{}, err
+	}
+	if tag > 1 {
+		return scriptcontext.Credential{}, fmt.Errorf("decode credential: unknown kind %d", tag)
+	}
+	hash, err := decodeHash(fields[0], hash28, "credential hash")
+	if err != nil {
+		return scriptcontext.Credential{}, err
+	}
+	return scriptcontext.Credential{Type: credentialType(tag == 1), Hash: hash}, nil
+}
+
+// compareCredentials orders credentials the way the ledger does: script
+// credentials before key credentials, then by hash.
+func compareCredentials(a, b scriptcontext.Credential) int {
+	if a.Type != b.Type {
+		if a.Type == scriptcontext.ScriptCredential {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(a.Hash, b.Hash)
+}
+
+// multiAsset is a decoded policy -> asset name -> quantity map.
+type multiAsset map[cbor.ByteString]map[cbor.ByteString]big.Int
+
+// decodeValue decodes an output value: a coin or [coin, multiasset].
+func decodeValue(raw []byte) (scriptcontext.Value, error) {
+	if len(raw) > 0 && raw[0]>>5 == 4 {
+		var value struct {
+			_      struct{} `cbor:",toarray"`
+			Coin   big.Int
+			Assets multiAsset
+		}
+		if err := unmarshal(raw, &value, "value"); err != nil {
+			return nil, err
+		}
+		return value.Assets.value(&value.Coin), nil
+	}
+	coin, err := decodeBigInt(raw, "value")
+	if err != nil {
+		return nil, err
+	}
+	return scriptcontext.Lovelace(coin), nil
+}
+
+// value converts the multiasset to a Value sorted by policy and asset name,
+// starting with an ada entry unless coin is nil.
+func (m multiAsset) value(coin *big.Int) scriptcontext.Value {
+	var value scriptcontext.Value
+	if coin != nil {
+		value = scriptcontext.Lovelace(coin)
+	}
+	policies := make([]cbor.ByteString, 0, len(m))
+	for policy := range m {
+		policies = append(policies, policy)
+	}
+	slices.Sort(policies)
+	for _, policy := range policies {
+		names := make([]cbor.ByteString, 0, len(m[policy]))
+		for name := range m[policy] {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		entry := scriptcontext.PolicyAssets{Policy: []byte(policy)}
+		for _, name := range names {
+			quantity := m[policy][name]
+			entry.Assets = append(entry.Assets, scriptcontext.Asset{
+				Name:     []byte(name),
+				Quantity: &quantity,
+			})
+		}
+		value = append(value, entry)
+	}
+	return value
+}
+
+// script is a native or Plutus script from a witness set or a reference
+// script. For Plutus scripts bytes holds the CBOR-wrapped FLAT program; for
+// native scripts it holds the script's CBOR.
+type script struct {
+	native   bool
+	language builtin.PlutusVersion
+	bytes    []byte
+}
+
+// hash returns the script hash: blake2b-224 over a language tag followed by
+// the script bytes.
+func (s *script) hash() []byte {
+	if s.native {
+		return hashScript(0, s.bytes)
+	}
+	return ScriptHash(s.language, s.bytes)
+}
+
+// ScriptHash returns the ledger hash of a Plutus script, given as the
+// CBOR-wrapped FLAT program that transactions carry. The hash covers these
+// bytes as they are, so a re-encoding of the program may hash differently.
+func ScriptHash(language builtin.PlutusVersion, script []byte) []byte {
+	return hashScript(byte(language), script)
+}
+
+func hashScript(tag byte, b []byte) []byte {
+	h, _ := blake2b.New(hash28, nil)
+	h.Write([]byte{tag})
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// program decodes the FLAT program inside the script's CBOR bytestring.
+func (s *script) program() (*syn.Program[syn.DeBruijn], error) {
+	flat, err := decodeBytes(s.bytes, "script bytes")
+	if err != nil {
+		return nil, err
+	}
+	program, err := syn.Decode[syn.DeBruijn](flat)
+	if err != nil {
+		return nil, fmt.Errorf("decode FLAT program: %w", err)
+	}
+	return program, nil
+}
+
+// decodeScriptRef decodes #6.24(bytes .cbor script).
+func decodeScriptRef(raw []byte) (*script, error) {
+	encoded, err := decodeBytes(raw, "reference script")
+	if err != nil {
+		return nil, err
+	}
+	tag, fields, err := decodeTagged(encoded, "reference script")
+	if err != nil {
+		return nil, err
+	}
+	if err := expectFields(fields, 1, "reference script"); err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		return &script{native: true, bytes: fields[0]}, nil
+	case 1, 2, 3:
+		b, err := decodeBytes(fields[0], "reference script")
+		if err != nil {
+			return nil, err
+		}
+		return &script{language: builtin.PlutusVersion(tag), bytes: b}, nil
+	default:
+		return nil, fmt.Errorf("decode reference script: unknown script kind %d", tag)
+	}
+}
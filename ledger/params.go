@@ -0,0 +1,292 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/policy"
+)
+
+// LoadParametersFile reads protocol parameters from a JSON file; see
+// [LoadParameters] for the accepted formats.
+func LoadParametersFile(path string) (*Parameters, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open protocol parameters: %w", err)
+	}
+	defer f.Close()
+	return LoadParameters(f)
+}
+
+// LoadParameters reads the protocol parameters that affect script evaluation
+// from JSON in any of these formats:
+//
+//   - the output of cardano-cli query protocol-parameters
+//   - the Ogmios protocol parameters query, v5 or v6, bare or as the full
+//     JSON-RPC response
+//   - the Blockfrost epoch parameters endpoint
+//
+// Cost models may be positional lists or maps from parameter name to value.
+// Named models are put in the order of the lang package's parameter names,
+// so lists and maps of the same model give the same parameters whatever
+// their length. A map must hold a prefix of the language's parameters: a gap
+// would shift every later parameter, so it is an error. PlutusV1 models may
+// use the later verifyEd25519Signature and blake2b_256 names for the
+// verifySignature and blake2b parameters.
+func LoadParameters(r io.Reader) (*Parameters, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var fields map[string]json.RawMessage
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("decode protocol parameters: %w", err)
+	}
+	// Ogmios JSON-RPC responses carry the parameters in their result.
+	if result, ok := fields["result"]; ok && fields["jsonrpc"] != nil {
+		fields = nil
+		if err := json.Unmarshal(result, &fields); err != nil {
+			return nil, fmt.Errorf("decode protocol parameters: %w", err)
+		}
+	}
+	if fields == nil {
+		return nil, errors.New("decode protocol parameters: not a JSON object")
+	}
+
+	p := &Parameters{}
+	var err error
+	if p.ProtocolVersion.Major, p.ProtocolVersion.Minor, err = protocolVersion(fields); err != nil {
+		return nil, err
+	}
+	if p.MaxTxExUnits, err = exUnitsField(fields, "transaction",
+		"maxTxExecutionUnits", "maxExecutionUnitsPerTransaction", "max_tx_ex"); err != nil {
+		return nil, err
+	}
+	if p.MaxBlockExUnits, err = exUnitsField(fields, "block",
+		"maxBlockExecutionUnits", "maxExecutionUnitsPerBlock", "max_block_ex"); err != nil {
+		return nil, err
+	}
+	if p.CostModels, err = costModels(fields); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// present reports whether a field exists and is not null.
+func present(raw json.RawMessage) bool {
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("null"))
+}
+
+// paramInt accepts integers as JSON numbers or, as Blockfrost sends some of
+// them, strings.
+type paramInt int64
+
+func (i *paramInt) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", b)
+	}
+	*i = paramInt(n)
+	return nil
+}
+
+func protocolVersion(fields map[string]json.RawMessage) (uint, uint, error) {
+	// cardano-cli and Ogmios v5 use protocolVersion, Ogmios v6 uses version.
+	for _, key := range []string{"protocolVersion", "version"} {
+		if raw := fields[key]; present(raw) {
+			var version struct {
+				Major paramInt `json:"major"`
+				Minor paramInt `json:"minor"`
+			}
+			if err := json.Unmarshal(raw, &version); err != nil {
+				return 0, 0, fmt.Errorf("decode %s: %w", key, err)
+			}
+			if version.Major <= 0 || version.Minor < 0 {
+				return 0, 0, fmt.Errorf("invalid protocol version %d.%d", version.Major, version.Minor)
+			}
+			return uint(version.Major), uint(version.Minor), nil
+		}
+	}
+	// Blockfrost.
+	if raw := fields["protocol_major_ver"]; present(raw) {
+		var major, minor paramInt
+		if err := json.Unmarshal(raw, &major); err != nil {
+			return 0, 0, fmt.Errorf("decode protocol_major_ver: %w", err)
+		}
+		if raw := fields["protocol_minor_ver"]; present(raw) {
+			if err := json.Unmarshal(raw, &minor); err != nil {
+				return 0, 0, fmt.Errorf("decode protocol_minor_ver: %w", err)
+			}
+		}
+		if major <= 0 || minor < 0 {
+			return 0, 0, fmt.Errorf("invalid protocol version %d.%d", major, minor)
+		}
+		return uint(major), uint(minor), nil
+	}
+	return 0, 0, errors.New("protocol parameters have no protocol version")
+}
+
+// exUnitsField reads execution units from the object field of cardano-cli
+// (memory and steps), or Ogmios (memory and steps or cpu), or from the pair
+// of Blockfrost fields with the given prefix.
+func exUnitsField(
+	fields map[string]json.RawMessage,
+	what, cardanoCLI, ogmios, blockfrost string,
+) (ExUnits, error) {
+	for _, key := range []string{cardanoCLI, ogmios} {
+		raw := fields[key]
+		if !present(raw) {
+			continue
+		}
+		var units struct {
+			Memory *paramInt `json:"memory"`
+			Steps  *paramInt `json:"steps"`
+			CPU    *paramInt `json:"cpu"`
+		}
+		if err := json.Unmarshal(raw, &units); err != nil {
+			return ExUnits{}, fmt.Errorf("decode %s: %w", key, err)
+		}
+		if units.Steps == nil {
+			units.Steps = units.CPU
+		}
+		if units.Memory == nil || units.Steps == nil {
+			return ExUnits{}, fmt.Errorf("decode %s: missing memory or steps", key)
+		}
+		return ExUnits{Steps: int64(*units.Steps), Memory: int64(*units.Memory)}, nil
+	}
+	mem, steps := fields[blockfrost+"_mem"], fields[blockfrost+"_steps"]
+	if present(mem) && present(steps) {
+		var units ExUnits
+		if err := json.Unmarshal(mem, (*paramInt)(&units.Memory)); err != nil {
+			return ExUnits{}, fmt.Errorf("decode %s_mem: %w", blockfrost, err)
+		}
+		if err := json.Unmarshal(steps, (*paramInt)(&units.Steps)); err != nil {
+			return ExUnits{}, fmt.Errorf("decode %s_steps: %w", blockfrost, err)
+		}
+		return units, nil
+	}
+	return ExUnits{}, fmt.Errorf("protocol parameters have no maximum %s execution units", what)
+}
+
+// costModels reads the cost models. Blockfrost sends both the positional
+// cost_models_raw and the named cost_models; the positional form wins.
+func costModels(fields map[string]json.RawMessage) (map[builtin.PlutusVersion][]int64, error) {
+	for _, key := range []string{"cost_models_raw", "costModels", "plutusCostModels", "cost_models"} {
+		raw := fields[key]
+		if !present(raw) {
+			continue
+		}
+		var models map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &models); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", key, err)
+		}
+		ret := make(map[builtin.PlutusVersion][]int64, len(models))
+		for name, model := range models {
+			language, err := costModelLanguage(name)
+			if err != nil {
+				return nil, fmt.Errorf("decode %s: %w", key, err)
+			}
+			if !present(model) {
+				continue
+			}
+			params, err := costModel(language, model)
+			if err != nil {
+				return nil, fmt.Errorf("decode %s %s: %w", key, name, err)
+			}
+			ret[language] = params
+		}
+		return ret, nil
+	}
+	return nil, errors.New("protocol parameters have no cost models")
+}
+
+// costModelLanguage recognizes the language names of cardano-cli (PlutusV1,
+// or PlutusScriptV1 in older releases), Ogmios (plutus:v1) and Blockfrost.
+func costModelLanguage(name string) (builtin.PlutusVersion, error) {
+	switch strings.ToLower(name) {
+	case "plutusv1", "plutusscriptv1", "plutus:v1":
+		return builtin.PlutusV1, nil
+	case "plutusv2", "plutusscriptv2", "plutus:v2":
+		return builtin.PlutusV2, nil
+	case "plutusv3", "plutusscriptv3", "plutus:v3":
+		return builtin.PlutusV3, nil
+	}
+	return 0, fmt.Errorf("unknown cost model language %q", name)
+}
+
+func costModel(language builtin.PlutusVersion, raw json.RawMessage) ([]int64, error) {
+	if raw[0] == '[' {
+		var list []paramInt
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, err
+		}
+		params := make([]int64, len(list))
+		for i, param := range list {
+			params[i] = int64(param)
+		}
+		return params, nil
+	}
+	var named map[string]paramInt
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, err
+	}
+	version, err := policy.LanguageVersion(language)
+	if err != nil {
+		return nil, err
+	}
+	names := lang.GetParamNamesForVersion(version)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	params := make([]int64, len(named))
+	seen := make([]bool, len(named))
+	for name, value := range named {
+		if language == builtin.PlutusV1 {
+			name = legacyParamName(name)
+		}
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+		if i >= len(params) {
+			// Some parameter before this one is missing, which the loop
+			// below reports.
+			continue
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("duplicate parameter %q", name)
+		}
+		params[i], seen[i] = int64(value), true
+	}
+	for i, ok := range seen {
+		if !ok {
+			return nil, fmt.Errorf("missing parameter %q", names[i])
+		}
+	}
+	return params, nil
+}
+
+// legacyParamName maps the names that later languages gave two PlutusV1
+// builtins to the names PlutusV1 cost models use.
+func legacyParamName(name string) string {
+	for _, prefix := range [][2]string{
+		{"verifyEd25519Signature-", "verifySignature-"},
+		{"blake2b_256-", "blake2b-"},
+	} {
+		if rest, ok := strings.CutPrefix(name, prefix[0]); ok {
+			return prefix[1] + rest
+		}
+	}
+	return name
+}
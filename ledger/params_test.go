@@ -0,0 +1,229 @@
+package ledger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/lang"
+)
+
+// mainnetV1Length is the length of the PlutusV1 cost model since Alonzo.
+const mainnetV1Length = 166
+
+func positionalModel(n int) []int64 {
+	params := make([]int64, n)
+	for i := range params {
+		params[i] = int64(i + 1)
+	}
+	return params
+}
+
+func namedModel(names []string, n int) map[string]any {
+	named := make(map[string]any, n)
+	for i, name := range names[:n] {
+		named[name] = i + 1
+	}
+	return named
+}
+
+func TestLoadParameters(t *testing.T) {
+	v1 := positionalModel(mainnetV1Length)
+	v3 := positionalModel(len(lang.CostModelParamNamesV3))
+	legacyV1 := namedModel(lang.CostModelParamNamesV1, mainnetV1Length)
+	for _, suffix := range []string{"cpu-arguments-intercept", "cpu-arguments-slope", "memory-arguments"} {
+		legacyV1["verifyEd25519Signature-"+suffix] = legacyV1["verifySignature-"+suffix]
+		delete(legacyV1, "verifySignature-"+suffix)
+	}
+	tests := []struct {
+		name  string
+		input map[string]any
+		major uint
+		block ExUnits
+	}{
+		{
+			name: "cardano-cli",
+			input: map[string]any{
+				"costModels":             map[string]any{"PlutusV1": v1, "PlutusV3": v3},
+				"protocolVersion":        map[string]any{"major": 10, "minor": 0},
+				"maxTxExecutionUnits":    map[string]any{"memory": 14_000_000, "steps": 10_000_000_000},
+				"maxBlockExecutionUnits": map[string]any{"memory": 62_000_000, "steps": 20_000_000_000},
+			},
+			major: 10,
+			block: ExUnits{Steps: 20_000_000_000, Memory: 62_000_000},
+		},
+		{
+			name: "ogmios json-rpc",
+			input: map[string]any{
+				"jsonrpc": "2.0",
+				"method":  "queryLedgerState/protocolParameters",
+				"result": map[string]any{
+					"plutusCostModels":                map[string]any{"plutus:v1": v1, "plutus:v3": v3},
+					"version":                         map[string]any{"major": 9, "minor": 1},
+					"maxExecutionUnitsPerTransaction": map[string]any{"memory": 14_000_000, "cpu": 10_000_000_000},
+					"maxExecutionUnitsPerBlock":       map[string]any{"memory": 62_000_000, "cpu": 20_000_000_000},
+				},
+			},
+			major: 9,
+			block: ExUnits{Steps: 20_000_000_000, Memory: 62_000_000},
+		},
+		{
+			name: "blockfrost named",
+			input: map[string]any{
+				"cost_models": map[string]any{
+					"PlutusV1": legacyV1,
+					"PlutusV3": namedModel(lang.CostModelParamNamesV3, len(v3)),
+				},
+				"protocol_major_ver": 10,
+				"protocol_minor_ver": 0,
+				"max_tx_ex_mem":      "14000000",
+				"max_tx_ex_steps":    "10000000000",
+				"max_block_ex_mem":   "62000000",
+				"max_block_ex_steps": "20000000000",
+			},
+			major: 10,
+			block: ExUnits{Steps: 20_000_000_000, Memory: 62_000_000},
+		},
+		{
+			name: "blockfrost raw",
+			input: map[string]any{
+				// The raw models take precedence.
+				"cost_models":        map[string]any{"PlutusV1": map[string]any{"unknown": 1}},
+				"cost_models_raw":    map[string]any{"PlutusV1": v1, "PlutusV2": nil, "PlutusV3": v3},
+				"protocol_major_ver": 10,
+				"max_tx_ex_mem":      "14000000",
+				"max_tx_ex_steps":    "10000000000",
+				"max_block_ex_mem":   62000000,
+				"max_block_ex_steps": 20000000000,
+			},
+			major: 10,
+			block: ExUnits{Steps: 20_000_000_000, Memory: 62_000_000},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, err := json.Marshal(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := LoadParameters(strings.NewReader(string(input)))
+			if err != nil {
+				t.Fatalf("LoadParameters() failed: %v", err)
+			}
+			if p.ProtocolVersion.Major != test.major {
+				t.Errorf("protocol major = %d, want %d", p.ProtocolVersion.Major, test.major)
+			}
+			if want := (ExUnits{Steps: 10_000_000_000, Memory: 14_000_000}); p.MaxTxExUnits != want {
+				t.Errorf("MaxTxExUnits = %+v, want %+v", p.MaxTxExUnits, want)
+			}
+			if p.MaxBlockExUnits != test.block {
+				t.Errorf("MaxBlockExUnits = %+v, want %+v", p.MaxBlockExUnits, test.block)
+			}
+			if len(p.CostModels) != 2 ||
+				!slices.Equal(p.CostModels[builtin.PlutusV1], v1) ||
+				!slices.Equal(p.CostModels[builtin.PlutusV3], v3) {
+				t.Errorf("CostModels = %v", p.CostModels)
+			}
+			for _, language := range []builtin.PlutusVersion{builtin.PlutusV1, builtin.PlutusV2, builtin.PlutusV3} {
+				if _, err := p.EvalContext(language); err != nil {
+					t.Errorf("EvalContext(PlutusV%d) failed: %v", language, err)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadParametersV1Lengths checks that a named PlutusV1 model longer than
+// the Alonzo one gives the same parameters as the positional model.
+func TestLoadParametersV1Lengths(t *testing.T) {
+	for _, n := range []int{mainnetV1Length, mainnetV1Length + 9, len(lang.CostModelParamNamesV1)} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			input, err := json.Marshal(map[string]any{
+				"costModels":             map[string]any{"PlutusV1": namedModel(lang.CostModelParamNamesV1, n)},
+				"protocolVersion":        map[string]any{"major": 10, "minor": 0},
+				"maxTxExecutionUnits":    map[string]any{"memory": 1, "steps": 1},
+				"maxBlockExecutionUnits": map[string]any{"memory": 1, "steps": 1},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := LoadParameters(strings.NewReader(string(input)))
+			if err != nil {
+				t.Fatalf("LoadParameters() failed: %v", err)
+			}
+			if !slices.Equal(p.CostModels[builtin.PlutusV1], positionalModel(n)) {
+				t.Errorf("PlutusV1 model = %v", p.CostModels[builtin.PlutusV1])
+			}
+		})
+	}
+}
+
+func TestLoadParametersErrors(t *testing.T) {
+	gap := namedModel(lang.CostModelParamNamesV1, mainnetV1Length)
+	delete(gap, "cekApplyCost-exBudgetCPU")
+	gap[lang.CostModelParamNamesV1[mainnetV1Length]] = 1
+	base := func(models any) map[string]any {
+		return map[string]any{
+			"costModels":             models,
+			"protocolVersion":        map[string]any{"major": 10, "minor": 0},
+			"maxTxExecutionUnits":    map[string]any{"memory": 1, "steps": 1},
+			"maxBlockExecutionUnits": map[string]any{"memory": 1, "steps": 1},
+		}
+	}
+	noVersion := base(map[string]any{})
+	delete(noVersion, "protocolVersion")
+	noBudget := base(map[string]any{})
+	delete(noBudget, "maxTxExecutionUnits")
+	tests := []struct {
+		name  string
+		input any
+		want  string
+	}{
+		{"gap", base(map[string]any{"PlutusV1": gap}), `missing parameter "cekApplyCost-exBudgetCPU"`},
+		{"unknown parameter", base(map[string]any{"PlutusV2": map[string]any{"nope": 1}}), `unknown parameter "nope"`},
+		{"unknown language", base(map[string]any{"PlutusV9": []int{1}}), `unknown cost model language "PlutusV9"`},
+		{"bad integer", base(map[string]any{"PlutusV1": []any{"x"}}), "invalid integer"},
+		{"no version", noVersion, "no protocol version"},
+		{"no budget", noBudget, "no maximum transaction execution units"},
+		{"not an object", []int{1}, "decode protocol parameters"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, err := json.Marshal(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = LoadParameters(strings.NewReader(string(input)))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("LoadParameters() error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadParametersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "params.json")
+	input := `{"protocolVersion": {"major": 10, "minor": 0},
+		"maxTxExecutionUnits": {"memory": 14000000, "steps": 10000000000},
+		"maxBlockExecutionUnits": {"memory": 62000000, "steps": 20000000000},
+		"costModels": {"PlutusV2": [1, 2, 3]}}`
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadParametersFile(path)
+	if err != nil {
+		t.Fatalf("LoadParametersFile() failed: %v", err)
+	}
+	if p.MaxTxExUnits.ExBudget() != (cek.ExBudget{Cpu: 10_000_000_000, Mem: 14_000_000}) {
+		t.Errorf("budget = %+v", p.MaxTxExUnits.ExBudget())
+	}
+	if _, err := LoadParametersFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadParametersFile() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,406 @@
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+// transaction is the part of a Conway-era transaction that scripts can
+// observe. Set-like fields are sorted the way the ledger orders them, so that
+// redeemer indexes can be resolved by position.
+type transaction struct {
+	id              []byte
+	inputs          []scriptcontext.TxOutRef
+	referenceInputs []scriptcontext.TxOutRef
+	outputs         []output
+	fee             *big.Int
+	validityStart   *uint64
+	ttl             *uint64
+	certificates    []certificate
+	withdrawals     []scriptcontext.Withdrawal
+	mint            scriptcontext.Value
+	signatories     [][]byte
+	voters          []voter
+	proposals       []scriptcontext.ProposalProcedure
+	currentTreasury *big.Int
+	donation        *big.Int
+
+	scripts   []*script
+	datums    []scriptcontext.DatumEntry
+	redeemers []redeemer
+}
+
+type redeemer struct {
+	pointer RedeemerPointer
+	data    data.PlutusData
+	budget  ExUnits
+}
+
+// decodeTransaction decodes [body, witness set, is valid, auxiliary data].
+func decodeTransaction(txCBOR []byte) (*transaction, error) {
+	items, err := decodeArray(txCBOR, "transaction")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 3 && len(items) != 4 {
+		return nil, fmt.Errorf("decode transaction: got %d fields, want 4", len(items))
+	}
+	tx := &transaction{fee: new(big.Int)}
+	// The transaction ID hashes the body exactly as it was serialized.
+	id := blake2b.Sum256(items[0])
+	tx.id = id[:]
+	if err := tx.decodeBody(items[0]); err != nil {
+		return nil, err
+	}
+	if err := tx.decodeWitnesses(items[1]); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (tx *transaction) decodeBody(raw []byte) error {
+	fields, err := decodeMap(raw, "transaction body")
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		key, err := decodeUint(field[0], "transaction body key")
+		if err != nil {
+			return err
+		}
+		value := field[1]
+		switch key {
+		case 0:
+			tx.inputs, err = decodeInputs(value, "inputs")
+		case 1:
+			tx.outputs, err = decodeOutputs(value)
+		case 2:
+			tx.fee, err = decodeBigInt(value, "fee")
+		case 3:
+			tx.ttl, err = decodeSlot(value, "time to live")
+		case 4:
+			tx.certificates, err = decodeCertificates(value)
+		case 5:
+			tx.withdrawals, err = decodeWithdrawals(value)
+		case 8:
+			tx.validityStart, err = decodeSlot(value, "validity interval start")
+		case 9:
+			var mint multiAsset
+			if err = unmarshal(value, &mint, "mint"); err == nil {
+				tx.mint = mint.value(nil)
+			}
+		case 14:
+			tx.signatories, err = decodeSignatories(value)
+		case 18:
+			tx.referenceInputs, err = decodeInputs(value, "reference inputs")
+		case 19:
+			tx.voters, err = decodeVotingProcedures(value)
+		case 20:
+			tx.proposals, err = decodeProposals(value)
+		case 21:
+			tx.currentTreasury, err = decodeBigInt(value, "current treasury value")
+		case 22:
+			tx.donation, err = decodeBigInt(value, "treasury donation")
+		}
+		// Other fields, such as collateral, do not appear in script contexts.
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeSlot(raw []byte, what string) (*uint64, error) {
+	slot, err := decodeUint(raw, what)
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// decodeInputs decodes a set of [transaction id, index] inputs in ledger
+// order.
+func decodeInputs(raw []byte, what string) ([]scriptcontext.TxOutRef, error) {
+	items, err := decodeArray(raw, what)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]scriptcontext.TxOutRef, 0, len(items))
+	for _, item := range items {
+		ref, err := decodeOutRef(item)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", what, err)
+		}
+		refs = append(refs, ref)
+	}
+	slices.SortFunc(refs, compareOutRefs)
+	return slices.CompactFunc(refs, func(a, b scriptcontext.TxOutRef) bool {
+		return compareOutRefs(a, b) == 0
+	}), nil
+}
+
+func decodeOutRef(raw []byte) (scriptcontext.TxOutRef, error) {
+	var input struct {
+		_     struct{} `cbor:",toarray"`
+		TxID  []byte
+		Index uint64
+	}
+	if err := unmarshal(raw, &input, "input"); err != nil {
+		return scriptcontext.TxOutRef{}, err
+	}
+	if len(input.TxID) != hash32 {
+		return scriptcontext.TxOutRef{}, fmt.Errorf("input transaction id is %d bytes", len(input.TxID))
+	}
+	return scriptcontext.TxOutRef{TxID: input.TxID, Index: input.Index}, nil
+}
+
+func compareOutRefs(a, b scriptcontext.TxOutRef) int {
+	if c := bytes.Compare(a.TxID, b.TxID); c != 0 {
+		return c
+	}
+	switch {
+	case a.Index < b.Index:
+		return -1
+	case a.Index > b.Index:
+		return 1
+	}
+	return 0
+}
+
+func decodeOutputs(raw []byte) ([]output, error) {
+	items, err := decodeArray(raw, "outputs")
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]output, 0, len(items))
+	for i, item := range items {
+		out, err := decodeOutput(item)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+func decodeCertificates(raw []byte) ([]certificate, error) {
+	items, err := decodeArray(raw, "certificates")
+	if err != nil {
+		return nil, err
+	}
+	certs := make([]certificate, 0, len(items))
+	for i, item := range items {
+		cert, err := decodeCertificate(item)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func decodeSignatories(raw []byte) ([][]byte, error) {
+	items, err := decodeArray(raw, "required signers")
+	if err != nil {
+		return nil, err
+	}
+	signatories := make([][]byte, 0, len(items))
+	for _, item := range items {
+		hash, err := decodeHash(item, hash28, "required signer")
+		if err != nil {
+			return nil, err
+		}
+		signatories = append(signatories, hash)
+	}
+	slices.SortFunc(signatories, bytes.Compare)
+	return slices.CompactFunc(signatories, bytes.Equal), nil
+}
+
+func decodeProposals(raw []byte) ([]scriptcontext.ProposalProcedure, error) {
+	items, err := decodeArray(raw, "proposal procedures")
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]scriptcontext.ProposalProcedure, 0, len(items))
+	for i, item := range items {
+		proposal, err := decodeProposal(item)
+		if err != nil {
+			return nil, fmt.Errorf("proposal %d: %w", i, err)
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+func (tx *transaction) decodeWitnesses(raw []byte) error {
+	fields, err := decodeMap(raw, "witness set")
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		key, err := decodeUint(field[0], "witness set key")
+		if err != nil {
+			return err
+		}
+		value := field[1]
+		switch key {
+		case 1:
+			err = tx.decodeNativeScripts(value)
+		case 3, 6, 7:
+			language := map[uint64]builtin.PlutusVersion{
+				3: builtin.PlutusV1,
+				6: builtin.PlutusV2,
+				7: builtin.PlutusV3,
+			}[key]
+			err = tx.decodePlutusScripts(value, language)
+		case 4:
+			err = tx.decodeDatums(value)
+		case 5:
+			err = tx.decodeRedeemers(value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	slices.SortFunc(tx.datums, func(a, b scriptcontext.DatumEntry) int {
+		return bytes.Compare(a.Hash, b.Hash)
+	})
+	slices.SortFunc(tx.redeemers, func(a, b redeemer) int {
+		return a.pointer.compare(b.pointer)
+	})
+	return nil
+}
+
+func (tx *transaction) decodeNativeScripts(raw []byte) error {
+	items, err := decodeArray(raw, "native scripts")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		tx.scripts = append(tx.scripts, &script{native: true, bytes: item})
+	}
+	return nil
+}
+
+func (tx *transaction) decodePlutusScripts(raw []byte, language builtin.PlutusVersion) error {
+	var items [][]byte
+	if err := unmarshal(raw, &items, fmt.Sprintf("PlutusV%d scripts", language)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		tx.scripts = append(tx.scripts, &script{language: language, bytes: item})
+	}
+	return nil
+}
+
+// decodeDatums hashes each datum's original bytes, and decodes the datum
+// canonically, as the node passes it to scripts.
+func (tx *transaction) decodeDatums(raw []byte) error {
+	items, err := decodeArray(raw, "datums")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		datum, err := data.Decode(item)
+		if err != nil {
+			return fmt.Errorf("decode datum: %w", err)
+		}
+		hash := data.HashBytes(item)
+		tx.datums = append(tx.datums, scriptcontext.DatumEntry{
+			Hash:  hash[:],
+			Datum: datum,
+		})
+	}
+	return nil
+}
+
+// decodeRedeemers accepts both the legacy array of [tag, index, data, ex
+// units] and the Conway map from [tag, index] to [data, ex units].
+func (tx *transaction) decodeRedeemers(raw []byte) error {
+	type exUnits struct {
+		_      struct{} `cbor:",toarray"`
+		Memory int64
+		Steps  int64
+	}
+	if len(raw) > 0 && raw[0]>>5 == 5 {
+		pairs, err := decodeMap(raw, "redeemers")
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			var key struct {
+				_     struct{} `cbor:",toarray"`
+				Tag   RedeemerTag
+				Index uint64
+			}
+			if err := unmarshal(pair[0], &key, "redeemer key"); err != nil {
+				return err
+			}
+			var value struct {
+				_       struct{} `cbor:",toarray"`
+				Data    cbor.RawMessage
+				ExUnits exUnits
+			}
+			if err := unmarshal(pair[1], &value, "redeemer"); err != nil {
+				return err
+			}
+			err := tx.addRedeemer(
+				RedeemerPointer{Tag: key.Tag, Index: key.Index},
+				value.Data,
+				ExUnits{Memory: value.ExUnits.Memory, Steps: value.ExUnits.Steps},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var items []struct {
+		_       struct{} `cbor:",toarray"`
+		Tag     RedeemerTag
+		Index   uint64
+		Data    cbor.RawMessage
+		ExUnits exUnits
+	}
+	if err := unmarshal(raw, &items, "redeemers"); err != nil {
+		return err
+	}
+	for _, item := range items {
+		err := tx.addRedeemer(
+			RedeemerPointer{Tag: item.Tag, Index: item.Index},
+			item.Data,
+			ExUnits{Memory: item.ExUnits.Memory, Steps: item.ExUnits.Steps},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *transaction) addRedeemer(pointer RedeemerPointer, raw []byte, budget ExUnits) error {
+	if pointer.Tag > Propose {
+		return fmt.Errorf("decode redeemer: unknown tag %d", pointer.Tag)
+	}
+	for _, r := range tx.redeemers {
+		if r.pointer == pointer {
+			return fmt.Errorf("decode redeemers: duplicate redeemer %s", pointer)
+		}
+	}
+	pd, err := data.Decode(raw)
+	if err != nil {
+		return fmt.Errorf("decode redeemer %s: %w", pointer, err)
+	}
+	tx.redeemers = append(tx.redeemers, redeemer{pointer: pointer, data: pd, budget: budget})
+	return nil
+}
@@ -0,0 +1,554 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+// resolvedInput is a transaction input together with the output it spends
+// or references.
+type resolvedInput struct {
+	ref scriptcontext.TxOutRef
+	out output
+}
+
+// evaluation is a transaction with its inputs resolved, from which the
+// script context of every redeemer is built.
+type evaluation struct {
+	tx              *transaction
+	slots           SlotConfig
+	inputs          []resolvedInput
+	referenceInputs []resolvedInput
+	scripts         map[string]*script
+
+	// Transaction infos are shared by every redeemer of a language and are
+	// built on first use.
+	infoV1    *scriptcontext.TxInfoV1
+	infoV2    *scriptcontext.TxInfoV2
+	infoV3    *scriptcontext.TxInfoV3
+	infoError map[builtin.PlutusVersion]error
+}
+
+func newEvaluation(tx *transaction, utxos []UTxO, slots SlotConfig) (*evaluation, error) {
+	outputs := make(map[string]output, len(utxos))
+	for i, utxo := range utxos {
+		out, err := decodeOutput(utxo.Output)
+		if err != nil {
+			return nil, fmt.Errorf("utxo %d: %w", i, err)
+		}
+		outputs[outRefKey(utxo.Input)] = out
+	}
+	var unknown []scriptcontext.TxOutRef
+	resolve := func(refs []scriptcontext.TxOutRef) []resolvedInput {
+		resolved := make([]resolvedInput, 0, len(refs))
+		for _, ref := range refs {
+			out, ok := outputs[outRefKey(ref)]
+			if !ok {
+				unknown = append(unknown, ref)
+				continue
+			}
+			resolved = append(resolved, resolvedInput{ref: ref, out: out})
+		}
+		return resolved
+	}
+
+	e := &evaluation{
+		tx:        tx,
+		slots:     slots,
+		scripts:   make(map[string]*script),
+		infoError: make(map[builtin.PlutusVersion]error),
+	}
+	e.inputs = resolve(tx.inputs)
+	e.referenceInputs = resolve(tx.referenceInputs)
+	if len(unknown) > 0 {
+		return nil, &UnknownInputsError{Inputs: unknown}
+	}
+
+	for _, s := range tx.scripts {
+		e.scripts[string(s.hash())] = s
+	}
+	for _, inputs := range [][]resolvedInput{e.inputs, e.referenceInputs} {
+		for _, in := range inputs {
+			if s := in.out.referenceScript; s != nil {
+				e.scripts[string(s.hash())] = s
+			}
+		}
+	}
+	return e, nil
+}
+
+func outRefKey(ref scriptcontext.TxOutRef) string {
+	return fmt.Sprintf("%x#%d", ref.TxID, ref.Index)
+}
+
+// validRange converts the slot validity interval to POSIX milliseconds: the
+// lower bound is inclusive and the time-to-live is exclusive.
+func (e *evaluation) validRange() scriptcontext.Interval {
+	interval := scriptcontext.Always()
+	if e.tx.validityStart != nil {
+		interval.Lower = scriptcontext.IntervalBound{
+			Kind:   scriptcontext.Finite,
+			Time:   e.slots.SlotToTime(*e.tx.validityStart),
+			Closed: true,
+		}
+	}
+	if e.tx.ttl != nil {
+		interval.Upper = scriptcontext.IntervalBound{
+			Kind: scriptcontext.Finite,
+			Time: e.slots.SlotToTime(*e.tx.ttl),
+		}
+	}
+	return interval
+}
+
+// conwayFieldsV1 rejects governance fields that V1 and V2 contexts cannot
+// represent.
+func (e *evaluation) conwayFieldsV1() error {
+	switch {
+	case len(e.tx.voters) > 0:
+		return errors.New("voting procedures are not supported")
+	case len(e.tx.proposals) > 0:
+		return errors.New("proposal procedures are not supported")
+	case e.tx.currentTreasury != nil:
+		return errors.New("current treasury value is not supported")
+	case e.tx.donation != nil:
+		return errors.New("treasury donation is not supported")
+	}
+	return nil
+}
+
+func (e *evaluation) certificatesV1() ([]scriptcontext.DCert, error) {
+	certs := make([]scriptcontext.DCert, 0, len(e.tx.certificates))
+	for i, cert := range e.tx.certificates {
+		if cert.v1 == nil {
+			return nil, fmt.Errorf("certificate %d (kind %d) is not supported", i, cert.kind)
+		}
+		certs = append(certs, cert.v1)
+	}
+	return certs, nil
+}
+
+func (e *evaluation) withdrawalsV1() []scriptcontext.WithdrawalV1 {
+	withdrawals := make([]scriptcontext.WithdrawalV1, 0, len(e.tx.withdrawals))
+	for _, w := range e.tx.withdrawals {
+		withdrawals = append(withdrawals, scriptcontext.WithdrawalV1{
+			Credential: scriptcontext.StakingHash{Credential: w.Credential},
+			Amount:     w.Amount,
+		})
+	}
+	return withdrawals
+}
+
+// mintV1 adds the zero-ada entry that V1 and V2 contexts carry in Mint.
+func (e *evaluation) mintV1() scriptcontext.Value {
+	return append(scriptcontext.Lovelace(new(big.Int)), e.tx.mint...)
+}
+
+func outputV1(out output) (scriptcontext.TxOutV1, error) {
+	if out.byron {
+		return scriptcontext.TxOutV1{}, errByronAddress
+	}
+	if out.referenceScript != nil {
+		return scriptcontext.TxOutV1{}, errors.New("reference scripts are not supported")
+	}
+	result := scriptcontext.TxOutV1{Address: out.address, Value: out.value}
+	switch datum := out.datum.(type) {
+	case scriptcontext.OutputDatumHash:
+		result.DatumHash = datum.Hash
+	case scriptcontext.InlineDatum:
+		return scriptcontext.TxOutV1{}, errors.New("inline datums are not supported")
+	}
+	return result, nil
+}
+
+func outputV2(out output) (scriptcontext.TxOut, error) {
+	if out.byron {
+		return scriptcontext.TxOut{}, errByronAddress
+	}
+	result := scriptcontext.TxOut{
+		Address: out.address,
+		Value:   out.value,
+		Datum:   out.datum,
+	}
+	if out.referenceScript != nil {
+		result.ReferenceScript = out.referenceScript.hash()
+	}
+	return result, nil
+}
+
+func inputsV2(inputs []resolvedInput) ([]scriptcontext.TxInInfo, error) {
+	result := make([]scriptcontext.TxInInfo, 0, len(inputs))
+	for _, in := range inputs {
+		out, err := outputV2(in.out)
+		if err != nil {
+			return nil, fmt.Errorf("input %x#%d: %w", in.ref.TxID, in.ref.Index, err)
+		}
+		result = append(result, scriptcontext.TxInInfo{OutRef: in.ref, Resolved: out})
+	}
+	return result, nil
+}
+
+func outputsV2(outputs []output) ([]scriptcontext.TxOut, error) {
+	result := make([]scriptcontext.TxOut, 0, len(outputs))
+	for i, o := range outputs {
+		out, err := outputV2(o)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
+
+func (e *evaluation) buildInfoV1() (*scriptcontext.TxInfoV1, error) {
+	if err := e.conwayFieldsV1(); err != nil {
+		return nil, err
+	}
+	if len(e.referenceInputs) > 0 {
+		return nil, errors.New("reference inputs are not supported")
+	}
+	info := &scriptcontext.TxInfoV1{
+		Fee:         scriptcontext.Lovelace(e.tx.fee),
+		Mint:        e.mintV1(),
+		Withdrawals: e.withdrawalsV1(),
+		ValidRange:  e.validRange(),
+		Signatories: e.tx.signatories,
+		Data:        e.tx.datums,
+		ID:          e.tx.id,
+	}
+	for _, in := range e.inputs {
+		out, err := outputV1(in.out)
+		if err != nil {
+			return nil, fmt.Errorf("input %x#%d: %w", in.ref.TxID, in.ref.Index, err)
+		}
+		info.Inputs = append(info.Inputs, scriptcontext.TxInInfoV1{OutRef: in.ref, Resolved: out})
+	}
+	for i, o := range e.tx.outputs {
+		out, err := outputV1(o)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		info.Outputs = append(info.Outputs, out)
+	}
+	var err error
+	if info.Certificates, err = e.certificatesV1(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (e *evaluation) buildInfoV2() (*scriptcontext.TxInfoV2, error) {
+	if err := e.conwayFieldsV1(); err != nil {
+		return nil, err
+	}
+	info := &scriptcontext.TxInfoV2{
+		Fee:         scriptcontext.Lovelace(e.tx.fee),
+		Mint:        e.mintV1(),
+		Withdrawals: e.withdrawalsV1(),
+		ValidRange:  e.validRange(),
+		Signatories: e.tx.signatories,
+		Data:        e.tx.datums,
+		ID:          e.tx.id,
+	}
+	var err error
+	if info.Inputs, err = inputsV2(e.inputs); err != nil {
+		return nil, err
+	}
+	if info.ReferenceInputs, err = inputsV2(e.referenceInputs); err != nil {
+		return nil, err
+	}
+	if info.Outputs, err = outputsV2(e.tx.outputs); err != nil {
+		return nil, err
+	}
+	if info.Certificates, err = e.certificatesV1(); err != nil {
+		return nil, err
+	}
+	for _, r := range e.tx.redeemers {
+		purpose, err := e.purposeV1(r.pointer)
+		if err != nil {
+			return nil, err
+		}
+		info.Redeemers = append(info.Redeemers, scriptcontext.RedeemerEntryV1{
+			Purpose:  purpose,
+			Redeemer: r.data,
+		})
+	}
+	return info, nil
+}
+
+func (e *evaluation) buildInfoV3() (*scriptcontext.TxInfoV3, error) {
+	info := &scriptcontext.TxInfoV3{
+		Fee:                   e.tx.fee,
+		Mint:                  e.tx.mint,
+		Withdrawals:           e.tx.withdrawals,
+		ValidRange:            e.validRange(),
+		Signatories:           e.tx.signatories,
+		Data:                  e.tx.datums,
+		ID:                    e.tx.id,
+		ProposalProcedures:    e.tx.proposals,
+		CurrentTreasuryAmount: e.tx.currentTreasury,
+		TreasuryDonation:      e.tx.donation,
+	}
+	var err error
+	if info.Inputs, err = inputsV2(e.inputs); err != nil {
+		return nil, err
+	}
+	if info.ReferenceInputs, err = inputsV2(e.referenceInputs); err != nil {
+		return nil, err
+	}
+	if info.Outputs, err = outputsV2(e.tx.outputs); err != nil {
+		return nil, err
+	}
+	for i, cert := range e.tx.certificates {
+		if cert.v3 == nil {
+			return nil, fmt.Errorf("certificate %d (kind %d) is not supported", i, cert.kind)
+		}
+		info.Certificates = append(info.Certificates, cert.v3)
+	}
+	for _, v := range e.tx.voters {
+		info.Votes = append(info.Votes, v.votes)
+	}
+	for _, r := range e.tx.redeemers {
+		purpose, err := e.purposeV3(r.pointer)
+		if err != nil {
+			return nil, err
+		}
+		info.Redeemers = append(info.Redeemers, scriptcontext.RedeemerEntry{
+			Purpose:  purpose,
+			Redeemer: r.data,
+		})
+	}
+	return info, nil
+}
+
+// context builds the script context argument of a redeemer. datum is the
+// datum of the spent output, if any.
+func (e *evaluation) context(
+	language builtin.PlutusVersion,
+	r redeemer,
+	datum data.PlutusData,
+) (data.PlutusData, error) {
+	if err := e.buildInfo(language); err != nil {
+		return nil, err
+	}
+	switch language {
+	case builtin.PlutusV1, builtin.PlutusV2:
+		purpose, err := e.purposeV1(r.pointer)
+		if err != nil {
+			return nil, err
+		}
+		if language == builtin.PlutusV1 {
+			return scriptcontext.ScriptContextV1{TxInfo: *e.infoV1, Purpose: purpose}.ToData(), nil
+		}
+		return scriptcontext.ScriptContextV2{TxInfo: *e.infoV2, Purpose: purpose}.ToData(), nil
+	default:
+		info, err := e.scriptInfo(r.pointer, datum)
+		if err != nil {
+			return nil, err
+		}
+		return scriptcontext.ScriptContextV3{
+			TxInfo:   *e.infoV3,
+			Redeemer: r.data,
+			Info:     info,
+		}.ToData(), nil
+	}
+}
+
+// buildInfo builds the transaction info of a language once, remembering the
+// error if the transaction cannot be represented in that language.
+func (e *evaluation) buildInfo(language builtin.PlutusVersion) error {
+	if err, ok := e.infoError[language]; ok {
+		return err
+	}
+	var err error
+	switch language {
+	case builtin.PlutusV1:
+		e.infoV1, err = e.buildInfoV1()
+	case builtin.PlutusV2:
+		e.infoV2, err = e.buildInfoV2()
+	case builtin.PlutusV3:
+		e.infoV3, err = e.buildInfoV3()
+	default:
+		err = fmt.Errorf("no script context for Plutus version %d", language)
+	}
+	if err != nil {
+		err = fmt.Errorf("build PlutusV%d context: %w", language, err)
+	}
+	e.infoError[language] = err
+	return err
+}
+
+// target returns the input, policy, certificate, withdrawal, voter or
+// proposal that a redeemer pointer points at.
+func (e *evaluation) target(p RedeemerPointer) (any, error) {
+	var n int
+	switch p.Tag {
+	case Spend:
+		n = len(e.inputs)
+	case Mint:
+		n = len(e.tx.mint)
+	case Cert:
+		n = len(e.tx.certificates)
+	case Reward:
+		n = len(e.tx.withdrawals)
+	case Vote:
+		n = len(e.tx.voters)
+	case Propose:
+		n = len(e.tx.proposals)
+	default:
+		return nil, fmt.Errorf("unknown redeemer tag %d", p.Tag)
+	}
+	if p.Index >= uint64(n) {
+		return nil, fmt.Errorf("redeemer %s points past the %d entries", p, n)
+	}
+	switch p.Tag {
+	case Spend:
+		return e.inputs[p.Index], nil
+	case Mint:
+		return e.tx.mint[p.Index].Policy, nil
+	case Cert:
+		return e.tx.certificates[p.Index], nil
+	case Reward:
+		return e.tx.withdrawals[p.Index], nil
+	case Vote:
+		return e.tx.voters[p.Index], nil
+	default:
+		return e.tx.proposals[p.Index], nil
+	}
+}
+
+// scriptHash returns the hash of the script that must run for a redeemer.
+func (e *evaluation) scriptHash(p RedeemerPointer) ([]byte, error) {
+	target, err := e.target(p)
+	if err != nil {
+		return nil, err
+	}
+	var credential *scriptcontext.Credential
+	switch t := target.(type) {
+	case resolvedInput:
+		if !t.out.byron {
+			credential = &t.out.address.Credential
+		}
+	case []byte:
+		return t, nil
+	case certificate:
+		credential = t.credential
+	case scriptcontext.Withdrawal:
+		credential = &t.Credential
+	case voter:
+		credential = t.credential
+	case scriptcontext.ProposalProcedure:
+		switch action := t.Action.(type) {
+		case scriptcontext.ParameterChange:
+			if action.GuardrailScript != nil {
+				return action.GuardrailScript, nil
+			}
+		case scriptcontext.TreasuryWithdrawals:
+			if action.GuardrailScript != nil {
+				return action.GuardrailScript, nil
+			}
+		}
+		return nil, fmt.Errorf("redeemer %s does not point at a guardrail script", p)
+	}
+	if credential == nil || credential.Type != scriptcontext.ScriptCredential {
+		return nil, fmt.Errorf("redeemer %s does not point at a script", p)
+	}
+	return credential.Hash, nil
+}
+
+func (e *evaluation) purposeV1(p RedeemerPointer) (scriptcontext.ScriptPurposeV1, error) {
+	target, err := e.target(p)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case resolvedInput:
+		return scriptcontext.SpendingV1{OutRef: t.ref}, nil
+	case []byte:
+		return scriptcontext.Minting{Policy: t}, nil
+	case certificate:
+		if t.v1 == nil {
+			return nil, fmt.Errorf("certificate kind %d is not supported", t.kind)
+		}
+		return scriptcontext.CertifyingV1{Cert: t.v1}, nil
+	case scriptcontext.Withdrawal:
+		return scriptcontext.RewardingV1{
+			Credential: scriptcontext.StakingHash{Credential: t.Credential},
+		}, nil
+	default:
+		return nil, fmt.Errorf("redeemer %s has no PlutusV1/V2 script purpose", p)
+	}
+}
+
+func (e *evaluation) purposeV3(p RedeemerPointer) (scriptcontext.ScriptPurpose, error) {
+	target, err := e.target(p)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case resolvedInput:
+		return scriptcontext.Spending{OutRef: t.ref}, nil
+	case []byte:
+		return scriptcontext.Minting{Policy: t}, nil
+	case certificate:
+		if t.v3 == nil {
+			return nil, fmt.Errorf("certificate kind %d is not supported", t.kind)
+		}
+		return scriptcontext.Certifying{Index: p.Index, Cert: t.v3}, nil
+	case scriptcontext.Withdrawal:
+		return scriptcontext.Rewarding{Credential: t.Credential}, nil
+	case voter:
+		return scriptcontext.Voting{Voter: t.votes.Voter}, nil
+	case scriptcontext.ProposalProcedure:
+		return scriptcontext.Proposing{Index: p.Index, Proposal: t}, nil
+	default:
+		return nil, fmt.Errorf("redeemer %s has no PlutusV3 script purpose", p)
+	}
+}
+
+func (e *evaluation) scriptInfo(p RedeemerPointer, datum data.PlutusData) (scriptcontext.ScriptInfo, error) {
+	purpose, err := e.purposeV3(p)
+	if err != nil {
+		return nil, err
+	}
+	switch purpose := purpose.(type) {
+	case scriptcontext.Spending:
+		return scriptcontext.SpendingScript{OutRef: purpose.OutRef, Datum: datum}, nil
+	case scriptcontext.Minting:
+		return scriptcontext.MintingScript{Policy: purpose.Policy}, nil
+	case scriptcontext.Certifying:
+		return scriptcontext.CertifyingScript{Index: purpose.Index, Cert: purpose.Cert}, nil
+	case scriptcontext.Rewarding:
+		return scriptcontext.RewardingScript{Credential: purpose.Credential}, nil
+	case scriptcontext.Voting:
+		return scriptcontext.VotingScript{Voter: purpose.Voter}, nil
+	default:
+		proposing := purpose.(scriptcontext.Proposing)
+		return scriptcontext.ProposingScript{Index: proposing.Index, Proposal: proposing.Proposal}, nil
+	}
+}
+
+// spentDatum returns the datum of the output spent by in: an inline datum or
+// the witnessed datum matching its hash. It returns nil when the output has
+// no datum.
+func (e *evaluation) spentDatum(in resolvedInput) (data.PlutusData, error) {
+	switch datum := in.out.datum.(type) {
+	case scriptcontext.InlineDatum:
+		return datum.Datum, nil
+	case scriptcontext.OutputDatumHash:
+		for _, entry := range e.tx.datums {
+			if bytes.Equal(entry.Hash, datum.Hash) {
+				return entry.Datum, nil
+			}
+		}
+		return nil, fmt.Errorf("missing datum %x for input %x#%d", datum.Hash, in.ref.TxID, in.ref.Index)
+	default:
+		return nil, nil
+	}
+}
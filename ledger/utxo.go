@@ -0,0 +1,447 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+)
+
+// LoadUTxOFile reads resolved UTxOs from a JSON file; see [LoadUTxOs] for
+// the accepted formats.
+func LoadUTxOFile(path string) ([]UTxO, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open UTxOs: %w", err)
+	}
+	defer f.Close()
+	return LoadUTxOs(f)
+}
+
+// LoadUTxOs reads resolved UTxOs from JSON in either of these formats:
+//
+//   - a JSON array of Ogmios v6 UTxOs, bare or as the result of a
+//     queryLedgerState/utxo JSON-RPC response
+//   - the output of cardano-cli query utxo --output-json, an object keyed by
+//     "txid#index", which is also the --utxo-file of cardano-cli
+//     calculate-plutus-script-cost
+//
+// cardano-cli inline datums are read from inlineDatumRaw, their CBOR, rather
+// than from the JSON form of inlineDatum.
+func LoadUTxOs(r io.Reader) ([]UTxO, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read UTxOs: %w", err)
+	}
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("decode UTxOs: %w", err)
+		}
+		if fields["jsonrpc"] == nil {
+			return decodeCardanoCLIUTxOs(fields)
+		}
+		trimmed = fields["result"]
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, fmt.Errorf("decode UTxOs: %w", err)
+	}
+	utxos := make([]UTxO, 0, len(items))
+	for i, item := range items {
+		utxo, err := DecodeOgmiosUTxO(item)
+		if err != nil {
+			return nil, fmt.Errorf("UTxO %d: %w", i, err)
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
+// ogmiosUTxO is a UTxO in the Ogmios v6 JSON format, as returned by
+// queryLedgerState/utxo and accepted in additionalUtxo.
+type ogmiosUTxO struct {
+	Transaction struct {
+		ID string `json:"id"`
+	} `json:"transaction"`
+	Index     *uint64                           `json:"index"`
+	Address   string                            `json:"address"`
+	Value     map[string]map[string]json.Number `json:"value"`
+	DatumHash string                            `json:"datumHash"`
+	Datum     string                            `json:"datum"`
+	Script    *ogmiosScript                     `json:"script"`
+}
+
+type ogmiosScript struct {
+	Language string `json:"language"`
+	CBOR     string `json:"cbor"`
+}
+
+// DecodeOgmiosUTxO converts a UTxO in the Ogmios v6 JSON format to the
+// output CBOR that EvaluateTransaction reads.
+func DecodeOgmiosUTxO(raw json.RawMessage) (UTxO, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var u ogmiosUTxO
+	if err := decoder.Decode(&u); err != nil {
+		return UTxO{}, err
+	}
+	input, err := parseOutRef(u.Transaction.ID, 0)
+	if err != nil {
+		return UTxO{}, err
+	}
+	if u.Index == nil {
+		return UTxO{}, errors.New("missing output index")
+	}
+	input.Index = *u.Index
+	assets := make(map[string]map[string]json.Number, len(u.Value))
+	for policy, tokens := range u.Value {
+		if policy != "ada" {
+			assets[policy] = tokens
+		}
+	}
+	out, err := encodeOutput(outputJSON{
+		address:   u.Address,
+		coin:      u.Value["ada"]["lovelace"],
+		assets:    assets,
+		datum:     u.Datum,
+		datumHash: u.DatumHash,
+		script:    u.Script,
+	})
+	if err != nil {
+		return UTxO{}, err
+	}
+	return UTxO{Input: input, Output: out}, nil
+}
+
+// cardanoCLIUTxO is an output of cardano-cli query utxo --output-json.
+type cardanoCLIUTxO struct {
+	Address         string                     `json:"address"`
+	Value           map[string]json.RawMessage `json:"value"`
+	DatumHash       string                     `json:"datumhash"`
+	InlineDatum     json.RawMessage            `json:"inlineDatum"`
+	InlineDatumRaw  string                     `json:"inlineDatumRaw"`
+	ReferenceScript *struct {
+		Script struct {
+			Type    string `json:"type"`
+			CBORHex string `json:"cborHex"`
+		} `json:"script"`
+	} `json:"referenceScript"`
+}
+
+// cardanoCLIScriptLanguages maps text envelope script types to Ogmios
+// script languages.
+var cardanoCLIScriptLanguages = map[string]string{
+	"SimpleScript":   "native",
+	"SimpleScriptV1": "native",
+	"SimpleScriptV2": "native",
+	"PlutusScriptV1": "plutus:v1",
+	"PlutusScriptV2": "plutus:v2",
+	"PlutusScriptV3": "plutus:v3",
+}
+
+func decodeCardanoCLIUTxOs(entries map[string]json.RawMessage) ([]UTxO, error) {
+	utxos := make([]UTxO, 0, len(entries))
+	for key, raw := range entries {
+		utxo, err := decodeCardanoCLIUTxO(key, raw)
+		if err != nil {
+			return nil, fmt.Errorf("UTxO %s: %w", key, err)
+		}
+		utxos = append(utxos, utxo)
+	}
+	// Objects have no order, so keep loads of the same file identical.
+	slices.SortFunc(utxos, func(a, b UTxO) int {
+		return compareOutRefs(a.Input, b.Input)
+	})
+	return utxos, nil
+}
+
+func decodeCardanoCLIUTxO(key string, raw json.RawMessage) (UTxO, error) {
+	txID, indexText, ok := strings.Cut(key, "#")
+	if !ok {
+		return UTxO{}, errors.New(`key is not "txid#index"`)
+	}
+	index, err := strconv.ParseUint(indexText, 10, 64)
+	if err != nil {
+		return UTxO{}, fmt.Errorf("invalid output index %q", indexText)
+	}
+	input, err := parseOutRef(txID, index)
+	if err != nil {
+		return UTxO{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var u cardanoCLIUTxO
+	if err := decoder.Decode(&u); err != nil {
+		return UTxO{}, err
+	}
+	output := outputJSON{address: u.Address, assets: map[string]map[string]json.Number{}}
+	for policy, tokens := range u.Value {
+		if policy == "lovelace" {
+			if err := json.Unmarshal(tokens, &output.coin); err != nil {
+				return UTxO{}, fmt.Errorf("invalid lovelace: %w", err)
+			}
+			continue
+		}
+		named := map[string]json.Number{}
+		if err := json.Unmarshal(tokens, &named); err != nil {
+			return UTxO{}, fmt.Errorf("invalid assets of %s: %w", policy, err)
+		}
+		output.assets[policy] = named
+	}
+	switch {
+	case u.InlineDatumRaw != "":
+		output.datum = u.InlineDatumRaw
+	case len(u.InlineDatum) > 0 && !bytes.Equal(u.InlineDatum, []byte("null")):
+		return UTxO{}, errors.New("inline datum without inlineDatumRaw")
+	case u.DatumHash != "":
+		output.datumHash = u.DatumHash
+	}
+	if ref := u.ReferenceScript; ref != nil {
+		language, ok := cardanoCLIScriptLanguages[ref.Script.Type]
+		if !ok {
+			return UTxO{}, fmt.Errorf("unknown reference script type %q", ref.Script.Type)
+		}
+		output.script = &ogmiosScript{Language: language, CBOR: ref.Script.CBORHex}
+	}
+	out, err := encodeOutput(output)
+	if err != nil {
+		return UTxO{}, err
+	}
+	return UTxO{Input: input, Output: out}, nil
+}
+
+func parseOutRef(txID string, index uint64) (scriptcontext.TxOutRef, error) {
+	id, err := hex.DecodeString(txID)
+	if err != nil || len(id) != hash32 {
+		return scriptcontext.TxOutRef{}, fmt.Errorf("invalid transaction id %q", txID)
+	}
+	return scriptcontext.TxOutRef{TxID: id, Index: index}, nil
+}
+
+// outputJSON holds the fields that the UTxO JSON formats share. Datums and
+// scripts are hex.
+type outputJSON struct {
+	address   string
+	coin      json.Number
+	assets    map[string]map[string]json.Number
+	datum     string
+	datumHash string
+	script    *ogmiosScript
+}
+
+// encodeOutput encodes a post-Alonzo map output.
+func encodeOutput(o outputJSON) ([]byte, error) {
+	out := map[uint64]any{}
+	var err error
+	if out[0], err = parseAddress(o.address); err != nil {
+		return nil, err
+	}
+	if out[1], err = encodeValue(o.coin, o.assets); err != nil {
+		return nil, err
+	}
+	switch {
+	case o.datum != "":
+		datum, err := hex.DecodeString(o.datum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datum: %w", err)
+		}
+		out[2] = []any{1, cbor.Tag{Number: 24, Content: datum}}
+	case o.datumHash != "":
+		hash, err := hex.DecodeString(o.datumHash)
+		if err != nil || len(hash) != hash32 {
+			return nil, fmt.Errorf("invalid datum hash %q", o.datumHash)
+		}
+		out[2] = []any{0, hash}
+	}
+	if o.script != nil {
+		ref, err := encodeScriptRef(o.script.Language, o.script.CBOR)
+		if err != nil {
+			return nil, err
+		}
+		out[3] = cbor.Tag{Number: 24, Content: ref}
+	}
+	b, err := cbor.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("encode output: %w", err)
+	}
+	return b, nil
+}
+
+// encodeValue encodes a coin, or a coin and the assets of each policy.
+func encodeValue(coin json.Number, assets map[string]map[string]json.Number) (any, error) {
+	lovelace, err := parseQuantity(coin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lovelace: %w", err)
+	}
+	encoded := map[cbor.ByteString]map[cbor.ByteString]uint64{}
+	for policy, tokens := range assets {
+		id, err := hex.DecodeString(policy)
+		if err != nil || len(id) != hash28 {
+			return nil, fmt.Errorf("invalid policy id %q", policy)
+		}
+		named := map[cbor.ByteString]uint64{}
+		for name, quantity := range tokens {
+			assetName, err := hex.DecodeString(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asset name %q", name)
+			}
+			if named[cbor.ByteString(assetName)], err = parseQuantity(quantity); err != nil {
+				return nil, fmt.Errorf("invalid quantity of %s.%s: %w", policy, name, err)
+			}
+		}
+		encoded[cbor.ByteString(id)] = named
+	}
+	if len(encoded) == 0 {
+		return lovelace, nil
+	}
+	return []any{lovelace, encoded}, nil
+}
+
+func parseQuantity(n json.Number) (uint64, error) {
+	return strconv.ParseUint(n.String(), 10, 64)
+}
+
+// encodeScriptRef encodes [kind, script]. Plutus scripts are given as the
+// CBOR byte string of the witness set and native scripts as their CBOR.
+func encodeScriptRef(language, scriptHex string) ([]byte, error) {
+	kind, ok := map[string]int{
+		"native":    0,
+		"plutus:v1": 1,
+		"plutus:v2": 2,
+		"plutus:v3": 3,
+	}[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown script language %q", language)
+	}
+	script, err := hex.DecodeString(scriptHex)
+	if err != nil || len(script) == 0 {
+		return nil, fmt.Errorf("invalid %s script CBOR", language)
+	}
+	if kind > 0 && script[0]>>5 != 2 {
+		return nil, fmt.Errorf("%s script CBOR is not a byte string", language)
+	}
+	return cbor.Marshal([]any{kind, cbor.RawMessage(script)})
+}
+
+// parseAddress decodes a Shelley bech32 or a Byron base58 address.
+func parseAddress(address string) ([]byte, error) {
+	if strings.HasPrefix(address, "addr") {
+		hrp, b, err := decodeBech32(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		if hrp != "addr" && hrp != "addr_test" {
+			return nil, fmt.Errorf("invalid address %q: unexpected prefix %q", address, hrp)
+		}
+		return b, nil
+	}
+	b, err := decodeBase58(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return b, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	ret := make([]byte, 0, 2*len(hrp)+1)
+	for i := range len(hrp) {
+		ret = append(ret, hrp[i]>>5)
+	}
+	ret = append(ret, 0)
+	for i := range len(hrp) {
+		ret = append(ret, hrp[i]&31)
+	}
+	return ret
+}
+
+// decodeBech32 decodes a bech32 string without BIP-173's length limit, which
+// Cardano addresses exceed.
+func decodeBech32(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("missing separator or checksum")
+	}
+	hrp := s[:sep]
+	values := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", s[i])
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+	// Regroup the 5-bit values into bytes.
+	var ret []byte
+	acc, bits := uint32(0), 0
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			ret = append(ret, byte(acc>>bits))
+			acc &= 1<<bits - 1
+		}
+	}
+	if bits >= 5 || acc != 0 {
+		return "", nil, errors.New("invalid padding")
+	}
+	return hrp, ret, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty address")
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		digit := strings.IndexRune(base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,175 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// encodeBech32 is the inverse of decodeBech32.
+func encodeBech32(hrp string, b []byte) string {
+	var values []byte
+	acc, bits := uint32(0), 0
+	for _, c := range b {
+		acc = acc<<8 | uint32(c)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	polymod := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := range 6 {
+		values = append(values, byte(polymod>>(5*(5-i)))&31)
+	}
+	var sb strings.Builder
+	sb.WriteString(hrp + "1")
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String()
+}
+
+func TestDecodeBech32(t *testing.T) {
+	// BIP-173 test vectors.
+	hrp, b, err := decodeBech32("A12UEL5L")
+	if err != nil || hrp != "a" || len(b) != 0 {
+		t.Errorf("decodeBech32(A12UEL5L) = %q, %x, %v", hrp, b, err)
+	}
+	for _, bad := range []string{"a12uel5m", "A12uEL5L", "pzry9x0s0muk", "1qzzfhee"} {
+		if _, _, err := decodeBech32(bad); err == nil {
+			t.Errorf("decodeBech32(%q) succeeded", bad)
+		}
+	}
+	payload := append([]byte{0x61}, bytes.Repeat([]byte{0xab}, 28)...)
+	hrp, b, err = decodeBech32(encodeBech32("addr_test", payload))
+	if err != nil || hrp != "addr_test" || !bytes.Equal(b, payload) {
+		t.Errorf("round trip = %q, %x, %v", hrp, b, err)
+	}
+}
+
+func TestDecodeBase58(t *testing.T) {
+	b, err := decodeBase58("11StV1DL6CwTryKyV")
+	if err != nil || string(b) != "\x00\x00hello world" {
+		t.Errorf("decodeBase58() = %q, %v", b, err)
+	}
+	if _, err := decodeBase58("0OIl"); err == nil {
+		t.Error("decodeBase58() accepted characters outside the alphabet")
+	}
+}
+
+// jsonOutput is the output CBOR that both UTxO JSON formats are expected
+// to produce.
+type jsonOutput struct {
+	Address []byte            `cbor:"0,keyasint"`
+	Value   []cbor.RawMessage `cbor:"1,keyasint"`
+	Datum   []cbor.RawMessage `cbor:"2,keyasint"`
+	Script  cbor.Tag          `cbor:"3,keyasint"`
+}
+
+func checkJSONOutput(t *testing.T, utxo UTxO) {
+	t.Helper()
+	if utxo.Input.Index != 2 || !bytes.Equal(utxo.Input.TxID, hash(0x01, 32)) {
+		t.Errorf("input = %x#%d", utxo.Input.TxID, utxo.Input.Index)
+	}
+	var out jsonOutput
+	if err := cbor.Unmarshal(utxo.Output, &out); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if len(out.Address) != 29 || len(out.Value) != 2 || len(out.Datum) != 2 || out.Script.Number != 24 {
+		t.Errorf("output = %+v", out)
+	}
+	want, _ := hex.DecodeString("82024e4d01000033222220051200120011")
+	if !bytes.Equal(out.Script.Content.([]byte), want) {
+		t.Errorf("script reference = %x, want %x", out.Script.Content, want)
+	}
+	if _, err := decodeOutput(utxo.Output); err != nil {
+		t.Errorf("decodeOutput() failed: %v", err)
+	}
+}
+
+const testScriptCBOR = "4e4d01000033222220051200120011"
+
+func ogmiosUTxOJSON() string {
+	return `{
+		"transaction": {"id": "` + hex.EncodeToString(hash(0x01, 32)) + `"},
+		"index": 2,
+		"address": "` + encodeBech32("addr", append([]byte{0x61}, hash(0x03, 28)...)) + `",
+		"value": {"ada": {"lovelace": 5000000}, "` + hex.EncodeToString(hash(0x02, 28)) + `": {"74": 7}},
+		"datum": "d87980",
+		"script": {"language": "plutus:v2", "cbor": "` + testScriptCBOR + `"}
+	}`
+}
+
+func TestDecodeOgmiosUTxO(t *testing.T) {
+	utxo, err := DecodeOgmiosUTxO([]byte(ogmiosUTxOJSON()))
+	if err != nil {
+		t.Fatalf("DecodeOgmiosUTxO() failed: %v", err)
+	}
+	checkJSONOutput(t, utxo)
+
+	for _, bad := range []string{
+		`{"transaction": {"id": "00"}, "index": 0}`,
+		`{"transaction": {"id": "` + hex.EncodeToString(make([]byte, 32)) + `"}}`,
+		`{"transaction": {"id": "` + hex.EncodeToString(make([]byte, 32)) + `"}, "index": 0, "address": "addr1x"}`,
+	} {
+		if _, err := DecodeOgmiosUTxO([]byte(bad)); err == nil {
+			t.Errorf("DecodeOgmiosUTxO(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestLoadUTxOs(t *testing.T) {
+	address := encodeBech32("addr", append([]byte{0x61}, hash(0x03, 28)...))
+	cardanoCLI := func(datum string) string {
+		return `{"` + hex.EncodeToString(hash(0x01, 32)) + `#2": {
+			"address": "` + address + `",
+			"value": {"lovelace": 5000000, "` + hex.EncodeToString(hash(0x02, 28)) + `": {"74": 7}},
+			"datum": null,
+			` + datum + `,
+			"referenceScript": {
+				"script": {"cborHex": "` + testScriptCBOR + `", "description": "", "type": "PlutusScriptV2"},
+				"scriptLanguage": "PlutusScriptLanguage PlutusScriptV2"
+			}
+		}}`
+	}
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		json  string
+		error string
+	}{
+		{"ogmios", "[" + ogmiosUTxOJSON() + "]", ""},
+		{"ogmios response", `{"jsonrpc": "2.0", "method": "queryLedgerState/utxo", "result": [` + ogmiosUTxOJSON() + `]}`, ""},
+		{"cardano-cli", cardanoCLI(`"inlineDatum": {"constructor": 0, "fields": []}, "inlineDatumRaw": "d87980"`), ""},
+		{"cardano-cli without raw datum", cardanoCLI(`"inlineDatum": {"constructor": 0, "fields": []}`), "inline datum without inlineDatumRaw"},
+		{"cardano-cli bad key", `{"00": {}}`, `key is not "txid#index"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			utxos, err := LoadUTxOFile(path)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Errorf("LoadUTxOFile() error = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil || len(utxos) != 1 {
+				t.Fatalf("LoadUTxOFile() = %d UTxOs, %v", len(utxos), err)
+			}
+			checkJSONOutput(t, utxos[0])
+		})
+	}
+}
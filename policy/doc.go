@@ -0,0 +1,28 @@
+// Package policy is the table of which Plutus rules apply at which protocol
+// version.
+//
+// Three versions meet in script evaluation: the ledger language of a script
+// ([builtin.PlutusVersion]), the UPLC version in its program header and the
+// protocol major version of the chain. Each hard fork in the table records
+// what it changed:
+//
+//   - the ledger languages it introduced ([Languages], [LanguageAllowed])
+//   - the program versions each language accepts ([ProgramVersions])
+//   - the semantics variant of each language ([Semantics])
+//   - the builtins each language may call ([BuiltinAvailable], [Builtins])
+//
+// For example, at protocol version 10 (Plomin) PlutusV1, V2 and V3 are
+// allowed, PlutusV1 accepts only UPLC 1.0.0 programs and uses semantics
+// variant B, and it cannot call the BLS12-381 builtins, which van Rossem
+// (protocol version 11) opens to every language:
+//
+//	policy.LanguageAllowed(builtin.PlutusV3, 10) // true
+//	policy.ProgramVersions(builtin.PlutusV1, 10) // [[1 0 0]]
+//	policy.Semantics(builtin.PlutusV1, 10)       // B
+//	policy.BuiltinAvailable(builtin.Bls12_381_G1_Add, builtin.PlutusV1, 11) // true
+//
+// The cek package takes its semantics variants and builtin availability from
+// this package, so a future hard fork only needs a new entry in the table,
+// and any builtins it releases a change to
+// [builtin.DefaultFunction.IsAvailableInWithProto].
+package policy
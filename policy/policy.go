@@ -0,0 +1,213 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/lang"
+)
+
+// SemanticsVariant selects the evaluation rules and builtin cost functions
+// that changed at hard forks without a new ledger language.
+type SemanticsVariant int
+
+const (
+	SemanticsVariantA SemanticsVariant = 1 // V1 and V2 before Chang
+	SemanticsVariantB SemanticsVariant = 2 // V1 and V2 from Chang
+	SemanticsVariantC SemanticsVariant = 3 // V3 and later before van Rossem
+	SemanticsVariantD SemanticsVariant = 4 // V1 and V2 from van Rossem
+	SemanticsVariantE SemanticsVariant = 5 // V3 and later from van Rossem
+)
+
+// String returns the variant's letter.
+func (v SemanticsVariant) String() string {
+	if v >= SemanticsVariantA && v <= SemanticsVariantE {
+		return string(rune('A' + v - SemanticsVariantA))
+	}
+	return fmt.Sprintf("SemanticsVariant(%d)", int(v))
+}
+
+// UPLC program versions, as declared in program headers.
+var (
+	ProgramVersion100 = lang.LanguageVersion{1, 0, 0}
+	ProgramVersion110 = lang.LanguageVersion{1, 1, 0}
+)
+
+// HardFork records how a hard fork changed the rules for scripts. Each field
+// only lists what changed at the fork; everything else carries over from the
+// forks before it.
+type HardFork struct {
+	Name       string
+	ProtoMajor uint
+	// Languages are the ledger languages that scripts may use from this fork.
+	Languages []builtin.PlutusVersion
+	// ProgramVersions sets the UPLC program versions that each ledger
+	// language accepts from this fork.
+	ProgramVersions map[builtin.PlutusVersion][]lang.LanguageVersion
+	// Semantics sets the semantics variant of each ledger language from
+	// this fork.
+	Semantics map[builtin.PlutusVersion]SemanticsVariant
+}
+
+// hardForks is the policy table, in protocol version order. A future hard
+// fork only needs a new entry here; the builtins it releases are gated in
+// the builtin package.
+var hardForks = []HardFork{
+	{
+		Name:            "Alonzo",
+		ProtoMajor:      5,
+		Languages:       []builtin.PlutusVersion{builtin.PlutusV1},
+		ProgramVersions: map[builtin.PlutusVersion][]lang.LanguageVersion{builtin.PlutusV1: {ProgramVersion100}},
+		Semantics:       map[builtin.PlutusVersion]SemanticsVariant{builtin.PlutusV1: SemanticsVariantA},
+	},
+	{
+		Name:            "Vasil",
+		ProtoMajor:      7,
+		Languages:       []builtin.PlutusVersion{builtin.PlutusV2},
+		ProgramVersions: map[builtin.PlutusVersion][]lang.LanguageVersion{builtin.PlutusV2: {ProgramVersion100}},
+		Semantics:       map[builtin.PlutusVersion]SemanticsVariant{builtin.PlutusV2: SemanticsVariantA},
+	},
+	{
+		Name:       "Chang",
+		ProtoMajor: 9,
+		Languages:  []builtin.PlutusVersion{builtin.PlutusV3},
+		ProgramVersions: map[builtin.PlutusVersion][]lang.LanguageVersion{
+			builtin.PlutusV3: {ProgramVersion100, ProgramVersion110},
+			builtin.PlutusV4: {ProgramVersion100, ProgramVersion110},
+		},
+		Semantics: map[builtin.PlutusVersion]SemanticsVariant{
+			builtin.PlutusV1: SemanticsVariantB,
+			builtin.PlutusV2: SemanticsVariantB,
+			builtin.PlutusV3: SemanticsVariantC,
+			builtin.PlutusV4: SemanticsVariantC,
+		},
+	},
+	{
+		Name:       "van Rossem",
+		ProtoMajor: builtin.VanRossemProtoVersion,
+		ProgramVersions: map[builtin.PlutusVersion][]lang.LanguageVersion{
+			builtin.PlutusV1: {ProgramVersion100, ProgramVersion110},
+			builtin.PlutusV2: {ProgramVersion100, ProgramVersion110},
+		},
+		Semantics: map[builtin.PlutusVersion]SemanticsVariant{
+			builtin.PlutusV1: SemanticsVariantD,
+			builtin.PlutusV2: SemanticsVariantD,
+			builtin.PlutusV3: SemanticsVariantE,
+			builtin.PlutusV4: SemanticsVariantE,
+		},
+	},
+}
+
+// HardForks returns a copy of the policy table in protocol version order.
+// The copy shares nothing with the table, so changing it does not change the
+// policy.
+func HardForks() []HardFork {
+	forks := make([]HardFork, len(hardForks))
+	for i, fork := range hardForks {
+		fork.Languages = slices.Clone(fork.Languages)
+		fork.ProgramVersions = maps.Clone(fork.ProgramVersions)
+		for language, versions := range fork.ProgramVersions {
+			fork.ProgramVersions[language] = slices.Clone(versions)
+		}
+		fork.Semantics = maps.Clone(fork.Semantics)
+		forks[i] = fork
+	}
+	return forks
+}
+
+// Languages returns the ledger languages allowed at a protocol major version.
+func Languages(protoMajor uint) []builtin.PlutusVersion {
+	var ret []builtin.PlutusVersion
+	for _, fork := range hardForks {
+		if fork.ProtoMajor <= protoMajor {
+			ret = append(ret, fork.Languages...)
+		}
+	}
+	return ret
+}
+
+// LanguageAllowed reports whether scripts of a ledger language may run at a
+// protocol major version.
+func LanguageAllowed(language builtin.PlutusVersion, protoMajor uint) bool {
+	return slices.Contains(Languages(protoMajor), language)
+}
+
+// ProgramVersions returns the UPLC program versions that a ledger language
+// accepts at a protocol major version. Before the language's introduction it
+// returns the versions of its introduction.
+func ProgramVersions(language builtin.PlutusVersion, protoMajor uint) []lang.LanguageVersion {
+	var ret []lang.LanguageVersion
+	for _, fork := range hardForks {
+		versions, ok := fork.ProgramVersions[language]
+		if !ok {
+			continue
+		}
+		if ret != nil && fork.ProtoMajor > protoMajor {
+			break
+		}
+		ret = versions
+	}
+	return slices.Clone(ret)
+}
+
+// Semantics returns the semantics variant of a ledger language at a protocol
+// major version. Before the language's introduction it returns the variant
+// of its introduction, which is how cek evaluates scripts without a protocol
+// version. Languages outside the table follow PlutusV3.
+func Semantics(language builtin.PlutusVersion, protoMajor uint) SemanticsVariant {
+	var ret SemanticsVariant
+	found := false
+	for _, fork := range hardForks {
+		variant, ok := fork.Semantics[language]
+		if !ok {
+			continue
+		}
+		if found && fork.ProtoMajor > protoMajor {
+			break
+		}
+		ret, found = variant, true
+	}
+	if !found && language != builtin.PlutusV3 {
+		return Semantics(builtin.PlutusV3, protoMajor)
+	}
+	return ret
+}
+
+// BuiltinAvailable reports whether scripts of a ledger language may call a
+// builtin at a protocol major version. Protocol major 0 stands for the
+// language's original rules. Builtin availability is kept with the builtins,
+// in [builtin.DefaultFunction.IsAvailableInWithProto].
+func BuiltinAvailable(fn builtin.DefaultFunction, language builtin.PlutusVersion, protoMajor uint) bool {
+	return fn.IsAvailableInWithProto(language, protoMajor)
+}
+
+// Builtins returns the builtins available to a ledger language at a
+// protocol major version.
+func Builtins(language builtin.PlutusVersion, protoMajor uint) []builtin.DefaultFunction {
+	var ret []builtin.DefaultFunction
+	for fn := range builtin.DefaultFunction(builtin.TotalBuiltinCount) {
+		if BuiltinAvailable(fn, language, protoMajor) {
+			ret = append(ret, fn)
+		}
+	}
+	return ret
+}
+
+// LanguageVersion returns the version that identifies a ledger language to
+// cek machines and cost models.
+func LanguageVersion(language builtin.PlutusVersion) (lang.LanguageVersion, error) {
+	switch language {
+	case builtin.PlutusV1:
+		return lang.LanguageVersionV1, nil
+	case builtin.PlutusV2:
+		return lang.LanguageVersionV2, nil
+	case builtin.PlutusV3:
+		return lang.LanguageVersionV3, nil
+	case builtin.PlutusV4:
+		return lang.LanguageVersionV4, nil
+	default:
+		return lang.LanguageVersion{}, fmt.Errorf("unsupported Plutus version %d", language)
+	}
+}
@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/lang"
+)
+
+var languages = []builtin.PlutusVersion{
+	builtin.PlutusV1,
+	builtin.PlutusV2,
+	builtin.PlutusV3,
+	builtin.PlutusV4,
+}
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		protoMajor uint
+		want       []builtin.PlutusVersion
+	}{
+		{4, nil},
+		{5, []builtin.PlutusVersion{builtin.PlutusV1}},
+		{7, []builtin.PlutusVersion{builtin.PlutusV1, builtin.PlutusV2}},
+		{9, []builtin.PlutusVersion{builtin.PlutusV1, builtin.PlutusV2, builtin.PlutusV3}},
+		{11, []builtin.PlutusVersion{builtin.PlutusV1, builtin.PlutusV2, builtin.PlutusV3}},
+	}
+	for _, test := range tests {
+		if got := Languages(test.protoMajor); !slices.Equal(got, test.want) {
+			t.Errorf("Languages(%d) = %v, want %v", test.protoMajor, got, test.want)
+		}
+	}
+	if LanguageAllowed(builtin.PlutusV3, 8) || !LanguageAllowed(builtin.PlutusV3, 9) {
+		t.Error("PlutusV3 is not allowed from protocol version 9 exactly")
+	}
+}
+
+func TestProgramVersions(t *testing.T) {
+	onlyV100 := []lang.LanguageVersion{ProgramVersion100}
+	both := []lang.LanguageVersion{ProgramVersion100, ProgramVersion110}
+	tests := []struct {
+		language   builtin.PlutusVersion
+		protoMajor uint
+		want       []lang.LanguageVersion
+	}{
+		{builtin.PlutusV1, 0, onlyV100},
+		{builtin.PlutusV1, 10, onlyV100},
+		{builtin.PlutusV1, 11, both},
+		{builtin.PlutusV2, 10, onlyV100},
+		{builtin.PlutusV3, 0, both},
+		{builtin.PlutusV3, 9, both},
+		{builtin.PlutusV3, 11, both},
+		{builtin.PlutusVUnreleased, 11, nil},
+	}
+	for _, test := range tests {
+		if got := ProgramVersions(test.language, test.protoMajor); !slices.Equal(got, test.want) {
+			t.Errorf("ProgramVersions(V%d, %d) = %v, want %v", test.language, test.protoMajor, got, test.want)
+		}
+	}
+}
+
+// TestSemantics checks the table against the Chang and van Rossem thresholds
+// that cek used before the table existed.
+func TestSemantics(t *testing.T) {
+	for _, language := range append(slices.Clone(languages), 0) {
+		for protoMajor := range uint(13) {
+			var want SemanticsVariant
+			switch {
+			case language == builtin.PlutusV1 || language == builtin.PlutusV2:
+				switch {
+				case protoMajor < 9:
+					want = SemanticsVariantA
+				case protoMajor < 11:
+					want = SemanticsVariantB
+				default:
+					want = SemanticsVariantD
+				}
+			case protoMajor < 11:
+				want = SemanticsVariantC
+			default:
+				want = SemanticsVariantE
+			}
+			if got := Semantics(language, protoMajor); got != want {
+				t.Errorf("Semantics(V%d, %d) = %v, want %v", language, protoMajor, got, want)
+			}
+		}
+	}
+}
+
+func TestBuiltinAvailable(t *testing.T) {
+	tests := []struct {
+		fn         builtin.DefaultFunction
+		language   builtin.PlutusVersion
+		protoMajor uint
+		want       bool
+	}{
+		{builtin.AddInteger, builtin.PlutusV1, 5, true},
+		{builtin.SerialiseData, builtin.PlutusV1, 10, false},
+		{builtin.SerialiseData, builtin.PlutusV2, 10, true},
+		{builtin.Bls12_381_G1_Add, builtin.PlutusV1, 10, false},
+		{builtin.Bls12_381_G1_Add, builtin.PlutusV1, 11, true},
+		{builtin.DropList, builtin.PlutusV3, 10, false},
+		{builtin.DropList, builtin.PlutusV1, 11, true},
+	}
+	for _, tt := range tests {
+		if got := BuiltinAvailable(tt.fn, tt.language, tt.protoMajor); got != tt.want {
+			t.Errorf("BuiltinAvailable(%s, V%d, %d) = %v, want %v", tt.fn, tt.language, tt.protoMajor, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	v1 := Builtins(builtin.PlutusV1, 10)
+	if slices.Contains(v1, builtin.SerialiseData) || !slices.Contains(v1, builtin.AddInteger) {
+		t.Errorf("PlutusV1 builtins at protocol version 10: %v", v1)
+	}
+	if !slices.Contains(Builtins(builtin.PlutusV1, 11), builtin.DropList) {
+		t.Error("dropList is not available to PlutusV1 at protocol version 11")
+	}
+}
+
+func TestHardForksCopy(t *testing.T) {
+	forks := HardForks()
+	for i := range forks {
+		for language := range forks[i].ProgramVersions {
+			forks[i].ProgramVersions[language][0] = lang.LanguageVersion{9, 9, 9}
+			forks[i].ProgramVersions[language] = nil
+		}
+		clear(forks[i].Semantics)
+		if len(forks[i].Languages) > 0 {
+			forks[i].Languages[0] = builtin.PlutusVUnreleased
+		}
+	}
+	versions := ProgramVersions(builtin.PlutusV1, 10)
+	versions[0] = lang.LanguageVersion{9, 9, 9}
+	if !LanguageAllowed(builtin.PlutusV1, 5) ||
+		!slices.Equal(ProgramVersions(builtin.PlutusV1, 10), []lang.LanguageVersion{ProgramVersion100}) ||
+		Semantics(builtin.PlutusV3, 11) != SemanticsVariantE {
+		t.Error("changing a copy of the table changed the policy")
+	}
+}
+
+func TestLanguageVersion(t *testing.T) {
+	want := []lang.LanguageVersion{
+		lang.LanguageVersionV1,
+		lang.LanguageVersionV2,
+		lang.LanguageVersionV3,
+		lang.LanguageVersionV4,
+	}
+	for i, language := range languages {
+		if got, err := LanguageVersion(language); err != nil || got != want[i] {
+			t.Errorf("LanguageVersion(V%d) = %v, %v", language, got, err)
+		}
+	}
+	if _, err := LanguageVersion(builtin.PlutusVUnreleased); err == nil {
+		t.Error("LanguageVersion() accepted an unreleased language")
+	}
+}
+
+func TestSemanticsVariantString(t *testing.T) {
+	if SemanticsVariantA.String() != "A" || SemanticsVariantE.String() != "E" ||
+		SemanticsVariant(9).String() != "SemanticsVariant(9)" {
+		t.Error("SemanticsVariant.String() is wrong")
+	}
+}
@@ -0,0 +1,322 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"time"
+)
+
+// BenchOptions configures Bench.
+type BenchOptions struct {
+	// Warmup is the number of unmeasured evaluations of each case before
+	// its repetitions, which let the machine's arenas reach their steady
+	// size.
+	Warmup int
+	// Repetitions is the number of measured evaluations of each case.
+	// Values below one mean one.
+	Repetitions int
+}
+
+type BenchReport struct {
+	SchemaVersion int              `json:"schema_version"`
+	Network       string           `json:"network"`
+	Reference     Reference        `json:"reference"`
+	Environment   BenchEnvironment `json:"environment"`
+	Warmup        int              `json:"warmup"`
+	Repetitions   int              `json:"repetitions"`
+	Cases         []BenchCase      `json:"cases"`
+	Summary       BenchSummary     `json:"summary"`
+	// Comparison is set by the caller against a baseline report.
+	Comparison *BenchComparison `json:"comparison,omitempty"`
+}
+
+// BenchEnvironment records where a benchmark ran, since its numbers only
+// compare with runs on the same hardware and toolchain.
+type BenchEnvironment struct {
+	GoVersion  string `json:"go_version"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+}
+
+// BenchCase holds the latency statistics of a case's repetitions and the
+// heap allocations of an average repetition.
+type BenchCase struct {
+	ID          string         `json:"id"`
+	Transaction TransactionRef `json:"transaction"`
+	Passed      bool           `json:"passed"`
+	Mismatches  []string       `json:"mismatches,omitempty"`
+	ExUnits     ExUnits        `json:"ex_units"`
+	MeanNS      int64          `json:"mean_ns"`
+	MedianNS    int64          `json:"median_ns"`
+	StddevNS    float64        `json:"stddev_ns"`
+	MinNS       int64          `json:"min_ns"`
+	MaxNS       int64          `json:"max_ns"`
+	AllocsPerOp uint64         `json:"allocs_per_op"`
+	BytesPerOp  uint64         `json:"bytes_per_op"`
+}
+
+// BenchSummary totals the cases: MeanNS is the mean time of one pass over
+// the corpus.
+type BenchSummary struct {
+	Total       int    `json:"total"`
+	Passed      int    `json:"passed"`
+	Failed      int    `json:"failed"`
+	MeanNS      int64  `json:"mean_ns"`
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+	BytesPerOp  uint64 `json:"bytes_per_op"`
+}
+
+// Bench evaluates each case of src opts.Warmup times and then measures
+// opts.Repetitions evaluations of it. Cases are read, validated and run one
+// at a time, so that the allocation counts, which are process-wide, belong
+// to the measured case and not to decoding the corpus.
+// Each case is checked against its expectations once, after its
+// repetitions.
+func Bench(ctx context.Context, src Source, opts BenchOptions) (*BenchReport, error) {
+	header := src.Header()
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Warmup < 0 {
+		return nil, errors.New("benchmark warm-up must not be negative")
+	}
+	opts.Repetitions = max(opts.Repetitions, 1)
+
+	var (
+		w     worker
+		cases []BenchCase
+	)
+	ids := make(map[string]struct{})
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("run replay corpus: %w", err)
+		}
+		replayCase, err := src.Next()
+		if errors.Is(err, errEmptyShard) {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			if i == 0 {
+				return nil, errors.New("replay corpus must contain at least one case")
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ids[replayCase.ID]; exists {
+			return nil, fmt.Errorf("replay case %d: duplicate id %q", i, replayCase.ID)
+		}
+		ids[replayCase.ID] = struct{}{}
+		decoded, err := replayCase.validate()
+		if err != nil {
+			return nil, fmt.Errorf("replay case %d: %w", i, err)
+		}
+		cases = append(cases, w.bench(replayCase, decoded, opts))
+	}
+
+	report := &BenchReport{
+		SchemaVersion: header.SchemaVersion,
+		Network:       header.Network,
+		Reference:     header.Reference,
+		Environment: BenchEnvironment{
+			GoVersion:  runtime.Version(),
+			GOOS:       runtime.GOOS,
+			GOARCH:     runtime.GOARCH,
+			NumCPU:     runtime.NumCPU(),
+			GOMAXPROCS: runtime.GOMAXPROCS(0),
+		},
+		Warmup:      opts.Warmup,
+		Repetitions: opts.Repetitions,
+		Cases:       cases,
+	}
+	for _, c := range cases {
+		report.Summary.Total++
+		if c.Passed {
+			report.Summary.Passed++
+		} else {
+			report.Summary.Failed++
+		}
+		report.Summary.MeanNS += c.MeanNS
+		report.Summary.AllocsPerOp += c.AllocsPerOp
+		report.Summary.BytesPerOp += c.BytesPerOp
+	}
+	return report, nil
+}
+
+func (w *worker) bench(
+	replayCase *Case,
+	decoded decodedCase,
+	opts BenchOptions,
+) BenchCase {
+	for range opts.Warmup {
+		w.evaluate(replayCase, decoded)
+	}
+
+	durations := make([]int64, opts.Repetitions)
+	var before, after runtime.MemStats
+	// Collect the garbage of earlier cases so that it is not swept during
+	// the measurements.
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := range durations {
+		start := time.Now()
+		w.evaluate(replayCase, decoded)
+		durations[i] = time.Since(start).Nanoseconds()
+	}
+	runtime.ReadMemStats(&after)
+	// One more, unmeasured evaluation gives the outcome with its result.
+	actual := w.actual(replayCase, decoded)
+
+	result := BenchCase{
+		ID:          replayCase.ID,
+		Transaction: replayCase.Transaction,
+		ExUnits:     actual.ExUnits,
+		MedianNS:    percentile(durations, 50),
+		MinNS:       durations[0],
+		MaxNS:       durations[0],
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(opts.Repetitions),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(opts.Repetitions),
+	}
+	var sum int64
+	for _, d := range durations {
+		sum += d
+		result.MinNS = min(result.MinNS, d)
+		result.MaxNS = max(result.MaxNS, d)
+	}
+	result.MeanNS = sum / int64(len(durations))
+	if len(durations) > 1 {
+		var squares float64
+		for _, d := range durations {
+			deviation := float64(d - result.MeanNS)
+			squares += deviation * deviation
+		}
+		result.StddevNS = math.Sqrt(squares / float64(len(durations)-1))
+	}
+	result.Mismatches = compare(replayCase.Expected, decoded, actual)
+	result.Passed = len(result.Mismatches) == 0
+	return result
+}
+
+// BenchComparison compares the mean latencies of the cases two benchmark
+// reports share, in the order of the new report.
+type BenchComparison struct {
+	Cases   []BenchCaseComparison `json:"cases"`
+	Added   []string              `json:"added,omitempty"`
+	Removed []string              `json:"removed,omitempty"`
+	// Mean compares the sums of the shared cases' means.
+	Mean DurationChange `json:"mean"`
+}
+
+type BenchCaseComparison struct {
+	ID             string         `json:"id"`
+	Mean           DurationChange `json:"mean"`
+	OldAllocsPerOp uint64         `json:"old_allocs_per_op"`
+	NewAllocsPerOp uint64         `json:"new_allocs_per_op"`
+}
+
+// Slower returns the IDs of the cases whose mean grew by more than
+// percent.
+func (c *BenchComparison) Slower(percent float64) []string {
+	var ids []string
+	for _, change := range c.Cases {
+		if change.Mean.ChangePercent > percent {
+			ids = append(ids, change.ID)
+		}
+	}
+	return ids
+}
+
+func LoadBenchReportFile(path string) (*BenchReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open benchmark report: %w", err)
+	}
+	defer f.Close()
+
+	report, err := LoadBenchReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("load benchmark report %q: %w", path, err)
+	}
+	return report, nil
+}
+
+func LoadBenchReport(r io.Reader) (*BenchReport, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var report BenchReport
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("decode benchmark report: %w", err)
+	}
+	if report.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf(
+			"unsupported benchmark report schema version %d (want %d)",
+			report.SchemaVersion,
+			SchemaVersion,
+		)
+	}
+	return &report, nil
+}
+
+// CompareBench matches the cases of two benchmark reports by ID and compares
+// their mean latencies and allocations.
+func CompareBench(oldReport, newReport *BenchReport) (*BenchComparison, error) {
+	if oldReport == nil || newReport == nil {
+		return nil, errors.New("two benchmark reports are required")
+	}
+	oldCases, err := benchCasesByID(oldReport)
+	if err != nil {
+		return nil, fmt.Errorf("old report: %w", err)
+	}
+	if _, err := benchCasesByID(newReport); err != nil {
+		return nil, fmt.Errorf("new report: %w", err)
+	}
+
+	comparison := &BenchComparison{Cases: []BenchCaseComparison{}}
+	matched := make(map[string]struct{}, len(newReport.Cases))
+	var oldTotal, newTotal int64
+	for _, newCase := range newReport.Cases {
+		oldCase, ok := oldCases[newCase.ID]
+		if !ok {
+			comparison.Added = append(comparison.Added, newCase.ID)
+			continue
+		}
+		matched[newCase.ID] = struct{}{}
+		oldTotal += oldCase.MeanNS
+		newTotal += newCase.MeanNS
+		comparison.Cases = append(comparison.Cases, BenchCaseComparison{
+			ID:             newCase.ID,
+			Mean:           durationChange(oldCase.MeanNS, newCase.MeanNS),
+			OldAllocsPerOp: oldCase.AllocsPerOp,
+			NewAllocsPerOp: newCase.AllocsPerOp,
+		})
+	}
+	for _, oldCase := range oldReport.Cases {
+		if _, ok := matched[oldCase.ID]; !ok {
+			comparison.Removed = append(comparison.Removed, oldCase.ID)
+		}
+	}
+	comparison.Mean = durationChange(oldTotal, newTotal)
+	return comparison, nil
+}
+
+func benchCasesByID(report *BenchReport) (map[string]*BenchCase, error) {
+	cases := make(map[string]*BenchCase, len(report.Cases))
+	for i := range report.Cases {
+		result := &report.Cases[i]
+		if _, exists := cases[result.ID]; exists {
+			return nil, fmt.Errorf("case %d: duplicate id %q", i, result.ID)
+		}
+		cases[result.ID] = result
+	}
+	return cases, nil
+}
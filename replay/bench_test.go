@@ -0,0 +1,136 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	passing := successfulCase(t)
+	passing.Expected.ExUnits = RunCase(&passing).Actual.ExUnits
+	failing := successfulCase(t)
+	failing.ID = "tx-1#spend:1"
+	corpus := validCorpus(passing)
+	corpus.Cases = append(corpus.Cases, failing)
+
+	report, err := Bench(
+		context.Background(),
+		NewSource(corpus),
+		BenchOptions{Warmup: 2, Repetitions: 5},
+	)
+	if err != nil {
+		t.Fatalf("Bench() failed: %v", err)
+	}
+	if report.Warmup != 2 || report.Repetitions != 5 || len(report.Cases) != 2 ||
+		report.Environment.GoVersion == "" || report.Environment.NumCPU < 1 {
+		t.Fatalf("Bench() report = %+v", report)
+	}
+	first := report.Cases[0]
+	if !first.Passed || first.ExUnits != passing.Expected.ExUnits {
+		t.Errorf("passing case = %+v", first)
+	}
+	if first.MinNS <= 0 || first.MinNS > first.MedianNS || first.MedianNS > first.MaxNS ||
+		first.MeanNS < first.MinNS || first.MeanNS > first.MaxNS || first.StddevNS < 0 {
+		t.Errorf("passing case statistics = %+v", first)
+	}
+	if second := report.Cases[1]; second.Passed || len(second.Mismatches) == 0 {
+		t.Errorf("failing case = %+v", second)
+	}
+	summary := report.Summary
+	if summary.Total != 2 || summary.Passed != 1 || summary.Failed != 1 ||
+		summary.MeanNS != first.MeanNS+report.Cases[1].MeanNS {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	single, err := Bench(context.Background(), NewSource(validCorpus(passing)), BenchOptions{})
+	if err != nil {
+		t.Fatalf("Bench() without repetitions failed: %v", err)
+	}
+	if single.Repetitions != 1 || single.Cases[0].StddevNS != 0 {
+		t.Errorf("Bench() without repetitions = %+v", single)
+	}
+
+	duplicate := validCorpus(passing)
+	duplicate.Cases = append(duplicate.Cases, passing)
+	_, err = Bench(context.Background(), NewSource(duplicate), BenchOptions{})
+	if err == nil || !strings.Contains(err.Error(), "replay case 1: duplicate id") {
+		t.Errorf("Bench() duplicate case error = %v", err)
+	}
+
+	_, err = Bench(context.Background(), NewSource(corpus), BenchOptions{Warmup: -1})
+	if err == nil || !strings.Contains(err.Error(), "warm-up must not be negative") {
+		t.Errorf("Bench() negative warm-up error = %v", err)
+	}
+}
+
+func benchReport(cases ...BenchCase) *BenchReport {
+	return &BenchReport{SchemaVersion: SchemaVersion, Cases: cases}
+}
+
+func TestCompareBench(t *testing.T) {
+	oldReport := benchReport(
+		BenchCase{ID: "same", MeanNS: 100, AllocsPerOp: 5},
+		BenchCase{ID: "slower", MeanNS: 100, AllocsPerOp: 5},
+		BenchCase{ID: "removed", MeanNS: 100},
+	)
+	newReport := benchReport(
+		BenchCase{ID: "added", MeanNS: 100},
+		BenchCase{ID: "slower", MeanNS: 150, AllocsPerOp: 7},
+		BenchCase{ID: "same", MeanNS: 100, AllocsPerOp: 5},
+	)
+	comparison, err := CompareBench(oldReport, newReport)
+	if err != nil {
+		t.Fatalf("CompareBench() failed: %v", err)
+	}
+	if len(comparison.Cases) != 2 || comparison.Cases[0].ID != "slower" ||
+		comparison.Cases[0].Mean.ChangePercent != 50 ||
+		comparison.Cases[0].NewAllocsPerOp != 7 {
+		t.Errorf("CompareBench() cases = %+v", comparison.Cases)
+	}
+	if !slices.Equal(comparison.Added, []string{"added"}) ||
+		!slices.Equal(comparison.Removed, []string{"removed"}) {
+		t.Errorf("CompareBench() added %v, removed %v", comparison.Added, comparison.Removed)
+	}
+	if comparison.Mean != (DurationChange{OldNS: 200, NewNS: 250, ChangePercent: 25}) {
+		t.Errorf("CompareBench() mean = %+v", comparison.Mean)
+	}
+	if slower := comparison.Slower(20); !slices.Equal(slower, []string{"slower"}) {
+		t.Errorf("Slower(20) = %v", slower)
+	}
+	if slower := comparison.Slower(50); len(slower) != 0 {
+		t.Errorf("Slower(50) = %v", slower)
+	}
+
+	duplicate := benchReport(BenchCase{ID: "a"}, BenchCase{ID: "a"})
+	if _, err := CompareBench(duplicate, newReport); err == nil ||
+		!strings.HasPrefix(err.Error(), "old report:") {
+		t.Errorf("CompareBench() duplicate error = %v", err)
+	}
+}
+
+func TestLoadBenchReport(t *testing.T) {
+	report := benchReport(BenchCase{ID: "a", MeanNS: 10})
+	report.Comparison = &BenchComparison{Cases: []BenchCaseComparison{}}
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("encode benchmark report: %v", err)
+	}
+	loaded, err := LoadBenchReport(bytes.NewReader(encoded))
+	if err != nil || len(loaded.Cases) != 1 || loaded.Cases[0].MeanNS != 10 {
+		t.Fatalf("LoadBenchReport() = %+v, %v", loaded, err)
+	}
+
+	report.SchemaVersion = SchemaVersion + 1
+	encoded, err = json.Marshal(report)
+	if err != nil {
+		t.Fatalf("encode benchmark report: %v", err)
+	}
+	if _, err := LoadBenchReport(bytes.NewReader(encoded)); err == nil ||
+		!strings.Contains(err.Error(), "unsupported benchmark report schema version") {
+		t.Errorf("LoadBenchReport() error = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package replay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/ledger"
+)
+
+// Bundle is an offline transaction bundle: a transaction with the UTxOs it
+// spends and references and the protocol parameters it was evaluated with.
+type Bundle struct {
+	// Transaction is the transaction's CBOR.
+	Transaction []byte
+	UTxOs       []ledger.UTxO
+	Parameters  *ledger.Parameters
+	Slots       ledger.SlotConfig
+	// Slot and Block locate the transaction on chain and are optional.
+	Slot  uint64
+	Block string
+	// Costs are the reference execution units of the transaction's
+	// redeemers, in redeemer order. Without them, the cases expect
+	// plutigo's own outcome, which makes them regression rather than
+	// parity cases.
+	Costs []ScriptCost
+}
+
+// ScriptCost is an entry of the output of cardano-cli transaction
+// calculate-plutus-script-cost.
+type ScriptCost struct {
+	ScriptHash     string      `json:"scriptHash"`
+	ExecutionUnits ExUnits     `json:"executionUnits"`
+	LovelaceCost   json.Number `json:"lovelaceCost,omitempty"`
+}
+
+func LoadScriptCostsFile(path string) ([]ScriptCost, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open script costs: %w", err)
+	}
+	defer f.Close()
+
+	costs, err := LoadScriptCosts(f)
+	if err != nil {
+		return nil, fmt.Errorf("load script costs %q: %w", path, err)
+	}
+	return costs, nil
+}
+
+func LoadScriptCosts(r io.Reader) ([]ScriptCost, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var costs []ScriptCost
+	if err := decoder.Decode(&costs); err != nil {
+		return nil, fmt.Errorf("decode script costs: %w", err)
+	}
+	return costs, nil
+}
+
+// Collect builds a replay case for every redeemer of a bundle's
+// transaction: it resolves each redeemer's script and the arguments the
+// ledger applies it to, and records the bundle's cost model, protocol
+// version and maximum transaction execution units. A redeemer whose script
+// cannot be run, such as one with a missing datum, is an error, as is a
+// cost that does not match its redeemer's script.
+func Collect(bundle *Bundle) ([]Case, error) {
+	if bundle == nil || bundle.Parameters == nil {
+		return nil, errors.New("transaction bundle with protocol parameters is required")
+	}
+	params := bundle.Parameters
+	id, err := ledger.TransactionID(bundle.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	invocations, err := ledger.TransactionInvocations(
+		bundle.Transaction,
+		bundle.UTxOs,
+		params,
+		bundle.Slots,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Costs != nil && len(bundle.Costs) != len(invocations) {
+		return nil, fmt.Errorf(
+			"got %d script costs for %d redeemers",
+			len(bundle.Costs),
+			len(invocations),
+		)
+	}
+
+	txID := hex.EncodeToString(id)
+	reference := "plutigo"
+	if bundle.Costs != nil {
+		reference = "cardano-cli calculate-plutus-script-cost"
+	}
+	cases := make([]Case, 0, len(invocations))
+	for i, invocation := range invocations {
+		pointer := invocation.Redeemer.String()
+		if invocation.Err != nil {
+			return nil, fmt.Errorf("redeemer %s: %w", pointer, invocation.Err)
+		}
+		scriptHash := hex.EncodeToString(invocation.ScriptHash)
+		arguments := make([]string, 0, len(invocation.Arguments))
+		for j, argument := range invocation.Arguments {
+			encoded, err := data.Encode(argument)
+			if err != nil {
+				return nil, fmt.Errorf("redeemer %s: encode argument %d: %w", pointer, j, err)
+			}
+			arguments = append(arguments, hex.EncodeToString(encoded))
+		}
+		metadata, err := json.Marshal(map[string]string{
+			"collector":   "plutigo-replay collect",
+			"reference":   reference,
+			"script_hash": scriptHash,
+		})
+		if err != nil {
+			return nil, err
+		}
+		replayCase := Case{
+			ID: txID + "#" + pointer,
+			Transaction: TransactionRef{
+				ID:       txID,
+				Slot:     bundle.Slot,
+				Block:    bundle.Block,
+				Redeemer: pointer,
+			},
+			Language: Language("PlutusV" + strconv.Itoa(int(invocation.Language))),
+			ProtocolVersion: ProtocolVersion{
+				Major: params.ProtocolVersion.Major,
+				Minor: params.ProtocolVersion.Minor,
+			},
+			FlatProgramHex:   hex.EncodeToString(invocation.Program),
+			ArgumentsCBORHex: arguments,
+			BudgetLimit: ExUnits{
+				Steps:  params.MaxTxExUnits.Steps,
+				Memory: params.MaxTxExUnits.Memory,
+			},
+			AdditionalMetadata: metadata,
+		}
+		if model, ok := params.CostModels[invocation.Language]; ok {
+			replayCase.CostModel.Parameters = model
+		} else {
+			replayCase.CostModel.UseDefault = true
+		}
+
+		if bundle.Costs != nil {
+			cost := bundle.Costs[i]
+			if cost.ScriptHash != scriptHash {
+				return nil, fmt.Errorf(
+					"redeemer %s: script cost %d is for script %s, not %s",
+					pointer,
+					i,
+					cost.ScriptHash,
+					scriptHash,
+				)
+			}
+			// cardano-cli only calculates the costs of transactions whose
+			// scripts all succeed.
+			replayCase.Expected = Expected{Success: true, ExUnits: cost.ExecutionUnits}
+		} else {
+			actual := RunCase(&replayCase).Actual
+			if actual.SetupError {
+				return nil, fmt.Errorf("redeemer %s: %s", pointer, actual.Error)
+			}
+			replayCase.Expected = Expected{
+				Success:   actual.Success,
+				ExUnits:   actual.ExUnits,
+				ErrorCode: actual.ErrorCode,
+			}
+		}
+		if _, err := replayCase.validate(); err != nil {
+			return nil, fmt.Errorf("redeemer %s: %w", pointer, err)
+		}
+		cases = append(cases, replayCase)
+	}
+	return cases, nil
+}
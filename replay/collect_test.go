@@ -0,0 +1,145 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/scriptcontext"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// mintBundle returns a bundle whose transaction mints with an always
+// succeeding PlutusV3 policy, and the policy's hash.
+func mintBundle(t *testing.T) (*Bundle, string) {
+	t.Helper()
+	program, err := syn.Parse(`(program 1.1.0 (lam ctx (con unit ())))`)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	debruijn, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("NameToDeBruijn() failed: %v", err)
+	}
+	flat, err := syn.Encode(debruijn)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	marshal := func(v any) []byte {
+		b, err := cbor.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal() failed: %v", err)
+		}
+		return b
+	}
+	script := marshal(flat)
+	h, _ := blake2b.New(28, nil)
+	h.Write([]byte{3})
+	h.Write(script)
+	policy := h.Sum(nil)
+
+	input := scriptcontext.TxOutRef{TxID: bytes.Repeat([]byte{0x01}, 32), Index: 0}
+	body := map[uint64]any{
+		0: []any{[]any{input.TxID, input.Index}},
+		1: []any{},
+		2: 0,
+		9: map[cbor.ByteString]map[cbor.ByteString]int64{cbor.ByteString(policy): {"a": 1}},
+	}
+	witnesses := map[uint64]any{
+		5: []any{[]any{1, 0, 0, []any{0, 0}}},
+		7: []any{script},
+	}
+	address := append([]byte{0x61}, bytes.Repeat([]byte{0x03}, 28)...)
+	return &Bundle{
+		Transaction: marshal([]any{body, witnesses, true, nil}),
+		UTxOs:       []ledger.UTxO{{Input: input, Output: marshal([]any{address, 2_000_000})}},
+		Parameters: &ledger.Parameters{
+			ProtocolVersion: cek.ProtoVersion{Major: 10},
+			MaxTxExUnits:    ledger.ExUnits{Steps: 10_000_000_000, Memory: 14_000_000},
+		},
+		Slots: ledger.MainnetSlotConfig,
+		Slot:  42,
+	}, hex.EncodeToString(policy)
+}
+
+func TestCollect(t *testing.T) {
+	bundle, policy := mintBundle(t)
+	cases, err := Collect(bundle)
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("Collect() = %d cases, want 1", len(cases))
+	}
+	collected := cases[0]
+	if collected.Transaction.Redeemer != "mint:0" || collected.Transaction.Slot != 42 ||
+		collected.ID != collected.Transaction.ID+"#mint:0" || collected.Language != PlutusV3 ||
+		!collected.CostModel.UseDefault || len(collected.ArgumentsCBORHex) != 1 ||
+		!strings.Contains(string(collected.AdditionalMetadata), policy) {
+		t.Errorf("collected case = %+v", collected)
+	}
+	// Without reference costs, the case expects what plutigo does.
+	if !collected.Expected.Success || collected.Expected.ExUnits.Steps == 0 {
+		t.Errorf("expected = %+v", collected.Expected)
+	}
+	report, err := Run(context.Background(), &Corpus{
+		SchemaVersion: SchemaVersion,
+		Network:       "mainnet",
+		Reference:     Reference{Implementation: "plutigo", Version: "test"},
+		Cases:         cases,
+	})
+	if err != nil || report.Summary.Failed != 0 {
+		t.Fatalf("Run() = %+v, %v", report, err)
+	}
+
+	bundle.Costs = []ScriptCost{{ScriptHash: policy, ExecutionUnits: ExUnits{Steps: 100, Memory: 10}}}
+	cases, err = Collect(bundle)
+	if err != nil {
+		t.Fatalf("Collect() with costs failed: %v", err)
+	}
+	if cases[0].Expected.ExUnits != (ExUnits{Steps: 100, Memory: 10}) ||
+		!strings.Contains(string(cases[0].AdditionalMetadata), "calculate-plutus-script-cost") {
+		t.Errorf("collected case with costs = %+v", cases[0])
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*Bundle)
+		error string
+	}{
+		{"cost count", func(b *Bundle) { b.Costs = append(b.Costs, b.Costs[0]) }, "got 2 script costs for 1 redeemers"},
+		{"cost script", func(b *Bundle) { b.Costs[0].ScriptHash = "00" }, "is for script 00"},
+		{"unresolved input", func(b *Bundle) { b.UTxOs = nil }, "unknown transaction input"},
+		{"language not allowed", func(b *Bundle) { b.Costs = nil; b.Parameters.ProtocolVersion.Major = 8 }, "not allowed at protocol version 8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, policy := mintBundle(t)
+			bundle.Costs = []ScriptCost{{ScriptHash: policy, ExecutionUnits: ExUnits{Steps: 100, Memory: 10}}}
+			tt.edit(bundle)
+			if _, err := Collect(bundle); err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("Collect() error = %v, want %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestLoadScriptCosts(t *testing.T) {
+	costs, err := LoadScriptCosts(strings.NewReader(`[
+		{"executionUnits": {"memory": 468430, "steps": 112946394}, "lovelaceCost": 35746, "scriptHash": "2d8e"}
+	]`))
+	if err != nil || len(costs) != 1 || costs[0].ScriptHash != "2d8e" ||
+		costs[0].ExecutionUnits != (ExUnits{Steps: 112946394, Memory: 468430}) {
+		t.Errorf("LoadScriptCosts() = %+v, %v", costs, err)
+	}
+	if _, err := LoadScriptCosts(strings.NewReader(`{}`)); err == nil {
+		t.Error("LoadScriptCosts() accepted an object")
+	}
+}
@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CaseStatus describes how a case changed between two reports.
+type CaseStatus string
+
+const (
+	// StatusRegressed marks a case that passed before and fails now.
+	StatusRegressed CaseStatus = "regressed"
+	// StatusFixed marks a case that failed before and passes now.
+	StatusFixed CaseStatus = "fixed"
+	// StatusChanged marks a case whose status is unchanged but whose
+	// ExUnits differ.
+	StatusChanged CaseStatus = "changed"
+)
+
+// Comparison lists the differences between an old and a new report.
+type Comparison struct {
+	// Cases holds the cases present in both reports whose status or ExUnits
+	// changed, in the order of the new report.
+	Cases          []CaseComparison  `json:"cases"`
+	Added          []string          `json:"added,omitempty"`
+	Removed        []string          `json:"removed,omitempty"`
+	Regressed      int               `json:"regressed"`
+	Fixed          int               `json:"fixed"`
+	ExUnitsChanged int               `json:"ex_units_changed"`
+	Latency        LatencyComparison `json:"latency"`
+}
+
+type CaseComparison struct {
+	ID           string     `json:"id"`
+	Status       CaseStatus `json:"status"`
+	OldPassed    bool       `json:"old_passed"`
+	NewPassed    bool       `json:"new_passed"`
+	OldExUnits   ExUnits    `json:"old_ex_units"`
+	NewExUnits   ExUnits    `json:"new_ex_units"`
+	ExUnitsDelta ExUnits    `json:"ex_units_delta"`
+}
+
+type LatencyComparison struct {
+	Median DurationChange `json:"median"`
+	P95    DurationChange `json:"p95"`
+}
+
+// DurationChange compares a latency statistic. ChangePercent is zero when
+// the old value is zero.
+type DurationChange struct {
+	OldNS         int64   `json:"old_ns"`
+	NewNS         int64   `json:"new_ns"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+func LoadReportFile(path string) (*Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay report: %w", err)
+	}
+	defer f.Close()
+
+	report, err := LoadReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("load replay report %q: %w", path, err)
+	}
+	return report, nil
+}
+
+func LoadReport(r io.Reader) (*Report, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var report Report
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("decode replay report: %w", err)
+	}
+	if report.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf(
+			"unsupported replay report schema version %d (want %d)",
+			report.SchemaVersion,
+			SchemaVersion,
+		)
+	}
+	return &report, nil
+}
+
+// CompareReports matches the cases of two reports by ID and returns what
+// changed from oldReport to newReport.
+func CompareReports(oldReport, newReport *Report) (*Comparison, error) {
+	if oldReport == nil || newReport == nil {
+		return nil, errors.New("two replay reports are required")
+	}
+	oldCases, err := casesByID(oldReport)
+	if err != nil {
+		return nil, fmt.Errorf("old report: %w", err)
+	}
+	if _, err := casesByID(newReport); err != nil {
+		return nil, fmt.Errorf("new report: %w", err)
+	}
+
+	comparison := &Comparison{
+		Cases: []CaseComparison{},
+		Latency: LatencyComparison{
+			Median: durationChange(
+				oldReport.Summary.MedianDurationNS,
+				newReport.Summary.MedianDurationNS,
+			),
+			P95: durationChange(
+				oldReport.Summary.P95DurationNS,
+				newReport.Summary.P95DurationNS,
+			),
+		},
+	}
+	matched := make(map[string]struct{}, len(newReport.Cases))
+	for _, newCase := range newReport.Cases {
+		oldCase, ok := oldCases[newCase.ID]
+		if !ok {
+			comparison.Added = append(comparison.Added, newCase.ID)
+			continue
+		}
+		matched[newCase.ID] = struct{}{}
+
+		change := CaseComparison{
+			ID:         newCase.ID,
+			OldPassed:  oldCase.Passed,
+			NewPassed:  newCase.Passed,
+			OldExUnits: oldCase.Actual.ExUnits,
+			NewExUnits: newCase.Actual.ExUnits,
+			ExUnitsDelta: ExUnits{
+				Steps:  newCase.Actual.ExUnits.Steps - oldCase.Actual.ExUnits.Steps,
+				Memory: newCase.Actual.ExUnits.Memory - oldCase.Actual.ExUnits.Memory,
+			},
+		}
+		if change.ExUnitsDelta != (ExUnits{}) {
+			comparison.ExUnitsChanged++
+		}
+		switch {
+		case oldCase.Passed && !newCase.Passed:
+			change.Status = StatusRegressed
+			comparison.Regressed++
+		case !oldCase.Passed && newCase.Passed:
+			change.Status = StatusFixed
+			comparison.Fixed++
+		case change.ExUnitsDelta != (ExUnits{}):
+			change.Status = StatusChanged
+		default:
+			continue
+		}
+		comparison.Cases = append(comparison.Cases, change)
+	}
+	for _, oldCase := range oldReport.Cases {
+		if _, ok := matched[oldCase.ID]; !ok {
+			comparison.Removed = append(comparison.Removed, oldCase.ID)
+		}
+	}
+	return comparison, nil
+}
+
+func casesByID(report *Report) (map[string]*CaseResult, error) {
+	cases := make(map[string]*CaseResult, len(report.Cases))
+	for i := range report.Cases {
+		result := &report.Cases[i]
+		if _, exists := cases[result.ID]; exists {
+			return nil, fmt.Errorf("case %d: duplicate id %q", i, result.ID)
+		}
+		cases[result.ID] = result
+	}
+	return cases, nil
+}
+
+func durationChange(oldNS, newNS int64) DurationChange {
+	change := DurationChange{OldNS: oldNS, NewNS: newNS}
+	if oldNS != 0 {
+		change.ChangePercent = float64(newNS-oldNS) * 100 / float64(oldNS)
+	}
+	return change
+}
@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func comparedReport(p95 int64, cases ...CaseResult) *Report {
+	return &Report{
+		SchemaVersion: SchemaVersion,
+		Cases:         cases,
+		Summary: Summary{
+			MedianDurationNS: p95 / 2,
+			P95DurationNS:    p95,
+		},
+	}
+}
+
+func comparedCase(id string, passed bool, steps, memory int64) CaseResult {
+	return CaseResult{
+		ID:     id,
+		Passed: passed,
+		Actual: Actual{ExUnits: ExUnits{Steps: steps, Memory: memory}},
+	}
+}
+
+func TestCompareReports(t *testing.T) {
+	oldReport := comparedReport(
+		1000,
+		comparedCase("same", true, 10, 1),
+		comparedCase("regressed", true, 10, 1),
+		comparedCase("fixed", false, 10, 1),
+		comparedCase("cheaper", false, 10, 1),
+		comparedCase("removed", true, 10, 1),
+	)
+	newReport := comparedReport(
+		1250,
+		comparedCase("added", true, 10, 1),
+		comparedCase("cheaper", false, 7, 1),
+		comparedCase("fixed", true, 10, 1),
+		comparedCase("regressed", false, 12, 3),
+		comparedCase("same", true, 10, 1),
+	)
+
+	comparison, err := CompareReports(oldReport, newReport)
+	if err != nil {
+		t.Fatalf("CompareReports() failed: %v", err)
+	}
+	want := []CaseComparison{
+		{
+			ID:           "cheaper",
+			Status:       StatusChanged,
+			OldExUnits:   ExUnits{Steps: 10, Memory: 1},
+			NewExUnits:   ExUnits{Steps: 7, Memory: 1},
+			ExUnitsDelta: ExUnits{Steps: -3},
+		},
+		{
+			ID:         "fixed",
+			Status:     StatusFixed,
+			NewPassed:  true,
+			OldExUnits: ExUnits{Steps: 10, Memory: 1},
+			NewExUnits: ExUnits{Steps: 10, Memory: 1},
+		},
+		{
+			ID:           "regressed",
+			Status:       StatusRegressed,
+			OldPassed:    true,
+			OldExUnits:   ExUnits{Steps: 10, Memory: 1},
+			NewExUnits:   ExUnits{Steps: 12, Memory: 3},
+			ExUnitsDelta: ExUnits{Steps: 2, Memory: 2},
+		},
+	}
+	if !slices.Equal(comparison.Cases, want) {
+		t.Errorf("Cases = %+v, want %+v", comparison.Cases, want)
+	}
+	if !slices.Equal(comparison.Added, []string{"added"}) ||
+		!slices.Equal(comparison.Removed, []string{"removed"}) {
+		t.Errorf("Added = %v, Removed = %v", comparison.Added, comparison.Removed)
+	}
+	if comparison.Regressed != 1 || comparison.Fixed != 1 || comparison.ExUnitsChanged != 2 {
+		t.Errorf("counts = %d regressed, %d fixed, %d changed",
+			comparison.Regressed, comparison.Fixed, comparison.ExUnitsChanged)
+	}
+	if p95 := comparison.Latency.P95; p95.OldNS != 1000 || p95.NewNS != 1250 || p95.ChangePercent != 25 {
+		t.Errorf("P95 = %+v", p95)
+	}
+}
+
+func TestCompareReportsErrors(t *testing.T) {
+	valid := comparedReport(0, comparedCase("a", true, 1, 1))
+	duplicate := comparedReport(
+		0,
+		comparedCase("a", true, 1, 1),
+		comparedCase("a", true, 1, 1),
+	)
+	tests := []struct {
+		name     string
+		old, new *Report
+		want     string
+	}{
+		{"missing report", valid, nil, "two replay reports are required"},
+		{"duplicate old id", duplicate, valid, `old report: case 1: duplicate id "a"`},
+		{"duplicate new id", valid, duplicate, `new report: case 1: duplicate id "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompareReports(tt.old, tt.new)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("CompareReports() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReportRejectsSchemaVersion(t *testing.T) {
+	_, err := LoadReport(strings.NewReader(`{"schema_version": 2}`))
+	if err == nil || !strings.Contains(err.Error(), "schema version 2") {
+		t.Fatalf("LoadReport() error = %v, want schema version error", err)
+	}
+}
@@ -9,11 +9,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
 
+	"github.com/blinklabs-io/plutigo/builtin"
 	"github.com/blinklabs-io/plutigo/cek"
 	"github.com/blinklabs-io/plutigo/data"
 	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/policy"
 	"github.com/blinklabs-io/plutigo/syn"
 )
 
@@ -26,6 +29,44 @@ type Corpus struct {
 	Cases         []Case    `json:"cases"`
 }
 
+// Header is a corpus without its cases. It is the first record of a JSON
+// Lines corpus.
+type Header struct {
+	SchemaVersion int       `json:"schema_version"`
+	Network       string    `json:"network"`
+	Reference     Reference `json:"reference"`
+}
+
+func (c *Corpus) Header() Header {
+	return Header{
+		SchemaVersion: c.SchemaVersion,
+		Network:       c.Network,
+		Reference:     c.Reference,
+	}
+}
+
+func (h Header) validate() error {
+	if h.SchemaVersion != SchemaVersion {
+		return fmt.Errorf(
+			"unsupported replay corpus schema version %d (want %d)",
+			h.SchemaVersion,
+			SchemaVersion,
+		)
+	}
+	if strings.TrimSpace(h.Network) == "" {
+		return errors.New("replay corpus network is required")
+	}
+	if strings.TrimSpace(h.Reference.Implementation) == "" {
+		return errors.New(
+			"replay corpus reference implementation is required",
+		)
+	}
+	if strings.TrimSpace(h.Reference.Version) == "" {
+		return errors.New("replay corpus reference version is required")
+	}
+	return nil
+}
+
 type Reference struct {
 	Implementation string `json:"implementation"`
 	Version        string `json:"version"`
@@ -65,21 +106,31 @@ const (
 	PlutusV4 Language = "PlutusV4"
 )
 
-func (l Language) Version() (lang.LanguageVersion, error) {
+// PlutusVersion returns the ledger language named by l.
+func (l Language) PlutusVersion() (builtin.PlutusVersion, error) {
 	switch l {
 	case PlutusV1:
-		return lang.LanguageVersionV1, nil
+		return builtin.PlutusV1, nil
 	case PlutusV2:
-		return lang.LanguageVersionV2, nil
+		return builtin.PlutusV2, nil
 	case PlutusV3:
-		return lang.LanguageVersionV3, nil
+		return builtin.PlutusV3, nil
 	case PlutusV4:
-		return lang.LanguageVersionV4, nil
+		return builtin.PlutusV4, nil
 	default:
-		return lang.LanguageVersion{}, fmt.Errorf("unsupported Plutus language %q", l)
+		return 0, fmt.Errorf("unsupported Plutus language %q", l)
 	}
 }
 
+// Version returns the cek language version of l.
+func (l Language) Version() (lang.LanguageVersion, error) {
+	language, err := l.PlutusVersion()
+	if err != nil {
+		return lang.LanguageVersion{}, err
+	}
+	return policy.LanguageVersion(language)
+}
+
 type CostModel struct {
 	UseDefault bool    `json:"use_default,omitempty"`
 	Parameters []int64 `json:"parameters,omitempty"`
@@ -92,14 +143,20 @@ type ExUnits struct {
 }
 
 type Expected struct {
-	Success   bool           `json:"success"`
-	ExUnits   ExUnits        `json:"ex_units"`
-	ErrorCode *cek.ErrorCode `json:"error_code,omitempty"`
+	Success   bool            `json:"success"`
+	ExUnits   ExUnits         `json:"ex_units"`
+	ErrorCode *cek.ErrorCode  `json:"error_code,omitempty"`
+	Logs      *ExpectedLogs   `json:"logs,omitempty"`
+	Result    *ExpectedResult `json:"result,omitempty"`
 }
 
 type decodedCase struct {
 	program   *syn.Program[syn.DeBruijn]
 	arguments []data.PlutusData
+	// logsPattern is the compiled Expected.Logs pattern and result the
+	// pretty-printed Expected.Result, prepared once for every comparison.
+	logsPattern *regexp.Regexp
+	result      string
 }
 
 func LoadFile(path string) (*Corpus, error) {
@@ -147,23 +204,8 @@ func (c *Corpus) validate() error {
 	if c == nil {
 		return errors.New("replay corpus is required")
 	}
-	if c.SchemaVersion != SchemaVersion {
-		return fmt.Errorf(
-			"unsupported replay corpus schema version %d (want %d)",
-			c.SchemaVersion,
-			SchemaVersion,
-		)
-	}
-	if strings.TrimSpace(c.Network) == "" {
-		return errors.New("replay corpus network is required")
-	}
-	if strings.TrimSpace(c.Reference.Implementation) == "" {
-		return errors.New(
-			"replay corpus reference implementation is required",
-		)
-	}
-	if strings.TrimSpace(c.Reference.Version) == "" {
-		return errors.New("replay corpus reference version is required")
+	if err := c.Header().validate(); err != nil {
+		return err
 	}
 	if len(c.Cases) == 0 {
 		return errors.New("replay corpus must contain at least one case")
@@ -248,6 +290,23 @@ func (c *Case) validate() (decodedCase, error) {
 			"successful expected result cannot include an error code",
 		)
 	}
+	var logsPattern *regexp.Regexp
+	if c.Expected.Logs != nil {
+		if logsPattern, err = c.Expected.Logs.validate(); err != nil {
+			return decodedCase{}, err
+		}
+	}
+	var result string
+	if c.Expected.Result != nil {
+		if !c.Expected.Success {
+			return decodedCase{}, errors.New(
+				"failing expected result cannot include a result value",
+			)
+		}
+		if result, err = c.Expected.Result.pretty(); err != nil {
+			return decodedCase{}, err
+		}
+	}
 	if c.Expected.ExUnits.Steps > c.BudgetLimit.Steps ||
 		c.Expected.ExUnits.Memory > c.BudgetLimit.Memory {
 		return decodedCase{}, errors.New(
@@ -255,8 +314,10 @@ func (c *Case) validate() (decodedCase, error) {
 		)
 	}
 	return decodedCase{
-		program:   program,
-		arguments: arguments,
+		program:     program,
+		arguments:   arguments,
+		logsPattern: logsPattern,
+		result:      result,
 	}, nil
 }
 
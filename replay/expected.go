@@ -0,0 +1,133 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// ExpectedLogs is either the exact list of trace messages, encoded as a JSON
+// array, or a regular expression that the messages joined by newlines must
+// match in full, encoded as {"pattern": "..."}.
+type ExpectedLogs struct {
+	Exact   []string
+	Pattern string
+}
+
+func (l *ExpectedLogs) UnmarshalJSON(b []byte) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		exact := []string{}
+		if err := json.Unmarshal(trimmed, &exact); err != nil {
+			return err
+		}
+		*l = ExpectedLogs{Exact: exact}
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	var pattern struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := decoder.Decode(&pattern); err != nil {
+		return fmt.Errorf("logs must be a list or a pattern: %w", err)
+	}
+	// An empty pattern would encode as the empty list.
+	if pattern.Pattern == "" {
+		return errors.New("logs pattern must not be empty")
+	}
+	*l = ExpectedLogs{Pattern: pattern.Pattern}
+	return nil
+}
+
+func (l ExpectedLogs) MarshalJSON() ([]byte, error) {
+	if l.Pattern != "" {
+		return json.Marshal(map[string]string{"pattern": l.Pattern})
+	}
+	if l.Exact == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l.Exact)
+}
+
+// validate returns the compiled pattern, or nil for an exact list.
+func (l *ExpectedLogs) validate() (*regexp.Regexp, error) {
+	if l.Pattern == "" {
+		return nil, nil
+	}
+	if l.Exact != nil {
+		return nil, errors.New("expected logs cannot be both a list and a pattern")
+	}
+	// The pattern must match all of the logs, not just some substring.
+	pattern, err := regexp.Compile(`^(?:` + l.Pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("expected logs pattern: %w", err)
+	}
+	return pattern, nil
+}
+
+// mismatch compares logs with the expectation; pattern is the one validate
+// returned.
+func (l *ExpectedLogs) mismatch(pattern *regexp.Regexp, logs []string) string {
+	if l.Pattern != "" {
+		if pattern.MatchString(strings.Join(logs, "\n")) {
+			return ""
+		}
+		return fmt.Sprintf("logs: got %q, want a match for %q", logs, l.Pattern)
+	}
+	if slices.Equal(logs, l.Exact) {
+		return ""
+	}
+	return fmt.Sprintf("logs: got %q, want %q", logs, l.Exact)
+}
+
+// ExpectedResult is the value a successful evaluation discharges to, given
+// either as a UPLC term in the textual syntax, such as "(con unit ())", or as
+// the CBOR of a Data value, which stands for the term (con data ...). Results
+// are compared in their pretty-printed form, so the term's layout and
+// variable names do not matter.
+type ExpectedResult struct {
+	Term        string `json:"term,omitempty"`
+	DataCBORHex string `json:"data_cbor_hex,omitempty"`
+}
+
+// pretty returns the expected result as Actual.Result would report it.
+func (r *ExpectedResult) pretty() (string, error) {
+	if (r.Term == "") == (r.DataCBORHex == "") {
+		return "", errors.New(
+			"expected result must set exactly one of term or data_cbor_hex",
+		)
+	}
+	if r.DataCBORHex != "" {
+		encoded, err := decodeHex("expected result data CBOR", r.DataCBORHex)
+		if err != nil {
+			return "", err
+		}
+		decoded, err := data.Decode(encoded)
+		if err != nil {
+			return "", fmt.Errorf("decode expected result data: %w", err)
+		}
+		return prettyResult(&syn.Constant{Con: &syn.Data{Inner: decoded}}), nil
+	}
+
+	// The parser reads whole programs; 1.1.0 admits every term.
+	program, err := syn.Parse("(program 1.1.0 " + r.Term + ")")
+	if err != nil {
+		return "", fmt.Errorf("parse expected result term: %w", err)
+	}
+	converted, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		return "", fmt.Errorf("parse expected result term: %w", err)
+	}
+	return prettyResult(converted.Term), nil
+}
+
+func prettyResult(term syn.Term[syn.DeBruijn]) string {
+	return syn.PrettyTerm[syn.DeBruijn](term)
+}
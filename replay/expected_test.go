@@ -0,0 +1,192 @@
+package replay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+func TestExpectedLogsJSON(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    ExpectedLogs
+		err     string
+	}{
+		{`["a", "b"]`, ExpectedLogs{Exact: []string{"a", "b"}}, ""},
+		{`[]`, ExpectedLogs{Exact: []string{}}, ""},
+		{`{"pattern": "^a"}`, ExpectedLogs{Pattern: "^a"}, ""},
+		{`{"regexp": "^a"}`, ExpectedLogs{}, `unknown field "regexp"`},
+		{`{"pattern": ""}`, ExpectedLogs{}, "logs pattern must not be empty"},
+		{`{}`, ExpectedLogs{}, "logs pattern must not be empty"},
+		{`"a"`, ExpectedLogs{}, "logs must be a list or a pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoded, func(t *testing.T) {
+			var logs ExpectedLogs
+			err := json.Unmarshal([]byte(tt.encoded), &logs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Unmarshal() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+			if logs.Pattern != tt.want.Pattern ||
+				strings.Join(logs.Exact, ",") != strings.Join(tt.want.Exact, ",") ||
+				(logs.Exact == nil) != (tt.want.Exact == nil) {
+				t.Fatalf("Unmarshal() = %#v, want %#v", logs, tt.want)
+			}
+			encoded, err := json.Marshal(logs)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+			var again ExpectedLogs
+			if err := json.Unmarshal(encoded, &again); err != nil ||
+				again.Pattern != logs.Pattern ||
+				len(again.Exact) != len(logs.Exact) {
+				t.Errorf("round trip of %s = %#v, %v", encoded, again, err)
+			}
+		})
+	}
+}
+
+func tracingCase(t *testing.T) Case {
+	t.Helper()
+	program, err := syn.Parse(`(program 1.0.0
+		[(force (builtin trace)) (con string "first")
+			[(force (builtin trace)) (con string "second") (con integer 7)]])`)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	converted, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("NameToDeBruijn() failed: %v", err)
+	}
+	replayCase := baseCase(t, converted.Term, nil)
+	replayCase.Expected.Success = true
+	replayCase.Expected.ExUnits = RunCase(&replayCase).Actual.ExUnits
+	return replayCase
+}
+
+func TestRunCaseComparesLogsAndResult(t *testing.T) {
+	answer, err := data.Encode(data.NewInteger(big.NewInt(42)))
+	if err != nil {
+		t.Fatalf("data.Encode() failed: %v", err)
+	}
+	dataCase := successfulCase(t)
+	dataCase.Expected.ExUnits = RunCase(&dataCase).Actual.ExUnits
+
+	tests := []struct {
+		name     string
+		base     Case
+		expected func(*Expected)
+		mismatch string
+	}{
+		{"exact logs", tracingCase(t), func(e *Expected) {
+			e.Logs = &ExpectedLogs{Exact: []string{"second", "first"}}
+		}, ""},
+		{"different logs", tracingCase(t), func(e *Expected) {
+			e.Logs = &ExpectedLogs{Exact: []string{"first", "second"}}
+		}, `logs: got ["second" "first"], want ["first" "second"]`},
+		{"no logs", successfulCase(t), func(e *Expected) {
+			e.ExUnits = dataCase.Expected.ExUnits
+			e.Logs = &ExpectedLogs{Exact: []string{}}
+		}, ""},
+		{"log pattern", tracingCase(t), func(e *Expected) {
+			e.Logs = &ExpectedLogs{Pattern: `second\nf\w+`}
+		}, ""},
+		{"partial log pattern", tracingCase(t), func(e *Expected) {
+			e.Logs = &ExpectedLogs{Pattern: `first`}
+		}, `logs: got ["second" "first"], want a match for "first"`},
+		{"unmatched log pattern", tracingCase(t), func(e *Expected) {
+			e.Logs = &ExpectedLogs{Pattern: `^first`}
+		}, `logs: got ["second" "first"], want a match for "^first"`},
+		{"result term", tracingCase(t), func(e *Expected) {
+			e.Result = &ExpectedResult{Term: "(con  integer\n7)"}
+		}, ""},
+		{"different result term", tracingCase(t), func(e *Expected) {
+			e.Result = &ExpectedResult{Term: "(con integer 8)"}
+		}, "result: got (con integer 7), want (con integer 8)"},
+		{"result data", dataCase, func(e *Expected) {
+			e.Result = &ExpectedResult{DataCBORHex: hex.EncodeToString(answer)}
+		}, ""},
+		{"result data of a term", tracingCase(t), func(e *Expected) {
+			e.Result = &ExpectedResult{DataCBORHex: hex.EncodeToString(answer)}
+		}, "result: got (con integer 7), want (con data (I 42))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayCase := tt.base
+			tt.expected(&replayCase.Expected)
+			result := RunCase(&replayCase)
+			got := strings.Join(result.Mismatches, "; ")
+			if got != tt.mismatch {
+				t.Errorf("RunCase() mismatches = %q, want %q", got, tt.mismatch)
+			}
+		})
+	}
+}
+
+func TestRunCaseReportsLogsAndResult(t *testing.T) {
+	replayCase := tracingCase(t)
+	result := RunCase(&replayCase)
+	if strings.Join(result.Actual.Logs, ",") != "second,first" ||
+		result.Actual.Result != "(con integer 7)" {
+		t.Errorf("RunCase() actual = %+v", result.Actual)
+	}
+
+	replayCase.BudgetLimit.Steps = 1
+	replayCase.Expected.ExUnits = ExUnits{}
+	result = RunCase(&replayCase)
+	if result.Actual.Success || result.Actual.Result != "" {
+		t.Errorf("RunCase() over budget actual = %+v", result.Actual)
+	}
+}
+
+func TestCaseValidateExpectations(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected func(*Expected)
+		want     string
+	}{
+		{"pattern", func(e *Expected) {
+			e.Logs = &ExpectedLogs{Pattern: "("}
+		}, "expected logs pattern"},
+		{"list and pattern", func(e *Expected) {
+			e.Logs = &ExpectedLogs{Exact: []string{}, Pattern: "a"}
+		}, "both a list and a pattern"},
+		{"result of a failure", func(e *Expected) {
+			e.Success = false
+			e.Result = &ExpectedResult{Term: "(con unit ())"}
+		}, "failing expected result cannot include a result value"},
+		{"empty result", func(e *Expected) {
+			e.Result = &ExpectedResult{}
+		}, "exactly one of term or data_cbor_hex"},
+		{"unparsable term", func(e *Expected) {
+			e.Result = &ExpectedResult{Term: "(con"}
+		}, "parse expected result term"},
+		{"free variable", func(e *Expected) {
+			e.Result = &ExpectedResult{Term: "x"}
+		}, "parse expected result term"},
+		{"invalid data", func(e *Expected) {
+			e.Result = &ExpectedResult{DataCBORHex: "ff"}
+		}, "decode expected result data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayCase := successfulCase(t)
+			tt.expected(&replayCase.Expected)
+			_, err := replayCase.validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package replay
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes report as JUnit XML: one test suite for the corpus and a
+// test case per replay case, named by its ID and classed by its transaction.
+// Mismatches become the failure text.
+func WriteJUnit(w io.Writer, report *Report) error {
+	suite := junitSuite{
+		Name:     "plutigo-replay " + report.Network,
+		Tests:    report.Summary.Total,
+		Failures: report.Summary.Failed,
+		Time:     seconds(report.Summary.TotalDurationNS),
+		Properties: []junitProperty{
+			{Name: "reference.implementation", Value: report.Reference.Implementation},
+			{Name: "reference.version", Value: report.Reference.Version},
+		},
+		Cases: make([]junitCase, 0, len(report.Cases)),
+	}
+	for _, result := range report.Cases {
+		testCase := junitCase{
+			Name:      result.ID,
+			ClassName: result.Transaction.ID,
+			Time:      seconds(result.DurationNS),
+		}
+		if !result.Passed {
+			failureType := "mismatch"
+			if result.Actual.SetupError {
+				failureType = "setup"
+			}
+			message := ""
+			if len(result.Mismatches) > 0 {
+				message = result.Mismatches[0]
+			}
+			testCase.Failure = &junitFailure{
+				Message: message,
+				Type:    failureType,
+				Text:    strings.Join(result.Mismatches, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	})
+	if err != nil {
+		return fmt.Errorf("encode JUnit report: %w", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// WriteMarkdown writes a summary table of report followed by a table of its
+// failed cases.
+func WriteMarkdown(w io.Writer, report *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"## Replay: %s against %s %s\n\n",
+		report.Network,
+		report.Reference.Implementation,
+		report.Reference.Version,
+	)
+	b.WriteString("| Cases | Passed | Failed | Median | p95 | Throughput |\n")
+	b.WriteString("| ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(
+		&b,
+		"| %d | %d | %d | %s | %s | %.1f tx/s |\n",
+		report.Summary.Total,
+		report.Summary.Passed,
+		report.Summary.Failed,
+		time.Duration(report.Summary.MedianDurationNS),
+		time.Duration(report.Summary.P95DurationNS),
+		report.Summary.TransactionsPerSecond,
+	)
+	if report.Summary.Failed > 0 {
+		b.WriteString("\n### Failed cases\n\n")
+		b.WriteString("| Case | Steps | Memory | Mismatches |\n")
+		b.WriteString("| --- | ---: | ---: | --- |\n")
+		for _, result := range report.Cases {
+			if result.Passed {
+				continue
+			}
+			fmt.Fprintf(
+				&b,
+				"| %s | %d | %d | %s |\n",
+				markdownCell(result.ID),
+				result.Actual.ExUnits.Steps,
+				result.Actual.ExUnits.Memory,
+				markdownCell(strings.Join(result.Mismatches, "; ")),
+			)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell keeps s on one line of a table row.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// WriteCSV writes one row per case with its script hash, outcome, ExUnits
+// and latency. The script hashes come from the cases of src, usually the
+// corpus that report was run on; a case missing from src has no hash.
+func WriteCSV(w io.Writer, report *Report, src Source) error {
+	hashes := make(map[string]string, len(report.Cases))
+	for {
+		replayCase, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		hashes[replayCase.ID] = scriptHash(replayCase)
+	}
+
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"id",
+		"transaction_id",
+		"redeemer",
+		"script_hash",
+		"passed",
+		"success",
+		"steps",
+		"memory",
+		"error_code",
+		"duration_ns",
+		"mismatches",
+	})
+	if err != nil {
+		return err
+	}
+	for _, result := range report.Cases {
+		errorCode := ""
+		if result.Actual.ErrorCode != nil {
+			errorCode = strconv.Itoa(int(*result.Actual.ErrorCode))
+		}
+		err := writer.Write([]string{
+			result.ID,
+			result.Transaction.ID,
+			result.Transaction.Redeemer,
+			hashes[result.ID],
+			strconv.FormatBool(result.Passed),
+			strconv.FormatBool(result.Actual.Success),
+			strconv.FormatInt(result.Actual.ExUnits.Steps, 10),
+			strconv.FormatInt(result.Actual.ExUnits.Memory, 10),
+			errorCode,
+			strconv.FormatInt(result.DurationNS, 10),
+			strings.Join(result.Mismatches, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func seconds(ns int64) string {
+	return strconv.FormatFloat(time.Duration(ns).Seconds(), 'f', 6, 64)
+}
@@ -0,0 +1,118 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/cek"
+)
+
+func formattedReport() *Report {
+	code := cek.ErrCodeCPUExhausted
+	return &Report{
+		SchemaVersion: SchemaVersion,
+		Network:       "mainnet",
+		Reference:     testReference(),
+		Cases: []CaseResult{
+			{
+				ID:          "tx-1#spend:0",
+				Transaction: TransactionRef{ID: "tx-1", Redeemer: "spend:0"},
+				Passed:      true,
+				Actual:      Actual{Success: true, ExUnits: ExUnits{Steps: 100, Memory: 10}},
+				DurationNS:  1_500_000,
+			},
+			{
+				ID:          "tx-2#mint:0",
+				Transaction: TransactionRef{ID: "tx-2", Redeemer: "mint:0"},
+				Actual: Actual{
+					ExUnits:   ExUnits{Steps: 7, Memory: 3},
+					ErrorCode: &code,
+				},
+				DurationNS: 500_000,
+				Mismatches: []string{"success: got false, want true", "a | b\nc"},
+			},
+		},
+		Summary: Summary{
+			Total:            2,
+			Passed:           1,
+			Failed:           1,
+			TotalDurationNS:  2_000_000,
+			MedianDurationNS: 500_000,
+			P95DurationNS:    1_500_000,
+		},
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJUnit(&b, formattedReport()); err != nil {
+		t.Fatalf("WriteJUnit() failed: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+		t.Fatalf("decode JUnit: %v\n%s", err, b.String())
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("testsuites = %+v", suites)
+	}
+	cases := suites.Suites[0].Cases
+	if len(cases) != 2 || cases[0].Failure != nil || cases[0].Time != "0.001500" {
+		t.Fatalf("testcases = %+v", cases)
+	}
+	failure := cases[1].Failure
+	if cases[1].ClassName != "tx-2" || failure == nil ||
+		failure.Type != "mismatch" ||
+		failure.Message != "success: got false, want true" ||
+		failure.Text != "success: got false, want true\na | b\nc" {
+		t.Errorf("failed testcase = %+v, failure %+v", cases[1], failure)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, formattedReport()); err != nil {
+		t.Fatalf("WriteMarkdown() failed: %v", err)
+	}
+	for _, want := range []string{
+		"| 2 | 1 | 1 | 500µs | 1.5ms | 0.0 tx/s |\n",
+		"| tx-2#mint:0 | 7 | 3 | success: got false, want true; a \\| b c |\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteMarkdown() = %s, want row %q", b.String(), want)
+		}
+	}
+	if strings.Contains(b.String(), "tx-1#spend:0") {
+		t.Errorf("WriteMarkdown() lists a passed case:\n%s", b.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	replayCase := successfulCase(t)
+	replayCase.ID = "tx-1#spend:0"
+	corpus := validCorpus(Case{})
+	corpus.Cases = []Case{replayCase}
+	var b bytes.Buffer
+	if err := WriteCSV(&b, formattedReport(), NewSource(corpus)); err != nil {
+		t.Fatalf("WriteCSV() failed: %v", err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("decode CSV: %v", err)
+	}
+	want := [][]string{
+		{"id", "transaction_id", "redeemer", "script_hash", "passed", "success", "steps", "memory", "error_code", "duration_ns", "mismatches"},
+		{"tx-1#spend:0", "tx-1", "spend:0", scriptHash(&replayCase), "true", "true", "100", "10", "", "1500000", ""},
+		{"tx-2#mint:0", "tx-2", "mint:0", "", "false", "false", "7", "3", "102", "500000", "success: got false, want true; a | b\nc"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("WriteCSV() rows = %q", rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
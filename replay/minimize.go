@@ -0,0 +1,351 @@
+package replay
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/ledger"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// Minimization is a corpus cut down to the cases that cover distinct code
+// paths, with the groups it was chosen from.
+type Minimization struct {
+	Corpus *Corpus         `json:"-"`
+	Total  int             `json:"total"`
+	Kept   int             `json:"kept"`
+	Groups []CoverageGroup `json:"groups"`
+}
+
+// CoverageGroup holds the cases that evaluate the same script under the same
+// protocol version, with the same expected outcome, arguments of the same
+// shape and the same builtins reached. Kept lists the IDs of the cases that
+// together reach every path of the group.
+type CoverageGroup struct {
+	ScriptHash      string          `json:"script_hash"`
+	ProtocolVersion ProtocolVersion `json:"protocol_version"`
+	ExpectedSuccess bool            `json:"expected_success"`
+	ArgumentShape   []string        `json:"argument_shape"`
+	Builtins        []string        `json:"builtins"`
+	Cases           int             `json:"cases"`
+	Paths           int             `json:"paths"`
+	Kept            []string        `json:"kept"`
+}
+
+// Coverage is measured by evaluating a copy of each program that traces a
+// marker on entering every delayed term and case branch, and on reaching
+// every builtin. The markers start with a byte that scripts do not trace.
+const (
+	coverageMarker = "\x00plutigo-coverage:"
+	// coverageBudgetFactor multiplies the budget of instrumented evaluations,
+	// which spend more than the program does.
+	coverageBudgetFactor = 64
+)
+
+// Minimize evaluates every case of src for coverage and keeps, for each
+// group of cases that share a script hash, protocol version, expected
+// outcome, argument shape and set of builtins reached, the fewest cases that
+// reach all the delayed terms and case branches that any case of the group
+// reaches. Kept cases stay in corpus order.
+func Minimize(ctx context.Context, src Source, opts Options) (*Minimization, error) {
+	header := src.Header()
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	covered, err := runCases(
+		ctx,
+		src,
+		opts.Jobs,
+		func() func(*Case, decodedCase) coveredCase {
+			var w worker
+			return w.coverage
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*coverageGroup
+	byKey := make(map[string]*coverageGroup)
+	for i := range covered {
+		c := &covered[i]
+		group, ok := byKey[c.key]
+		if !ok {
+			group = &coverageGroup{CoverageGroup: c.group}
+			byKey[c.key] = group
+			groups = append(groups, group)
+		}
+		group.Cases++
+		group.members = append(group.members, i)
+	}
+
+	keep := make([]bool, len(covered))
+	result := &Minimization{
+		Corpus: &Corpus{
+			SchemaVersion: header.SchemaVersion,
+			Network:       header.Network,
+			Reference:     header.Reference,
+		},
+		Total:  len(covered),
+		Groups: make([]CoverageGroup, 0, len(groups)),
+	}
+	for _, group := range groups {
+		group.minimize(covered, keep)
+		result.Groups = append(result.Groups, group.CoverageGroup)
+	}
+	for i := range covered {
+		if keep[i] {
+			result.Corpus.Cases = append(result.Corpus.Cases, covered[i].replayCase)
+		}
+	}
+	result.Kept = len(result.Corpus.Cases)
+	return result, nil
+}
+
+type coveredCase struct {
+	replayCase Case
+	key        string
+	group      CoverageGroup
+	paths      []int
+}
+
+type coverageGroup struct {
+	CoverageGroup
+	members []int
+}
+
+// minimize marks the kept cases of the group: greedily, the case that
+// reaches the most paths not yet reached, first in corpus order on ties.
+func (g *coverageGroup) minimize(covered []coveredCase, keep []bool) {
+	// Cases that reach the same paths are interchangeable.
+	var candidates []int
+	seen := make(map[string]struct{})
+	for _, i := range g.members {
+		key := fmt.Sprint(covered[i].paths)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			candidates = append(candidates, i)
+		}
+	}
+
+	reached := make(map[int]struct{})
+	var kept []int
+	for len(candidates) > 0 {
+		best, bestNew := 0, -1
+		for j, i := range candidates {
+			added := 0
+			for _, path := range covered[i].paths {
+				if _, ok := reached[path]; !ok {
+					added++
+				}
+			}
+			if added > bestNew {
+				best, bestNew = j, added
+			}
+		}
+		if bestNew == 0 && len(kept) > 0 {
+			break
+		}
+		i := candidates[best]
+		candidates = slices.Delete(candidates, best, best+1)
+		for _, path := range covered[i].paths {
+			reached[path] = struct{}{}
+		}
+		keep[i] = true
+		kept = append(kept, i)
+	}
+	slices.Sort(kept)
+	g.Paths = len(reached)
+	g.Kept = make([]string, 0, len(kept))
+	for _, i := range kept {
+		g.Kept = append(g.Kept, covered[i].replayCase.ID)
+	}
+}
+
+// coverage evaluates the instrumented program of a case and groups the case
+// by what it reached.
+func (w *worker) coverage(replayCase *Case, decoded decodedCase) coveredCase {
+	var sites int
+	instrumented := &syn.Program[syn.DeBruijn]{
+		Version: decoded.program.Version,
+		Term:    instrument(decoded.program.Term, &sites),
+	}
+	scaled := *replayCase
+	scaled.BudgetLimit = ExUnits{
+		Steps: min(replayCase.BudgetLimit.Steps, math.MaxInt64/coverageBudgetFactor) *
+			coverageBudgetFactor,
+		Memory: min(replayCase.BudgetLimit.Memory, math.MaxInt64/coverageBudgetFactor) *
+			coverageBudgetFactor,
+	}
+	actual, _ := w.evaluate(
+		&scaled,
+		decodedCase{program: instrumented, arguments: decoded.arguments},
+	)
+
+	builtins := make(map[string]struct{})
+	paths := make(map[int]struct{})
+	for _, message := range actual.Logs {
+		marker, ok := strings.CutPrefix(message, coverageMarker)
+		if !ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(marker, "builtin:"); ok {
+			builtins[name] = struct{}{}
+		} else if site, err := strconv.Atoi(marker); err == nil {
+			paths[site] = struct{}{}
+		}
+	}
+
+	c := coveredCase{
+		replayCase: *replayCase,
+		group: CoverageGroup{
+			ScriptHash:      scriptHash(replayCase),
+			ProtocolVersion: replayCase.ProtocolVersion,
+			ExpectedSuccess: replayCase.Expected.Success,
+			ArgumentShape:   make([]string, 0, len(decoded.arguments)),
+			Builtins:        make([]string, 0, len(builtins)),
+		},
+		paths: make([]int, 0, len(paths)),
+	}
+	for _, argument := range decoded.arguments {
+		c.group.ArgumentShape = append(c.group.ArgumentShape, dataShape(argument, 2))
+	}
+	for name := range builtins {
+		c.group.Builtins = append(c.group.Builtins, name)
+	}
+	slices.Sort(c.group.Builtins)
+	for site := range paths {
+		c.paths = append(c.paths, site)
+	}
+	slices.Sort(c.paths)
+	c.key = fmt.Sprintf(
+		"%s %d.%d %t %s %s",
+		c.group.ScriptHash,
+		c.group.ProtocolVersion.Major,
+		c.group.ProtocolVersion.Minor,
+		c.group.ExpectedSuccess,
+		strings.Join(c.group.ArgumentShape, ","),
+		strings.Join(c.group.Builtins, ","),
+	)
+	return c
+}
+
+// instrument returns a copy of term that traces a coverage marker on entering
+// each delayed term and case branch, numbered in the order sites counts them,
+// and on reaching each builtin.
+func instrument(term syn.Term[syn.DeBruijn], sites *int) syn.Term[syn.DeBruijn] {
+	switch t := term.(type) {
+	case *syn.Delay[syn.DeBruijn]:
+		site := *sites
+		*sites++
+		return &syn.Delay[syn.DeBruijn]{
+			Term: traced(strconv.Itoa(site), instrument(t.Term, sites)),
+		}
+	case *syn.Force[syn.DeBruijn]:
+		return &syn.Force[syn.DeBruijn]{Term: instrument(t.Term, sites)}
+	case *syn.Lambda[syn.DeBruijn]:
+		return &syn.Lambda[syn.DeBruijn]{
+			ParameterName: t.ParameterName,
+			Body:          instrument(t.Body, sites),
+		}
+	case *syn.Apply[syn.DeBruijn]:
+		return &syn.Apply[syn.DeBruijn]{
+			Function: instrument(t.Function, sites),
+			Argument: instrument(t.Argument, sites),
+		}
+	case *syn.Constr[syn.DeBruijn]:
+		fields := make([]syn.Term[syn.DeBruijn], len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = instrument(field, sites)
+		}
+		return &syn.Constr[syn.DeBruijn]{Tag: t.Tag, Fields: fields}
+	case *syn.Case[syn.DeBruijn]:
+		scrutinee := instrument(t.Constr, sites)
+		branches := make([]syn.Term[syn.DeBruijn], len(t.Branches))
+		for i, branch := range t.Branches {
+			site := *sites
+			*sites++
+			branches[i] = traced(strconv.Itoa(site), instrument(branch, sites))
+		}
+		return &syn.Case[syn.DeBruijn]{Constr: scrutinee, Branches: branches}
+	case *syn.Builtin:
+		return traced("builtin:"+t.DefaultFunction.String(), t)
+	default:
+		return term
+	}
+}
+
+// traced returns a term that traces the coverage marker for site and then
+// evaluates term: (force [(force (builtin trace)) (con string marker)
+// (delay term)]).
+func traced(site string, term syn.Term[syn.DeBruijn]) syn.Term[syn.DeBruijn] {
+	return &syn.Force[syn.DeBruijn]{
+		Term: &syn.Apply[syn.DeBruijn]{
+			Function: &syn.Apply[syn.DeBruijn]{
+				Function: &syn.Force[syn.DeBruijn]{
+					Term: &syn.Builtin{DefaultFunction: builtin.Trace},
+				},
+				Argument: &syn.Constant{
+					Con: &syn.String{Inner: coverageMarker + site},
+				},
+			},
+			Argument: &syn.Delay[syn.DeBruijn]{Term: term},
+		},
+	}
+}
+
+// scriptHash returns the ledger hash of the case's script: blake2b-224 over
+// the language tag and the CBOR-wrapped FLAT program.
+func scriptHash(replayCase *Case) string {
+	flat, err := hex.DecodeString(replayCase.FlatProgramHex)
+	if err != nil {
+		return ""
+	}
+	language, err := replayCase.Language.PlutusVersion()
+	if err != nil {
+		return ""
+	}
+	wrapped, err := cbor.Marshal(flat)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(ledger.ScriptHash(language, wrapped))
+}
+
+// dataShape describes the constructors of d to the given depth, without the
+// values or lengths that vary between otherwise alike arguments: I, B, L, M,
+// or C followed by the tag and, above the last level, the shapes of the
+// fields, such as "C0(C1,I)".
+func dataShape(d data.PlutusData, depth int) string {
+	switch d := d.(type) {
+	case *data.Constr:
+		shape := "C" + strconv.FormatUint(uint64(d.Tag), 10)
+		if depth <= 1 {
+			return shape
+		}
+		fields := make([]string, len(d.Fields))
+		for i, field := range d.Fields {
+			fields[i] = dataShape(field, depth-1)
+		}
+		return shape + "(" + strings.Join(fields, ",") + ")"
+	case *data.Integer:
+		return "I"
+	case *data.ByteString:
+		return "B"
+	case *data.List:
+		return "L"
+	case *data.Map:
+		return "M"
+	default:
+		return "?"
+	}
+}
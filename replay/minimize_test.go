@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/data"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// signCase returns a case whose script takes one of two branches depending
+// on the sign of its integer argument.
+func signCase(t *testing.T, id string, argument data.PlutusData) Case {
+	t.Helper()
+	program, err := syn.Parse(`(program 1.0.0 (lam d
+		(force [(force (builtin ifThenElse))
+			[(builtin lessThanInteger) [(builtin unIData) d] (con integer 0)]
+			(delay (con integer 1))
+			(delay (con integer 2))])))`)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	converted, err := syn.NameToDeBruijn(program)
+	if err != nil {
+		t.Fatalf("NameToDeBruijn() failed: %v", err)
+	}
+	encoded, err := data.Encode(argument)
+	if err != nil {
+		t.Fatalf("data.Encode() failed: %v", err)
+	}
+	replayCase := baseCase(t, converted.Term, []string{hex.EncodeToString(encoded)})
+	replayCase.ID = id
+	replayCase.Expected.Success = true
+	return replayCase
+}
+
+func TestMinimize(t *testing.T) {
+	integer := func(n int64) data.PlutusData {
+		return data.NewInteger(big.NewInt(n))
+	}
+	corpus := validCorpus(signCase(t, "negative", integer(-1)))
+	corpus.Cases = append(
+		corpus.Cases,
+		signCase(t, "more negative", integer(-5)),
+		signCase(t, "positive", integer(3)),
+		signCase(t, "bytes", data.NewByteString([]byte{1})),
+		successfulCase(t),
+		tracingCase(t),
+	)
+	corpus.Cases[3].Expected.Success = false
+	corpus.Cases[5].ID = "tracing"
+
+	result, err := Minimize(context.Background(), NewSource(corpus), Options{Jobs: 2})
+	if err != nil {
+		t.Fatalf("Minimize() failed: %v", err)
+	}
+	var kept []string
+	for _, c := range result.Corpus.Cases {
+		kept = append(kept, c.ID)
+	}
+	want := []string{"negative", "positive", "bytes", "tx-1#spend:0", "tracing"}
+	if !slices.Equal(kept, want) {
+		t.Errorf("Minimize() kept %q, want %q", kept, want)
+	}
+	if result.Total != 6 || result.Kept != 5 || len(result.Groups) != 4 {
+		t.Fatalf("Minimize() = %+v", result)
+	}
+	if result.Corpus.Network != corpus.Network {
+		t.Errorf("Minimize() corpus header = %+v", result.Corpus.Header())
+	}
+
+	sign := result.Groups[0]
+	if sign.Cases != 3 || sign.Paths != 2 ||
+		!slices.Equal(sign.Kept, []string{"negative", "positive"}) ||
+		!slices.Equal(sign.ArgumentShape, []string{"I"}) ||
+		!slices.Equal(sign.Builtins, []string{"ifThenElse", "lessThanInteger", "unIData"}) ||
+		len(sign.ScriptHash) != 56 {
+		t.Errorf("sign group = %+v", sign)
+	}
+	if failing := result.Groups[1]; failing.ExpectedSuccess ||
+		failing.ScriptHash != sign.ScriptHash ||
+		!slices.Equal(failing.ArgumentShape, []string{"B"}) {
+		t.Errorf("failing group = %+v", failing)
+	}
+	if tracing := result.Groups[3]; !slices.Equal(tracing.Builtins, []string{"trace"}) ||
+		tracing.Paths != 0 {
+		t.Errorf("tracing group = %+v", tracing)
+	}
+}
+
+func TestInstrumentKeepsResult(t *testing.T) {
+	replayCase := tracingCase(t)
+	decoded, err := replayCase.validate()
+	if err != nil {
+		t.Fatalf("validate() failed: %v", err)
+	}
+	var sites int
+	decoded.program.Term = instrument(decoded.program.Term, &sites)
+	var w worker
+	actual, term := w.evaluate(&replayCase, decoded)
+	if !actual.Success || prettyResult(term) != "(con integer 7)" {
+		t.Fatalf("instrumented evaluation = %+v", actual)
+	}
+	var logs []string
+	for _, message := range actual.Logs {
+		if !strings.HasPrefix(message, coverageMarker) {
+			logs = append(logs, message)
+		}
+	}
+	if !slices.Equal(logs, []string{"second", "first"}) {
+		t.Errorf("instrumented evaluation logs = %q", actual.Logs)
+	}
+}
+
+func TestDataShape(t *testing.T) {
+	tests := []struct {
+		data  data.PlutusData
+		depth int
+		want  string
+	}{
+		{data.NewInteger(big.NewInt(1)), 2, "I"},
+		{data.NewConstr(1), 2, "C1()"},
+		{data.NewConstr(0, data.NewList(), data.NewMap(nil)), 1, "C0"},
+		{
+			data.NewConstr(0, data.NewConstr(2, data.NewByteString(nil)), data.NewList()),
+			2,
+			"C0(C2,L)",
+		},
+	}
+	for _, tt := range tests {
+		if got := dataShape(tt.data, tt.depth); got != tt.want {
+			t.Errorf("dataShape(%v, %d) = %q, want %q", tt.data, tt.depth, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
@@ -111,7 +112,8 @@ func TestEvaluateRecoversMachinePanic(t *testing.T) {
 	}
 	decoded.program.Term = (*syn.Apply[syn.DeBruijn])(nil)
 
-	actual := evaluate(&replayCase, decoded)
+	var w worker
+	actual, _ := w.evaluate(&replayCase, decoded)
 	if actual.Success {
 		t.Fatal("evaluate() unexpectedly succeeded")
 	}
@@ -124,6 +126,9 @@ func TestEvaluateRecoversMachinePanic(t *testing.T) {
 	if actual.ExUnits == (ExUnits{}) {
 		t.Fatal("evaluate() did not preserve consumed execution units")
 	}
+	if w.machine != nil {
+		t.Fatal("evaluate() kept the machine of a panicked run")
+	}
 }
 
 func TestRunCaseUsesLedgerLanguageInsteadOfUPLCVersion(t *testing.T) {
@@ -194,11 +199,114 @@ func TestRunBuildsSummary(t *testing.T) {
 		t.Fatalf("Run() summary = %+v", report.Summary)
 	}
 	if report.Summary.TotalDurationNS <= 0 ||
+		report.Summary.WallDurationNS <= 0 ||
 		report.Summary.TransactionsPerSecond <= 0 {
 		t.Fatalf("Run() timing summary = %+v", report.Summary)
 	}
 }
 
+func TestWorkerReusesMachine(t *testing.T) {
+	replayCase := successfulCase(t)
+	decoded, err := replayCase.validate()
+	if err != nil {
+		t.Fatalf("validate() failed: %v", err)
+	}
+
+	var w worker
+	first, _ := w.evaluate(&replayCase, decoded)
+	machine := w.machine
+	if machine == nil {
+		t.Fatal("evaluate() did not keep its machine")
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*Case)
+		reuse bool
+	}{
+		{"same case", func(*Case) {}, true},
+		// The budget the previous run ended with must not be mistaken for
+		// an unchanged one.
+		{"remaining budget", func(c *Case) {
+			c.BudgetLimit.Steps -= first.ExUnits.Steps
+			c.BudgetLimit.Memory -= first.ExUnits.Memory
+		}, false},
+		{"protocol version", func(c *Case) { c.ProtocolVersion.Major++ }, false},
+		{"language", func(c *Case) { c.Language = PlutusV2 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.machine = machine
+			w.language = replayCase.Language
+			w.protocol = replayCase.ProtocolVersion
+			w.budget = cek.ExBudget{
+				Cpu: replayCase.BudgetLimit.Steps,
+				Mem: replayCase.BudgetLimit.Memory,
+			}
+			w.machine.ExBudget = w.budget.Sub(&cek.ExBudget{
+				Cpu: first.ExUnits.Steps,
+				Mem: first.ExUnits.Memory,
+			})
+
+			edited := replayCase
+			tt.edit(&edited)
+			actual, _ := w.evaluate(&edited, decoded)
+			if actual.ExUnits != first.ExUnits || !actual.Success {
+				t.Errorf("evaluate() = %+v, want %+v", actual, first)
+			}
+			if reused := w.machine == machine; reused != tt.reuse {
+				t.Errorf("machine reused = %t, want %t", reused, tt.reuse)
+			}
+		})
+	}
+}
+
+func TestRunWithOptionsKeepsCorpusOrder(t *testing.T) {
+	corpus := &Corpus{
+		SchemaVersion: SchemaVersion,
+		Network:       "mainnet",
+		Reference:     testReference(),
+	}
+	for i := range 16 {
+		replayCase := successfulCase(t)
+		replayCase.ID = fmt.Sprintf("tx-%d#spend:0", i)
+		// Every other case fails on its expected budget.
+		if i%2 == 0 {
+			replayCase.Expected.ExUnits = RunCase(&replayCase).Actual.ExUnits
+		}
+		corpus.Cases = append(corpus.Cases, replayCase)
+	}
+
+	want, err := Run(context.Background(), corpus)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	for _, jobs := range []int{0, 3, 64} {
+		report, err := RunWithOptions(
+			context.Background(),
+			corpus,
+			Options{Jobs: jobs},
+		)
+		if err != nil {
+			t.Fatalf("RunWithOptions(%d) failed: %v", jobs, err)
+		}
+		if report.Summary.Passed != want.Summary.Passed ||
+			report.Summary.Failed != want.Summary.Failed {
+			t.Errorf("RunWithOptions(%d) summary = %+v", jobs, report.Summary)
+		}
+		for i, result := range report.Cases {
+			if result.ID != corpus.Cases[i].ID ||
+				result.Passed != want.Cases[i].Passed ||
+				result.Actual.ExUnits != want.Cases[i].Actual.ExUnits {
+				t.Errorf("RunWithOptions(%d) case %d = %+v", jobs, i, result)
+			}
+			if result.DurationNS <= 0 {
+				t.Errorf("RunWithOptions(%d) case %d was not timed", jobs, i)
+			}
+		}
+	}
+}
+
 func TestRunHonorsCanceledContext(t *testing.T) {
 	replayCase := successfulCase(t)
 	corpus := &Corpus{
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/blinklabs-io/plutigo/cek"
@@ -34,43 +36,87 @@ type Actual struct {
 	SetupError bool           `json:"setup_error,omitempty"`
 	Error      string         `json:"error,omitempty"`
 	ErrorCode  *cek.ErrorCode `json:"error_code,omitempty"`
+	Logs       []string       `json:"logs,omitempty"`
+	Result     string         `json:"result,omitempty"`
 }
 
 type Summary struct {
-	Total                 int     `json:"total"`
-	Passed                int     `json:"passed"`
-	Failed                int     `json:"failed"`
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// TotalDurationNS adds up the durations of the cases, which overlap
+	// when they run on several workers. WallDurationNS is the elapsed time
+	// of the whole run, which TransactionsPerSecond is measured against.
 	TotalDurationNS       int64   `json:"total_duration_ns"`
+	WallDurationNS        int64   `json:"wall_duration_ns"`
 	MedianDurationNS      int64   `json:"median_duration_ns"`
 	P95DurationNS         int64   `json:"p95_duration_ns"`
 	TransactionsPerSecond float64 `json:"transactions_per_second"`
 }
 
+// Options configures Run.
+type Options struct {
+	// Jobs is the number of cases evaluated concurrently. Values below one
+	// evaluate sequentially.
+	Jobs int
+}
+
+// Run evaluates every case of the corpus sequentially.
 func Run(ctx context.Context, corpus *Corpus) (*Report, error) {
+	return RunWithOptions(ctx, corpus, Options{})
+}
+
+// RunWithOptions evaluates the corpus on opts.Jobs workers. Each worker
+// reuses its machine between cases and times the cases it runs. The report
+// lists cases in corpus order and its summary is computed from them, so
+// only the measured durations depend on scheduling.
+func RunWithOptions(
+	ctx context.Context,
+	corpus *Corpus,
+	opts Options,
+) (*Report, error) {
 	if err := corpus.validate(); err != nil {
 		return nil, err
 	}
+	return RunSource(ctx, NewSource(corpus), opts)
+}
 
-	report := &Report{
-		SchemaVersion: SchemaVersion,
-		Network:       corpus.Network,
-		Reference:     corpus.Reference,
-		Cases:         make([]CaseResult, 0, len(corpus.Cases)),
+// RunSource is RunWithOptions for a corpus read from src. Cases are
+// validated and evaluated as they are read, so only their results are held
+// in memory. An invalid case stops reading; the error of the first invalid
+// case in corpus order is returned.
+func RunSource(
+	ctx context.Context,
+	src Source,
+	opts Options,
+) (*Report, error) {
+	header := src.Header()
+	if err := header.validate(); err != nil {
+		return nil, err
 	}
-	durations := make([]int64, 0, len(corpus.Cases))
-
-	for i := range corpus.Cases {
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("run replay corpus: %w", err)
-		}
+	start := time.Now()
+	results, err := runCases(
+		ctx,
+		src,
+		opts.Jobs,
+		func() func(*Case, decodedCase) CaseResult {
+			var w worker
+			return w.runDecodedCase
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	wall := time.Since(start)
 
-		replayCase := &corpus.Cases[i]
-		decoded, err := replayCase.validate()
-		if err != nil {
-			return nil, fmt.Errorf("replay case %d: %w", i, err)
-		}
-		result := runDecodedCase(replayCase, decoded)
-		report.Cases = append(report.Cases, result)
+	report := &Report{
+		SchemaVersion: header.SchemaVersion,
+		Network:       header.Network,
+		Reference:     header.Reference,
+		Cases:         results,
+	}
+	durations := make([]int64, 0, len(results))
+	for _, result := range results {
 		durations = append(durations, result.DurationNS)
 		report.Summary.TotalDurationNS += result.DurationNS
 		if result.Passed {
@@ -82,20 +128,112 @@ func Run(ctx context.Context, corpus *Corpus) (*Report, error) {
 	report.Summary.Failed = report.Summary.Total - report.Summary.Passed
 	report.Summary.MedianDurationNS = percentile(durations, 50)
 	report.Summary.P95DurationNS = percentile(durations, 95)
-	if report.Summary.TotalDurationNS > 0 {
-		duration := time.Duration(report.Summary.TotalDurationNS)
+	report.Summary.WallDurationNS = wall.Nanoseconds()
+	if wall > 0 {
 		report.Summary.TransactionsPerSecond = float64(report.Summary.Total) /
-			duration.Seconds()
+			wall.Seconds()
 	}
 	return report, nil
 }
 
+// runCases validates the cases of src as it reads them and evaluates them on
+// jobs workers, each running the function that newWorker returns to it. It
+// returns the results in corpus order, or the error of the first invalid
+// case in corpus order, after which it stops reading.
+func runCases[T any](
+	ctx context.Context,
+	src Source,
+	jobs int,
+	newWorker func() func(*Case, decodedCase) T,
+) ([]T, error) {
+	var (
+		mu       sync.Mutex
+		results  []T
+		failed   = -1
+		firstErr error
+	)
+	fail := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failed < 0 || i < failed {
+			failed, firstErr = i, err
+		}
+	}
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return failed >= 0
+	}
+
+	type job struct {
+		index      int
+		replayCase *Case
+	}
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for range max(jobs, 1) {
+		wg.Go(func() {
+			run := newWorker()
+			for j := range queue {
+				decoded, err := j.replayCase.validate()
+				if err != nil {
+					fail(j.index, fmt.Errorf("replay case %d: %w", j.index, err))
+					continue
+				}
+				result := run(j.replayCase, decoded)
+				mu.Lock()
+				results[j.index] = result
+				mu.Unlock()
+			}
+		})
+	}
+
+	ids := make(map[string]struct{})
+	for i := 0; !stopped(); i++ {
+		if err := ctx.Err(); err != nil {
+			fail(i, fmt.Errorf("run replay corpus: %w", err))
+			break
+		}
+		replayCase, err := src.Next()
+		if errors.Is(err, errEmptyShard) {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			if i == 0 {
+				fail(i, errors.New("replay corpus must contain at least one case"))
+			}
+			break
+		}
+		if err != nil {
+			fail(i, err)
+			break
+		}
+		if _, exists := ids[replayCase.ID]; exists {
+			fail(i, fmt.Errorf("replay case %d: duplicate id %q", i, replayCase.ID))
+			break
+		}
+		ids[replayCase.ID] = struct{}{}
+
+		mu.Lock()
+		var zero T
+		results = append(results, zero)
+		mu.Unlock()
+		queue <- job{index: i, replayCase: replayCase}
+	}
+	close(queue)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
 func RunCase(replayCase *Case) CaseResult {
 	if replayCase == nil {
 		actual := setupFailure(errors.New("replay case is required"))
 		return CaseResult{
 			Actual:     actual,
-			Mismatches: compare(Expected{}, actual),
+			Mismatches: compare(Expected{}, decodedCase{}, actual),
 		}
 	}
 	decoded, err := replayCase.validate()
@@ -105,31 +243,56 @@ func RunCase(replayCase *Case) CaseResult {
 			ID:          replayCase.ID,
 			Transaction: replayCase.Transaction,
 			Actual:      actual,
-			Mismatches:  compare(replayCase.Expected, actual),
+			Mismatches:  compare(replayCase.Expected, decodedCase{}, actual),
 		}
 	}
-	return runDecodedCase(replayCase, decoded)
+	var w worker
+	return w.runDecodedCase(replayCase, decoded)
 }
 
-func runDecodedCase(replayCase *Case, decoded decodedCase) CaseResult {
+// worker evaluates cases on one goroutine. It keeps the machine of its last
+// case and reuses it for the next one when the language, protocol version
+// and cost model match.
+type worker struct {
+	machine   *cek.Machine[syn.DeBruijn]
+	language  Language
+	protocol  ProtocolVersion
+	costModel CostModel
+	budget    cek.ExBudget
+}
+
+func (w *worker) runDecodedCase(replayCase *Case, decoded decodedCase) CaseResult {
 	result := CaseResult{
 		ID:          replayCase.ID,
 		Transaction: replayCase.Transaction,
 	}
 	start := time.Now()
-	result.Actual = evaluate(replayCase, decoded)
+	actual, term := w.evaluate(replayCase, decoded)
 	result.DurationNS = time.Since(start).Nanoseconds()
-	result.Mismatches = compare(replayCase.Expected, result.Actual)
+	if term != nil {
+		actual.Result = prettyResult(term)
+	}
+	result.Actual = actual
+	result.Mismatches = compare(replayCase.Expected, decoded, result.Actual)
 	result.Passed = len(result.Mismatches) == 0
 	return result
 }
 
-func evaluate(replayCase *Case, decoded decodedCase) Actual {
-	languageVersion, err := replayCase.Language.Version()
-	if err != nil {
-		return setupFailure(err)
+// actual evaluates a case and pretty-prints its result.
+func (w *worker) actual(replayCase *Case, decoded decodedCase) Actual {
+	actual, term := w.evaluate(replayCase, decoded)
+	if term != nil {
+		actual.Result = prettyResult(term)
 	}
+	return actual
+}
 
+// evaluate runs a case and returns its outcome and, if it succeeded, the
+// discharged term, which the caller pretty-prints outside the timed region.
+func (w *worker) evaluate(
+	replayCase *Case,
+	decoded decodedCase,
+) (Actual, syn.Term[syn.DeBruijn]) {
 	term := decoded.program.Term
 	for _, argument := range decoded.arguments {
 		term = &syn.Apply[syn.DeBruijn]{
@@ -140,6 +303,66 @@ func evaluate(replayCase *Case, decoded decodedCase) Actual {
 		}
 	}
 
+	initialBudget := cek.ExBudget{
+		Cpu: replayCase.BudgetLimit.Steps,
+		Mem: replayCase.BudgetLimit.Memory,
+	}
+	machine, err := w.machineFor(replayCase, initialBudget)
+	if err != nil {
+		return setupFailure(err), nil
+	}
+	machine.ExBudget = initialBudget
+	discharged, evalErr := runMachine(machine, term)
+	consumed := initialBudget.Sub(&machine.ExBudget)
+	if errors.Is(evalErr, errEvaluationPanic) {
+		// A panic can leave the machine in any state.
+		w.machine = nil
+	}
+
+	actual := Actual{
+		Success: evalErr == nil,
+		ExUnits: ExUnits{
+			Steps:  consumed.Cpu,
+			Memory: consumed.Mem,
+		},
+	}
+	if len(machine.Logs) > 0 {
+		// The machine reuses its log slice in the next run.
+		actual.Logs = slices.Clone(machine.Logs)
+	}
+	if evalErr != nil {
+		actual.Error = evalErr.Error()
+		if code, ok := cek.GetErrorCode(evalErr); ok {
+			actual.ErrorCode = &code
+		}
+		return actual, nil
+	}
+	return actual, discharged
+}
+
+// machineFor returns the worker's machine if it was built for the case's
+// language, protocol version and cost model, and a new one otherwise.
+func (w *worker) machineFor(
+	replayCase *Case,
+	budget cek.ExBudget,
+) (*cek.Machine[syn.DeBruijn], error) {
+	// A machine given the budget its last run ended with restores the budget
+	// of that run instead, so only reuse it when that cannot be mistaken.
+	if w.machine != nil &&
+		w.language == replayCase.Language &&
+		w.protocol == replayCase.ProtocolVersion &&
+		w.costModel.UseDefault == replayCase.CostModel.UseDefault &&
+		slices.Equal(w.costModel.Parameters, replayCase.CostModel.Parameters) &&
+		(budget == w.budget || budget != w.machine.ExBudget) {
+		w.budget = budget
+		return w.machine, nil
+	}
+	w.machine = nil
+
+	languageVersion, err := replayCase.Language.Version()
+	if err != nil {
+		return nil, err
+	}
 	protoVersion := cek.ProtoVersion{
 		Major: replayCase.ProtocolVersion.Major,
 		Minor: replayCase.ProtocolVersion.Minor,
@@ -154,50 +377,35 @@ func evaluate(replayCase *Case, decoded decodedCase) Actual {
 			replayCase.CostModel.Parameters,
 		)
 		if err != nil {
-			return setupFailure(fmt.Errorf("build evaluation context: %w", err))
+			return nil, fmt.Errorf("build evaluation context: %w", err)
 		}
 	}
 
-	initialBudget := cek.ExBudget{
-		Cpu: replayCase.BudgetLimit.Steps,
-		Mem: replayCase.BudgetLimit.Memory,
-	}
-	machine := cek.NewMachine[syn.DeBruijn](
+	w.machine = cek.NewMachine[syn.DeBruijn](
 		languageVersion,
 		0,
 		evalContext,
 	)
-	machine.ExBudget = initialBudget
-	evalErr := runMachine(machine, term)
-	consumed := initialBudget.Sub(&machine.ExBudget)
-
-	actual := Actual{
-		Success: evalErr == nil,
-		ExUnits: ExUnits{
-			Steps:  consumed.Cpu,
-			Memory: consumed.Mem,
-		},
-	}
-	if evalErr != nil {
-		actual.Error = evalErr.Error()
-		if code, ok := cek.GetErrorCode(evalErr); ok {
-			actual.ErrorCode = &code
-		}
-	}
-	return actual
+	w.language = replayCase.Language
+	w.protocol = replayCase.ProtocolVersion
+	w.costModel = replayCase.CostModel
+	w.budget = budget
+	return w.machine, nil
 }
 
+var errEvaluationPanic = errors.New("panic during script evaluation")
+
 func runMachine(
 	machine *cek.Machine[syn.DeBruijn],
 	term syn.Term[syn.DeBruijn],
-) (err error) {
+) (result syn.Term[syn.DeBruijn], err error) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
-			err = fmt.Errorf("panic during script evaluation: %v", recovered)
+			result = nil
+			err = fmt.Errorf("%w: %v", errEvaluationPanic, recovered)
 		}
 	}()
-	_, err = machine.Run(term)
-	return err
+	return machine.Run(term)
 }
 
 func setupFailure(err error) Actual {
@@ -208,7 +416,7 @@ func setupFailure(err error) Actual {
 	}
 }
 
-func compare(expected Expected, actual Actual) []string {
+func compare(expected Expected, decoded decodedCase, actual Actual) []string {
 	var mismatches []string
 	if actual.SetupError {
 		mismatches = append(mismatches, actual.Error)
@@ -235,6 +443,17 @@ func compare(expected Expected, actual Actual) []string {
 			),
 		)
 	}
+	if expected.Logs != nil && !actual.SetupError {
+		if mismatch := expected.Logs.mismatch(decoded.logsPattern, actual.Logs); mismatch != "" {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	if expected.Result != nil && actual.Success && actual.Result != decoded.result {
+		mismatches = append(
+			mismatches,
+			fmt.Sprintf("result: got %s, want %s", actual.Result, decoded.result),
+		)
+	}
 	if expected.ErrorCode != nil {
 		if actual.ErrorCode == nil {
 			mismatches = append(
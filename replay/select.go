@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Selection chooses the cases of a corpus to run. A case is selected when it
+// satisfies every criterion that is set; within a list, any entry matches.
+type Selection struct {
+	ID               *regexp.Regexp
+	Languages        []Language
+	ProtocolMajors   []uint
+	ProtocolVersions []ProtocolVersion
+	TransactionIDs   []string
+	ExpectedSuccess  *bool
+	// Shard and Shards split the corpus into Shards parts by a hash of the
+	// case ID and select part Shard, counting from 1. A case stays in its
+	// shard when other cases are added or reordered. Zero Shards disables
+	// sharding.
+	Shard, Shards int
+}
+
+func (s *Selection) Matches(c *Case) bool {
+	if s.ID != nil && !s.ID.MatchString(c.ID) {
+		return false
+	}
+	if len(s.Languages) > 0 && !slices.Contains(s.Languages, c.Language) {
+		return false
+	}
+	if len(s.ProtocolMajors) > 0 || len(s.ProtocolVersions) > 0 {
+		if !slices.Contains(s.ProtocolMajors, c.ProtocolVersion.Major) &&
+			!slices.Contains(s.ProtocolVersions, c.ProtocolVersion) {
+			return false
+		}
+	}
+	if len(s.TransactionIDs) > 0 && !slices.ContainsFunc(
+		s.TransactionIDs,
+		func(id string) bool { return strings.EqualFold(id, c.Transaction.ID) },
+	) {
+		return false
+	}
+	if s.ExpectedSuccess != nil && *s.ExpectedSuccess != c.Expected.Success {
+		return false
+	}
+	if s.Shards > 0 {
+		h := fnv.New64a()
+		h.Write([]byte(c.ID))
+		if h.Sum64()%uint64(s.Shards) != uint64(s.Shard-1) { //nolint:gosec // Shards is positive
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Selection) validate() error {
+	if s.Shards < 0 || (s.Shards == 0 && s.Shard != 0) ||
+		(s.Shards > 0 && (s.Shard < 1 || s.Shard > s.Shards)) {
+		return fmt.Errorf("replay shard %d/%d is out of range", s.Shard, s.Shards)
+	}
+	return nil
+}
+
+// errEmptyShard ends a selection whose shard of a non-empty corpus holds no
+// cases. With many shards of a small corpus some are empty, and running one
+// is not an error.
+var errEmptyShard = errors.New("no replay cases in the shard")
+
+// Select returns a source of the cases of src that sel matches. Reading it
+// fails at the end if no case matched, unless sel selects a shard, and fails
+// before reading src if the shard is out of range.
+func Select(src Source, sel Selection) Source {
+	return &selectSource{Source: src, selection: sel, err: sel.validate()}
+}
+
+type selectSource struct {
+	Source
+	selection Selection
+	err       error
+	read      bool
+	selected  bool
+}
+
+func (s *selectSource) Next() (*Case, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for {
+		replayCase, err := s.Source.Next()
+		if errors.Is(err, io.EOF) && s.read && !s.selected {
+			if s.selection.Shards > 0 {
+				return nil, errEmptyShard
+			}
+			return nil, errors.New("no replay cases match the selection")
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.read = true
+		if s.selection.Matches(replayCase) {
+			s.selected = true
+			return replayCase, nil
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSelectionMatches(t *testing.T) {
+	replayCase := Case{
+		ID:              "abc#spend:0",
+		Transaction:     TransactionRef{ID: "ABC"},
+		Language:        PlutusV2,
+		ProtocolVersion: ProtocolVersion{Major: 10, Minor: 2},
+		Expected:        Expected{Success: true},
+	}
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		selection Selection
+		want      bool
+	}{
+		{"everything", Selection{}, true},
+		{"id", Selection{ID: regexp.MustCompile(`#spend:`)}, true},
+		{"other id", Selection{ID: regexp.MustCompile(`#mint:`)}, false},
+		{"language", Selection{Languages: []Language{PlutusV1, PlutusV2}}, true},
+		{"other language", Selection{Languages: []Language{PlutusV3}}, false},
+		{"protocol major", Selection{ProtocolMajors: []uint{10}}, true},
+		{"protocol version", Selection{ProtocolVersions: []ProtocolVersion{{Major: 10, Minor: 2}}}, true},
+		{"other protocol minor", Selection{ProtocolVersions: []ProtocolVersion{{Major: 10}}}, false},
+		{"either protocol filter", Selection{
+			ProtocolMajors:   []uint{9},
+			ProtocolVersions: []ProtocolVersion{{Major: 10, Minor: 2}},
+		}, true},
+		{"transaction", Selection{TransactionIDs: []string{"abc"}}, true},
+		{"other transaction", Selection{TransactionIDs: []string{"def"}}, false},
+		{"expected success", Selection{ExpectedSuccess: &yes}, true},
+		{"expected failure", Selection{ExpectedSuccess: &no}, false},
+		{"all criteria", Selection{
+			ID:              regexp.MustCompile(`^abc`),
+			Languages:       []Language{PlutusV2},
+			ProtocolMajors:  []uint{10},
+			ExpectedSuccess: &yes,
+			Shard:           1,
+			Shards:          1,
+		}, true},
+		{"one failing criterion", Selection{
+			ID:        regexp.MustCompile(`^abc`),
+			Languages: []Language{PlutusV3},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selection.Matches(&replayCase); got != tt.want {
+				t.Errorf("Matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionShards(t *testing.T) {
+	const shards = 4
+	ids := make([]string, 0, 100)
+	for i := range 100 {
+		ids = append(ids, fmt.Sprintf("tx-%d#spend:0", i))
+	}
+	seen := make(map[string]int)
+	for shard := 1; shard <= shards; shard++ {
+		selection := Selection{Shard: shard, Shards: shards}
+		count := 0
+		for _, id := range ids {
+			if selection.Matches(&Case{ID: id}) {
+				seen[id]++
+				count++
+			}
+		}
+		if count == 0 {
+			t.Errorf("shard %d/%d is empty", shard, shards)
+		}
+	}
+	for _, id := range ids {
+		if seen[id] != 1 {
+			t.Errorf("case %s is in %d shards", id, seen[id])
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	corpus := validCorpus(Case{})
+	corpus.Cases = numberedCases(t, "a-1", "b-1", "a-2")
+	source := Select(NewSource(corpus), Selection{ID: regexp.MustCompile(`^a-`)})
+	report, err := RunSource(context.Background(), source, Options{})
+	if err != nil {
+		t.Fatalf("RunSource() failed: %v", err)
+	}
+	if len(report.Cases) != 2 || report.Cases[0].ID != "a-1" || report.Cases[1].ID != "a-2" {
+		t.Errorf("RunSource() cases = %+v", report.Cases)
+	}
+
+	source = Select(NewSource(corpus), Selection{ID: regexp.MustCompile(`^c-`)})
+	_, err = RunSource(context.Background(), source, Options{})
+	if err == nil || !strings.Contains(err.Error(), "no replay cases match the selection") {
+		t.Errorf("RunSource() error = %v, want empty selection error", err)
+	}
+
+	// Every case is in exactly one shard, and an empty shard passes, as a CI
+	// matrix with more shards than cases runs some.
+	const shards = 16
+	seen := make(map[string]int)
+	empties := 0
+	for shard := range shards {
+		sel := Selection{Shard: shard + 1, Shards: shards}
+		report, err := RunSource(context.Background(), Select(NewSource(corpus), sel), Options{})
+		if err != nil {
+			t.Fatalf("RunSource() of shard %d/%d failed: %v", sel.Shard, shards, err)
+		}
+		if len(report.Cases) == 0 {
+			empties++
+			if report.Summary.Total != 0 || report.Summary.Failed != 0 {
+				t.Errorf("RunSource() of empty shard = %+v", report)
+			}
+		}
+		for _, result := range report.Cases {
+			seen[result.ID]++
+		}
+	}
+	if empties == 0 {
+		t.Error("no shard is empty")
+	}
+	if len(seen) != len(corpus.Cases) {
+		t.Errorf("shards hold %d cases, want %d", len(seen), len(corpus.Cases))
+	}
+	for _, c := range corpus.Cases {
+		if seen[c.ID] != 1 {
+			t.Errorf("case %s is in %d shards", c.ID, seen[c.ID])
+		}
+	}
+
+	shard := Selection{Shard: 1, Shards: shards}
+	empty := validCorpus(Case{})
+	empty.Cases = nil
+	_, err = RunSource(context.Background(), Select(NewSource(empty), shard), Options{})
+	if err == nil {
+		t.Error("RunSource() of an empty corpus succeeded")
+	}
+
+	for _, sel := range []Selection{
+		{Shard: 0, Shards: 4},
+		{Shard: 5, Shards: 4},
+		{Shard: 1, Shards: -1},
+		{Shard: 1},
+	} {
+		_, err := Select(&failingSource{}, sel).Next()
+		if err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("Select(%d/%d).Next() error = %v, want out of range", sel.Shard, sel.Shards, err)
+		}
+	}
+}
+
+// failingSource fails the test if it is read.
+type failingSource struct{ Source }
+
+func (*failingSource) Next() (*Case, error) {
+	panic("read a case before validating the selection")
+}
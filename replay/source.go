@@ -0,0 +1,303 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Source yields the cases of a corpus one at a time, so that a corpus does
+// not have to fit in memory.
+type Source interface {
+	Header() Header
+	// Next returns the next case, or io.EOF after the last one.
+	Next() (*Case, error)
+	Close() error
+}
+
+// Open opens a corpus file or a directory of corpus files. Files ending in
+// .jsonl are JSON Lines corpora, read as their cases are consumed; other
+// files are JSON corpora and are loaded whole. A directory is read in file
+// name order, one file at a time, and its files must share a header.
+func Open(path string) (Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay corpus: %w", err)
+	}
+	if info.IsDir() {
+		return openDir(path)
+	}
+	return openFile(path)
+}
+
+// WriteFile writes corpus to path as JSON Lines if the path ends in .jsonl,
+// as Open reads it, and as indented JSON otherwise.
+func WriteFile(path string, corpus *Corpus) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create replay corpus: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		err = WriteJSONL(f, corpus)
+	} else {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(corpus)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write replay corpus %q: %w", path, err)
+	}
+	return nil
+}
+
+// WriteJSONL writes corpus as JSON Lines: its header, then a case per line.
+func WriteJSONL(w io.Writer, corpus *Corpus) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(corpus.Header()); err != nil {
+		return err
+	}
+	for i := range corpus.Cases {
+		if err := encoder.Encode(&corpus.Cases[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSource returns a source over the cases of corpus.
+func NewSource(corpus *Corpus) Source {
+	return &corpusSource{corpus: corpus}
+}
+
+type corpusSource struct {
+	corpus *Corpus
+	next   int
+}
+
+func (s *corpusSource) Header() Header {
+	return s.corpus.Header()
+}
+
+func (s *corpusSource) Next() (*Case, error) {
+	if s.next >= len(s.corpus.Cases) {
+		return nil, io.EOF
+	}
+	s.next++
+	return &s.corpus.Cases[s.next-1], nil
+}
+
+func (s *corpusSource) Close() error {
+	return nil
+}
+
+// NewJSONLSource reads a JSON Lines corpus: a Header on the first line and
+// one Case on each following line. Blank lines are ignored.
+func NewJSONLSource(r io.Reader) (Source, error) {
+	return newJSONLSource(r, nil)
+}
+
+func newJSONLSource(r io.Reader, closer io.Closer) (*jsonlSource, error) {
+	s := &jsonlSource{reader: bufio.NewReader(r), closer: closer}
+	line, err := s.readLine()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("decode replay corpus header: empty corpus")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeLine(line, &s.header); err != nil {
+		return nil, fmt.Errorf("line %d: decode replay corpus header: %w", s.line, err)
+	}
+	if err := s.header.validate(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", s.line, err)
+	}
+	return s, nil
+}
+
+type jsonlSource struct {
+	reader *bufio.Reader
+	closer io.Closer
+	header Header
+	line   int
+}
+
+func (s *jsonlSource) Header() Header {
+	return s.header
+}
+
+func (s *jsonlSource) Next() (*Case, error) {
+	line, err := s.readLine()
+	if err != nil {
+		return nil, err
+	}
+	var replayCase Case
+	if err := decodeLine(line, &replayCase); err != nil {
+		return nil, fmt.Errorf("line %d: decode replay case: %w", s.line, err)
+	}
+	return &replayCase, nil
+}
+
+func (s *jsonlSource) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// readLine returns the next non-blank line.
+func (s *jsonlSource) readLine() ([]byte, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			s.line++
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			return trimmed, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("read replay corpus: %w", err)
+		}
+	}
+}
+
+func decodeLine(line []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("multiple JSON values")
+	}
+	return nil
+}
+
+// fileSource names the file in the errors of the source it wraps.
+type fileSource struct {
+	Source
+	path string
+}
+
+func (s *fileSource) Next() (*Case, error) {
+	replayCase, err := s.Source.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("load replay corpus %q: %w", s.path, err)
+	}
+	return replayCase, err
+}
+
+func openFile(path string) (Source, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		corpus, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewSource(corpus), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay corpus: %w", err)
+	}
+	source, err := newJSONLSource(f, f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("load replay corpus %q: %w", path, err)
+	}
+	return &fileSource{Source: source, path: path}, nil
+}
+
+type dirSource struct {
+	header  Header
+	paths   []string
+	current Source
+}
+
+func openDir(dir string) (Source, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open replay corpus: %w", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".jsonl") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	slices.Sort(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("replay corpus directory %q has no .json or .jsonl files", dir)
+	}
+
+	first, err := openFile(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	return &dirSource{
+		header:  first.Header(),
+		paths:   paths[1:],
+		current: first,
+	}, nil
+}
+
+func (s *dirSource) Header() Header {
+	return s.header
+}
+
+func (s *dirSource) Next() (*Case, error) {
+	for s.current != nil {
+		replayCase, err := s.current.Next()
+		if !errors.Is(err, io.EOF) {
+			return replayCase, err
+		}
+		if err := s.current.Close(); err != nil {
+			return nil, err
+		}
+		s.current = nil
+		if len(s.paths) == 0 {
+			break
+		}
+
+		path := s.paths[0]
+		s.paths = s.paths[1:]
+		next, err := openFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if next.Header() != s.header {
+			next.Close()
+			return nil, fmt.Errorf(
+				"replay corpus %q: header %+v differs from %+v",
+				path,
+				next.Header(),
+				s.header,
+			)
+		}
+		s.current = next
+	}
+	return nil, io.EOF
+}
+
+func (s *dirSource) Close() error {
+	if s.current == nil {
+		return nil
+	}
+	err := s.current.Close()
+	s.current = nil
+	return err
+}
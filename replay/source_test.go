@@ -0,0 +1,220 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeJSONL(t *testing.T, path string, header Header, cases ...Case) {
+	t.Helper()
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	if err := encoder.Encode(header); err != nil {
+		t.Fatalf("encode header: %v", err)
+	}
+	for _, replayCase := range cases {
+		b.WriteString("\n")
+		if err := encoder.Encode(replayCase); err != nil {
+			t.Fatalf("encode case: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatalf("write corpus: %v", err)
+	}
+}
+
+func writeJSON(t *testing.T, path string, corpus *Corpus) {
+	t.Helper()
+	encoded, err := json.Marshal(corpus)
+	if err != nil {
+		t.Fatalf("encode corpus: %v", err)
+	}
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		t.Fatalf("write corpus: %v", err)
+	}
+}
+
+func numberedCases(t *testing.T, ids ...string) []Case {
+	t.Helper()
+	cases := make([]Case, 0, len(ids))
+	for _, id := range ids {
+		replayCase := successfulCase(t)
+		replayCase.ID = id
+		cases = append(cases, replayCase)
+	}
+	return cases
+}
+
+func sourceIDs(t *testing.T, path string) ([]string, error) {
+	t.Helper()
+	source, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	report, err := RunSource(context.Background(), source, Options{Jobs: 2})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(report.Cases))
+	for _, result := range report.Cases {
+		ids = append(ids, result.ID)
+	}
+	return ids, nil
+}
+
+func TestOpen(t *testing.T) {
+	header := validCorpus(Case{}).Header()
+	dir := t.TempDir()
+
+	jsonl := filepath.Join(dir, "corpus.jsonl")
+	writeJSONL(t, jsonl, header, numberedCases(t, "a", "b", "c")...)
+	ids, err := sourceIDs(t, jsonl)
+	if err != nil || strings.Join(ids, ",") != "a,b,c" {
+		t.Fatalf("JSON Lines corpus = %v, %v", ids, err)
+	}
+
+	corpusDir := filepath.Join(dir, "corpus")
+	if err := os.Mkdir(corpusDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	second := validCorpus(Case{})
+	second.Cases = numberedCases(t, "d")
+	writeJSON(t, filepath.Join(corpusDir, "2.json"), second)
+	writeJSONL(t, filepath.Join(corpusDir, "1.jsonl"), header, numberedCases(t, "a", "b")...)
+	writeJSONL(t, filepath.Join(corpusDir, "3.jsonl"), header)
+	if err := os.WriteFile(filepath.Join(corpusDir, "README.md"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ids, err = sourceIDs(t, corpusDir)
+	if err != nil || strings.Join(ids, ",") != "a,b,d" {
+		t.Fatalf("directory corpus = %v, %v", ids, err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	corpus := validCorpus(Case{})
+	corpus.Cases = numberedCases(t, "a", "b")
+	for _, name := range []string{"corpus.json", "corpus.jsonl"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := WriteFile(path, corpus); err != nil {
+			t.Fatalf("WriteFile(%s) failed: %v", name, err)
+		}
+		ids, err := sourceIDs(t, path)
+		if err != nil || strings.Join(ids, ",") != "a,b" {
+			t.Errorf("%s corpus = %v, %v", name, ids, err)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	header := validCorpus(Case{}).Header()
+	invalid := successfulCase(t)
+	invalid.ID = "invalid"
+	invalid.BudgetLimit = ExUnits{}
+	other := header
+	other.Network = "preprod"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"empty", map[string]string{"c.jsonl": "\n"}, "empty corpus"},
+		{"no cases", map[string]string{"c.jsonl": jsonLine(t, header)}, "at least one case"},
+		{"header", map[string]string{"c.jsonl": `{"schema_version": 1}`}, "line 1: replay corpus network is required"},
+		{
+			"unknown field",
+			map[string]string{"c.jsonl": jsonLine(t, header) + "\n" + `{"id": "a", "extra": 1}`},
+			`line 3: decode replay case: json: unknown field "extra"`,
+		},
+		{
+			"invalid case",
+			map[string]string{"c.jsonl": jsonLine(t, header) + jsonLine(t, successfulCase(t)) + jsonLine(t, invalid)},
+			"replay case 1: budget limit",
+		},
+		{
+			"duplicate id across files",
+			map[string]string{
+				"a.jsonl": jsonLine(t, header) + jsonLine(t, successfulCase(t)),
+				"b.jsonl": jsonLine(t, header) + jsonLine(t, successfulCase(t)),
+			},
+			"replay case 1: duplicate id",
+		},
+		{
+			"different headers",
+			map[string]string{
+				"a.jsonl": jsonLine(t, header) + jsonLine(t, successfulCase(t)),
+				"b.jsonl": jsonLine(t, other) + jsonLine(t, successfulCase(t)),
+			},
+			"differs from",
+		},
+		{"no corpus files", map[string]string{"notes.txt": ""}, "has no .json or .jsonl files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			path := dir
+			if len(tt.files) == 1 {
+				for name := range tt.files {
+					if strings.HasSuffix(name, ".jsonl") {
+						path = filepath.Join(dir, name)
+					}
+				}
+			}
+			_, err := sourceIDs(t, path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func jsonLine(t *testing.T, v any) string {
+	t.Helper()
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return string(encoded) + "\n"
+}
+
+// countingSource counts the cases read from it.
+type countingSource struct {
+	Source
+	reads int
+}
+
+func (s *countingSource) Next() (*Case, error) {
+	s.reads++
+	return s.Source.Next()
+}
+
+func TestRunSourceStopsAtInvalidCase(t *testing.T) {
+	corpus := validCorpus(Case{})
+	corpus.Cases = numberedCases(t, "0", "1", "2", "3", "4", "5", "6", "7")
+	corpus.Cases[1].BudgetLimit = ExUnits{}
+	source := &countingSource{Source: NewSource(corpus)}
+
+	_, err := RunSource(context.Background(), source, Options{})
+	if err == nil || !strings.HasPrefix(err.Error(), "replay case 1:") {
+		t.Fatalf("RunSource() error = %v, want replay case 1 error", err)
+	}
+	if source.reads > 3 {
+		t.Errorf("RunSource() read %d cases after an invalid one", source.reads-2)
+	}
+	if _, err := source.Next(); errors.Is(err, io.EOF) {
+		t.Error("RunSource() read the whole corpus")
+	}
+}
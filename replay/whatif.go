@@ -0,0 +1,257 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/blinklabs-io/plutigo/cek"
+)
+
+// Scenario replaces the evaluation settings of replay cases, such as with
+// the cost models of a proposed protocol parameter update.
+type Scenario struct {
+	// CostModels replaces the cost model of the cases of each language it
+	// holds.
+	CostModels map[Language][]int64 `json:"cost_models,omitempty"`
+	// ProtocolVersion, if set, replaces the protocol version of every case.
+	ProtocolVersion *ProtocolVersion `json:"protocol_version,omitempty"`
+	// MaxTxExUnits, if set, is the limit that the scripts of a transaction
+	// must fit in together.
+	MaxTxExUnits *ExUnits `json:"max_tx_ex_units,omitempty"`
+}
+
+// whatIfHeadroom multiplies the budget that what-if evaluations get over the
+// larger of the case's budget limit and the transaction limit, so that the
+// cost of a script that outgrows them can still be measured.
+const whatIfHeadroom = 4
+
+type WhatIfReport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Network       string        `json:"network"`
+	Reference     Reference     `json:"reference"`
+	Scenario      Scenario      `json:"scenario"`
+	Cases         []WhatIfCase  `json:"cases"`
+	Summary       WhatIfSummary `json:"summary"`
+}
+
+// WhatIfCase compares a case evaluated as recorded with its evaluation under
+// the scenario. The flags are only set for limits the scenario newly
+// exceeds. An evaluation that runs out of its budget, which has headroom over
+// the limits, reports only the ExUnits it spent before.
+type WhatIfCase struct {
+	ID                  string         `json:"id"`
+	Transaction         TransactionRef `json:"transaction"`
+	Baseline            Actual         `json:"baseline"`
+	Scenario            Actual         `json:"scenario"`
+	Delta               ExUnits        `json:"delta"`
+	ExceedsBudgetLimit  bool           `json:"exceeds_budget_limit,omitempty"`
+	ExceedsMaxTxExUnits bool           `json:"exceeds_max_tx_ex_units,omitempty"`
+}
+
+// WhatIfSummary totals a what-if report. ExceedBudgetLimit counts cases and
+// ExceedMaxTxExUnits counts transactions.
+type WhatIfSummary struct {
+	Total               int     `json:"total"`
+	Changed             int     `json:"changed"`
+	NewlyFailing        int     `json:"newly_failing"`
+	ExceedBudgetLimit   int     `json:"exceed_budget_limit"`
+	ExceedMaxTxExUnits  int     `json:"exceed_max_tx_ex_units"`
+	Baseline            ExUnits `json:"baseline"`
+	Scenario            ExUnits `json:"scenario"`
+	Delta               ExUnits `json:"delta"`
+	StepsChangePercent  float64 `json:"steps_change_percent"`
+	MemoryChangePercent float64 `json:"memory_change_percent"`
+}
+
+// Regressed reports whether the scenario makes a case fail or exceed a
+// limit that it met before.
+func (r *WhatIfReport) Regressed() bool {
+	return r.Summary.NewlyFailing > 0 ||
+		r.Summary.ExceedBudgetLimit > 0 ||
+		r.Summary.ExceedMaxTxExUnits > 0
+}
+
+// WhatIf evaluates every case of src as recorded and under scenario and
+// reports the differences. Expectations are not checked.
+func WhatIf(
+	ctx context.Context,
+	src Source,
+	scenario Scenario,
+	opts Options,
+) (*WhatIfReport, error) {
+	header := src.Header()
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	if scenario.ProtocolVersion != nil && scenario.ProtocolVersion.Major == 0 {
+		return nil, errors.New("scenario protocol major version must be positive")
+	}
+	for language := range scenario.CostModels {
+		if _, err := language.Version(); err != nil {
+			return nil, fmt.Errorf("scenario cost model: %w", err)
+		}
+	}
+
+	cases, err := runCases(
+		ctx,
+		src,
+		opts.Jobs,
+		func() func(*Case, decodedCase) WhatIfCase {
+			// Separate workers keep a machine for each side.
+			var baseline, substituted worker
+			return func(replayCase *Case, decoded decodedCase) WhatIfCase {
+				return whatIfCase(
+					&baseline,
+					&substituted,
+					replayCase,
+					decoded,
+					scenario,
+				)
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WhatIfReport{
+		SchemaVersion: header.SchemaVersion,
+		Network:       header.Network,
+		Reference:     header.Reference,
+		Scenario:      scenario,
+		Cases:         cases,
+	}
+	if scenario.MaxTxExUnits != nil {
+		flagTransactions(report.Cases, *scenario.MaxTxExUnits)
+	}
+	summary := &report.Summary
+	exceeding := make(map[string]struct{})
+	for _, c := range report.Cases {
+		summary.Total++
+		if c.Delta != (ExUnits{}) || c.Baseline.Success != c.Scenario.Success {
+			summary.Changed++
+		}
+		if c.Baseline.Success && !c.Scenario.Success {
+			summary.NewlyFailing++
+		}
+		if c.ExceedsBudgetLimit {
+			summary.ExceedBudgetLimit++
+		}
+		if c.ExceedsMaxTxExUnits {
+			exceeding[c.Transaction.ID] = struct{}{}
+		}
+		summary.Baseline = summary.Baseline.add(c.Baseline.ExUnits)
+		summary.Scenario = summary.Scenario.add(c.Scenario.ExUnits)
+	}
+	summary.ExceedMaxTxExUnits = len(exceeding)
+	summary.Delta = ExUnits{
+		Steps:  summary.Scenario.Steps - summary.Baseline.Steps,
+		Memory: summary.Scenario.Memory - summary.Baseline.Memory,
+	}
+	if summary.Baseline.Steps != 0 {
+		summary.StepsChangePercent = float64(summary.Delta.Steps) * 100 /
+			float64(summary.Baseline.Steps)
+	}
+	if summary.Baseline.Memory != 0 {
+		summary.MemoryChangePercent = float64(summary.Delta.Memory) * 100 /
+			float64(summary.Baseline.Memory)
+	}
+	return report, nil
+}
+
+func whatIfCase(
+	baseline, substituted *worker,
+	replayCase *Case,
+	decoded decodedCase,
+	scenario Scenario,
+) WhatIfCase {
+	budget := replayCase.BudgetLimit
+	if scenario.MaxTxExUnits != nil {
+		budget = ExUnits{
+			Steps:  max(budget.Steps, scenario.MaxTxExUnits.Steps),
+			Memory: max(budget.Memory, scenario.MaxTxExUnits.Memory),
+		}
+	}
+	budget = ExUnits{
+		Steps:  min(budget.Steps, math.MaxInt64/whatIfHeadroom) * whatIfHeadroom,
+		Memory: min(budget.Memory, math.MaxInt64/whatIfHeadroom) * whatIfHeadroom,
+	}
+
+	recorded := *replayCase
+	recorded.BudgetLimit = budget
+	modified := recorded
+	if scenario.ProtocolVersion != nil {
+		modified.ProtocolVersion = *scenario.ProtocolVersion
+	}
+	if params, ok := scenario.CostModels[replayCase.Language]; ok {
+		modified.CostModel = CostModel{Parameters: params}
+	}
+
+	result := WhatIfCase{
+		ID:          replayCase.ID,
+		Transaction: replayCase.Transaction,
+		Baseline:    baseline.actual(&recorded, decoded),
+		Scenario:    substituted.actual(&modified, decoded),
+	}
+	result.Delta = ExUnits{
+		Steps:  result.Scenario.ExUnits.Steps - result.Baseline.ExUnits.Steps,
+		Memory: result.Scenario.ExUnits.Memory - result.Baseline.ExUnits.Memory,
+	}
+	result.ExceedsBudgetLimit = exceeds(result.Scenario, replayCase.BudgetLimit) &&
+		!exceeds(result.Baseline, replayCase.BudgetLimit)
+	return result
+}
+
+// flagTransactions flags the cases of transactions whose scripts together
+// newly exceed limit.
+func flagTransactions(cases []WhatIfCase, limit ExUnits) {
+	type totals struct {
+		baseline, scenario ExUnits
+		exhausted          bool
+	}
+	transactions := make(map[string]*totals)
+	for _, c := range cases {
+		t, ok := transactions[c.Transaction.ID]
+		if !ok {
+			t = &totals{}
+			transactions[c.Transaction.ID] = t
+		}
+		t.baseline = t.baseline.add(c.Baseline.ExUnits)
+		t.scenario = t.scenario.add(c.Scenario.ExUnits)
+		t.exhausted = t.exhausted || budgetExhausted(c.Scenario)
+	}
+	for i := range cases {
+		t := transactions[cases[i].Transaction.ID]
+		cases[i].ExceedsMaxTxExUnits = t.baseline.within(limit) &&
+			(t.exhausted || !t.scenario.within(limit))
+	}
+}
+
+// exceeds reports whether an evaluation went beyond limit, counting the
+// exhaustion of the headroom budget.
+func exceeds(actual Actual, limit ExUnits) bool {
+	return budgetExhausted(actual) || !actual.ExUnits.within(limit)
+}
+
+func budgetExhausted(actual Actual) bool {
+	if actual.ErrorCode == nil {
+		return false
+	}
+	switch *actual.ErrorCode {
+	case cek.ErrCodeBudgetExhausted,
+		cek.ErrCodeMemoryExhausted,
+		cek.ErrCodeCPUExhausted:
+		return true
+	}
+	return false
+}
+
+func (u ExUnits) add(other ExUnits) ExUnits {
+	return ExUnits{Steps: u.Steps + other.Steps, Memory: u.Memory + other.Memory}
+}
+
+func (u ExUnits) within(limit ExUnits) bool {
+	return u.Steps <= limit.Steps && u.Memory <= limit.Memory
+}
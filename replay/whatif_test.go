@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/lang"
+)
+
+// costlyModel returns a PlutusV1 cost model whose machine steps cost cpu
+// and memory each. Builtin costs are left at one, which the test scripts do
+// not use.
+func costlyModel(cpu, memory int64) []int64 {
+	names := lang.GetParamNamesForVersion(lang.LanguageVersionV1)
+	params := make([]int64, len(names))
+	for i, name := range names {
+		switch {
+		case strings.HasSuffix(name, "-exBudgetCPU"):
+			params[i] = cpu
+		case strings.HasSuffix(name, "-exBudgetMemory"):
+			params[i] = memory
+		default:
+			params[i] = 1
+		}
+	}
+	return params
+}
+
+func TestWhatIf(t *testing.T) {
+	first := successfulCase(t)
+	baseline := RunCase(&first).Actual.ExUnits
+	first.BudgetLimit = baseline
+	second := successfulCase(t)
+	second.ID = "tx-1#spend:1"
+	third := successfulCase(t)
+	third.ID = "tx-2#spend:0"
+	third.Transaction.ID = "tx-2"
+	corpus := validCorpus(first)
+	corpus.Cases = append(corpus.Cases, second, third)
+
+	scenario := Scenario{
+		// Half as much again as the default machine costs.
+		CostModels:      map[Language][]int64{PlutusV1: costlyModel(24_000, 150)},
+		ProtocolVersion: &ProtocolVersion{Major: 10, Minor: 1},
+		// Enough for the two scripts of tx-1 as recorded, but not after.
+		MaxTxExUnits: &ExUnits{Steps: baseline.Steps * 2, Memory: baseline.Memory * 2},
+	}
+	report, err := WhatIf(context.Background(), NewSource(corpus), scenario, Options{})
+	if err != nil {
+		t.Fatalf("WhatIf() failed: %v", err)
+	}
+	if len(report.Cases) != 3 || report.Network != "mainnet" {
+		t.Fatalf("WhatIf() report = %+v", report)
+	}
+	for _, c := range report.Cases {
+		if c.Baseline.ExUnits != baseline || !c.Baseline.Success ||
+			!c.Scenario.Success {
+			t.Errorf("case %s = %+v", c.ID, c)
+		}
+		if c.Delta.Steps <= 0 || c.Delta.Memory <= 0 {
+			t.Errorf("case %s delta = %+v, want an increase", c.ID, c.Delta)
+		}
+		if c.ExceedsMaxTxExUnits != (c.Transaction.ID == "tx-1") {
+			t.Errorf("case %s exceeds transaction limit = %v", c.ID, c.ExceedsMaxTxExUnits)
+		}
+		if c.ExceedsBudgetLimit != (c.ID == first.ID) {
+			t.Errorf("case %s exceeds budget limit = %v", c.ID, c.ExceedsBudgetLimit)
+		}
+	}
+
+	summary := report.Summary
+	if summary.Total != 3 || summary.Changed != 3 || summary.NewlyFailing != 0 ||
+		summary.ExceedBudgetLimit != 1 || summary.ExceedMaxTxExUnits != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if summary.Baseline.Steps != 3*baseline.Steps || summary.StepsChangePercent <= 0 {
+		t.Errorf("summary totals = %+v", summary)
+	}
+	if !report.Regressed() {
+		t.Error("Regressed() = false")
+	}
+}
+
+func TestWhatIfUnchangedScenario(t *testing.T) {
+	replayCase := successfulCase(t)
+	report, err := WhatIf(
+		context.Background(),
+		NewSource(validCorpus(replayCase)),
+		Scenario{ProtocolVersion: &replayCase.ProtocolVersion},
+		Options{Jobs: 2},
+	)
+	if err != nil {
+		t.Fatalf("WhatIf() failed: %v", err)
+	}
+	if report.Summary.Changed != 0 || report.Regressed() ||
+		report.Cases[0].Delta != (ExUnits{}) {
+		t.Errorf("WhatIf() = %+v", report)
+	}
+}
+
+func TestWhatIfMeasuresBeyondLimits(t *testing.T) {
+	replayCase := tracingCase(t)
+	recorded := replayCase.Expected.ExUnits
+	replayCase.BudgetLimit = recorded
+	limit := recorded
+	report, err := WhatIf(
+		context.Background(),
+		NewSource(validCorpus(replayCase)),
+		Scenario{
+			CostModels:   map[Language][]int64{PlutusV1: costlyModel(200_000, 200_000)},
+			MaxTxExUnits: &limit,
+		},
+		Options{},
+	)
+	if err != nil {
+		t.Fatalf("WhatIf() failed: %v", err)
+	}
+	c := report.Cases[0]
+	if !c.ExceedsBudgetLimit || !c.ExceedsMaxTxExUnits {
+		t.Errorf("case flags = %+v", c)
+	}
+	if report.Summary.NewlyFailing != 1 || c.Scenario.ErrorCode == nil {
+		t.Errorf("scenario past the headroom = %+v", c.Scenario)
+	}
+}
+
+func TestWhatIfRejectsInvalidScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario Scenario
+		want     string
+	}{
+		{
+			"protocol version",
+			Scenario{ProtocolVersion: &ProtocolVersion{}},
+			"scenario protocol major version must be positive",
+		},
+		{
+			"language",
+			Scenario{CostModels: map[Language][]int64{"PlutusV9": {1}}},
+			"scenario cost model",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WhatIf(
+				context.Background(),
+				NewSource(validCorpus(successfulCase(t))),
+				tt.scenario,
+				Options{},
+			)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("WhatIf() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,576 @@
+package scriptcontext
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// CredentialType distinguishes key and script credentials.
+type CredentialType uint8
+
+const (
+	PubKeyCredential CredentialType = iota
+	ScriptCredential
+)
+
+func (t CredentialType) String() string {
+	switch t {
+	case PubKeyCredential:
+		return "PubKeyCredential"
+	case ScriptCredential:
+		return "ScriptCredential"
+	default:
+		return fmt.Sprintf("CredentialType(%d)", uint8(t))
+	}
+}
+
+// Credential is a payment or stake credential: a key hash or a script hash.
+type Credential struct {
+	Type CredentialType
+	Hash []byte
+}
+
+func (c Credential) ToData() data.PlutusData {
+	return data.NewConstr(uint(c.Type), data.NewByteString(c.Hash))
+}
+
+func CredentialFromData(pd data.PlutusData) (Credential, error) {
+	tag, fields, err := variantOf(pd, "Credential", []int{1, 1})
+	if err != nil {
+		return Credential{}, err
+	}
+	hash, err := bytesOf(fields[0], "Credential hash")
+	if err != nil {
+		return Credential{}, err
+	}
+	return Credential{Type: CredentialType(tag), Hash: hash}, nil
+}
+
+// StakingCredential is either a StakingHash or a StakingPtr.
+type StakingCredential interface {
+	ToData() data.PlutusData
+	isStakingCredential()
+}
+
+// StakingHash identifies a stake address by its credential.
+type StakingHash struct {
+	Credential Credential
+}
+
+func (StakingHash) isStakingCredential() {}
+
+func (s StakingHash) ToData() data.PlutusData {
+	return data.NewConstr(0, s.Credential.ToData())
+}
+
+// StakingPtr identifies a stake address by the position of its registration
+// certificate.
+type StakingPtr struct {
+	Slot      uint64
+	TxIndex   uint64
+	CertIndex uint64
+}
+
+func (StakingPtr) isStakingCredential() {}
+
+func (s StakingPtr) ToData() data.PlutusData {
+	return data.NewConstr(
+		1,
+		uint64Data(s.Slot),
+		uint64Data(s.TxIndex),
+		uint64Data(s.CertIndex),
+	)
+}
+
+func StakingCredentialFromData(pd data.PlutusData) (StakingCredential, error) {
+	tag, fields, err := variantOf(pd, "StakingCredential", []int{1, 3})
+	if err != nil {
+		return nil, err
+	}
+	if tag == 0 {
+		cred, err := CredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return StakingHash{Credential: cred}, nil
+	}
+	var ptr StakingPtr
+	for i, dst := range []*uint64{&ptr.Slot, &ptr.TxIndex, &ptr.CertIndex} {
+		v, err := uint64Of(fields[i], "StakingPtr")
+		if err != nil {
+			return nil, err
+		}
+		*dst = v
+	}
+	return ptr, nil
+}
+
+// Address is a payment credential with an optional stake credential.
+type Address struct {
+	Credential        Credential
+	StakingCredential StakingCredential // nil for enterprise addresses
+}
+
+func (a Address) ToData() data.PlutusData {
+	var staking data.PlutusData
+	if a.StakingCredential != nil {
+		staking = a.StakingCredential.ToData()
+	}
+	return data.NewConstr(0, a.Credential.ToData(), maybeToData(staking))
+}
+
+func AddressFromData(pd data.PlutusData) (Address, error) {
+	fields, err := fieldsOf(pd, "Address", 0, 2)
+	if err != nil {
+		return Address{}, err
+	}
+	cred, err := CredentialFromData(fields[0])
+	if err != nil {
+		return Address{}, err
+	}
+	addr := Address{Credential: cred}
+	staking, err := maybeFromData(fields[1], "Address staking credential")
+	if err != nil {
+		return Address{}, err
+	}
+	if staking != nil {
+		addr.StakingCredential, err = StakingCredentialFromData(staking)
+		if err != nil {
+			return Address{}, err
+		}
+	}
+	return addr, nil
+}
+
+// Asset is a quantity of one token.
+type Asset struct {
+	Name     []byte
+	Quantity *big.Int
+}
+
+// PolicyAssets holds the tokens of one minting policy. Ada uses the empty
+// policy ID and the empty token name.
+type PolicyAssets struct {
+	Policy []byte
+	Assets []Asset
+}
+
+// Value is a multi-asset value. Entries keep the order they are encoded in,
+// so decoding and re-encoding a value is lossless.
+type Value []PolicyAssets
+
+// Lovelace returns a Value holding only the given amount of ada.
+func Lovelace(amount *big.Int) Value {
+	return Value{{
+		Policy: []byte{},
+		Assets: []Asset{{Name: []byte{}, Quantity: amount}},
+	}}
+}
+
+// Lovelace returns the ada quantity of v, or zero if v holds no ada.
+func (v Value) Lovelace() *big.Int {
+	return v.Quantity(nil, nil)
+}
+
+// Quantity returns the quantity of the given token in v.
+func (v Value) Quantity(policy []byte, name []byte) *big.Int {
+	total := new(big.Int)
+	for _, entry := range v {
+		if string(entry.Policy) != string(policy) {
+			continue
+		}
+		for _, asset := range entry.Assets {
+			if string(asset.Name) == string(name) && asset.Quantity != nil {
+				total.Add(total, asset.Quantity)
+			}
+		}
+	}
+	return total
+}
+
+func (v Value) ToData() data.PlutusData {
+	return mapToData(v, func(entry PolicyAssets) (data.PlutusData, data.PlutusData) {
+		assets := mapToData(entry.Assets, func(a Asset) (data.PlutusData, data.PlutusData) {
+			return data.NewByteString(a.Name), integerData(a.Quantity)
+		})
+		return data.NewByteString(entry.Policy), assets
+	})
+}
+
+func ValueFromData(pd data.PlutusData) (Value, error) {
+	return mapFromData(pd, "Value", func(k, v data.PlutusData) (PolicyAssets, error) {
+		policy, err := bytesOf(k, "Value policy")
+		if err != nil {
+			return PolicyAssets{}, err
+		}
+		assets, err := mapFromData(v, "Value assets", func(k, v data.PlutusData) (Asset, error) {
+			name, err := bytesOf(k, "Value token name")
+			if err != nil {
+				return Asset{}, err
+			}
+			quantity, err := integerOf(v, "Value quantity")
+			if err != nil {
+				return Asset{}, err
+			}
+			return Asset{Name: name, Quantity: quantity}, nil
+		})
+		if err != nil {
+			return PolicyAssets{}, err
+		}
+		return PolicyAssets{Policy: policy, Assets: assets}, nil
+	})
+}
+
+// ExtendedKind is the kind of an interval bound.
+type ExtendedKind uint8
+
+const (
+	NegInf ExtendedKind = iota
+	Finite
+	PosInf
+)
+
+// IntervalBound is one end of an Interval. Time is only used for Finite
+// bounds and is in POSIX milliseconds.
+type IntervalBound struct {
+	Kind   ExtendedKind
+	Time   *big.Int
+	Closed bool
+}
+
+func (b IntervalBound) ToData() data.PlutusData {
+	var extended data.PlutusData
+	if b.Kind == Finite {
+		extended = data.NewConstr(uint(Finite), integerData(b.Time))
+	} else {
+		extended = data.NewConstr(uint(b.Kind))
+	}
+	return data.NewConstr(0, extended, boolToData(b.Closed))
+}
+
+func IntervalBoundFromData(pd data.PlutusData) (IntervalBound, error) {
+	fields, err := fieldsOf(pd, "IntervalBound", 0, 2)
+	if err != nil {
+		return IntervalBound{}, err
+	}
+	tag, extended, err := variantOf(fields[0], "Extended", []int{0, 1, 0})
+	if err != nil {
+		return IntervalBound{}, err
+	}
+	bound := IntervalBound{Kind: ExtendedKind(tag)}
+	if bound.Kind == Finite {
+		bound.Time, err = integerOf(extended[0], "Extended time")
+		if err != nil {
+			return IntervalBound{}, err
+		}
+	}
+	bound.Closed, err = boolFromData(fields[1], "IntervalBound closure")
+	if err != nil {
+		return IntervalBound{}, err
+	}
+	return bound, nil
+}
+
+// Interval is a POSIX time range, such as a transaction validity range.
+type Interval struct {
+	Lower IntervalBound
+	Upper IntervalBound
+}
+
+// Always returns the interval covering all time, used for transactions
+// without a validity range.
+func Always() Interval {
+	return Interval{
+		Lower: IntervalBound{Kind: NegInf, Closed: true},
+		Upper: IntervalBound{Kind: PosInf, Closed: true},
+	}
+}
+
+func (i Interval) ToData() data.PlutusData {
+	return data.NewConstr(0, i.Lower.ToData(), i.Upper.ToData())
+}
+
+func IntervalFromData(pd data.PlutusData) (Interval, error) {
+	fields, err := fieldsOf(pd, "Interval", 0, 2)
+	if err != nil {
+		return Interval{}, err
+	}
+	lower, err := IntervalBoundFromData(fields[0])
+	if err != nil {
+		return Interval{}, fmt.Errorf("lower bound: %w", err)
+	}
+	upper, err := IntervalBoundFromData(fields[1])
+	if err != nil {
+		return Interval{}, fmt.Errorf("upper bound: %w", err)
+	}
+	return Interval{Lower: lower, Upper: upper}, nil
+}
+
+// TxOutRef references a transaction output. Its encoding differs between
+// V1/V2, where the transaction ID is wrapped in a constructor, and V3.
+type TxOutRef struct {
+	TxID  []byte
+	Index uint64
+}
+
+func (r TxOutRef) toDataV1() data.PlutusData {
+	return data.NewConstr(
+		0,
+		data.NewConstr(0, data.NewByteString(r.TxID)),
+		uint64Data(r.Index),
+	)
+}
+
+func (r TxOutRef) toData() data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(r.TxID), uint64Data(r.Index))
+}
+
+func txOutRefV1FromData(pd data.PlutusData) (TxOutRef, error) {
+	fields, err := fieldsOf(pd, "TxOutRef", 0, 2)
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	txID, err := txIDV1FromData(fields[0])
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	index, err := uint64Of(fields[1], "TxOutRef index")
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	return TxOutRef{TxID: txID, Index: index}, nil
+}
+
+func txOutRefFromData(pd data.PlutusData) (TxOutRef, error) {
+	fields, err := fieldsOf(pd, "TxOutRef", 0, 2)
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	txID, err := bytesOf(fields[0], "TxOutRef id")
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	index, err := uint64Of(fields[1], "TxOutRef index")
+	if err != nil {
+		return TxOutRef{}, err
+	}
+	return TxOutRef{TxID: txID, Index: index}, nil
+}
+
+func txIDV1ToData(id []byte) data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(id))
+}
+
+func txIDV1FromData(pd data.PlutusData) ([]byte, error) {
+	fields, err := fieldsOf(pd, "TxId", 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	return bytesOf(fields[0], "TxId")
+}
+
+// OutputDatum is the datum attached to a V2 or V3 transaction output:
+// NoOutputDatum, OutputDatumHash or InlineDatum.
+type OutputDatum interface {
+	ToData() data.PlutusData
+	isOutputDatum()
+}
+
+type NoOutputDatum struct{}
+
+func (NoOutputDatum) isOutputDatum() {}
+
+func (NoOutputDatum) ToData() data.PlutusData {
+	return data.NewConstr(0)
+}
+
+type OutputDatumHash struct {
+	Hash []byte
+}
+
+func (OutputDatumHash) isOutputDatum() {}
+
+func (d OutputDatumHash) ToData() data.PlutusData {
+	return data.NewConstr(1, data.NewByteString(d.Hash))
+}
+
+type InlineDatum struct {
+	Datum data.PlutusData
+}
+
+func (InlineDatum) isOutputDatum() {}
+
+func (d InlineDatum) ToData() data.PlutusData {
+	return data.NewConstr(2, d.Datum)
+}
+
+func OutputDatumFromData(pd data.PlutusData) (OutputDatum, error) {
+	tag, fields, err := variantOf(pd, "OutputDatum", []int{0, 1, 1})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		return NoOutputDatum{}, nil
+	case 1:
+		hash, err := bytesOf(fields[0], "OutputDatumHash")
+		if err != nil {
+			return nil, err
+		}
+		return OutputDatumHash{Hash: hash}, nil
+	default:
+		return InlineDatum{Datum: fields[0]}, nil
+	}
+}
+
+// TxOut is a V2 or V3 transaction output.
+type TxOut struct {
+	Address         Address
+	Value           Value
+	Datum           OutputDatum // nil encodes as NoOutputDatum
+	ReferenceScript []byte      // script hash, nil when absent
+}
+
+func (o TxOut) ToData() data.PlutusData {
+	datum := o.Datum
+	if datum == nil {
+		datum = NoOutputDatum{}
+	}
+	return data.NewConstr(
+		0,
+		o.Address.ToData(),
+		o.Value.ToData(),
+		datum.ToData(),
+		maybeBytesToData(o.ReferenceScript),
+	)
+}
+
+func TxOutFromData(pd data.PlutusData) (TxOut, error) {
+	fields, err := fieldsOf(pd, "TxOut", 0, 4)
+	if err != nil {
+		return TxOut{}, err
+	}
+	var out TxOut
+	if out.Address, err = AddressFromData(fields[0]); err != nil {
+		return TxOut{}, err
+	}
+	if out.Value, err = ValueFromData(fields[1]); err != nil {
+		return TxOut{}, err
+	}
+	if out.Datum, err = OutputDatumFromData(fields[2]); err != nil {
+		return TxOut{}, err
+	}
+	if out.ReferenceScript, err = maybeBytesFromData(fields[3], "TxOut reference script"); err != nil {
+		return TxOut{}, err
+	}
+	return out, nil
+}
+
+// TxOutV1 is a V1 transaction output, which can only carry a datum hash.
+type TxOutV1 struct {
+	Address   Address
+	Value     Value
+	DatumHash []byte // nil when absent
+}
+
+func (o TxOutV1) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		o.Address.ToData(),
+		o.Value.ToData(),
+		maybeBytesToData(o.DatumHash),
+	)
+}
+
+func TxOutV1FromData(pd data.PlutusData) (TxOutV1, error) {
+	fields, err := fieldsOf(pd, "TxOut", 0, 3)
+	if err != nil {
+		return TxOutV1{}, err
+	}
+	var out TxOutV1
+	if out.Address, err = AddressFromData(fields[0]); err != nil {
+		return TxOutV1{}, err
+	}
+	if out.Value, err = ValueFromData(fields[1]); err != nil {
+		return TxOutV1{}, err
+	}
+	if out.DatumHash, err = maybeBytesFromData(fields[2], "TxOut datum hash"); err != nil {
+		return TxOutV1{}, err
+	}
+	return out, nil
+}
+
+// TxInInfo is a resolved V2 or V3 transaction input.
+type TxInInfo struct {
+	OutRef   TxOutRef
+	Resolved TxOut
+}
+
+func (in TxInInfo) toDataV2() data.PlutusData {
+	return data.NewConstr(0, in.OutRef.toDataV1(), in.Resolved.ToData())
+}
+
+func (in TxInInfo) toDataV3() data.PlutusData {
+	return data.NewConstr(0, in.OutRef.toData(), in.Resolved.ToData())
+}
+
+func txInInfoV2FromData(pd data.PlutusData) (TxInInfo, error) {
+	return txInInfoFromData(pd, txOutRefV1FromData)
+}
+
+func txInInfoV3FromData(pd data.PlutusData) (TxInInfo, error) {
+	return txInInfoFromData(pd, txOutRefFromData)
+}
+
+func txInInfoFromData(
+	pd data.PlutusData,
+	outRefFromData func(data.PlutusData) (TxOutRef, error),
+) (TxInInfo, error) {
+	fields, err := fieldsOf(pd, "TxInInfo", 0, 2)
+	if err != nil {
+		return TxInInfo{}, err
+	}
+	outRef, err := outRefFromData(fields[0])
+	if err != nil {
+		return TxInInfo{}, err
+	}
+	resolved, err := TxOutFromData(fields[1])
+	if err != nil {
+		return TxInInfo{}, err
+	}
+	return TxInInfo{OutRef: outRef, Resolved: resolved}, nil
+}
+
+// TxInInfoV1 is a resolved V1 transaction input.
+type TxInInfoV1 struct {
+	OutRef   TxOutRef
+	Resolved TxOutV1
+}
+
+func (in TxInInfoV1) ToData() data.PlutusData {
+	return data.NewConstr(0, in.OutRef.toDataV1(), in.Resolved.ToData())
+}
+
+func TxInInfoV1FromData(pd data.PlutusData) (TxInInfoV1, error) {
+	fields, err := fieldsOf(pd, "TxInInfo", 0, 2)
+	if err != nil {
+		return TxInInfoV1{}, err
+	}
+	outRef, err := txOutRefV1FromData(fields[0])
+	if err != nil {
+		return TxInInfoV1{}, err
+	}
+	resolved, err := TxOutV1FromData(fields[1])
+	if err != nil {
+		return TxInInfoV1{}, err
+	}
+	return TxInInfoV1{OutRef: outRef, Resolved: resolved}, nil
+}
+
+// DatumEntry is a datum witnessed by a transaction, keyed by its hash.
+type DatumEntry struct {
+	Hash  []byte
+	Datum data.PlutusData
+}
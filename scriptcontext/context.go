@@ -0,0 +1,43 @@
+package scriptcontext
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// ScriptContext is a ScriptContextV1, ScriptContextV2 or ScriptContextV3.
+type ScriptContext interface {
+	ToData() data.PlutusData
+	isScriptContext()
+}
+
+func (ScriptContextV1) isScriptContext() {}
+func (ScriptContextV2) isScriptContext() {}
+func (ScriptContextV3) isScriptContext() {}
+
+// Decode decodes the ScriptContext argument passed to a script of the given
+// ledger language, which is the last script argument in every version.
+func Decode(version builtin.PlutusVersion, pd data.PlutusData) (ScriptContext, error) {
+	switch version {
+	case builtin.PlutusV1:
+		return ScriptContextV1FromData(pd)
+	case builtin.PlutusV2:
+		return ScriptContextV2FromData(pd)
+	case builtin.PlutusV3:
+		return ScriptContextV3FromData(pd)
+	default:
+		return nil, fmt.Errorf("no ScriptContext encoding for Plutus version %d", version)
+	}
+}
+
+// DecodeCBOR decodes a CBOR-encoded ScriptContext, such as the last entry of
+// a replay case's arguments_cbor_hex.
+func DecodeCBOR(version builtin.PlutusVersion, b []byte) (ScriptContext, error) {
+	pd, err := data.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(version, pd)
+}
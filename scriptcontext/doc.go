@@ -0,0 +1,58 @@
+// Package scriptcontext provides Go types for the ledger ScriptContext
+// passed to Plutus validators, with lossless conversion to and from
+// PlutusData.
+//
+// The context shape depends on the ledger language:
+//
+//   - PlutusV1: ScriptContext(TxInfo, ScriptPurpose), see [ScriptContextV1]
+//   - PlutusV2: ScriptContext(TxInfo, ScriptPurpose), see [ScriptContextV2]
+//   - PlutusV3: ScriptContext(TxInfo, Redeemer, ScriptInfo), see [ScriptContextV3]
+//
+// # Naming
+//
+// Types without a suffix use the current (V3) encoding. Types that only
+// exist in the V1/V2 encoding carry a V1 suffix and are shared by V1 and V2,
+// mirroring the Haskell ledger API, where V2 re-exports most of V1:
+// [TxOutV1] can only hold a datum hash, [ScriptPurposeV1] certifies [DCert]
+// certificates and rewards a [StakingCredential], and so on. [Minting],
+// [Credential], [Address], [Value] and [Interval] encode identically in
+// every version.
+//
+// [TxOutRef] and [TxInInfo] are shared Go types whose encoding differs
+// between V2 and V3 (V1/V2 wrap the transaction ID in a constructor); the
+// enclosing TxInfo picks the right encoding.
+//
+// # Building and Decoding
+//
+// Every type has a ToData method, and every type that can appear on its own
+// has a matching FromData function:
+//
+//	ctx := scriptcontext.ScriptContextV3{
+//	    TxInfo:   txInfo,
+//	    Redeemer: redeemer,
+//	    Info:     scriptcontext.SpendingScript{OutRef: ref, Datum: datum},
+//	}
+//	arg := ctx.ToData()
+//
+//	decoded, err := scriptcontext.ScriptContextV3FromData(arg)
+//
+// Maps such as Value, withdrawals and redeemers are represented as slices so
+// that entry order survives a round trip. Builders reproducing ledger
+// contexts must follow the ledger's own conventions, such as the sorted map
+// keys and, for V1/V2, the zero-ada entry in Mint.
+//
+// # Debugging
+//
+// [Decode] and [DecodeCBOR] pick the decoder for a ledger language, which
+// makes it easy to inspect the last argument of a failing script:
+//
+//	ctx, err := scriptcontext.DecodeCBOR(builtin.PlutusV3, argCBOR)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(scriptcontext.Summary(ctx))
+//
+// [Summary] lists the purpose, validity range, inputs, outputs, minted value,
+// signatories, certificates, withdrawals, redeemers, datums and governance
+// fields of a context, one item per line.
+package scriptcontext
@@ -0,0 +1,328 @@
+package scriptcontext
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// force materializes Lazy values so the helpers below can type-switch on
+// the concrete PlutusData types.
+func force(pd data.PlutusData) (data.PlutusData, error) {
+	if l, ok := pd.(*data.Lazy); ok {
+		return l.Force()
+	}
+	return pd, nil
+}
+
+func constrOf(pd data.PlutusData, name string) (*data.Constr, error) {
+	v, err := force(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	c, ok := v.(*data.Constr)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected Constr, got %s", name, describe(v))
+	}
+	return c, nil
+}
+
+// fieldsOf returns the fields of a Constr with the given tag and arity.
+func fieldsOf(
+	pd data.PlutusData,
+	name string,
+	tag uint,
+	arity int,
+) ([]data.PlutusData, error) {
+	c, err := constrOf(pd, name)
+	if err != nil {
+		return nil, err
+	}
+	if c.Tag != tag {
+		return nil, fmt.Errorf("%s: expected constructor %d, got %d", name, tag, c.Tag)
+	}
+	if len(c.Fields) != arity {
+		return nil, fmt.Errorf(
+			"%s: expected %d fields, got %d",
+			name,
+			arity,
+			len(c.Fields),
+		)
+	}
+	return c.Fields, nil
+}
+
+// variantOf returns the fields of a Constr after checking the arity expected
+// for its tag.
+func variantOf(
+	pd data.PlutusData,
+	name string,
+	arities []int,
+) (uint, []data.PlutusData, error) {
+	c, err := constrOf(pd, name)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.Tag >= uint(len(arities)) {
+		return 0, nil, fmt.Errorf("%s: unknown constructor %d", name, c.Tag)
+	}
+	if len(c.Fields) != arities[c.Tag] {
+		return 0, nil, fmt.Errorf(
+			"%s: constructor %d expects %d fields, got %d",
+			name,
+			c.Tag,
+			arities[c.Tag],
+			len(c.Fields),
+		)
+	}
+	return c.Tag, c.Fields, nil
+}
+
+func bytesOf(pd data.PlutusData, name string) ([]byte, error) {
+	v, err := force(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	b, ok := v.(*data.ByteString)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected ByteString, got %s", name, describe(v))
+	}
+	return b.Inner, nil
+}
+
+func integerOf(pd data.PlutusData, name string) (*big.Int, error) {
+	v, err := force(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	i, ok := v.(*data.Integer)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected Integer, got %s", name, describe(v))
+	}
+	return new(big.Int).Set(i.Inner), nil
+}
+
+func uint64Of(pd data.PlutusData, name string) (uint64, error) {
+	i, err := integerOf(pd, name)
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("%s: integer %s out of range", name, i)
+	}
+	return i.Uint64(), nil
+}
+
+func listOf(pd data.PlutusData, name string) ([]data.PlutusData, error) {
+	v, err := force(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	l, ok := v.(*data.List)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected List, got %s", name, describe(v))
+	}
+	return l.Items, nil
+}
+
+func mapOf(pd data.PlutusData, name string) ([][2]data.PlutusData, error) {
+	v, err := force(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	m, ok := v.(*data.Map)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected Map, got %s", name, describe(v))
+	}
+	return m.Pairs, nil
+}
+
+func describe(pd data.PlutusData) string {
+	switch v := pd.(type) {
+	case *data.Constr:
+		return fmt.Sprintf("Constr %d", v.Tag)
+	case *data.Map:
+		return "Map"
+	case *data.List:
+		return "List"
+	case *data.Integer:
+		return "Integer"
+	case *data.ByteString:
+		return "ByteString"
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%T", pd)
+	}
+}
+
+func integerData(i *big.Int) data.PlutusData {
+	if i == nil {
+		return data.NewInteger(new(big.Int))
+	}
+	return data.NewInteger(i)
+}
+
+func uint64Data(i uint64) data.PlutusData {
+	return data.NewInteger(new(big.Int).SetUint64(i))
+}
+
+func boolToData(b bool) data.PlutusData {
+	if b {
+		return data.NewConstr(1)
+	}
+	return data.NewConstr(0)
+}
+
+func boolFromData(pd data.PlutusData, name string) (bool, error) {
+	tag, _, err := variantOf(pd, name, []int{0, 0})
+	if err != nil {
+		return false, err
+	}
+	return tag == 1, nil
+}
+
+// maybeToData encodes Just pd, or Nothing when pd is nil.
+func maybeToData(pd data.PlutusData) data.PlutusData {
+	if pd == nil {
+		return data.NewConstr(1)
+	}
+	return data.NewConstr(0, pd)
+}
+
+// maybeFromData returns the payload of Just, or nil for Nothing.
+func maybeFromData(pd data.PlutusData, name string) (data.PlutusData, error) {
+	tag, fields, err := variantOf(pd, name, []int{1, 0})
+	if err != nil {
+		return nil, err
+	}
+	if tag == 1 {
+		return nil, nil
+	}
+	return fields[0], nil
+}
+
+func maybeBytesToData(b []byte) data.PlutusData {
+	if b == nil {
+		return maybeToData(nil)
+	}
+	return maybeToData(data.NewByteString(b))
+}
+
+func maybeBytesFromData(pd data.PlutusData, name string) ([]byte, error) {
+	inner, err := maybeFromData(pd, name)
+	if err != nil || inner == nil {
+		return nil, err
+	}
+	b, err := bytesOf(inner, name)
+	if err != nil {
+		return nil, err
+	}
+	// Just an empty hash is distinct from Nothing.
+	if b == nil {
+		b = []byte{}
+	}
+	return b, nil
+}
+
+func maybeIntegerToData(i *big.Int) data.PlutusData {
+	if i == nil {
+		return maybeToData(nil)
+	}
+	return maybeToData(data.NewInteger(i))
+}
+
+func maybeIntegerFromData(pd data.PlutusData, name string) (*big.Int, error) {
+	inner, err := maybeFromData(pd, name)
+	if err != nil || inner == nil {
+		return nil, err
+	}
+	return integerOf(inner, name)
+}
+
+func listToData[T any](items []T, f func(T) data.PlutusData) data.PlutusData {
+	out := make([]data.PlutusData, len(items))
+	for i, item := range items {
+		out[i] = f(item)
+	}
+	return data.NewList(out...)
+}
+
+func listFromData[T any](
+	pd data.PlutusData,
+	name string,
+	f func(data.PlutusData) (T, error),
+) ([]T, error) {
+	items, err := listOf(pd, name)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(items))
+	for i, item := range items {
+		v, err := f(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", name, i, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func mapToData[T any](
+	items []T,
+	f func(T) (data.PlutusData, data.PlutusData),
+) data.PlutusData {
+	pairs := make([][2]data.PlutusData, len(items))
+	for i, item := range items {
+		k, v := f(item)
+		pairs[i] = [2]data.PlutusData{k, v}
+	}
+	return data.NewMap(pairs)
+}
+
+func mapFromData[T any](
+	pd data.PlutusData,
+	name string,
+	f func(k, v data.PlutusData) (T, error),
+) ([]T, error) {
+	pairs, err := mapOf(pd, name)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(pairs))
+	for i, pair := range pairs {
+		v, err := f(pair[0], pair[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", name, i, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// tupleToData encodes a Haskell pair, as used by the V1 association lists.
+func tupleToData(a, b data.PlutusData) data.PlutusData {
+	return data.NewConstr(0, a, b)
+}
+
+func tupleFromData(
+	pd data.PlutusData,
+	name string,
+) (data.PlutusData, data.PlutusData, error) {
+	fields, err := fieldsOf(pd, name, 0, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fields[0], fields[1], nil
+}
+
+func bytesListToData(items [][]byte) data.PlutusData {
+	return listToData(items, data.NewByteString)
+}
+
+func bytesListFromData(pd data.PlutusData, name string) ([][]byte, error) {
+	return listFromData(pd, name, func(item data.PlutusData) ([]byte, error) {
+		return bytesOf(item, "hash")
+	})
+}
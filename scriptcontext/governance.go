@@ -0,0 +1,570 @@
+package scriptcontext
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// DRep is a delegated representative: a credential or one of the two
+// predefined voting options.
+type DRep interface {
+	ToData() data.PlutusData
+	isDRep()
+}
+
+type DRepCredential struct {
+	Credential Credential
+}
+
+type DRepAlwaysAbstain struct{}
+
+type DRepAlwaysNoConfidence struct{}
+
+func (DRepCredential) isDRep()         {}
+func (DRepAlwaysAbstain) isDRep()      {}
+func (DRepAlwaysNoConfidence) isDRep() {}
+
+func (d DRepCredential) ToData() data.PlutusData {
+	return data.NewConstr(0, d.Credential.ToData())
+}
+
+func (DRepAlwaysAbstain) ToData() data.PlutusData {
+	return data.NewConstr(1)
+}
+
+func (DRepAlwaysNoConfidence) ToData() data.PlutusData {
+	return data.NewConstr(2)
+}
+
+func DRepFromData(pd data.PlutusData) (DRep, error) {
+	tag, fields, err := variantOf(pd, "DRep", []int{1, 0, 0})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		cred, err := CredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return DRepCredential{Credential: cred}, nil
+	case 1:
+		return DRepAlwaysAbstain{}, nil
+	default:
+		return DRepAlwaysNoConfidence{}, nil
+	}
+}
+
+// Delegatee is the target of a stake or vote delegation certificate.
+type Delegatee interface {
+	ToData() data.PlutusData
+	isDelegatee()
+}
+
+type DelegStake struct {
+	Pool []byte
+}
+
+type DelegVote struct {
+	DRep DRep
+}
+
+type DelegStakeVote struct {
+	Pool []byte
+	DRep DRep
+}
+
+func (DelegStake) isDelegatee()     {}
+func (DelegVote) isDelegatee()      {}
+func (DelegStakeVote) isDelegatee() {}
+
+func (d DelegStake) ToData() data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(d.Pool))
+}
+
+func (d DelegVote) ToData() data.PlutusData {
+	return data.NewConstr(1, d.DRep.ToData())
+}
+
+func (d DelegStakeVote) ToData() data.PlutusData {
+	return data.NewConstr(2, data.NewByteString(d.Pool), d.DRep.ToData())
+}
+
+func DelegateeFromData(pd data.PlutusData) (Delegatee, error) {
+	tag, fields, err := variantOf(pd, "Delegatee", []int{1, 1, 2})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		pool, err := bytesOf(fields[0], "Delegatee pool")
+		if err != nil {
+			return nil, err
+		}
+		return DelegStake{Pool: pool}, nil
+	case 1:
+		drep, err := DRepFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return DelegVote{DRep: drep}, nil
+	default:
+		pool, err := bytesOf(fields[0], "Delegatee pool")
+		if err != nil {
+			return nil, err
+		}
+		drep, err := DRepFromData(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return DelegStakeVote{Pool: pool, DRep: drep}, nil
+	}
+}
+
+// Voter is the author of a governance vote.
+type Voter interface {
+	ToData() data.PlutusData
+	isVoter()
+}
+
+type CommitteeVoter struct {
+	Credential Credential // hot committee credential
+}
+
+type DRepVoter struct {
+	Credential Credential
+}
+
+type StakePoolVoter struct {
+	Pool []byte
+}
+
+func (CommitteeVoter) isVoter() {}
+func (DRepVoter) isVoter()      {}
+func (StakePoolVoter) isVoter() {}
+
+func (v CommitteeVoter) ToData() data.PlutusData {
+	return data.NewConstr(0, v.Credential.ToData())
+}
+
+func (v DRepVoter) ToData() data.PlutusData {
+	return data.NewConstr(1, v.Credential.ToData())
+}
+
+func (v StakePoolVoter) ToData() data.PlutusData {
+	return data.NewConstr(2, data.NewByteString(v.Pool))
+}
+
+func VoterFromData(pd data.PlutusData) (Voter, error) {
+	tag, fields, err := variantOf(pd, "Voter", []int{1, 1, 1})
+	if err != nil {
+		return nil, err
+	}
+	if tag == 2 {
+		pool, err := bytesOf(fields[0], "Voter pool")
+		if err != nil {
+			return nil, err
+		}
+		return StakePoolVoter{Pool: pool}, nil
+	}
+	cred, err := CredentialFromData(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if tag == 0 {
+		return CommitteeVoter{Credential: cred}, nil
+	}
+	return DRepVoter{Credential: cred}, nil
+}
+
+// Vote is a governance vote.
+type Vote uint8
+
+const (
+	VoteNo Vote = iota
+	VoteYes
+	VoteAbstain
+)
+
+func (v Vote) String() string {
+	switch v {
+	case VoteNo:
+		return "No"
+	case VoteYes:
+		return "Yes"
+	case VoteAbstain:
+		return "Abstain"
+	default:
+		return fmt.Sprintf("Vote(%d)", uint8(v))
+	}
+}
+
+func (v Vote) ToData() data.PlutusData {
+	return data.NewConstr(uint(v))
+}
+
+func VoteFromData(pd data.PlutusData) (Vote, error) {
+	tag, _, err := variantOf(pd, "Vote", []int{0, 0, 0})
+	if err != nil {
+		return 0, err
+	}
+	return Vote(tag), nil
+}
+
+// GovernanceActionID identifies a governance action by the transaction that
+// proposed it and its index within that transaction.
+type GovernanceActionID struct {
+	TxID  []byte
+	Index uint64
+}
+
+func (id GovernanceActionID) ToData() data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(id.TxID), uint64Data(id.Index))
+}
+
+func GovernanceActionIDFromData(pd data.PlutusData) (GovernanceActionID, error) {
+	fields, err := fieldsOf(pd, "GovernanceActionId", 0, 2)
+	if err != nil {
+		return GovernanceActionID{}, err
+	}
+	txID, err := bytesOf(fields[0], "GovernanceActionId tx id")
+	if err != nil {
+		return GovernanceActionID{}, err
+	}
+	index, err := uint64Of(fields[1], "GovernanceActionId index")
+	if err != nil {
+		return GovernanceActionID{}, err
+	}
+	return GovernanceActionID{TxID: txID, Index: index}, nil
+}
+
+// GovernanceVote is a vote cast on one governance action.
+type GovernanceVote struct {
+	Action GovernanceActionID
+	Vote   Vote
+}
+
+// VoterVotes holds every vote cast by one voter in a transaction.
+type VoterVotes struct {
+	Voter Voter
+	Votes []GovernanceVote
+}
+
+// ProtocolVersion is a major/minor protocol version.
+type ProtocolVersion struct {
+	Major uint64
+	Minor uint64
+}
+
+func (v ProtocolVersion) ToData() data.PlutusData {
+	return data.NewConstr(0, uint64Data(v.Major), uint64Data(v.Minor))
+}
+
+func ProtocolVersionFromData(pd data.PlutusData) (ProtocolVersion, error) {
+	fields, err := fieldsOf(pd, "ProtocolVersion", 0, 2)
+	if err != nil {
+		return ProtocolVersion{}, err
+	}
+	major, err := uint64Of(fields[0], "ProtocolVersion major")
+	if err != nil {
+		return ProtocolVersion{}, err
+	}
+	minor, err := uint64Of(fields[1], "ProtocolVersion minor")
+	if err != nil {
+		return ProtocolVersion{}, err
+	}
+	return ProtocolVersion{Major: major, Minor: minor}, nil
+}
+
+// Rational is a fraction such as a committee quorum threshold.
+type Rational struct {
+	Numerator   *big.Int
+	Denominator *big.Int
+}
+
+func (r Rational) ToData() data.PlutusData {
+	return data.NewConstr(0, integerData(r.Numerator), integerData(r.Denominator))
+}
+
+func RationalFromData(pd data.PlutusData) (Rational, error) {
+	fields, err := fieldsOf(pd, "Rational", 0, 2)
+	if err != nil {
+		return Rational{}, err
+	}
+	num, err := integerOf(fields[0], "Rational numerator")
+	if err != nil {
+		return Rational{}, err
+	}
+	den, err := integerOf(fields[1], "Rational denominator")
+	if err != nil {
+		return Rational{}, err
+	}
+	return Rational{Numerator: num, Denominator: den}, nil
+}
+
+// Withdrawal is a V3 reward withdrawal or treasury withdrawal.
+type Withdrawal struct {
+	Credential Credential
+	Amount     *big.Int
+}
+
+func withdrawalToData(w Withdrawal) (data.PlutusData, data.PlutusData) {
+	return w.Credential.ToData(), integerData(w.Amount)
+}
+
+func withdrawalFromData(k, v data.PlutusData) (Withdrawal, error) {
+	cred, err := CredentialFromData(k)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	amount, err := integerOf(v, "withdrawal amount")
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	return Withdrawal{Credential: cred, Amount: amount}, nil
+}
+
+// CommitteeMember is a cold committee credential with its expiry epoch.
+type CommitteeMember struct {
+	Credential Credential
+	Expiry     uint64
+}
+
+// GovernanceAction is the action proposed by a ProposalProcedure. The
+// PrevAction fields are nil when there is no previous action.
+type GovernanceAction interface {
+	ToData() data.PlutusData
+	isGovernanceAction()
+}
+
+type ParameterChange struct {
+	PrevAction      *GovernanceActionID
+	Parameters      data.PlutusData
+	GuardrailScript []byte
+}
+
+type HardForkInitiation struct {
+	PrevAction *GovernanceActionID
+	Version    ProtocolVersion
+}
+
+type TreasuryWithdrawals struct {
+	Withdrawals     []Withdrawal
+	GuardrailScript []byte
+}
+
+type NoConfidence struct {
+	PrevAction *GovernanceActionID
+}
+
+type UpdateCommittee struct {
+	PrevAction *GovernanceActionID
+	Remove     []Credential
+	Add        []CommitteeMember
+	Quorum     Rational
+}
+
+type NewConstitution struct {
+	PrevAction      *GovernanceActionID
+	GuardrailScript []byte // constitution script hash, nil when absent
+}
+
+type InfoAction struct{}
+
+func (ParameterChange) isGovernanceAction()     {}
+func (HardForkInitiation) isGovernanceAction()  {}
+func (TreasuryWithdrawals) isGovernanceAction() {}
+func (NoConfidence) isGovernanceAction()        {}
+func (UpdateCommittee) isGovernanceAction()     {}
+func (NewConstitution) isGovernanceAction()     {}
+func (InfoAction) isGovernanceAction()          {}
+
+func (a ParameterChange) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		prevActionToData(a.PrevAction),
+		a.Parameters,
+		maybeBytesToData(a.GuardrailScript),
+	)
+}
+
+func (a HardForkInitiation) ToData() data.PlutusData {
+	return data.NewConstr(1, prevActionToData(a.PrevAction), a.Version.ToData())
+}
+
+func (a TreasuryWithdrawals) ToData() data.PlutusData {
+	return data.NewConstr(
+		2,
+		mapToData(a.Withdrawals, withdrawalToData),
+		maybeBytesToData(a.GuardrailScript),
+	)
+}
+
+func (a NoConfidence) ToData() data.PlutusData {
+	return data.NewConstr(3, prevActionToData(a.PrevAction))
+}
+
+func (a UpdateCommittee) ToData() data.PlutusData {
+	return data.NewConstr(
+		4,
+		prevActionToData(a.PrevAction),
+		listToData(a.Remove, Credential.ToData),
+		mapToData(a.Add, func(m CommitteeMember) (data.PlutusData, data.PlutusData) {
+			return m.Credential.ToData(), uint64Data(m.Expiry)
+		}),
+		a.Quorum.ToData(),
+	)
+}
+
+func (a NewConstitution) ToData() data.PlutusData {
+	return data.NewConstr(
+		5,
+		prevActionToData(a.PrevAction),
+		data.NewConstr(0, maybeBytesToData(a.GuardrailScript)),
+	)
+}
+
+func (InfoAction) ToData() data.PlutusData {
+	return data.NewConstr(6)
+}
+
+func GovernanceActionFromData(pd data.PlutusData) (GovernanceAction, error) {
+	tag, fields, err := variantOf(pd, "GovernanceAction", []int{3, 2, 2, 1, 4, 2, 0})
+	if err != nil {
+		return nil, err
+	}
+	if tag == 6 {
+		return InfoAction{}, nil
+	}
+	if tag == 2 {
+		withdrawals, err := mapFromData(fields[0], "TreasuryWithdrawals", withdrawalFromData)
+		if err != nil {
+			return nil, err
+		}
+		guardrail, err := maybeBytesFromData(fields[1], "TreasuryWithdrawals guardrail script")
+		if err != nil {
+			return nil, err
+		}
+		return TreasuryWithdrawals{Withdrawals: withdrawals, GuardrailScript: guardrail}, nil
+	}
+
+	prev, err := prevActionFromData(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		guardrail, err := maybeBytesFromData(fields[2], "ParameterChange guardrail script")
+		if err != nil {
+			return nil, err
+		}
+		return ParameterChange{
+			PrevAction:      prev,
+			Parameters:      fields[1],
+			GuardrailScript: guardrail,
+		}, nil
+	case 1:
+		version, err := ProtocolVersionFromData(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return HardForkInitiation{PrevAction: prev, Version: version}, nil
+	case 3:
+		return NoConfidence{PrevAction: prev}, nil
+	case 4:
+		remove, err := listFromData(fields[1], "UpdateCommittee remove", CredentialFromData)
+		if err != nil {
+			return nil, err
+		}
+		add, err := mapFromData(fields[2], "UpdateCommittee add", func(k, v data.PlutusData) (CommitteeMember, error) {
+			cred, err := CredentialFromData(k)
+			if err != nil {
+				return CommitteeMember{}, err
+			}
+			expiry, err := uint64Of(v, "UpdateCommittee expiry")
+			if err != nil {
+				return CommitteeMember{}, err
+			}
+			return CommitteeMember{Credential: cred, Expiry: expiry}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		quorum, err := RationalFromData(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		return UpdateCommittee{PrevAction: prev, Remove: remove, Add: add, Quorum: quorum}, nil
+	default:
+		constitution, err := fieldsOf(fields[1], "Constitution", 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		guardrail, err := maybeBytesFromData(constitution[0], "Constitution script")
+		if err != nil {
+			return nil, err
+		}
+		return NewConstitution{PrevAction: prev, GuardrailScript: guardrail}, nil
+	}
+}
+
+func prevActionToData(id *GovernanceActionID) data.PlutusData {
+	if id == nil {
+		return maybeToData(nil)
+	}
+	return maybeToData(id.ToData())
+}
+
+func prevActionFromData(pd data.PlutusData) (*GovernanceActionID, error) {
+	inner, err := maybeFromData(pd, "previous governance action")
+	if err != nil || inner == nil {
+		return nil, err
+	}
+	id, err := GovernanceActionIDFromData(inner)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ProposalProcedure is a governance proposal submitted in a transaction.
+type ProposalProcedure struct {
+	Deposit       *big.Int
+	ReturnAddress Credential
+	Action        GovernanceAction
+}
+
+func (p ProposalProcedure) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		integerData(p.Deposit),
+		p.ReturnAddress.ToData(),
+		p.Action.ToData(),
+	)
+}
+
+func ProposalProcedureFromData(pd data.PlutusData) (ProposalProcedure, error) {
+	fields, err := fieldsOf(pd, "ProposalProcedure", 0, 3)
+	if err != nil {
+		return ProposalProcedure{}, err
+	}
+	deposit, err := integerOf(fields[0], "ProposalProcedure deposit")
+	if err != nil {
+		return ProposalProcedure{}, err
+	}
+	returnAddr, err := CredentialFromData(fields[1])
+	if err != nil {
+		return ProposalProcedure{}, err
+	}
+	action, err := GovernanceActionFromData(fields[2])
+	if err != nil {
+		return ProposalProcedure{}, err
+	}
+	return ProposalProcedure{
+		Deposit:       deposit,
+		ReturnAddress: returnAddr,
+		Action:        action,
+	}, nil
+}
@@ -0,0 +1,418 @@
+package scriptcontext
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+func hash(b byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = b
+	}
+	return out
+}
+
+func roundTrip[T interface{ ToData() data.PlutusData }](
+	t *testing.T,
+	v T,
+	fromData func(data.PlutusData) (T, error),
+) {
+	t.Helper()
+	pd := v.ToData()
+	encoded, err := data.Encode(pd)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	decodedData, err := data.Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	decoded, err := fromData(decodedData)
+	if err != nil {
+		t.Fatalf("FromData() failed: %v", err)
+	}
+	if got := decoded.ToData(); !got.Equal(pd) {
+		t.Fatalf("round trip = %v, want %v", got, pd)
+	}
+}
+
+func sampleAddress() Address {
+	return Address{
+		Credential:        Credential{Type: ScriptCredential, Hash: hash(0xaa, 28)},
+		StakingCredential: StakingHash{Credential: Credential{Type: PubKeyCredential, Hash: hash(0xbb, 28)}},
+	}
+}
+
+func sampleValue() Value {
+	v := Lovelace(big.NewInt(2_000_000))
+	return append(v, PolicyAssets{
+		Policy: hash(0xcc, 28),
+		Assets: []Asset{
+			{Name: []byte("token"), Quantity: big.NewInt(5)},
+			{Name: []byte{}, Quantity: big.NewInt(-1)},
+		},
+	})
+}
+
+func sampleInterval() Interval {
+	return Interval{
+		Lower: IntervalBound{Kind: Finite, Time: big.NewInt(1_700_000_000_000), Closed: true},
+		Upper: IntervalBound{Kind: PosInf, Closed: true},
+	}
+}
+
+func TestEncodingShapes(t *testing.T) {
+	tests := []struct {
+		name string
+		pd   data.PlutusData
+		want string
+	}{
+		{
+			name: "pub key credential",
+			pd:   Credential{Type: PubKeyCredential, Hash: []byte{1}}.ToData(),
+			want: "d8799f4101ff",
+		},
+		{
+			name: "enterprise address",
+			pd:   Address{Credential: Credential{Type: ScriptCredential, Hash: []byte{1}}}.ToData(),
+			want: "d8799fd87a9f4101ffd87a80ff",
+		},
+		{
+			name: "always interval",
+			pd:   Always().ToData(),
+			want: "d8799fd8799fd87980d87a80ffd8799fd87b80d87a80ffff",
+		},
+		{
+			name: "lovelace",
+			pd:   Lovelace(big.NewInt(1)).ToData(),
+			want: "a140a14001",
+		},
+		{
+			name: "V1 spending purpose",
+			pd:   SpendingV1{OutRef: TxOutRef{TxID: []byte{1}, Index: 2}}.ToData(),
+			want: "d87a9fd8799fd8799f4101ff02ffff",
+		},
+		{
+			name: "V3 spending purpose",
+			pd:   Spending{OutRef: TxOutRef{TxID: []byte{1}, Index: 2}}.ToData(),
+			want: "d87a9fd8799f410102ffff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := data.Encode(tt.pd)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if got := hex.EncodeToString(encoded); got != tt.want {
+				t.Fatalf("Encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptContextV1RoundTrip(t *testing.T) {
+	ref := TxOutRef{TxID: hash(0x01, 32), Index: 3}
+	ctx := ScriptContextV1{
+		TxInfo: TxInfoV1{
+			Inputs: []TxInInfoV1{{
+				OutRef:   ref,
+				Resolved: TxOutV1{Address: sampleAddress(), Value: sampleValue(), DatumHash: hash(0x02, 32)},
+			}},
+			Outputs: []TxOutV1{{
+				Address: Address{Credential: Credential{Hash: hash(0x03, 28)}},
+				Value:   Lovelace(big.NewInt(1)),
+			}},
+			Fee:  Lovelace(big.NewInt(170_000)),
+			Mint: Lovelace(big.NewInt(0)),
+			Certificates: []DCert{
+				DCertDelegRegKey{Credential: StakingPtr{Slot: 1, TxIndex: 2, CertIndex: 3}},
+				DCertDelegDelegate{Credential: StakingHash{}, Pool: hash(0x04, 28)},
+				DCertPoolRetire{Pool: hash(0x04, 28), Epoch: 400},
+				DCertGenesis{},
+				DCertMir{},
+			},
+			Withdrawals: []WithdrawalV1{{
+				Credential: StakingHash{Credential: Credential{Type: ScriptCredential, Hash: hash(0x05, 28)}},
+				Amount:     big.NewInt(10),
+			}},
+			ValidRange:  sampleInterval(),
+			Signatories: [][]byte{hash(0x06, 28)},
+			Data:        []DatumEntry{{Hash: hash(0x02, 32), Datum: data.NewInteger(big.NewInt(42))}},
+			ID:          hash(0x07, 32),
+		},
+		Purpose: SpendingV1{OutRef: ref},
+	}
+	roundTrip(t, ctx, ScriptContextV1FromData)
+}
+
+func TestScriptContextV2RoundTrip(t *testing.T) {
+	ref := TxOutRef{TxID: hash(0x01, 32), Index: 0}
+	ctx := ScriptContextV2{
+		TxInfo: TxInfoV2{
+			Inputs: []TxInInfo{{
+				OutRef: ref,
+				Resolved: TxOut{
+					Address: sampleAddress(),
+					Value:   sampleValue(),
+					Datum:   InlineDatum{Datum: data.NewConstr(0)},
+				},
+			}},
+			ReferenceInputs: []TxInInfo{{
+				OutRef: TxOutRef{TxID: hash(0x08, 32), Index: 1},
+				Resolved: TxOut{
+					Address:         sampleAddress(),
+					Value:           Lovelace(big.NewInt(5)),
+					Datum:           OutputDatumHash{Hash: hash(0x09, 32)},
+					ReferenceScript: hash(0x0a, 28),
+				},
+			}},
+			Outputs:      []TxOut{{Address: sampleAddress(), Value: sampleValue()}},
+			Fee:          Lovelace(big.NewInt(200_000)),
+			Mint:         Lovelace(big.NewInt(0)),
+			Certificates: []DCert{DCertPoolRegister{Pool: hash(0x04, 28), VRF: hash(0x0b, 32)}},
+			Withdrawals: []WithdrawalV1{{
+				Credential: StakingHash{Credential: Credential{Hash: hash(0x05, 28)}},
+				Amount:     big.NewInt(0),
+			}},
+			ValidRange:  Always(),
+			Signatories: [][]byte{},
+			Redeemers: []RedeemerEntryV1{
+				{Purpose: SpendingV1{OutRef: ref}, Redeemer: data.NewList()},
+				{Purpose: Minting{Policy: hash(0xcc, 28)}, Redeemer: data.NewInteger(big.NewInt(1))},
+				{Purpose: CertifyingV1{Cert: DCertDelegDeRegKey{Credential: StakingHash{}}}, Redeemer: data.NewConstr(1)},
+				{Purpose: RewardingV1{Credential: StakingPtr{}}, Redeemer: data.NewConstr(2)},
+			},
+			ID: hash(0x07, 32),
+		},
+		Purpose: Minting{Policy: hash(0xcc, 28)},
+	}
+	roundTrip(t, ctx, ScriptContextV2FromData)
+}
+
+func TestScriptContextV3RoundTrip(t *testing.T) {
+	ref := TxOutRef{TxID: hash(0x01, 32), Index: 7}
+	cold := Credential{Type: ScriptCredential, Hash: hash(0x10, 28)}
+	hot := Credential{Type: PubKeyCredential, Hash: hash(0x11, 28)}
+	prev := &GovernanceActionID{TxID: hash(0x12, 32), Index: 0}
+	proposal := ProposalProcedure{
+		Deposit:       big.NewInt(100_000_000_000),
+		ReturnAddress: hot,
+		Action: UpdateCommittee{
+			PrevAction: prev,
+			Remove:     []Credential{cold},
+			Add:        []CommitteeMember{{Credential: hot, Expiry: 600}},
+			Quorum:     Rational{Numerator: big.NewInt(2), Denominator: big.NewInt(3)},
+		},
+	}
+	ctx := ScriptContextV3{
+		TxInfo: TxInfoV3{
+			Inputs: []TxInInfo{{
+				OutRef:   ref,
+				Resolved: TxOut{Address: sampleAddress(), Value: sampleValue(), Datum: NoOutputDatum{}},
+			}},
+			Outputs: []TxOut{{Address: sampleAddress(), Value: Lovelace(big.NewInt(9))}},
+			Fee:     big.NewInt(180_000),
+			Mint: Value{{
+				Policy: hash(0xcc, 28),
+				Assets: []Asset{{Name: []byte("x"), Quantity: big.NewInt(1)}},
+			}},
+			Certificates: []TxCert{
+				TxCertRegStaking{Credential: hot, Deposit: big.NewInt(2_000_000)},
+				TxCertUnRegStaking{Credential: hot},
+				TxCertDelegStaking{Credential: hot, Delegatee: DelegStake{Pool: hash(0x04, 28)}},
+				TxCertRegDeleg{
+					Credential: hot,
+					Delegatee:  DelegStakeVote{Pool: hash(0x04, 28), DRep: DRepAlwaysAbstain{}},
+					Deposit:    big.NewInt(2_000_000),
+				},
+				TxCertRegDRep{Credential: cold, Deposit: big.NewInt(500_000_000)},
+				TxCertUpdateDRep{Credential: cold},
+				TxCertUnRegDRep{Credential: cold, Refund: big.NewInt(500_000_000)},
+				TxCertPoolRegister{Pool: hash(0x04, 28), VRF: hash(0x0b, 32)},
+				TxCertPoolRetire{Pool: hash(0x04, 28), Epoch: 500},
+				TxCertAuthHotCommittee{Cold: cold, Hot: hot},
+				TxCertResignColdCommittee{Cold: cold},
+				TxCertDelegStaking{Credential: hot, Delegatee: DelegVote{DRep: DRepCredential{Credential: cold}}},
+			},
+			Withdrawals: []Withdrawal{{Credential: cold, Amount: big.NewInt(3)}},
+			ValidRange: Interval{
+				Lower: IntervalBound{Kind: NegInf, Closed: true},
+				Upper: IntervalBound{Kind: Finite, Time: big.NewInt(1_800_000_000_000)},
+			},
+			Signatories: [][]byte{hash(0x06, 28)},
+			Redeemers: []RedeemerEntry{
+				{Purpose: Spending{OutRef: ref}, Redeemer: data.NewConstr(0)},
+				{Purpose: Rewarding{Credential: cold}, Redeemer: data.NewConstr(0)},
+				{Purpose: Certifying{Index: 1, Cert: TxCertUnRegStaking{Credential: hot}}, Redeemer: data.NewConstr(0)},
+				{Purpose: Voting{Voter: DRepVoter{Credential: cold}}, Redeemer: data.NewConstr(0)},
+				{Purpose: Proposing{Index: 0, Proposal: proposal}, Redeemer: data.NewConstr(0)},
+			},
+			Data: []DatumEntry{{Hash: hash(0x02, 32), Datum: data.NewByteString([]byte("datum"))}},
+			ID:   hash(0x07, 32),
+			Votes: []VoterVotes{
+				{Voter: CommitteeVoter{Credential: hot}, Votes: []GovernanceVote{{Action: *prev, Vote: VoteYes}}},
+				{Voter: StakePoolVoter{Pool: hash(0x04, 28)}, Votes: []GovernanceVote{{Action: *prev, Vote: VoteAbstain}}},
+			},
+			ProposalProcedures: []ProposalProcedure{
+				proposal,
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: ParameterChange{
+					Parameters:      data.NewMap([][2]data.PlutusData{{data.NewInteger(big.NewInt(0)), data.NewInteger(big.NewInt(44))}}),
+					GuardrailScript: hash(0x13, 28),
+				}},
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: HardForkInitiation{Version: ProtocolVersion{Major: 11}}},
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: TreasuryWithdrawals{
+					Withdrawals: []Withdrawal{{Credential: hot, Amount: big.NewInt(1)}},
+				}},
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: NoConfidence{PrevAction: prev}},
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: NewConstitution{GuardrailScript: hash(0x13, 28)}},
+				{Deposit: big.NewInt(1), ReturnAddress: hot, Action: InfoAction{}},
+			},
+			CurrentTreasuryAmount: big.NewInt(1_500_000_000_000_000),
+		},
+		Redeemer: data.NewConstr(0),
+		Info:     SpendingScript{OutRef: ref, Datum: data.NewInteger(big.NewInt(1))},
+	}
+	roundTrip(t, ctx, ScriptContextV3FromData)
+
+	for _, info := range []ScriptInfo{
+		MintingScript{Policy: hash(0xcc, 28)},
+		SpendingScript{OutRef: ref},
+		RewardingScript{Credential: cold},
+		CertifyingScript{Index: 0, Cert: TxCertUpdateDRep{Credential: cold}},
+		VotingScript{Voter: CommitteeVoter{Credential: hot}},
+		ProposingScript{Index: 2, Proposal: proposal},
+	} {
+		ctx.Info = info
+		roundTrip(t, ctx, ScriptContextV3FromData)
+	}
+}
+
+func TestFromDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{
+			name: "credential bad tag",
+			run: func() error {
+				_, err := CredentialFromData(data.NewConstr(2, data.NewByteString(nil)))
+				return err
+			},
+		},
+		{
+			name: "address wrong arity",
+			run: func() error {
+				_, err := AddressFromData(data.NewConstr(0))
+				return err
+			},
+		},
+		{
+			name: "value not a map",
+			run: func() error {
+				_, err := ValueFromData(data.NewList())
+				return err
+			},
+		},
+		{
+			name: "V3 context passed to V2 decoder",
+			run: func() error {
+				_, err := ScriptContextV2FromData(data.NewConstr(0, data.NewConstr(0), data.NewConstr(0), data.NewConstr(0)))
+				return err
+			},
+		},
+		{
+			name: "negative output index",
+			run: func() error {
+				_, err := ScriptPurposeFromData(data.NewConstr(1, data.NewConstr(0, data.NewByteString(nil), data.NewInteger(big.NewInt(-1)))))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+// corpusCase is a case of the replay corpus.
+type corpusCase struct {
+	ID        string   `json:"id"`
+	Language  string   `json:"language"`
+	Arguments []string `json:"arguments_cbor_hex"`
+}
+
+// context returns the CBOR of the case's ScriptContext argument.
+func (c corpusCase) context(t *testing.T) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(c.Arguments[len(c.Arguments)-1])
+	if err != nil {
+		t.Fatalf("invalid hex: %v", err)
+	}
+	return b
+}
+
+func mainnetCases(t *testing.T) []corpusCase {
+	t.Helper()
+	raw, err := os.ReadFile("../replay/testdata/mainnet.json")
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	var corpus struct {
+		Cases []corpusCase `json:"cases"`
+	}
+	if err := json.Unmarshal(raw, &corpus); err != nil {
+		t.Fatalf("failed to parse corpus: %v", err)
+	}
+	return corpus.Cases
+}
+
+// TestMainnetContexts decodes the script contexts captured in the replay
+// corpus and checks that re-encoding them reproduces the original Data.
+func TestMainnetContexts(t *testing.T) {
+	for _, c := range mainnetCases(t) {
+		t.Run(c.ID, func(t *testing.T) {
+			pd, err := data.Decode(c.context(t))
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			var reencoded data.PlutusData
+			switch c.Language {
+			case "PlutusV1":
+				ctx, err := ScriptContextV1FromData(pd)
+				if err != nil {
+					t.Fatalf("ScriptContextV1FromData() failed: %v", err)
+				}
+				reencoded = ctx.ToData()
+			case "PlutusV2":
+				ctx, err := ScriptContextV2FromData(pd)
+				if err != nil {
+					t.Fatalf("ScriptContextV2FromData() failed: %v", err)
+				}
+				reencoded = ctx.ToData()
+			case "PlutusV3":
+				ctx, err := ScriptContextV3FromData(pd)
+				if err != nil {
+					t.Fatalf("ScriptContextV3FromData() failed: %v", err)
+				}
+				reencoded = ctx.ToData()
+			default:
+				t.Skipf("no context decoder for %s", c.Language)
+			}
+			if !reencoded.Equal(pd) {
+				t.Fatalf("re-encoded context differs from original")
+			}
+		})
+	}
+}
@@ -0,0 +1,613 @@
+package scriptcontext
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// summaryDataWidth bounds how much of a datum or redeemer is printed inline.
+const summaryDataWidth = 96
+
+// Summary returns a human-readable description of ctx: its purpose,
+// inputs, outputs, minted value, signatories, validity range, redeemers,
+// datums, certificates and, for V3, votes and proposals. Empty sections are
+// omitted. The format is meant for people and may change between releases.
+func Summary(ctx ScriptContext) string {
+	var s summary
+	switch c := ctx.(type) {
+	case ScriptContextV1:
+		s.summarizeV1(c)
+	case ScriptContextV2:
+		s.summarizeV2(c)
+	case ScriptContextV3:
+		s.summarizeV3(c)
+	default:
+		return fmt.Sprintf("unknown script context %T\n", ctx)
+	}
+	return s.String()
+}
+
+type summary struct {
+	strings.Builder
+}
+
+func (s *summary) line(indent int, format string, args ...any) {
+	s.WriteString(strings.Repeat("  ", indent))
+	fmt.Fprintf(s, format, args...)
+	s.WriteByte('\n')
+}
+
+// section writes a section header, returning false when the section is
+// empty and should be skipped.
+func (s *summary) section(title string, n int) bool {
+	if n == 0 {
+		return false
+	}
+	s.line(0, "%s (%d):", title, n)
+	return true
+}
+
+func (s *summary) summarizeV1(c ScriptContextV1) {
+	t := c.TxInfo
+	s.line(0, "PlutusV1 script context")
+	s.line(1, "purpose:     %s", describePurposeV1(c.Purpose))
+	s.line(1, "transaction: %x", t.ID)
+	s.line(1, "fee:         %s", describeValue(t.Fee))
+	s.line(1, "validity:    %s", describeInterval(t.ValidRange))
+	if s.section("inputs", len(t.Inputs)) {
+		for _, in := range t.Inputs {
+			s.line(1, "%s", describeOutRef(in.OutRef))
+			s.outputV1(in.Resolved)
+		}
+	}
+	if s.section("outputs", len(t.Outputs)) {
+		for i, out := range t.Outputs {
+			s.line(1, "#%d", i)
+			s.outputV1(out)
+		}
+	}
+	s.mint(t.Mint)
+	s.signatories(t.Signatories)
+	if s.section("certificates", len(t.Certificates)) {
+		for i, cert := range t.Certificates {
+			s.line(1, "[%d] %s", i, describeDCert(cert))
+		}
+	}
+	s.withdrawalsV1(t.Withdrawals)
+	s.datums(t.Data)
+}
+
+func (s *summary) summarizeV2(c ScriptContextV2) {
+	t := c.TxInfo
+	s.line(0, "PlutusV2 script context")
+	s.line(1, "purpose:     %s", describePurposeV1(c.Purpose))
+	s.line(1, "transaction: %x", t.ID)
+	s.line(1, "fee:         %s", describeValue(t.Fee))
+	s.line(1, "validity:    %s", describeInterval(t.ValidRange))
+	s.inputs("inputs", t.Inputs)
+	s.inputs("reference inputs", t.ReferenceInputs)
+	s.outputs(t.Outputs)
+	s.mint(t.Mint)
+	s.signatories(t.Signatories)
+	if s.section("certificates", len(t.Certificates)) {
+		for i, cert := range t.Certificates {
+			s.line(1, "[%d] %s", i, describeDCert(cert))
+		}
+	}
+	s.withdrawalsV1(t.Withdrawals)
+	if s.section("redeemers", len(t.Redeemers)) {
+		for _, r := range t.Redeemers {
+			s.line(1, "%s: %s", describePurposeV1(r.Purpose), describeData(r.Redeemer))
+		}
+	}
+	s.datums(t.Data)
+}
+
+func (s *summary) summarizeV3(c ScriptContextV3) {
+	t := c.TxInfo
+	s.line(0, "PlutusV3 script context")
+	s.line(1, "purpose:     %s", describeScriptInfo(c.Info))
+	s.line(1, "redeemer:    %s", describeData(c.Redeemer))
+	s.line(1, "transaction: %x", t.ID)
+	s.line(1, "fee:         %s lovelace", amount(t.Fee))
+	s.line(1, "validity:    %s", describeInterval(t.ValidRange))
+	s.inputs("inputs", t.Inputs)
+	s.inputs("reference inputs", t.ReferenceInputs)
+	s.outputs(t.Outputs)
+	s.mint(t.Mint)
+	s.signatories(t.Signatories)
+	if s.section("certificates", len(t.Certificates)) {
+		for i, cert := range t.Certificates {
+			s.line(1, "[%d] %s", i, describeTxCert(cert))
+		}
+	}
+	if s.section("withdrawals", len(t.Withdrawals)) {
+		for _, w := range t.Withdrawals {
+			s.line(1, "%s: %s lovelace", describeCredential(w.Credential), amount(w.Amount))
+		}
+	}
+	if s.section("redeemers", len(t.Redeemers)) {
+		for _, r := range t.Redeemers {
+			s.line(1, "%s: %s", describePurpose(r.Purpose), describeData(r.Redeemer))
+		}
+	}
+	s.datums(t.Data)
+	if s.section("votes", len(t.Votes)) {
+		for _, v := range t.Votes {
+			s.line(1, "%s:", describeVoter(v.Voter))
+			for _, vote := range v.Votes {
+				s.line(2, "%s: %s", describeActionID(vote.Action), vote.Vote)
+			}
+		}
+	}
+	if s.section("proposals", len(t.ProposalProcedures)) {
+		for i, p := range t.ProposalProcedures {
+			s.line(1, "[%d] %s", i, describeProposal(p))
+		}
+	}
+	if t.CurrentTreasuryAmount != nil {
+		s.line(0, "current treasury: %s lovelace", amount(t.CurrentTreasuryAmount))
+	}
+	if t.TreasuryDonation != nil {
+		s.line(0, "treasury donation: %s lovelace", amount(t.TreasuryDonation))
+	}
+}
+
+func (s *summary) inputs(title string, inputs []TxInInfo) {
+	if !s.section(title, len(inputs)) {
+		return
+	}
+	for _, in := range inputs {
+		s.line(1, "%s", describeOutRef(in.OutRef))
+		s.output(in.Resolved)
+	}
+}
+
+func (s *summary) outputs(outputs []TxOut) {
+	if !s.section("outputs", len(outputs)) {
+		return
+	}
+	for i, out := range outputs {
+		s.line(1, "#%d", i)
+		s.output(out)
+	}
+}
+
+func (s *summary) output(out TxOut) {
+	s.line(2, "address: %s", describeAddress(out.Address))
+	s.line(2, "value:   %s", describeValue(out.Value))
+	if out.Datum != nil {
+		if _, none := out.Datum.(NoOutputDatum); !none {
+			s.line(2, "datum:   %s", describeOutputDatum(out.Datum))
+		}
+	}
+	if out.ReferenceScript != nil {
+		s.line(2, "script:  %x", out.ReferenceScript)
+	}
+}
+
+func (s *summary) outputV1(out TxOutV1) {
+	s.line(2, "address: %s", describeAddress(out.Address))
+	s.line(2, "value:   %s", describeValue(out.Value))
+	if out.DatumHash != nil {
+		s.line(2, "datum:   hash %x", out.DatumHash)
+	}
+}
+
+func (s *summary) mint(v Value) {
+	if isZeroValue(v) {
+		return
+	}
+	s.line(0, "mint: %s", describeValue(v))
+}
+
+func (s *summary) signatories(signatories [][]byte) {
+	if !s.section("signatories", len(signatories)) {
+		return
+	}
+	for _, sig := range signatories {
+		s.line(1, "%x", sig)
+	}
+}
+
+func (s *summary) withdrawalsV1(withdrawals []WithdrawalV1) {
+	if !s.section("withdrawals", len(withdrawals)) {
+		return
+	}
+	for _, w := range withdrawals {
+		s.line(1, "%s: %s lovelace", describeStakingCredential(w.Credential), amount(w.Amount))
+	}
+}
+
+func (s *summary) datums(datums []DatumEntry) {
+	if !s.section("datums", len(datums)) {
+		return
+	}
+	for _, d := range datums {
+		s.line(1, "%x: %s", d.Hash, describeData(d.Datum))
+	}
+}
+
+func amount(i *big.Int) string {
+	if i == nil {
+		return "0"
+	}
+	return i.String()
+}
+
+func describeOutRef(r TxOutRef) string {
+	return fmt.Sprintf("%x#%d", r.TxID, r.Index)
+}
+
+func describeActionID(id GovernanceActionID) string {
+	return fmt.Sprintf("%x#%d", id.TxID, id.Index)
+}
+
+func describeCredential(c Credential) string {
+	if c.Type == ScriptCredential {
+		return fmt.Sprintf("script %x", c.Hash)
+	}
+	return fmt.Sprintf("key %x", c.Hash)
+}
+
+func describeStakingCredential(c StakingCredential) string {
+	switch v := c.(type) {
+	case StakingHash:
+		return describeCredential(v.Credential)
+	case StakingPtr:
+		return fmt.Sprintf("pointer %d/%d/%d", v.Slot, v.TxIndex, v.CertIndex)
+	default:
+		return "none"
+	}
+}
+
+func describeAddress(a Address) string {
+	out := describeCredential(a.Credential)
+	if a.StakingCredential != nil {
+		out += ", stake " + describeStakingCredential(a.StakingCredential)
+	}
+	return out
+}
+
+func isZeroValue(v Value) bool {
+	for _, entry := range v {
+		for _, asset := range entry.Assets {
+			if asset.Quantity != nil && asset.Quantity.Sign() != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func describeValue(v Value) string {
+	var parts []string
+	for _, entry := range v {
+		for _, asset := range entry.Assets {
+			if len(entry.Policy) == 0 && len(asset.Name) == 0 {
+				parts = append(parts, amount(asset.Quantity)+" lovelace")
+				continue
+			}
+			parts = append(parts, fmt.Sprintf(
+				"%s %x.%s",
+				amount(asset.Quantity),
+				entry.Policy,
+				describeAssetName(asset.Name),
+			))
+		}
+	}
+	if len(parts) == 0 {
+		return "0 lovelace"
+	}
+	return strings.Join(parts, " + ")
+}
+
+// describeAssetName prints printable ASCII names as text and others as hex.
+func describeAssetName(name []byte) string {
+	if len(name) == 0 {
+		return `""`
+	}
+	for _, b := range name {
+		if b < 0x20 || b > 0x7e {
+			return hex.EncodeToString(name)
+		}
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func describeInterval(i Interval) string {
+	open, close := "(", ")"
+	if i.Lower.Closed {
+		open = "["
+	}
+	if i.Upper.Closed {
+		close = "]"
+	}
+	return open + describeBound(i.Lower) + ", " + describeBound(i.Upper) + close
+}
+
+func describeBound(b IntervalBound) string {
+	switch b.Kind {
+	case NegInf:
+		return "-inf"
+	case PosInf:
+		return "+inf"
+	default:
+		return amount(b.Time)
+	}
+}
+
+func describeOutputDatum(d OutputDatum) string {
+	switch v := d.(type) {
+	case OutputDatumHash:
+		return fmt.Sprintf("hash %x", v.Hash)
+	case InlineDatum:
+		return "inline " + describeData(v.Datum)
+	default:
+		return "none"
+	}
+}
+
+func describePurposeV1(p ScriptPurposeV1) string {
+	switch v := p.(type) {
+	case Minting:
+		return fmt.Sprintf("minting %x", v.Policy)
+	case SpendingV1:
+		return "spending " + describeOutRef(v.OutRef)
+	case RewardingV1:
+		return "rewarding " + describeStakingCredential(v.Credential)
+	case CertifyingV1:
+		return "certifying " + describeDCert(v.Cert)
+	default:
+		return fmt.Sprintf("%T", p)
+	}
+}
+
+func describePurpose(p ScriptPurpose) string {
+	switch v := p.(type) {
+	case Minting:
+		return fmt.Sprintf("minting %x", v.Policy)
+	case Spending:
+		return "spending " + describeOutRef(v.OutRef)
+	case Rewarding:
+		return "rewarding " + describeCredential(v.Credential)
+	case Certifying:
+		return fmt.Sprintf("certifying [%d] %s", v.Index, describeTxCert(v.Cert))
+	case Voting:
+		return "voting " + describeVoter(v.Voter)
+	case Proposing:
+		return fmt.Sprintf("proposing [%d] %s", v.Index, describeProposal(v.Proposal))
+	default:
+		return fmt.Sprintf("%T", p)
+	}
+}
+
+func describeScriptInfo(info ScriptInfo) string {
+	switch v := info.(type) {
+	case MintingScript:
+		return fmt.Sprintf("minting %x", v.Policy)
+	case SpendingScript:
+		if v.Datum == nil {
+			return "spending " + describeOutRef(v.OutRef) + " (no datum)"
+		}
+		return "spending " + describeOutRef(v.OutRef) + " (datum " + describeData(v.Datum) + ")"
+	case RewardingScript:
+		return "rewarding " + describeCredential(v.Credential)
+	case CertifyingScript:
+		return fmt.Sprintf("certifying [%d] %s", v.Index, describeTxCert(v.Cert))
+	case VotingScript:
+		return "voting " + describeVoter(v.Voter)
+	case ProposingScript:
+		return fmt.Sprintf("proposing [%d] %s", v.Index, describeProposal(v.Proposal))
+	default:
+		return fmt.Sprintf("%T", info)
+	}
+}
+
+func describeDCert(c DCert) string {
+	switch v := c.(type) {
+	case DCertDelegRegKey:
+		return "register stake " + describeStakingCredential(v.Credential)
+	case DCertDelegDeRegKey:
+		return "deregister stake " + describeStakingCredential(v.Credential)
+	case DCertDelegDelegate:
+		return fmt.Sprintf("delegate %s to pool %x", describeStakingCredential(v.Credential), v.Pool)
+	case DCertPoolRegister:
+		return fmt.Sprintf("register pool %x", v.Pool)
+	case DCertPoolRetire:
+		return fmt.Sprintf("retire pool %x at epoch %d", v.Pool, v.Epoch)
+	case DCertGenesis:
+		return "genesis delegation"
+	case DCertMir:
+		return "move instantaneous rewards"
+	default:
+		return fmt.Sprintf("%T", c)
+	}
+}
+
+func describeTxCert(c TxCert) string {
+	switch v := c.(type) {
+	case TxCertRegStaking:
+		return "register stake " + describeCredential(v.Credential) + optionalAmount(" deposit", v.Deposit)
+	case TxCertUnRegStaking:
+		return "deregister stake " + describeCredential(v.Credential) + optionalAmount(" refund", v.Refund)
+	case TxCertDelegStaking:
+		return fmt.Sprintf("delegate %s to %s", describeCredential(v.Credential), describeDelegatee(v.Delegatee))
+	case TxCertRegDeleg:
+		return fmt.Sprintf(
+			"register and delegate %s to %s deposit %s",
+			describeCredential(v.Credential),
+			describeDelegatee(v.Delegatee),
+			amount(v.Deposit),
+		)
+	case TxCertRegDRep:
+		return fmt.Sprintf("register drep %s deposit %s", describeCredential(v.Credential), amount(v.Deposit))
+	case TxCertUpdateDRep:
+		return "update drep " + describeCredential(v.Credential)
+	case TxCertUnRegDRep:
+		return fmt.Sprintf("deregister drep %s refund %s", describeCredential(v.Credential), amount(v.Refund))
+	case TxCertPoolRegister:
+		return fmt.Sprintf("register pool %x", v.Pool)
+	case TxCertPoolRetire:
+		return fmt.Sprintf("retire pool %x at epoch %d", v.Pool, v.Epoch)
+	case TxCertAuthHotCommittee:
+		return fmt.Sprintf(
+			"authorize committee hot %s for cold %s",
+			describeCredential(v.Hot),
+			describeCredential(v.Cold),
+		)
+	case TxCertResignColdCommittee:
+		return "resign committee cold " + describeCredential(v.Cold)
+	default:
+		return fmt.Sprintf("%T", c)
+	}
+}
+
+func optionalAmount(label string, i *big.Int) string {
+	if i == nil {
+		return ""
+	}
+	return label + " " + i.String()
+}
+
+func describeDelegatee(d Delegatee) string {
+	switch v := d.(type) {
+	case DelegStake:
+		return fmt.Sprintf("pool %x", v.Pool)
+	case DelegVote:
+		return describeDRep(v.DRep)
+	case DelegStakeVote:
+		return fmt.Sprintf("pool %x and %s", v.Pool, describeDRep(v.DRep))
+	default:
+		return fmt.Sprintf("%T", d)
+	}
+}
+
+func describeDRep(d DRep) string {
+	switch v := d.(type) {
+	case DRepCredential:
+		return "drep " + describeCredential(v.Credential)
+	case DRepAlwaysAbstain:
+		return "always abstain"
+	case DRepAlwaysNoConfidence:
+		return "always no confidence"
+	default:
+		return fmt.Sprintf("%T", d)
+	}
+}
+
+func describeVoter(v Voter) string {
+	switch x := v.(type) {
+	case CommitteeVoter:
+		return "committee " + describeCredential(x.Credential)
+	case DRepVoter:
+		return "drep " + describeCredential(x.Credential)
+	case StakePoolVoter:
+		return fmt.Sprintf("pool %x", x.Pool)
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func describeProposal(p ProposalProcedure) string {
+	var action string
+	switch a := p.Action.(type) {
+	case ParameterChange:
+		action = "parameter change " + describeData(a.Parameters)
+	case HardForkInitiation:
+		action = fmt.Sprintf("hard fork to %d.%d", a.Version.Major, a.Version.Minor)
+	case TreasuryWithdrawals:
+		action = fmt.Sprintf("treasury withdrawals (%d)", len(a.Withdrawals))
+	case NoConfidence:
+		action = "no confidence"
+	case UpdateCommittee:
+		action = fmt.Sprintf(
+			"update committee (remove %d, add %d, quorum %s/%s)",
+			len(a.Remove),
+			len(a.Add),
+			amount(a.Quorum.Numerator),
+			amount(a.Quorum.Denominator),
+		)
+	case NewConstitution:
+		action = "new constitution"
+	case InfoAction:
+		action = "info"
+	default:
+		action = fmt.Sprintf("%T", p.Action)
+	}
+	return fmt.Sprintf(
+		"%s, deposit %s, return %s",
+		action,
+		amount(p.Deposit),
+		describeCredential(p.ReturnAddress),
+	)
+}
+
+// describeData renders pd on one line in the syntax used by the UPLC pretty
+// printer, truncated to summaryDataWidth characters.
+func describeData(pd data.PlutusData) string {
+	var b strings.Builder
+	writeData(&b, pd)
+	out := b.String()
+	if len(out) > summaryDataWidth {
+		out = out[:summaryDataWidth-3] + "..."
+	}
+	return out
+}
+
+func writeData(b *strings.Builder, pd data.PlutusData) {
+	// Stop early once the output will be truncated anyway.
+	if b.Len() > summaryDataWidth {
+		return
+	}
+	pd, err := force(pd)
+	if err != nil {
+		b.WriteString("<invalid>")
+		return
+	}
+	switch v := pd.(type) {
+	case *data.Integer:
+		b.WriteString("I " + v.Inner.String())
+	case *data.ByteString:
+		b.WriteString("B #" + hex.EncodeToString(v.Inner))
+	case *data.List:
+		b.WriteString("List [")
+		for i, item := range v.Items {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeData(b, item)
+		}
+		b.WriteString("]")
+	case *data.Map:
+		b.WriteString("Map [")
+		for i, pair := range v.Pairs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("(")
+			writeData(b, pair[0])
+			b.WriteString(", ")
+			writeData(b, pair[1])
+			b.WriteString(")")
+		}
+		b.WriteString("]")
+	case *data.Constr:
+		fmt.Fprintf(b, "Constr %d [", v.Tag)
+		for i, field := range v.Fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeData(b, field)
+		}
+		b.WriteString("]")
+	case nil:
+		b.WriteString("<none>")
+	default:
+		fmt.Fprintf(b, "%v", pd)
+	}
+}
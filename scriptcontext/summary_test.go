@@ -0,0 +1,156 @@
+package scriptcontext
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+func TestDecode(t *testing.T) {
+	ctx := ScriptContextV1{
+		TxInfo:  TxInfoV1{Fee: Lovelace(big.NewInt(0)), Mint: Lovelace(big.NewInt(0)), ID: hash(0x07, 32)},
+		Purpose: Minting{Policy: hash(0xcc, 28)},
+	}
+	pd := ctx.ToData()
+	for _, version := range []builtin.PlutusVersion{builtin.PlutusV1, builtin.PlutusV2} {
+		decoded, err := Decode(version, pd)
+		if version == builtin.PlutusV1 {
+			if err != nil {
+				t.Fatalf("Decode(V1) failed: %v", err)
+			}
+			if _, ok := decoded.(ScriptContextV1); !ok {
+				t.Fatalf("Decode(V1) returned %T", decoded)
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("Decode(V%d) accepted a V1 context", version)
+		}
+	}
+	if _, err := Decode(builtin.PlutusV4, pd); err == nil {
+		t.Fatal("Decode(V4) should fail")
+	}
+	if _, err := DecodeCBOR(builtin.PlutusV1, []byte{0xff}); err == nil {
+		t.Fatal("DecodeCBOR() should reject invalid CBOR")
+	}
+}
+
+func TestSummaryV3(t *testing.T) {
+	ref := TxOutRef{TxID: hash(0x01, 32), Index: 7}
+	key := Credential{Type: PubKeyCredential, Hash: hash(0x11, 28)}
+	ctx := ScriptContextV3{
+		TxInfo: TxInfoV3{
+			Inputs: []TxInInfo{{
+				OutRef: ref,
+				Resolved: TxOut{
+					Address: sampleAddress(),
+					Value:   sampleValue(),
+					Datum:   InlineDatum{Datum: data.NewConstr(0, data.NewInteger(big.NewInt(42)))},
+				},
+			}},
+			Outputs: []TxOut{{Address: sampleAddress(), Value: Lovelace(big.NewInt(9)), Datum: NoOutputDatum{}}},
+			Fee:     big.NewInt(180_000),
+			Mint: Value{{
+				Policy: hash(0xcc, 28),
+				Assets: []Asset{{Name: []byte("token"), Quantity: big.NewInt(-1)}},
+			}},
+			Certificates: []TxCert{TxCertPoolRetire{Pool: hash(0x04, 28), Epoch: 500}},
+			ValidRange: Interval{
+				Lower: IntervalBound{Kind: Finite, Time: big.NewInt(1000), Closed: true},
+				Upper: IntervalBound{Kind: PosInf, Closed: true},
+			},
+			Signatories: [][]byte{hash(0x06, 28)},
+			Redeemers: []RedeemerEntry{
+				{Purpose: Spending{OutRef: ref}, Redeemer: data.NewByteString([]byte{0xab})},
+			},
+			ID: hash(0x07, 32),
+			Votes: []VoterVotes{{
+				Voter: DRepVoter{Credential: key},
+				Votes: []GovernanceVote{{Action: GovernanceActionID{TxID: hash(0x12, 32)}, Vote: VoteNo}},
+			}},
+			TreasuryDonation: big.NewInt(5),
+		},
+		Redeemer: data.NewByteString([]byte{0xab}),
+		Info:     SpendingScript{OutRef: ref},
+	}
+
+	got := Summary(ctx)
+	for _, want := range []string{
+		"PlutusV3 script context\n",
+		"  purpose:     spending " + strings.Repeat("01", 32) + "#7 (no datum)\n",
+		"  redeemer:    B #ab\n",
+		"  fee:         180000 lovelace\n",
+		"  validity:    [1000, +inf]\n",
+		"inputs (1):\n",
+		"    datum:   inline Constr 0 [I 42]\n",
+		"outputs (1):\n",
+		"    value:   9 lovelace\n",
+		"mint: -1 " + strings.Repeat("cc", 28) + `."token"` + "\n",
+		"signatories (1):\n",
+		"  [0] retire pool " + strings.Repeat("04", 28) + " at epoch 500\n",
+		"votes (1):\n",
+		"  drep key " + strings.Repeat("11", 28) + ":\n",
+		"    " + strings.Repeat("12", 32) + "#0: No\n",
+		"treasury donation: 5 lovelace\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summary() missing %q\n%s", want, got)
+		}
+	}
+	for _, absent := range []string{"withdrawals", "reference inputs", "proposals", "datum:   none"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("Summary() unexpectedly contains %q\n%s", absent, got)
+		}
+	}
+}
+
+func TestDescribeDataTruncates(t *testing.T) {
+	items := make([]data.PlutusData, 100)
+	for i := range items {
+		items[i] = data.NewInteger(big.NewInt(int64(i)))
+	}
+	got := describeData(data.NewList(items...))
+	if len(got) != summaryDataWidth || !strings.HasSuffix(got, "...") {
+		t.Fatalf("describeData() = %q, want %d characters ending in ...", got, summaryDataWidth)
+	}
+	if got := describeData(data.NewMap([][2]data.PlutusData{
+		{data.NewByteString([]byte{1}), data.NewList()},
+	})); got != "Map [(B #01, List [])]" {
+		t.Fatalf("describeData() = %q", got)
+	}
+}
+
+func TestSummaryMainnet(t *testing.T) {
+	versions := map[string]builtin.PlutusVersion{
+		"PlutusV1": builtin.PlutusV1,
+		"PlutusV2": builtin.PlutusV2,
+		"PlutusV3": builtin.PlutusV3,
+	}
+	for _, c := range mainnetCases(t) {
+		t.Run(c.ID, func(t *testing.T) {
+			version, ok := versions[c.Language]
+			if !ok {
+				t.Skipf("no context decoder for %s", c.Language)
+			}
+			ctx, err := DecodeCBOR(version, c.context(t))
+			if err != nil {
+				t.Fatalf("DecodeCBOR() failed: %v", err)
+			}
+			got := Summary(ctx)
+			for _, want := range []string{
+				c.Language + " script context\n",
+				"  purpose:     ",
+				"  transaction: ",
+				"inputs (",
+				"outputs (",
+			} {
+				if !strings.Contains(got, want) {
+					t.Errorf("Summary() missing %q\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package scriptcontext
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// DCert is a V1/V2 delegation certificate.
+type DCert interface {
+	ToData() data.PlutusData
+	isDCert()
+}
+
+type DCertDelegRegKey struct {
+	Credential StakingCredential
+}
+
+type DCertDelegDeRegKey struct {
+	Credential StakingCredential
+}
+
+type DCertDelegDelegate struct {
+	Credential StakingCredential
+	Pool       []byte
+}
+
+type DCertPoolRegister struct {
+	Pool []byte
+	VRF  []byte
+}
+
+type DCertPoolRetire struct {
+	Pool  []byte
+	Epoch uint64
+}
+
+type DCertGenesis struct{}
+
+type DCertMir struct{}
+
+func (DCertDelegRegKey) isDCert()   {}
+func (DCertDelegDeRegKey) isDCert() {}
+func (DCertDelegDelegate) isDCert() {}
+func (DCertPoolRegister) isDCert()  {}
+func (DCertPoolRetire) isDCert()    {}
+func (DCertGenesis) isDCert()       {}
+func (DCertMir) isDCert()           {}
+
+func (c DCertDelegRegKey) ToData() data.PlutusData {
+	return data.NewConstr(0, c.Credential.ToData())
+}
+
+func (c DCertDelegDeRegKey) ToData() data.PlutusData {
+	return data.NewConstr(1, c.Credential.ToData())
+}
+
+func (c DCertDelegDelegate) ToData() data.PlutusData {
+	return data.NewConstr(2, c.Credential.ToData(), data.NewByteString(c.Pool))
+}
+
+func (c DCertPoolRegister) ToData() data.PlutusData {
+	return data.NewConstr(3, data.NewByteString(c.Pool), data.NewByteString(c.VRF))
+}
+
+func (c DCertPoolRetire) ToData() data.PlutusData {
+	return data.NewConstr(4, data.NewByteString(c.Pool), uint64Data(c.Epoch))
+}
+
+func (DCertGenesis) ToData() data.PlutusData {
+	return data.NewConstr(5)
+}
+
+func (DCertMir) ToData() data.PlutusData {
+	return data.NewConstr(6)
+}
+
+func DCertFromData(pd data.PlutusData) (DCert, error) {
+	tag, fields, err := variantOf(pd, "DCert", []int{1, 1, 2, 2, 2, 0, 0})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0, 1:
+		cred, err := StakingCredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if tag == 0 {
+			return DCertDelegRegKey{Credential: cred}, nil
+		}
+		return DCertDelegDeRegKey{Credential: cred}, nil
+	case 2:
+		cred, err := StakingCredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		pool, err := bytesOf(fields[1], "DCert pool")
+		if err != nil {
+			return nil, err
+		}
+		return DCertDelegDelegate{Credential: cred, Pool: pool}, nil
+	case 3:
+		pool, err := bytesOf(fields[0], "DCert pool")
+		if err != nil {
+			return nil, err
+		}
+		vrf, err := bytesOf(fields[1], "DCert VRF")
+		if err != nil {
+			return nil, err
+		}
+		return DCertPoolRegister{Pool: pool, VRF: vrf}, nil
+	case 4:
+		pool, err := bytesOf(fields[0], "DCert pool")
+		if err != nil {
+			return nil, err
+		}
+		epoch, err := uint64Of(fields[1], "DCert epoch")
+		if err != nil {
+			return nil, err
+		}
+		return DCertPoolRetire{Pool: pool, Epoch: epoch}, nil
+	case 5:
+		return DCertGenesis{}, nil
+	default:
+		return DCertMir{}, nil
+	}
+}
+
+// ScriptPurposeV1 is the V1/V2 script purpose: Minting, SpendingV1,
+// RewardingV1 or CertifyingV1.
+type ScriptPurposeV1 interface {
+	ToData() data.PlutusData
+	isScriptPurposeV1()
+}
+
+// Minting is the purpose of a minting policy. It has the same encoding in
+// every language version.
+type Minting struct {
+	Policy []byte
+}
+
+type SpendingV1 struct {
+	OutRef TxOutRef
+}
+
+type RewardingV1 struct {
+	Credential StakingCredential
+}
+
+type CertifyingV1 struct {
+	Cert DCert
+}
+
+func (Minting) isScriptPurposeV1()      {}
+func (SpendingV1) isScriptPurposeV1()   {}
+func (RewardingV1) isScriptPurposeV1()  {}
+func (CertifyingV1) isScriptPurposeV1() {}
+
+func (p Minting) ToData() data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(p.Policy))
+}
+
+func (p SpendingV1) ToData() data.PlutusData {
+	return data.NewConstr(1, p.OutRef.toDataV1())
+}
+
+func (p RewardingV1) ToData() data.PlutusData {
+	return data.NewConstr(2, p.Credential.ToData())
+}
+
+func (p CertifyingV1) ToData() data.PlutusData {
+	return data.NewConstr(3, p.Cert.ToData())
+}
+
+func ScriptPurposeV1FromData(pd data.PlutusData) (ScriptPurposeV1, error) {
+	tag, fields, err := variantOf(pd, "ScriptPurpose", []int{1, 1, 1, 1})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		policy, err := bytesOf(fields[0], "Minting policy")
+		if err != nil {
+			return nil, err
+		}
+		return Minting{Policy: policy}, nil
+	case 1:
+		ref, err := txOutRefV1FromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return SpendingV1{OutRef: ref}, nil
+	case 2:
+		cred, err := StakingCredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return RewardingV1{Credential: cred}, nil
+	default:
+		cert, err := DCertFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return CertifyingV1{Cert: cert}, nil
+	}
+}
+
+// WithdrawalV1 is a V1/V2 reward withdrawal.
+type WithdrawalV1 struct {
+	Credential StakingCredential
+	Amount     *big.Int
+}
+
+func withdrawalV1FromData(k, v data.PlutusData) (WithdrawalV1, error) {
+	cred, err := StakingCredentialFromData(k)
+	if err != nil {
+		return WithdrawalV1{}, err
+	}
+	amount, err := integerOf(v, "withdrawal amount")
+	if err != nil {
+		return WithdrawalV1{}, err
+	}
+	return WithdrawalV1{Credential: cred, Amount: amount}, nil
+}
+
+// TxInfoV1 is the transaction information seen by PlutusV1 scripts.
+//
+// The ledger includes a zero-ada entry in Mint and reports Fee as a Value;
+// builders reproducing ledger contexts must do the same.
+type TxInfoV1 struct {
+	Inputs       []TxInInfoV1
+	Outputs      []TxOutV1
+	Fee          Value
+	Mint         Value
+	Certificates []DCert
+	Withdrawals  []WithdrawalV1
+	ValidRange   Interval
+	Signatories  [][]byte
+	Data         []DatumEntry
+	ID           []byte
+}
+
+func (t TxInfoV1) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		listToData(t.Inputs, TxInInfoV1.ToData),
+		listToData(t.Outputs, TxOutV1.ToData),
+		t.Fee.ToData(),
+		t.Mint.ToData(),
+		listToData(t.Certificates, DCert.ToData),
+		listToData(t.Withdrawals, func(w WithdrawalV1) data.PlutusData {
+			return tupleToData(w.Credential.ToData(), integerData(w.Amount))
+		}),
+		t.ValidRange.ToData(),
+		bytesListToData(t.Signatories),
+		listToData(t.Data, func(d DatumEntry) data.PlutusData {
+			return tupleToData(data.NewByteString(d.Hash), d.Datum)
+		}),
+		txIDV1ToData(t.ID),
+	)
+}
+
+func TxInfoV1FromData(pd data.PlutusData) (TxInfoV1, error) {
+	fields, err := fieldsOf(pd, "TxInfo", 0, 10)
+	if err != nil {
+		return TxInfoV1{}, err
+	}
+	var t TxInfoV1
+	if t.Inputs, err = listFromData(fields[0], "inputs", TxInInfoV1FromData); err != nil {
+		return TxInfoV1{}, err
+	}
+	if t.Outputs, err = listFromData(fields[1], "outputs", TxOutV1FromData); err != nil {
+		return TxInfoV1{}, err
+	}
+	if t.Fee, err = ValueFromData(fields[2]); err != nil {
+		return TxInfoV1{}, fmt.Errorf("fee: %w", err)
+	}
+	if t.Mint, err = ValueFromData(fields[3]); err != nil {
+		return TxInfoV1{}, fmt.Errorf("mint: %w", err)
+	}
+	if t.Certificates, err = listFromData(fields[4], "certificates", DCertFromData); err != nil {
+		return TxInfoV1{}, err
+	}
+	t.Withdrawals, err = listFromData(fields[5], "withdrawals", func(item data.PlutusData) (WithdrawalV1, error) {
+		k, v, err := tupleFromData(item, "withdrawal")
+		if err != nil {
+			return WithdrawalV1{}, err
+		}
+		return withdrawalV1FromData(k, v)
+	})
+	if err != nil {
+		return TxInfoV1{}, err
+	}
+	if t.ValidRange, err = IntervalFromData(fields[6]); err != nil {
+		return TxInfoV1{}, fmt.Errorf("valid range: %w", err)
+	}
+	if t.Signatories, err = bytesListFromData(fields[7], "signatories"); err != nil {
+		return TxInfoV1{}, err
+	}
+	t.Data, err = listFromData(fields[8], "data", func(item data.PlutusData) (DatumEntry, error) {
+		k, v, err := tupleFromData(item, "datum")
+		if err != nil {
+			return DatumEntry{}, err
+		}
+		return datumEntryFromData(k, v)
+	})
+	if err != nil {
+		return TxInfoV1{}, err
+	}
+	if t.ID, err = txIDV1FromData(fields[9]); err != nil {
+		return TxInfoV1{}, err
+	}
+	return t, nil
+}
+
+func datumEntryFromData(k, v data.PlutusData) (DatumEntry, error) {
+	hash, err := bytesOf(k, "datum hash")
+	if err != nil {
+		return DatumEntry{}, err
+	}
+	return DatumEntry{Hash: hash, Datum: v}, nil
+}
+
+// ScriptContextV1 is the context argument passed to PlutusV1 scripts.
+type ScriptContextV1 struct {
+	TxInfo  TxInfoV1
+	Purpose ScriptPurposeV1
+}
+
+func (c ScriptContextV1) ToData() data.PlutusData {
+	return data.NewConstr(0, c.TxInfo.ToData(), c.Purpose.ToData())
+}
+
+func ScriptContextV1FromData(pd data.PlutusData) (ScriptContextV1, error) {
+	fields, err := fieldsOf(pd, "ScriptContext", 0, 2)
+	if err != nil {
+		return ScriptContextV1{}, err
+	}
+	txInfo, err := TxInfoV1FromData(fields[0])
+	if err != nil {
+		return ScriptContextV1{}, fmt.Errorf("TxInfo: %w", err)
+	}
+	purpose, err := ScriptPurposeV1FromData(fields[1])
+	if err != nil {
+		return ScriptContextV1{}, err
+	}
+	return ScriptContextV1{TxInfo: txInfo, Purpose: purpose}, nil
+}
@@ -0,0 +1,135 @@
+package scriptcontext
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// RedeemerEntryV1 is a V2 redeemer keyed by its V1/V2 script purpose.
+type RedeemerEntryV1 struct {
+	Purpose  ScriptPurposeV1
+	Redeemer data.PlutusData
+}
+
+// TxInfoV2 is the transaction information seen by PlutusV2 scripts. It adds
+// reference inputs, inline datums, reference scripts and the redeemer map
+// to TxInfoV1, and encodes withdrawals and datums as maps.
+type TxInfoV2 struct {
+	Inputs          []TxInInfo
+	ReferenceInputs []TxInInfo
+	Outputs         []TxOut
+	Fee             Value
+	Mint            Value
+	Certificates    []DCert
+	Withdrawals     []WithdrawalV1
+	ValidRange      Interval
+	Signatories     [][]byte
+	Redeemers       []RedeemerEntryV1
+	Data            []DatumEntry
+	ID              []byte
+}
+
+func (t TxInfoV2) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		listToData(t.Inputs, TxInInfo.toDataV2),
+		listToData(t.ReferenceInputs, TxInInfo.toDataV2),
+		listToData(t.Outputs, TxOut.ToData),
+		t.Fee.ToData(),
+		t.Mint.ToData(),
+		listToData(t.Certificates, DCert.ToData),
+		mapToData(t.Withdrawals, func(w WithdrawalV1) (data.PlutusData, data.PlutusData) {
+			return w.Credential.ToData(), integerData(w.Amount)
+		}),
+		t.ValidRange.ToData(),
+		bytesListToData(t.Signatories),
+		mapToData(t.Redeemers, func(r RedeemerEntryV1) (data.PlutusData, data.PlutusData) {
+			return r.Purpose.ToData(), r.Redeemer
+		}),
+		mapToData(t.Data, datumEntryToData),
+		txIDV1ToData(t.ID),
+	)
+}
+
+func TxInfoV2FromData(pd data.PlutusData) (TxInfoV2, error) {
+	fields, err := fieldsOf(pd, "TxInfo", 0, 12)
+	if err != nil {
+		return TxInfoV2{}, err
+	}
+	var t TxInfoV2
+	if t.Inputs, err = listFromData(fields[0], "inputs", txInInfoV2FromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.ReferenceInputs, err = listFromData(fields[1], "reference inputs", txInInfoV2FromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.Outputs, err = listFromData(fields[2], "outputs", TxOutFromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.Fee, err = ValueFromData(fields[3]); err != nil {
+		return TxInfoV2{}, fmt.Errorf("fee: %w", err)
+	}
+	if t.Mint, err = ValueFromData(fields[4]); err != nil {
+		return TxInfoV2{}, fmt.Errorf("mint: %w", err)
+	}
+	if t.Certificates, err = listFromData(fields[5], "certificates", DCertFromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.Withdrawals, err = mapFromData(fields[6], "withdrawals", withdrawalV1FromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.ValidRange, err = IntervalFromData(fields[7]); err != nil {
+		return TxInfoV2{}, fmt.Errorf("valid range: %w", err)
+	}
+	if t.Signatories, err = bytesListFromData(fields[8], "signatories"); err != nil {
+		return TxInfoV2{}, err
+	}
+	t.Redeemers, err = mapFromData(fields[9], "redeemers", func(k, v data.PlutusData) (RedeemerEntryV1, error) {
+		purpose, err := ScriptPurposeV1FromData(k)
+		if err != nil {
+			return RedeemerEntryV1{}, err
+		}
+		return RedeemerEntryV1{Purpose: purpose, Redeemer: v}, nil
+	})
+	if err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.Data, err = mapFromData(fields[10], "data", datumEntryFromData); err != nil {
+		return TxInfoV2{}, err
+	}
+	if t.ID, err = txIDV1FromData(fields[11]); err != nil {
+		return TxInfoV2{}, err
+	}
+	return t, nil
+}
+
+func datumEntryToData(d DatumEntry) (data.PlutusData, data.PlutusData) {
+	return data.NewByteString(d.Hash), d.Datum
+}
+
+// ScriptContextV2 is the context argument passed to PlutusV2 scripts.
+type ScriptContextV2 struct {
+	TxInfo  TxInfoV2
+	Purpose ScriptPurposeV1
+}
+
+func (c ScriptContextV2) ToData() data.PlutusData {
+	return data.NewConstr(0, c.TxInfo.ToData(), c.Purpose.ToData())
+}
+
+func ScriptContextV2FromData(pd data.PlutusData) (ScriptContextV2, error) {
+	fields, err := fieldsOf(pd, "ScriptContext", 0, 2)
+	if err != nil {
+		return ScriptContextV2{}, err
+	}
+	txInfo, err := TxInfoV2FromData(fields[0])
+	if err != nil {
+		return ScriptContextV2{}, fmt.Errorf("TxInfo: %w", err)
+	}
+	purpose, err := ScriptPurposeV1FromData(fields[1])
+	if err != nil {
+		return ScriptContextV2{}, err
+	}
+	return ScriptContextV2{TxInfo: txInfo, Purpose: purpose}, nil
+}
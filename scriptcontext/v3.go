@@ -0,0 +1,615 @@
+package scriptcontext
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// TxCert is a V3 (Conway) certificate. Deposit and Refund fields are nil
+// when the certificate does not state them.
+type TxCert interface {
+	ToData() data.PlutusData
+	isTxCert()
+}
+
+type TxCertRegStaking struct {
+	Credential Credential
+	Deposit    *big.Int
+}
+
+type TxCertUnRegStaking struct {
+	Credential Credential
+	Refund     *big.Int
+}
+
+type TxCertDelegStaking struct {
+	Credential Credential
+	Delegatee  Delegatee
+}
+
+type TxCertRegDeleg struct {
+	Credential Credential
+	Delegatee  Delegatee
+	Deposit    *big.Int
+}
+
+type TxCertRegDRep struct {
+	Credential Credential
+	Deposit    *big.Int
+}
+
+type TxCertUpdateDRep struct {
+	Credential Credential
+}
+
+type TxCertUnRegDRep struct {
+	Credential Credential
+	Refund     *big.Int
+}
+
+type TxCertPoolRegister struct {
+	Pool []byte
+	VRF  []byte
+}
+
+type TxCertPoolRetire struct {
+	Pool  []byte
+	Epoch uint64
+}
+
+type TxCertAuthHotCommittee struct {
+	Cold Credential
+	Hot  Credential
+}
+
+type TxCertResignColdCommittee struct {
+	Cold Credential
+}
+
+func (TxCertRegStaking) isTxCert()          {}
+func (TxCertUnRegStaking) isTxCert()        {}
+func (TxCertDelegStaking) isTxCert()        {}
+func (TxCertRegDeleg) isTxCert()            {}
+func (TxCertRegDRep) isTxCert()             {}
+func (TxCertUpdateDRep) isTxCert()          {}
+func (TxCertUnRegDRep) isTxCert()           {}
+func (TxCertPoolRegister) isTxCert()        {}
+func (TxCertPoolRetire) isTxCert()          {}
+func (TxCertAuthHotCommittee) isTxCert()    {}
+func (TxCertResignColdCommittee) isTxCert() {}
+
+func (c TxCertRegStaking) ToData() data.PlutusData {
+	return data.NewConstr(0, c.Credential.ToData(), maybeIntegerToData(c.Deposit))
+}
+
+func (c TxCertUnRegStaking) ToData() data.PlutusData {
+	return data.NewConstr(1, c.Credential.ToData(), maybeIntegerToData(c.Refund))
+}
+
+func (c TxCertDelegStaking) ToData() data.PlutusData {
+	return data.NewConstr(2, c.Credential.ToData(), c.Delegatee.ToData())
+}
+
+func (c TxCertRegDeleg) ToData() data.PlutusData {
+	return data.NewConstr(
+		3,
+		c.Credential.ToData(),
+		c.Delegatee.ToData(),
+		integerData(c.Deposit),
+	)
+}
+
+func (c TxCertRegDRep) ToData() data.PlutusData {
+	return data.NewConstr(4, c.Credential.ToData(), integerData(c.Deposit))
+}
+
+func (c TxCertUpdateDRep) ToData() data.PlutusData {
+	return data.NewConstr(5, c.Credential.ToData())
+}
+
+func (c TxCertUnRegDRep) ToData() data.PlutusData {
+	return data.NewConstr(6, c.Credential.ToData(), integerData(c.Refund))
+}
+
+func (c TxCertPoolRegister) ToData() data.PlutusData {
+	return data.NewConstr(7, data.NewByteString(c.Pool), data.NewByteString(c.VRF))
+}
+
+func (c TxCertPoolRetire) ToData() data.PlutusData {
+	return data.NewConstr(8, data.NewByteString(c.Pool), uint64Data(c.Epoch))
+}
+
+func (c TxCertAuthHotCommittee) ToData() data.PlutusData {
+	return data.NewConstr(9, c.Cold.ToData(), c.Hot.ToData())
+}
+
+func (c TxCertResignColdCommittee) ToData() data.PlutusData {
+	return data.NewConstr(10, c.Cold.ToData())
+}
+
+func TxCertFromData(pd data.PlutusData) (TxCert, error) {
+	tag, fields, err := variantOf(pd, "TxCert", []int{2, 2, 2, 3, 2, 1, 2, 2, 2, 2, 1})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 7, 8:
+		pool, err := bytesOf(fields[0], "TxCert pool")
+		if err != nil {
+			return nil, err
+		}
+		if tag == 7 {
+			vrf, err := bytesOf(fields[1], "TxCert VRF")
+			if err != nil {
+				return nil, err
+			}
+			return TxCertPoolRegister{Pool: pool, VRF: vrf}, nil
+		}
+		epoch, err := uint64Of(fields[1], "TxCert epoch")
+		if err != nil {
+			return nil, err
+		}
+		return TxCertPoolRetire{Pool: pool, Epoch: epoch}, nil
+	}
+
+	cred, err := CredentialFromData(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0, 1:
+		amount, err := maybeIntegerFromData(fields[1], "TxCert deposit")
+		if err != nil {
+			return nil, err
+		}
+		if tag == 0 {
+			return TxCertRegStaking{Credential: cred, Deposit: amount}, nil
+		}
+		return TxCertUnRegStaking{Credential: cred, Refund: amount}, nil
+	case 2, 3:
+		delegatee, err := DelegateeFromData(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		if tag == 2 {
+			return TxCertDelegStaking{Credential: cred, Delegatee: delegatee}, nil
+		}
+		deposit, err := integerOf(fields[2], "TxCert deposit")
+		if err != nil {
+			return nil, err
+		}
+		return TxCertRegDeleg{Credential: cred, Delegatee: delegatee, Deposit: deposit}, nil
+	case 4, 6:
+		amount, err := integerOf(fields[1], "TxCert deposit")
+		if err != nil {
+			return nil, err
+		}
+		if tag == 4 {
+			return TxCertRegDRep{Credential: cred, Deposit: amount}, nil
+		}
+		return TxCertUnRegDRep{Credential: cred, Refund: amount}, nil
+	case 5:
+		return TxCertUpdateDRep{Credential: cred}, nil
+	case 9:
+		hot, err := CredentialFromData(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return TxCertAuthHotCommittee{Cold: cred, Hot: hot}, nil
+	default:
+		return TxCertResignColdCommittee{Cold: cred}, nil
+	}
+}
+
+// ScriptPurpose is the V3 script purpose used as the key of the redeemer
+// map: Minting, Spending, Rewarding, Certifying, Voting or Proposing.
+type ScriptPurpose interface {
+	ToData() data.PlutusData
+	isScriptPurpose()
+}
+
+type Spending struct {
+	OutRef TxOutRef
+}
+
+type Rewarding struct {
+	Credential Credential
+}
+
+type Certifying struct {
+	Index uint64
+	Cert  TxCert
+}
+
+type Voting struct {
+	Voter Voter
+}
+
+type Proposing struct {
+	Index    uint64
+	Proposal ProposalProcedure
+}
+
+func (Minting) isScriptPurpose()    {}
+func (Spending) isScriptPurpose()   {}
+func (Rewarding) isScriptPurpose()  {}
+func (Certifying) isScriptPurpose() {}
+func (Voting) isScriptPurpose()     {}
+func (Proposing) isScriptPurpose()  {}
+
+func (p Spending) ToData() data.PlutusData {
+	return data.NewConstr(1, p.OutRef.toData())
+}
+
+func (p Rewarding) ToData() data.PlutusData {
+	return data.NewConstr(2, p.Credential.ToData())
+}
+
+func (p Certifying) ToData() data.PlutusData {
+	return data.NewConstr(3, uint64Data(p.Index), p.Cert.ToData())
+}
+
+func (p Voting) ToData() data.PlutusData {
+	return data.NewConstr(4, p.Voter.ToData())
+}
+
+func (p Proposing) ToData() data.PlutusData {
+	return data.NewConstr(5, uint64Data(p.Index), p.Proposal.ToData())
+}
+
+func ScriptPurposeFromData(pd data.PlutusData) (ScriptPurpose, error) {
+	tag, fields, err := variantOf(pd, "ScriptPurpose", []int{1, 1, 1, 2, 1, 2})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		policy, err := bytesOf(fields[0], "Minting policy")
+		if err != nil {
+			return nil, err
+		}
+		return Minting{Policy: policy}, nil
+	case 1:
+		ref, err := txOutRefFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return Spending{OutRef: ref}, nil
+	case 2:
+		cred, err := CredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return Rewarding{Credential: cred}, nil
+	case 3:
+		index, cert, err := certifyingFromData(fields)
+		if err != nil {
+			return nil, err
+		}
+		return Certifying{Index: index, Cert: cert}, nil
+	case 4:
+		voter, err := VoterFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return Voting{Voter: voter}, nil
+	default:
+		index, proposal, err := proposingFromData(fields)
+		if err != nil {
+			return nil, err
+		}
+		return Proposing{Index: index, Proposal: proposal}, nil
+	}
+}
+
+// ScriptInfo describes why a V3 script is being run. Unlike ScriptPurpose it
+// carries the datum of the output being spent, if any.
+type ScriptInfo interface {
+	ToData() data.PlutusData
+	isScriptInfo()
+}
+
+type MintingScript struct {
+	Policy []byte
+}
+
+type SpendingScript struct {
+	OutRef TxOutRef
+	Datum  data.PlutusData // nil when the output has no datum
+}
+
+type RewardingScript struct {
+	Credential Credential
+}
+
+type CertifyingScript struct {
+	Index uint64
+	Cert  TxCert
+}
+
+type VotingScript struct {
+	Voter Voter
+}
+
+type ProposingScript struct {
+	Index    uint64
+	Proposal ProposalProcedure
+}
+
+func (MintingScript) isScriptInfo()    {}
+func (SpendingScript) isScriptInfo()   {}
+func (RewardingScript) isScriptInfo()  {}
+func (CertifyingScript) isScriptInfo() {}
+func (VotingScript) isScriptInfo()     {}
+func (ProposingScript) isScriptInfo()  {}
+
+func (s MintingScript) ToData() data.PlutusData {
+	return data.NewConstr(0, data.NewByteString(s.Policy))
+}
+
+func (s SpendingScript) ToData() data.PlutusData {
+	return data.NewConstr(1, s.OutRef.toData(), maybeToData(s.Datum))
+}
+
+func (s RewardingScript) ToData() data.PlutusData {
+	return data.NewConstr(2, s.Credential.ToData())
+}
+
+func (s CertifyingScript) ToData() data.PlutusData {
+	return data.NewConstr(3, uint64Data(s.Index), s.Cert.ToData())
+}
+
+func (s VotingScript) ToData() data.PlutusData {
+	return data.NewConstr(4, s.Voter.ToData())
+}
+
+func (s ProposingScript) ToData() data.PlutusData {
+	return data.NewConstr(5, uint64Data(s.Index), s.Proposal.ToData())
+}
+
+func ScriptInfoFromData(pd data.PlutusData) (ScriptInfo, error) {
+	tag, fields, err := variantOf(pd, "ScriptInfo", []int{1, 2, 1, 2, 1, 2})
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		policy, err := bytesOf(fields[0], "MintingScript policy")
+		if err != nil {
+			return nil, err
+		}
+		return MintingScript{Policy: policy}, nil
+	case 1:
+		ref, err := txOutRefFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		datum, err := maybeFromData(fields[1], "SpendingScript datum")
+		if err != nil {
+			return nil, err
+		}
+		return SpendingScript{OutRef: ref, Datum: datum}, nil
+	case 2:
+		cred, err := CredentialFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return RewardingScript{Credential: cred}, nil
+	case 3:
+		index, cert, err := certifyingFromData(fields)
+		if err != nil {
+			return nil, err
+		}
+		return CertifyingScript{Index: index, Cert: cert}, nil
+	case 4:
+		voter, err := VoterFromData(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return VotingScript{Voter: voter}, nil
+	default:
+		index, proposal, err := proposingFromData(fields)
+		if err != nil {
+			return nil, err
+		}
+		return ProposingScript{Index: index, Proposal: proposal}, nil
+	}
+}
+
+func certifyingFromData(fields []data.PlutusData) (uint64, TxCert, error) {
+	index, err := uint64Of(fields[0], "certificate index")
+	if err != nil {
+		return 0, nil, err
+	}
+	cert, err := TxCertFromData(fields[1])
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, cert, nil
+}
+
+func proposingFromData(fields []data.PlutusData) (uint64, ProposalProcedure, error) {
+	index, err := uint64Of(fields[0], "proposal index")
+	if err != nil {
+		return 0, ProposalProcedure{}, err
+	}
+	proposal, err := ProposalProcedureFromData(fields[1])
+	if err != nil {
+		return 0, ProposalProcedure{}, err
+	}
+	return index, proposal, nil
+}
+
+// RedeemerEntry is a V3 redeemer keyed by its script purpose.
+type RedeemerEntry struct {
+	Purpose  ScriptPurpose
+	Redeemer data.PlutusData
+}
+
+// TxInfoV3 is the transaction information seen by PlutusV3 scripts.
+//
+// Compared to TxInfoV2, the fee is plain lovelace, Mint carries no zero-ada
+// entry, certificates are Conway TxCerts, withdrawals are keyed by
+// Credential, and the governance fields are added. CurrentTreasuryAmount and
+// TreasuryDonation are nil when absent.
+type TxInfoV3 struct {
+	Inputs                []TxInInfo
+	ReferenceInputs       []TxInInfo
+	Outputs               []TxOut
+	Fee                   *big.Int
+	Mint                  Value
+	Certificates          []TxCert
+	Withdrawals           []Withdrawal
+	ValidRange            Interval
+	Signatories           [][]byte
+	Redeemers             []RedeemerEntry
+	Data                  []DatumEntry
+	ID                    []byte
+	Votes                 []VoterVotes
+	ProposalProcedures    []ProposalProcedure
+	CurrentTreasuryAmount *big.Int
+	TreasuryDonation      *big.Int
+}
+
+func (t TxInfoV3) ToData() data.PlutusData {
+	return data.NewConstr(
+		0,
+		listToData(t.Inputs, TxInInfo.toDataV3),
+		listToData(t.ReferenceInputs, TxInInfo.toDataV3),
+		listToData(t.Outputs, TxOut.ToData),
+		integerData(t.Fee),
+		t.Mint.ToData(),
+		listToData(t.Certificates, TxCert.ToData),
+		mapToData(t.Withdrawals, withdrawalToData),
+		t.ValidRange.ToData(),
+		bytesListToData(t.Signatories),
+		mapToData(t.Redeemers, func(r RedeemerEntry) (data.PlutusData, data.PlutusData) {
+			return r.Purpose.ToData(), r.Redeemer
+		}),
+		mapToData(t.Data, datumEntryToData),
+		data.NewByteString(t.ID),
+		mapToData(t.Votes, func(v VoterVotes) (data.PlutusData, data.PlutusData) {
+			votes := mapToData(v.Votes, func(gv GovernanceVote) (data.PlutusData, data.PlutusData) {
+				return gv.Action.ToData(), gv.Vote.ToData()
+			})
+			return v.Voter.ToData(), votes
+		}),
+		listToData(t.ProposalProcedures, ProposalProcedure.ToData),
+		maybeIntegerToData(t.CurrentTreasuryAmount),
+		maybeIntegerToData(t.TreasuryDonation),
+	)
+}
+
+func TxInfoV3FromData(pd data.PlutusData) (TxInfoV3, error) {
+	fields, err := fieldsOf(pd, "TxInfo", 0, 16)
+	if err != nil {
+		return TxInfoV3{}, err
+	}
+	var t TxInfoV3
+	if t.Inputs, err = listFromData(fields[0], "inputs", txInInfoV3FromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.ReferenceInputs, err = listFromData(fields[1], "reference inputs", txInInfoV3FromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.Outputs, err = listFromData(fields[2], "outputs", TxOutFromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.Fee, err = integerOf(fields[3], "fee"); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.Mint, err = ValueFromData(fields[4]); err != nil {
+		return TxInfoV3{}, fmt.Errorf("mint: %w", err)
+	}
+	if t.Certificates, err = listFromData(fields[5], "certificates", TxCertFromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.Withdrawals, err = mapFromData(fields[6], "withdrawals", withdrawalFromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.ValidRange, err = IntervalFromData(fields[7]); err != nil {
+		return TxInfoV3{}, fmt.Errorf("valid range: %w", err)
+	}
+	if t.Signatories, err = bytesListFromData(fields[8], "signatories"); err != nil {
+		return TxInfoV3{}, err
+	}
+	t.Redeemers, err = mapFromData(fields[9], "redeemers", func(k, v data.PlutusData) (RedeemerEntry, error) {
+		purpose, err := ScriptPurposeFromData(k)
+		if err != nil {
+			return RedeemerEntry{}, err
+		}
+		return RedeemerEntry{Purpose: purpose, Redeemer: v}, nil
+	})
+	if err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.Data, err = mapFromData(fields[10], "data", datumEntryFromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.ID, err = bytesOf(fields[11], "id"); err != nil {
+		return TxInfoV3{}, err
+	}
+	t.Votes, err = mapFromData(fields[12], "votes", func(k, v data.PlutusData) (VoterVotes, error) {
+		voter, err := VoterFromData(k)
+		if err != nil {
+			return VoterVotes{}, err
+		}
+		votes, err := mapFromData(v, "voter votes", func(k, v data.PlutusData) (GovernanceVote, error) {
+			action, err := GovernanceActionIDFromData(k)
+			if err != nil {
+				return GovernanceVote{}, err
+			}
+			vote, err := VoteFromData(v)
+			if err != nil {
+				return GovernanceVote{}, err
+			}
+			return GovernanceVote{Action: action, Vote: vote}, nil
+		})
+		if err != nil {
+			return VoterVotes{}, err
+		}
+		return VoterVotes{Voter: voter, Votes: votes}, nil
+	})
+	if err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.ProposalProcedures, err = listFromData(fields[13], "proposal procedures", ProposalProcedureFromData); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.CurrentTreasuryAmount, err = maybeIntegerFromData(fields[14], "current treasury amount"); err != nil {
+		return TxInfoV3{}, err
+	}
+	if t.TreasuryDonation, err = maybeIntegerFromData(fields[15], "treasury donation"); err != nil {
+		return TxInfoV3{}, err
+	}
+	return t, nil
+}
+
+// ScriptContextV3 is the single argument passed to PlutusV3 scripts.
+type ScriptContextV3 struct {
+	TxInfo   TxInfoV3
+	Redeemer data.PlutusData
+	Info     ScriptInfo
+}
+
+func (c ScriptContextV3) ToData() data.PlutusData {
+	return data.NewConstr(0, c.TxInfo.ToData(), c.Redeemer, c.Info.ToData())
+}
+
+func ScriptContextV3FromData(pd data.PlutusData) (ScriptContextV3, error) {
+	fields, err := fieldsOf(pd, "ScriptContext", 0, 3)
+	if err != nil {
+		return ScriptContextV3{}, err
+	}
+	txInfo, err := TxInfoV3FromData(fields[0])
+	if err != nil {
+		return ScriptContextV3{}, fmt.Errorf("TxInfo: %w", err)
+	}
+	info, err := ScriptInfoFromData(fields[2])
+	if err != nil {
+		return ScriptContextV3{}, err
+	}
+	return ScriptContextV3{TxInfo: txInfo, Redeemer: fields[1], Info: info}, nil
+}
@@ -0,0 +1,66 @@
+package syn
+
+import (
+	"sort"
+)
+
+// Comments are the "--" comments of a program's source, which [Parse]
+// drops. [FormatComments] prints them again, each on its own line before
+// the term that follows it in the source.
+type Comments struct {
+	// leading come before the program and trailing after its last term.
+	leading, trailing []string
+	// terms holds the comments before each term, by the term's index in a
+	// preorder walk of the program.
+	terms map[int][]string
+}
+
+// termStart is where a term starts in the source. An application of n
+// arguments is n Apply nodes, which follow each other in preorder.
+type termStart struct {
+	position int
+	nodes    int
+}
+
+// ParseComments parses a program as [Parse] does, and also returns its
+// comments.
+func ParseComments(input string) (*Program[Name], *Comments, error) {
+	p := NewParser(input)
+	p.trackTerms = true
+	start := p.curToken.Position
+
+	program, err := p.ParseProgram()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Map the terms, in source order, to their preorder indices.
+	indices := make([]int, len(p.terms))
+	index := 0
+	for i, term := range p.terms {
+		indices[i] = index
+		index += term.nodes
+	}
+
+	comments := &Comments{terms: make(map[int][]string)}
+	for _, comment := range p.lexer.Comments() {
+		if comment.Position < start {
+			comments.leading = append(comments.leading, comment.Text)
+
+			continue
+		}
+
+		next := sort.Search(len(p.terms), func(i int) bool {
+			return p.terms[i].position > comment.Position
+		})
+		if next == len(p.terms) {
+			comments.trailing = append(comments.trailing, comment.Text)
+
+			continue
+		}
+
+		comments.terms[indices[next]] = append(comments.terms[indices[next]], comment.Text)
+	}
+
+	return program, comments, nil
+}
@@ -0,0 +1,121 @@
+package syn
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormatComments(t *testing.T) {
+	const input = `-- leading
+-- comments
+(program 1.1.0
+  -- before the lambda
+  (lam x [
+    (builtin addInteger) -- after the builtin
+    x
+    -- before the constant
+    (con integer 1)
+  ])
+  -- after the term
+)
+-- trailing
+`
+	const want = `-- leading
+-- comments
+(program 1.1.0
+  -- before the lambda
+  (lam x
+    [
+      [
+        (builtin addInteger)
+        -- after the builtin
+        x
+      ]
+      -- before the constant
+      (con integer 1)
+    ]
+  )
+)
+-- after the term
+-- trailing
+`
+	program, comments, err := ParseComments(input)
+	if err != nil {
+		t.Fatalf("ParseComments() failed: %v", err)
+	}
+	got := FormatComments(program, comments, DefaultLayout)
+	if got != want {
+		t.Errorf("FormatComments() =\n%s\nwant\n%s", got, want)
+	}
+	if again := formatComments(t, got); again != got {
+		t.Errorf("FormatComments() is not idempotent:\n%s", again)
+	}
+	if plain := Format(program, DefaultLayout); strings.Contains(plain, "--") {
+		t.Errorf("Format() printed comments:\n%s", plain)
+	}
+}
+
+// TestFormatCommentsRoundTrip formats the conformance programs that have
+// comments, and checks that the output keeps every comment, parses back to
+// the same program and formats the same again.
+func TestFormatCommentsRoundTrip(t *testing.T) {
+	err := filepath.WalkDir(filepath.Join("..", "tests", "conformance"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".uplc" {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		program, comments, err := ParseComments(string(src))
+		if err != nil || !strings.Contains(string(src), "--") {
+			return nil
+		}
+		formatted := FormatComments(program, comments, DefaultLayout)
+		reparsed, recomments, err := ParseComments(formatted)
+		if err != nil {
+			t.Errorf("%s: ParseComments(FormatComments()) failed: %v\n%s", path, err, formatted)
+			return nil
+		}
+		if Pretty(reparsed) != Pretty(program) {
+			t.Errorf("%s: FormatComments() changed the program:\n%s", path, formatted)
+		}
+		if again := FormatComments(reparsed, recomments, DefaultLayout); again != formatted {
+			t.Errorf("%s: FormatComments() is not idempotent:\n%s\n%s", path, formatted, again)
+		}
+		if got, want := commentTexts(formatted), commentTexts(string(src)); !slices.Equal(got, want) {
+			t.Errorf("%s: FormatComments() comments = %q, want %q", path, got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() failed: %v", err)
+	}
+}
+
+func formatComments(t *testing.T, input string) string {
+	t.Helper()
+	program, comments, err := ParseComments(input)
+	if err != nil {
+		t.Fatalf("ParseComments() failed: %v", err)
+	}
+	return FormatComments(program, comments, DefaultLayout)
+}
+
+// commentTexts returns the comments of a program, sorted.
+func commentTexts(src string) []string {
+	_, comments, err := ParseComments(src)
+	if err != nil {
+		return nil
+	}
+	texts := slices.Concat(comments.leading, comments.trailing)
+	for _, terms := range comments.terms {
+		texts = append(texts, terms...)
+	}
+	slices.Sort(texts)
+	return texts
+}
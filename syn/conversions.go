@@ -4,6 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strconv"
+	"unicode"
+
+	"github.com/blinklabs-io/plutigo/syn/lex"
 )
 
 func NameToNamedDeBruijn(p *Program[Name]) (*Program[NamedDeBruijn], error) {
@@ -53,6 +58,158 @@ func NameToDeBruijn(p *Program[Name]) (*Program[DeBruijn], error) {
 	return program, nil
 }
 
+// DeBruijnToName converts a program with De Bruijn indices, such as one
+// decoded from FLAT, to one with names, whose text parses back to the same
+// program. The binder of a lambda under n others is named i_n.
+func DeBruijnToName(p *Program[DeBruijn]) (*Program[Name], error) {
+	t, err := indexToName(p.Term, nil, func(_ DeBruijn, level int) string {
+		return "i_" + strconv.Itoa(level)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program[Name]{Version: p.Version, Term: t}, nil
+}
+
+// NamedDeBruijnToName converts a program with named De Bruijn indices to
+// one with names, whose text parses back to the same program. Binders keep
+// their text if it is an identifier; otherwise the runes that identifiers
+// cannot hold become _, with a v prefix where it is still not one. A binder
+// whose name would shadow a binder in scope, such as a lambda under n
+// others, gets an _n suffix.
+func NamedDeBruijnToName(p *Program[NamedDeBruijn]) (*Program[Name], error) {
+	t, err := indexToName(p.Term, nil, func(n NamedDeBruijn, _ int) string { return identifier(n.Text) })
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program[Name]{Version: p.Version, Term: t}, nil
+}
+
+// identifier returns text, or a name close to it that the parser reads as a
+// variable.
+func identifier(text string) string {
+	if isIdentifier(text) {
+		return text
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '\'' && r != '-' {
+			runes[i] = '_'
+		}
+	}
+
+	if name := string(runes); isIdentifier(name) {
+		return name
+	}
+
+	return "v" + string(runes)
+}
+
+// isIdentifier reports whether the parser reads text as a variable.
+func isIdentifier(text string) bool {
+	token := lex.NewLexer(text).NextToken()
+
+	return token.Type == lex.TokenIdentifier && token.Literal == text
+}
+
+// indexToName names the binders of a term, given the names of the binders
+// in scope, innermost last. A name that is already in scope gets the
+// binder's level as a suffix, which keeps the names in scope distinct.
+func indexToName[T Eval](
+	term Term[T],
+	scope []Name,
+	text func(T, int) string,
+) (Term[Name], error) {
+	switch t := term.(type) {
+	case *Var[T]:
+		index := t.Name.LookupIndex()
+		if index < 1 || index > len(scope) {
+			return nil, errors.New("FreeIndex")
+		}
+
+		return &Var[Name]{Name: scope[len(scope)-index]}, nil
+	case *Lambda[T]:
+		level := len(scope)
+		name := Name{Text: text(t.ParameterName, level), Unique: Unique(level)}
+		for slices.ContainsFunc(scope, func(n Name) bool { return n.Text == name.Text }) {
+			name.Text += "_" + strconv.Itoa(level)
+		}
+
+		body, err := indexToName(t.Body, append(scope[:level:level], name), text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Lambda[Name]{ParameterName: name, Body: body}, nil
+	case *Delay[T]:
+		inner, err := indexToName(t.Term, scope, text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Delay[Name]{Term: inner}, nil
+	case *Force[T]:
+		inner, err := indexToName(t.Term, scope, text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Force[Name]{Term: inner}, nil
+	case *Apply[T]:
+		f, err := indexToName(t.Function, scope, text)
+		if err != nil {
+			return nil, err
+		}
+
+		arg, err := indexToName(t.Argument, scope, text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Apply[Name]{Function: f, Argument: arg}, nil
+	case *Constant:
+		return t, nil
+	case *Error:
+		return t, nil
+	case *Builtin:
+		return t, nil
+	case *Constr[T]:
+		fields := make([]Term[Name], len(t.Fields))
+		for i, f := range t.Fields {
+			item, err := indexToName(f, scope, text)
+			if err != nil {
+				return nil, err
+			}
+
+			fields[i] = item
+		}
+
+		return &Constr[Name]{Tag: t.Tag, Fields: fields}, nil
+	case *Case[T]:
+		constr, err := indexToName(t.Constr, scope, text)
+		if err != nil {
+			return nil, err
+		}
+
+		branches := make([]Term[Name], len(t.Branches))
+		for i, b := range t.Branches {
+			item, err := indexToName(b, scope, text)
+			if err != nil {
+				return nil, err
+			}
+
+			branches[i] = item
+		}
+
+		return &Case[Name]{Constr: constr, Branches: branches}, nil
+	default:
+		panic(fmt.Sprintf("unknown Term type: %T", term))
+	}
+}
+
 type converter struct {
 	currentLevel  uint
 	currentUnique Unique
@@ -0,0 +1,127 @@
+package syn
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIndexToName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		named string
+	}{
+		{"identity", `(program 1.0.0 (lam x x))`, `(program 1.0.0 (lam i_0 i_0))`, `(program 1.0.0 (lam x x))`},
+		{"shadowing", `(program 1.0.0 (lam x (lam x [x (lam y x)])))`, `(program 1.0.0 (lam i_0 (lam i_1 [i_1 (lam i_2 i_1)])))`, `(program 1.0.0 (lam x (lam x_1 [x_1 (lam y x_1)])))`},
+		{"suffix clash", `(program 1.0.0 (lam x (lam x_1 (lam x [x x_1]))))`, `(program 1.0.0 (lam i_0 (lam i_1 (lam i_2 [i_2 i_1]))))`, `(program 1.0.0 (lam x (lam x_1 (lam x_2 [x_2 x_1]))))`},
+		{"case", `(program 1.1.0 (lam x (case x (lam y [y x]) (delay (force x)))))`, `(program 1.1.0 (lam i_0 (case i_0 (lam i_1 [i_1 i_0]) (delay (force i_0)))))`, `(program 1.1.0 (lam x (case x (lam y [y x]) (delay (force x)))))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			debruijn, err := NameToDeBruijn(p)
+			if err != nil {
+				t.Fatalf("NameToDeBruijn() failed: %v", err)
+			}
+			named, err := DeBruijnToName(debruijn)
+			if err != nil {
+				t.Fatalf("DeBruijnToName() failed: %v", err)
+			}
+			if got := Format(named, DefaultLayout); got != tt.want+"\n" {
+				t.Errorf("DeBruijnToName() = %s, want %s", got, tt.want)
+			}
+
+			// The names parse back to the same program.
+			reparsed, err := Parse(Format(named, DefaultLayout))
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			again, err := NameToDeBruijn(reparsed)
+			if err != nil {
+				t.Fatalf("NameToDeBruijn() failed: %v", err)
+			}
+			want, _ := Encode(debruijn)
+			if got, err := Encode(again); err != nil || !bytes.Equal(got, want) {
+				t.Errorf("round trip changed the program: %s", Pretty(again))
+			}
+
+			namedDeBruijn, err := NameToNamedDeBruijn(p)
+			if err != nil {
+				t.Fatalf("NameToNamedDeBruijn() failed: %v", err)
+			}
+			renamed, err := NamedDeBruijnToName(namedDeBruijn)
+			if err != nil {
+				t.Fatalf("NamedDeBruijnToName() failed: %v", err)
+			}
+			if got := Format(renamed, DefaultLayout); got != tt.named+"\n" {
+				t.Errorf("NamedDeBruijnToName() = %s, want %s", got, tt.named)
+			}
+		})
+	}
+
+	free := &Program[DeBruijn]{Version: [3]uint32{1, 0, 0}, Term: &Lambda[DeBruijn]{Body: &Var[DeBruijn]{Name: 2}}}
+	if _, err := DeBruijnToName(free); err == nil {
+		t.Error("DeBruijnToName() accepted a free variable")
+	}
+}
+
+func TestNamedDeBruijnToNameIdentifiers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"x", "x"},
+		{"x'-1", "x'-1"},
+		{"", "v"},
+		{"_x", "v_x"},
+		{"1x", "v1x"},
+		{"a b", "a_b"},
+		{"a(b)", "a_b_"},
+		{"lam", "vlam"},
+		{"True", "vTrue"},
+		{"--", "v--"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			// Two binders of the same text exercise the shadowing suffix.
+			program := &Program[NamedDeBruijn]{
+				Version: [3]uint32{1, 0, 0},
+				Term: &Lambda[NamedDeBruijn]{
+					ParameterName: NamedDeBruijn{Text: tt.text},
+					Body: &Lambda[NamedDeBruijn]{
+						ParameterName: NamedDeBruijn{Text: tt.text},
+						Body: &Apply[NamedDeBruijn]{
+							Function: &Var[NamedDeBruijn]{Name: NamedDeBruijn{Text: tt.text, Index: 2}},
+							Argument: &Var[NamedDeBruijn]{Name: NamedDeBruijn{Text: tt.text, Index: 1}},
+						},
+					},
+				},
+			}
+			named, err := NamedDeBruijnToName(program)
+			if err != nil {
+				t.Fatalf("NamedDeBruijnToName() failed: %v", err)
+			}
+			want := "(program 1.0.0 (lam " + tt.want + " (lam " + tt.want + "_1 [" + tt.want + " " + tt.want + "_1])))\n"
+			formatted := Format(named, DefaultLayout)
+			if formatted != want {
+				t.Errorf("NamedDeBruijnToName() = %s, want %s", formatted, want)
+			}
+
+			reparsed, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			again, err := NameToDeBruijn(reparsed)
+			if err != nil {
+				t.Fatalf("NameToDeBruijn() failed: %v", err)
+			}
+			if got := Format(again, DefaultLayout); got != "(program 1.0.0 (lam i_0 (lam i_0 [i_2 i_1])))\n" {
+				t.Errorf("round trip changed the program: %s", got)
+			}
+		})
+	}
+}
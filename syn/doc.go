@@ -47,11 +47,14 @@
 //	// Pretty-print a term
 //	output := syn.PrettyTerm[syn.DeBruijn](term)
 //
+//	// Format a program within 80 columns
+//	output = syn.Format(program, syn.DefaultLayout)
+//
 // # Serialization
 //
 // The package supports two serialization formats:
 //
-//   - Text format via [Parse] and [PrettyTerm]
+//   - Text format via [Parse], and [PrettyTerm] or the width-aware [Format]
 //   - FLAT binary format via [Decode] (used on-chain)
 //
 // # Binder Interface
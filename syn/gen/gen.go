@@ -0,0 +1,379 @@
+// Package gen generates random well-scoped, well-typed UPLC terms for
+// property-based tests.
+//
+// A [Generator] builds closed Term[DeBruijn] values of a requested constant
+// type. Every variable refers to an enclosing lambda, every builtin is
+// forced and saturated according to its signature, and only total builtins
+// are used, so generated terms evaluate without type errors. Terms exercise
+// lambdas and applications, delay/force, polymorphic builtins and, for
+// program version 1.1.0 and later, constr/case.
+//
+//	g := gen.New(rand.New(rand.NewSource(1)), gen.DefaultConfig())
+//	program := g.Program()
+//
+// [Program] implements [testing/quick.Generator].
+package gen
+
+import (
+	"math/rand"
+	"reflect"
+
+	"github.com/blinklabs-io/plutigo/builtin"
+	datagen "github.com/blinklabs-io/plutigo/data/gen"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+// Config bounds the terms produced by a Generator.
+type Config struct {
+	// Version is the program version; constr and case are only generated
+	// for version 1.1.0 and later.
+	Version lang.LanguageVersion
+	// MaxDepth is the maximum depth of the term tree.
+	MaxDepth int
+	// MaxFields bounds the number of constr fields and case branches.
+	MaxFields int
+	// MaxStringLength bounds the number of runes in string constants.
+	MaxStringLength int
+	// Data bounds integer, byte string and data constants.
+	Data datagen.Config
+}
+
+// DefaultConfig returns limits that produce terms which evaluate quickly
+// under the default budget.
+func DefaultConfig() Config {
+	return Config{
+		Version:         lang.LanguageVersionV3,
+		MaxDepth:        6,
+		MaxFields:       3,
+		MaxStringLength: 16,
+		Data: datagen.Config{
+			MaxDepth:       3,
+			MaxLength:      4,
+			MaxIntegerBits: 128,
+			MaxBytesLength: 70,
+		},
+	}
+}
+
+// Types lists the constant types a Generator can produce terms for.
+var Types = []syn.Typ{
+	&syn.TInteger{},
+	&syn.TByteString{},
+	&syn.TString{},
+	&syn.TBool{},
+	&syn.TUnit{},
+	&syn.TData{},
+}
+
+// Generator produces random UPLC terms. It is not safe for concurrent use.
+type Generator struct {
+	rand   *rand.Rand
+	config Config
+	data   *datagen.Generator
+}
+
+// New returns a Generator drawing from r within the given limits.
+func New(r *rand.Rand, config Config) *Generator {
+	return &Generator{
+		rand:   r,
+		config: config,
+		data:   datagen.New(r, config.Data),
+	}
+}
+
+// Program returns a random closed program whose result has one of Types.
+func (g *Generator) Program() *syn.Program[syn.DeBruijn] {
+	return &syn.Program[syn.DeBruijn]{
+		Version: g.config.Version,
+		Term:    g.Term(Types[g.rand.Intn(len(Types))]),
+	}
+}
+
+// Term returns a random closed term that evaluates to a constant of type
+// typ, which must be one of Types.
+func (g *Generator) Term(typ syn.Typ) syn.Term[syn.DeBruijn] {
+	return g.term(typ, g.config.MaxDepth, nil)
+}
+
+// Constant returns a random constant of type typ, which must be one of
+// Types.
+func (g *Generator) Constant(typ syn.Typ) syn.IConstant {
+	switch typ.(type) {
+	case *syn.TInteger:
+		return &syn.Integer{Inner: g.data.Integer()}
+	case *syn.TByteString:
+		return &syn.ByteString{Inner: g.data.Bytes()}
+	case *syn.TString:
+		return &syn.String{Inner: g.string()}
+	case *syn.TBool:
+		return &syn.Bool{Inner: g.rand.Intn(2) == 0}
+	case *syn.TUnit:
+		return &syn.Unit{}
+	case *syn.TData:
+		return &syn.Data{Inner: g.data.Data()}
+	default:
+		panic("gen: unsupported constant type")
+	}
+}
+
+// term generates a term of type typ. env holds the types of the enclosing
+// lambda parameters, innermost last.
+func (g *Generator) term(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	if depth <= 1 {
+		if v, ok := g.variable(typ, env); ok && g.rand.Intn(2) == 0 {
+			return v
+		}
+		return &syn.Constant{Con: g.Constant(typ)}
+	}
+	switch g.rand.Intn(9) {
+	case 0:
+		if v, ok := g.variable(typ, env); ok {
+			return v
+		}
+		return &syn.Constant{Con: g.Constant(typ)}
+	case 1:
+		return g.let(typ, depth, env)
+	case 2:
+		return &syn.Force[syn.DeBruijn]{
+			Term: &syn.Delay[syn.DeBruijn]{Term: g.term(typ, depth-1, env)},
+		}
+	case 3:
+		return g.ifThenElse(typ, depth, env)
+	case 4:
+		return g.trace(typ, depth, env)
+	case 5:
+		if g.caseSupported() {
+			return g.caseOf(typ, depth, env)
+		}
+		return g.let(typ, depth, env)
+	default:
+		return g.builtin(typ, depth, env)
+	}
+}
+
+// variable picks a bound variable of type typ, if any is in scope.
+func (g *Generator) variable(
+	typ syn.Typ,
+	env []syn.Typ,
+) (syn.Term[syn.DeBruijn], bool) {
+	var candidates []int
+	for i, bound := range env {
+		if syn.EqualType(bound, typ) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	i := candidates[g.rand.Intn(len(candidates))]
+	// De Bruijn indices are 1-based from the innermost binder.
+	return &syn.Var[syn.DeBruijn]{Name: syn.DeBruijn(len(env) - i)}, true
+}
+
+// let generates [(lam x body) arg].
+func (g *Generator) let(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	argTyp := Types[g.rand.Intn(len(Types))]
+	return &syn.Apply[syn.DeBruijn]{
+		Function: g.lambdas([]syn.Typ{argTyp}, typ, depth, env),
+		Argument: g.term(argTyp, depth-1, env),
+	}
+}
+
+// lambdas generates a chain of lambdas binding params, outermost first,
+// around a body of type typ.
+func (g *Generator) lambdas(
+	params []syn.Typ,
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	inner := append(env[:len(env):len(env)], params...)
+	term := g.term(typ, depth-1, inner)
+	for range params {
+		term = &syn.Lambda[syn.DeBruijn]{ParameterName: 0, Body: term}
+	}
+	return term
+}
+
+// ifThenElse generates force [(force (builtin ifThenElse)) c (delay t) (delay e)].
+func (g *Generator) ifThenElse(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	return &syn.Force[syn.DeBruijn]{
+		Term: apply(
+			forced(builtin.IfThenElse),
+			g.term(&syn.TBool{}, depth-1, env),
+			&syn.Delay[syn.DeBruijn]{Term: g.term(typ, depth-1, env)},
+			&syn.Delay[syn.DeBruijn]{Term: g.term(typ, depth-1, env)},
+		),
+	}
+}
+
+// trace generates [(force (builtin trace)) msg x].
+func (g *Generator) trace(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	return apply(
+		forced(builtin.Trace),
+		g.term(&syn.TString{}, depth-1, env),
+		g.term(typ, depth-1, env),
+	)
+}
+
+// caseOf generates (case (constr k fields...) branches...) where branch k
+// binds the constructor fields.
+func (g *Generator) caseOf(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	maxFields := max(g.config.MaxFields, 1)
+	branchCount := 1 + g.rand.Intn(maxFields)
+	tag := g.rand.Intn(branchCount)
+
+	branches := make([]syn.Term[syn.DeBruijn], branchCount)
+	var fields []syn.Term[syn.DeBruijn]
+	for i := range branches {
+		params := make([]syn.Typ, g.rand.Intn(maxFields+1))
+		for j := range params {
+			params[j] = Types[g.rand.Intn(len(Types))]
+		}
+		branches[i] = g.lambdas(params, typ, depth, env)
+		if i == tag {
+			fields = make([]syn.Term[syn.DeBruijn], len(params))
+			for j, param := range params {
+				fields[j] = g.term(param, depth-1, env)
+			}
+		}
+	}
+	return &syn.Case[syn.DeBruijn]{
+		Constr: &syn.Constr[syn.DeBruijn]{
+			Tag:    uint(tag),
+			Fields: fields,
+		},
+		Branches: branches,
+	}
+}
+
+type signature struct {
+	fn   builtin.DefaultFunction
+	args []syn.Typ
+}
+
+var (
+	tInteger    = &syn.TInteger{}
+	tByteString = &syn.TByteString{}
+	tString     = &syn.TString{}
+	tData       = &syn.TData{}
+)
+
+// builtins lists total monomorphic builtins by result type.
+var builtins = map[reflect.Type][]signature{
+	reflect.TypeFor[*syn.TInteger](): {
+		{builtin.AddInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.SubtractInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.MultiplyInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.LengthOfByteString, []syn.Typ{tByteString}},
+	},
+	reflect.TypeFor[*syn.TByteString](): {
+		{builtin.AppendByteString, []syn.Typ{tByteString, tByteString}},
+		{builtin.Sha2_256, []syn.Typ{tByteString}},
+		{builtin.Blake2b_256, []syn.Typ{tByteString}},
+		{builtin.EncodeUtf8, []syn.Typ{tString}},
+	},
+	reflect.TypeFor[*syn.TString](): {
+		{builtin.AppendString, []syn.Typ{tString, tString}},
+	},
+	reflect.TypeFor[*syn.TBool](): {
+		{builtin.EqualsInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.LessThanInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.LessThanEqualsInteger, []syn.Typ{tInteger, tInteger}},
+		{builtin.EqualsByteString, []syn.Typ{tByteString, tByteString}},
+		{builtin.EqualsString, []syn.Typ{tString, tString}},
+		{builtin.EqualsData, []syn.Typ{tData, tData}},
+	},
+	reflect.TypeFor[*syn.TData](): {
+		{builtin.IData, []syn.Typ{tInteger}},
+		{builtin.BData, []syn.Typ{tByteString}},
+	},
+}
+
+// builtin generates a saturated application of a builtin returning typ,
+// falling back to a constant when none does.
+func (g *Generator) builtin(
+	typ syn.Typ,
+	depth int,
+	env []syn.Typ,
+) syn.Term[syn.DeBruijn] {
+	candidates := builtins[reflect.TypeOf(typ)]
+	if len(candidates) == 0 {
+		return &syn.Constant{Con: g.Constant(typ)}
+	}
+	sig := candidates[g.rand.Intn(len(candidates))]
+	args := make([]syn.Term[syn.DeBruijn], len(sig.args))
+	for i, argTyp := range sig.args {
+		args[i] = g.term(argTyp, depth-1, env)
+	}
+	return apply(&syn.Builtin{DefaultFunction: sig.fn}, args...)
+}
+
+func (g *Generator) caseSupported() bool {
+	v := g.config.Version
+	return v[0] > 1 || (v[0] == 1 && v[1] >= 1)
+}
+
+func (g *Generator) string() string {
+	const alphabet = "abcxyz019 _-éλ☃\U0001f600"
+	runes := []rune(alphabet)
+	n := g.rand.Intn(max(g.config.MaxStringLength, 0) + 1)
+	s := make([]rune, n)
+	for i := range s {
+		s[i] = runes[g.rand.Intn(len(runes))]
+	}
+	return string(s)
+}
+
+func forced(fn builtin.DefaultFunction) syn.Term[syn.DeBruijn] {
+	var term syn.Term[syn.DeBruijn] = &syn.Builtin{DefaultFunction: fn}
+	for range fn.ForceCount() {
+		term = &syn.Force[syn.DeBruijn]{Term: term}
+	}
+	return term
+}
+
+func apply(
+	fn syn.Term[syn.DeBruijn],
+	args ...syn.Term[syn.DeBruijn],
+) syn.Term[syn.DeBruijn] {
+	for _, arg := range args {
+		fn = &syn.Apply[syn.DeBruijn]{Function: fn, Argument: arg}
+	}
+	return fn
+}
+
+// Program wraps a random program generated with DefaultConfig. It
+// implements testing/quick.Generator.
+type Program struct {
+	Program *syn.Program[syn.DeBruijn]
+}
+
+// Generate implements testing/quick.Generator. The quick size hint scales
+// the maximum term depth.
+func (Program) Generate(r *rand.Rand, size int) reflect.Value {
+	config := DefaultConfig()
+	config.MaxDepth = min(max(size/15, 2), config.MaxDepth)
+	return reflect.ValueOf(Program{Program: New(r, config).Program()})
+}
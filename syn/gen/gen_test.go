@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/blinklabs-io/plutigo/cek"
+	"github.com/blinklabs-io/plutigo/lang"
+	"github.com/blinklabs-io/plutigo/syn"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	first := New(rand.New(rand.NewSource(3)), DefaultConfig())
+	second := New(rand.New(rand.NewSource(3)), DefaultConfig())
+	for range 20 {
+		a, err := syn.Encode(first.Program())
+		if err != nil {
+			t.Fatalf("Encode() failed: %v", err)
+		}
+		b, err := syn.Encode(second.Program())
+		if err != nil {
+			t.Fatalf("Encode() failed: %v", err)
+		}
+		if !bytes.Equal(a, b) {
+			t.Fatalf("same seed produced %x and %x", a, b)
+		}
+	}
+}
+
+func TestQuickFlatRoundTrip(t *testing.T) {
+	roundTrip := func(p Program) bool {
+		encoded, err := syn.Encode(p.Program)
+		if err != nil {
+			t.Logf("Encode() failed: %v", err)
+			return false
+		}
+		decoded, err := syn.DecodeDeBruijn(encoded)
+		if err != nil {
+			t.Logf("DecodeDeBruijn(%x) failed: %v", encoded, err)
+			return false
+		}
+		reencoded, err := syn.Encode(decoded)
+		if err != nil {
+			t.Logf("Encode(decoded) failed: %v", err)
+			return false
+		}
+		return bytes.Equal(encoded, reencoded)
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 200}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeneratedTermsEvaluate(t *testing.T) {
+	versions := []lang.LanguageVersion{
+		lang.LanguageVersionV1,
+		lang.LanguageVersionV3,
+	}
+	for _, version := range versions {
+		config := DefaultConfig()
+		config.Version = version
+		g := New(rand.New(rand.NewSource(11)), config)
+		for i := range 200 {
+			program := g.Program()
+			machine := cek.NewMachine[syn.DeBruijn](version, 0, nil)
+			if _, err := machine.Run(program.Term); err != nil {
+				t.Fatalf(
+					"version %v program %d: Run() failed: %v\n%s",
+					version,
+					i,
+					err,
+					syn.Pretty(program),
+				)
+			}
+		}
+	}
+}
+
+func TestNoCaseBeforeVersion110(t *testing.T) {
+	config := DefaultConfig()
+	config.Version = lang.LanguageVersionV1
+	g := New(rand.New(rand.NewSource(5)), config)
+	for range 200 {
+		if containsCase(g.Program().Term) {
+			t.Fatal("generated constr/case for version 1.0.0")
+		}
+	}
+}
+
+func containsCase(term syn.Term[syn.DeBruijn]) bool {
+	switch t := term.(type) {
+	case *syn.Case[syn.DeBruijn], *syn.Constr[syn.DeBruijn]:
+		return true
+	case *syn.Lambda[syn.DeBruijn]:
+		return containsCase(t.Body)
+	case *syn.Apply[syn.DeBruijn]:
+		return containsCase(t.Function) || containsCase(t.Argument)
+	case *syn.Delay[syn.DeBruijn]:
+		return containsCase(t.Term)
+	case *syn.Force[syn.DeBruijn]:
+		return containsCase(t.Term)
+	default:
+		return false
+	}
+}
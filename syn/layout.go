@@ -0,0 +1,477 @@
+package syn
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blinklabs-io/plutigo/data"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Layout configures the width-aware printer behind [Format] and
+// [FormatTerm]. The zero value is 80 columns with an indent of two spaces,
+// terms broken across lines as by [Pretty], and constants kept on one line
+// when they fit.
+type Layout struct {
+	// Width is the line width the printer fills before it breaks a
+	// subterm across lines. Zero means 80.
+	Width int
+	// IndentSize is the number of spaces per nesting level. Zero means 2.
+	IndentSize int
+	// Compact prints subterms that fit in the remaining width on one line,
+	// such as [(builtin addInteger) x]. Without it, every lambda,
+	// application, delay, force, constr and case spans several lines.
+	Compact bool
+	// Constants lays out the items of list constants.
+	Constants CollectionLayout
+	// Data lays out the items of lists, maps and constructors in Data
+	// constants.
+	Data CollectionLayout
+}
+
+// CollectionLayout says how the printer lays out the items of a
+// collection.
+type CollectionLayout int
+
+const (
+	// CollectionFit keeps a collection on one line when it fits and puts
+	// each item on its own line otherwise.
+	CollectionFit CollectionLayout = iota
+	// CollectionOneLine keeps a collection on one line, however long.
+	CollectionOneLine
+	// CollectionExpanded puts each item of a non-empty collection on its
+	// own line, as [Pretty] does.
+	CollectionExpanded
+)
+
+// DefaultLayout is the layout of "plutigo fmt": compact terms within 80
+// columns.
+var DefaultLayout = Layout{Width: 80, IndentSize: 2, Compact: true}
+
+// PrettyLayout is the layout of [Pretty] and [PrettyTerm]: every term and
+// every non-empty collection spans several lines.
+var PrettyLayout = Layout{
+	Width:      80,
+	IndentSize: 2,
+	Constants:  CollectionExpanded,
+	Data:       CollectionExpanded,
+}
+
+// Format prints a program with a width-aware layout. The output parses
+// back to the same program.
+func Format[T Binder](p *Program[T], layout Layout) string {
+	return prettyPrintProgram(&PrettyPrinter{layout: layout}, p)
+}
+
+// FormatComments is [Format] for a program parsed by [ParseComments],
+// which prints the program's comments again.
+func FormatComments(p *Program[Name], comments *Comments, layout Layout) string {
+	return prettyPrintProgram(&PrettyPrinter{layout: layout, comments: comments}, p)
+}
+
+// FormatTerm prints a term with a width-aware layout.
+func FormatTerm[T Binder](t Term[T], layout Layout) string {
+	return prettyPrintTerm[T](&PrettyPrinter{layout: layout}, t)
+}
+
+func programDoc[T Binder](pp *PrettyPrinter, p *Program[T]) doc {
+	return pp.layout.termGroup(concat(
+		text(fmt.Sprintf("(program %d.%d.%d", p.Version[0], p.Version[1], p.Version[2])),
+		nest(line, termDoc[T](pp, p.Term)),
+		softline,
+		text(")"),
+	))
+}
+
+// The printer follows Wadler's "A prettier printer": a term becomes a
+// document of text, line breaks and nesting, whose groups print on one
+// line when they fit and break all their lines otherwise.
+type doc interface {
+	isDoc()
+}
+
+type (
+	docText   string
+	docConcat []doc
+	// docLine is a line break that prints as flat in a group on one line,
+	// unless it is hard.
+	docLine struct {
+		flat string
+		hard bool
+	}
+	// docNest indents the lines in its document by one more level.
+	docNest  struct{ doc doc }
+	docGroup struct {
+		doc  doc
+		mode CollectionLayout
+	}
+)
+
+func (docText) isDoc()   {}
+func (docConcat) isDoc() {}
+func (docLine) isDoc()   {}
+func (docNest) isDoc()   {}
+func (docGroup) isDoc()  {}
+
+var (
+	line     = docLine{flat: " "}
+	softline = docLine{}
+	// hardline ends a comment, so it breaks the groups around it.
+	hardline = docLine{hard: true}
+)
+
+func text(s string) doc {
+	return docText(s)
+}
+
+func concat(docs ...doc) doc {
+	return docConcat(docs)
+}
+
+func nest(docs ...doc) doc {
+	return docNest{doc: docConcat(docs)}
+}
+
+// termGroup groups the document of a term, which breaks unless the layout
+// is compact.
+func (l Layout) termGroup(d doc) doc {
+	if l.Compact {
+		return docGroup{doc: d, mode: CollectionFit}
+	}
+
+	return docGroup{doc: d, mode: CollectionExpanded}
+}
+
+// collection lays out comma-separated items between brackets.
+func collection(mode CollectionLayout, items []doc) doc {
+	if len(items) == 0 {
+		return text("[]")
+	}
+	inner := make([]doc, 0, 3*len(items))
+	for i, item := range items {
+		if i > 0 {
+			inner = append(inner, text(","), line)
+		} else {
+			inner = append(inner, softline)
+		}
+		inner = append(inner, item)
+	}
+
+	return docGroup{
+		doc:  concat(text("["), nest(inner...), softline, text("]")),
+		mode: mode,
+	}
+}
+
+// renderItem is a document to print at an indentation, on one line or not.
+type renderItem struct {
+	indent int
+	flat   bool
+	doc    doc
+}
+
+func (l Layout) render(d doc) string {
+	width := l.Width
+	if width <= 0 {
+		width = 80
+	}
+	indentSize := l.IndentSize
+	if indentSize <= 0 {
+		indentSize = 2
+	}
+
+	var b strings.Builder
+	column := 0
+	stack := []renderItem{{doc: d}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := item.doc.(type) {
+		case docText:
+			b.WriteString(string(d))
+			column += utf8.RuneCountInString(string(d))
+		case docLine:
+			if item.flat && !d.hard {
+				b.WriteString(d.flat)
+				column += len(d.flat)
+			} else {
+				b.WriteString("\n")
+				b.WriteString(strings.Repeat(" ", item.indent))
+				column = item.indent
+			}
+		case docNest:
+			stack = append(stack, renderItem{indent: item.indent + indentSize, flat: item.flat, doc: d.doc})
+		case docConcat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, renderItem{indent: item.indent, flat: item.flat, doc: d[i]})
+			}
+		case docGroup:
+			flat := false
+			switch d.mode {
+			case CollectionOneLine:
+				flat = true
+			case CollectionFit:
+				flat = item.flat || fits(width-column, d.doc, stack)
+			}
+			stack = append(stack, renderItem{indent: item.indent, flat: flat, doc: d.doc})
+		}
+	}
+
+	return b.String()
+}
+
+// fits reports whether d, on one line, and what follows it up to the next
+// line break fit in width columns.
+func fits(width int, d doc, rest []renderItem) bool {
+	stack := []renderItem{{flat: true, doc: d}}
+	for width >= 0 {
+		if len(stack) == 0 {
+			if len(rest) == 0 {
+				return true
+			}
+			stack = append(stack, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+		}
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := item.doc.(type) {
+		case docText:
+			width -= utf8.RuneCountInString(string(d))
+		case docLine:
+			if !item.flat {
+				return true
+			}
+			if d.hard {
+				return false
+			}
+			width -= len(d.flat)
+		case docNest:
+			stack = append(stack, renderItem{flat: item.flat, doc: d.doc})
+		case docConcat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, renderItem{flat: item.flat, doc: d[i]})
+			}
+		case docGroup:
+			switch d.mode {
+			case CollectionOneLine:
+				stack = append(stack, renderItem{flat: true, doc: d.doc})
+			case CollectionExpanded:
+				if item.flat {
+					// A group that always breaks cannot go on one line.
+					return false
+				}
+				stack = append(stack, renderItem{doc: d.doc})
+			default:
+				stack = append(stack, renderItem{flat: item.flat, doc: d.doc})
+			}
+		}
+	}
+
+	return false
+}
+
+// termDoc lays out a term, after the comments that came before it in the
+// source.
+func termDoc[T Binder](pp *PrettyPrinter, term Term[T]) doc {
+	index := pp.terms
+	pp.terms++
+
+	d := termBodyDoc[T](pp, term)
+	if pp.comments == nil || len(pp.comments.terms[index]) == 0 {
+		return d
+	}
+
+	return concat(commentsDoc(pp.comments.terms[index]), d)
+}
+
+func termBodyDoc[T Binder](pp *PrettyPrinter, term Term[T]) doc {
+	l := pp.layout
+
+	switch t := term.(type) {
+	case *Var[T]:
+		return text(t.Name.TextName())
+	case *Lambda[T]:
+		return l.termGroup(concat(
+			text("(lam "+t.ParameterName.TextName()),
+			nest(line, termDoc[T](pp, t.Body)),
+			softline,
+			text(")"),
+		))
+	case *Delay[T]:
+		return l.termGroup(concat(
+			text("(delay"),
+			nest(line, termDoc[T](pp, t.Term)),
+			softline,
+			text(")"),
+		))
+	case *Force[T]:
+		return l.termGroup(concat(
+			text("(force"),
+			nest(line, termDoc[T](pp, t.Term)),
+			softline,
+			text(")"),
+		))
+	case *Apply[T]:
+		return l.termGroup(concat(
+			text("["),
+			nest(softline, termDoc[T](pp, t.Function), line, termDoc[T](pp, t.Argument)),
+			softline,
+			text("]"),
+		))
+	case *Builtin:
+		return text("(builtin " + t.String() + ")")
+	case *Constr[T]:
+		header := "(constr " + strconv.FormatUint(uint64(t.Tag), 10)
+		if len(t.Fields) == 0 {
+			return text(header + ")")
+		}
+		fields := make([]doc, 0, 2*len(t.Fields))
+		for _, field := range t.Fields {
+			fields = append(fields, line, termDoc[T](pp, field))
+		}
+
+		return l.termGroup(concat(text(header), nest(fields...), softline, text(")")))
+	case *Case[T]:
+		branches := make([]doc, 0, 1+2*len(t.Branches))
+		branches = append(branches, termDoc[T](pp, t.Constr))
+		for _, branch := range t.Branches {
+			branches = append(branches, line, termDoc[T](pp, branch))
+		}
+
+		return l.termGroup(concat(text("(case "), nest(branches...), softline, text(")")))
+	case *Error:
+		return text("(error)")
+	case *Constant:
+		if _, ok := t.Con.(*Data); ok {
+			return concat(text("(con data ("), l.constantDoc(t.Con), text("))"))
+		}
+
+		return concat(text("(con "+FormatType(t.Con.Typ())+" "), l.constantDoc(t.Con), text(")"))
+	default:
+		panic(fmt.Sprintf("unknown term: %T: %v", t, t))
+	}
+}
+
+// commentsDoc lays out comments, each on its own line.
+func commentsDoc(comments []string) doc {
+	docs := make([]doc, 0, 2*len(comments))
+	for _, comment := range comments {
+		docs = append(docs, text(comment), hardline)
+	}
+
+	return concat(docs...)
+}
+
+// constantDoc lays out the value of a constant, without its type.
+func (l Layout) constantDoc(c IConstant) doc {
+	switch con := c.(type) {
+	case *Integer:
+		return text(con.Inner.String())
+	case *ByteString:
+		return text("#" + hex.EncodeToString(con.Inner))
+	case *String:
+		return text("\"" + escapeString(con.Inner) + "\"")
+	case *Unit:
+		return text("()")
+	case *Bool:
+		if con.Inner {
+			return text("True")
+		}
+
+		return text("False")
+	case *ProtoList:
+		items := make([]doc, len(con.List))
+		for i, item := range con.List {
+			items[i] = l.constantDoc(item)
+		}
+
+		return collection(l.Constants, items)
+	case *ProtoPair:
+		return concat(text("("), l.constantDoc(con.First), text(", "), l.constantDoc(con.Second), text(")"))
+	case *Data:
+		return l.dataDoc(con.Inner)
+	case *Bls12_381G1Element:
+		affine := new(bls.G1Affine).FromJacobian(con.Inner)
+		b := affine.Bytes()
+
+		return text("0x" + hex.EncodeToString(b[:]))
+	case *Bls12_381G2Element:
+		affine := new(bls.G2Affine).FromJacobian(con.Inner)
+		b := affine.Bytes()
+
+		return text("0x" + hex.EncodeToString(b[:]))
+	default:
+		return text(fmt.Sprintf("unknown constant: %v", c))
+	}
+}
+
+func (l Layout) dataDoc(pd data.PlutusData) doc {
+	switch d := pd.(type) {
+	case *data.Integer:
+		return text("I " + d.Inner.String())
+	case *data.ByteString:
+		return text("B #" + hex.EncodeToString(d.Inner))
+	case *data.List:
+		items := make([]doc, len(d.Items))
+		for i, item := range d.Items {
+			items[i] = l.dataDoc(item)
+		}
+
+		return concat(text("List "), collection(l.Data, items))
+	case *data.Map:
+		pairs := make([]doc, len(d.Pairs))
+		for i, pair := range d.Pairs {
+			pairs[i] = concat(text("("), l.dataDoc(pair[0]), text(", "), l.dataDoc(pair[1]), text(")"))
+		}
+
+		return concat(text("Map "), collection(l.Data, pairs))
+	case *data.Constr:
+		fields := make([]doc, len(d.Fields))
+		for i, field := range d.Fields {
+			fields[i] = l.dataDoc(field)
+		}
+
+		return concat(text("Constr "+strconv.FormatUint(uint64(d.Tag), 10)+" "), collection(l.Data, fields))
+	case *data.Lazy:
+		forced, err := d.Force()
+		if err != nil {
+			return text(fmt.Sprintf("invalid PlutusData: %v", err))
+		}
+
+		return l.dataDoc(forced)
+	default:
+		return text(fmt.Sprintf("unknown PlutusData: %v", pd))
+	}
+}
+
+// FormatType prints a constant type as the parser reads it, such as
+// (list integer).
+func FormatType(typ Typ) string {
+	switch t := typ.(type) {
+	case *TInteger:
+		return "integer"
+	case *TByteString:
+		return "bytestring"
+	case *TString:
+		return "string"
+	case *TUnit:
+		return "unit"
+	case *TBool:
+		return "bool"
+	case *TData:
+		return "data"
+	case *TBls12_381G1Element:
+		return "bls12_381_G1_element"
+	case *TBls12_381G2Element:
+		return "bls12_381_G2_element"
+	case *TList:
+		return "(list " + FormatType(t.Typ) + ")"
+	case *TPair:
+		return "(pair " + FormatType(t.First) + " " + FormatType(t.Second) + ")"
+	default:
+		return fmt.Sprintf("unknown type: %v", typ)
+	}
+}
@@ -0,0 +1,155 @@
+package syn
+
+import (
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+func TestFormat(t *testing.T) {
+	const program = `(program 1.1.0 (lam x [(builtin addInteger) x (con integer 1)]))`
+	tests := []struct {
+		name   string
+		input  string
+		layout Layout
+		want   string
+	}{
+		{
+			"compact fits",
+			program,
+			DefaultLayout,
+			"(program 1.1.0 (lam x [[(builtin addInteger) x] (con integer 1)]))\n",
+		},
+		{
+			"compact breaks outer terms",
+			program,
+			Layout{Width: 50, Compact: true},
+			"(program 1.1.0\n  (lam x\n    [[(builtin addInteger) x] (con integer 1)]\n  )\n)\n",
+		},
+		{
+			"not compact",
+			`(program 1.0.0 (delay (error)))`,
+			Layout{},
+			"(program 1.0.0\n  (delay\n    (error)\n  )\n)\n",
+		},
+		{
+			"indent size",
+			`(program 1.0.0 (force (error)))`,
+			Layout{IndentSize: 4},
+			"(program 1.0.0\n    (force\n        (error)\n    )\n)\n",
+		},
+		{
+			"constr and case",
+			`(program 1.1.0 (case (constr 1 (con unit ())) (lam x x) (constr 0)))`,
+			DefaultLayout,
+			"(program 1.1.0 (case (constr 1 (con unit ())) (lam x x) (constr 0)))\n",
+		},
+		{
+			"list fits",
+			`(program 1.0.0 (con (list integer) [1, 2, 3]))`,
+			Layout{},
+			"(program 1.0.0\n  (con (list integer) [1, 2, 3])\n)\n",
+		},
+		{
+			"list expanded",
+			`(program 1.0.0 (con (list integer) [1, 2]))`,
+			Layout{Compact: true, Constants: CollectionExpanded},
+			"(program 1.0.0\n  (con (list integer) [\n    1,\n    2\n  ])\n)\n",
+		},
+		{
+			"data breaks",
+			`(program 1.0.0 (con data (Constr 0 [I 1, B #00, List [], Map [(I 1, I 2)]])))`,
+			Layout{Width: 30, Compact: true},
+			"(program 1.0.0\n  (con data (Constr 0 [\n    I 1,\n    B #00,\n    List [],\n    Map [(I 1, I 2)]\n  ]))\n)\n",
+		},
+		{
+			"data one line",
+			`(program 1.0.0 (con data (Constr 0 [I 1, B #00, List [], Map [(I 1, I 2)]])))`,
+			Layout{Width: 30, Compact: true, Data: CollectionOneLine},
+			"(program 1.0.0\n  (con data (Constr 0 [I 1, B #00, List [], Map [(I 1, I 2)]]))\n)\n",
+		},
+		{
+			"escaped string",
+			`(program 1.0.0 (con string "a\"b\n"))`,
+			DefaultLayout,
+			"(program 1.0.0 (con string \"a\\\"b\\n\"))\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if got := Format(p, tt.layout); got != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatRoundTrip formats the conformance programs with several
+// layouts, and checks that the output parses back to the same program and
+// formats the same again.
+func TestFormatRoundTrip(t *testing.T) {
+	var paths []string
+	err := filepath.WalkDir(filepath.Join("..", "tests", "conformance"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".uplc" {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() failed: %v", err)
+	}
+	layouts := []Layout{
+		{},
+		DefaultLayout,
+		{Width: 20, Compact: true, Constants: CollectionExpanded, Data: CollectionExpanded},
+		{Width: 1000, IndentSize: 1, Compact: true, Constants: CollectionOneLine, Data: CollectionOneLine},
+	}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() failed: %v", err)
+		}
+		p, err := Parse(string(src))
+		if err != nil {
+			continue
+		}
+		for _, layout := range layouts {
+			formatted := Format(p, layout)
+			reparsed, err := Parse(formatted)
+			if err != nil {
+				t.Errorf("%s: Parse(Format()) failed: %v\n%s", path, err, formatted)
+				continue
+			}
+			if got, want := Pretty(reparsed), Pretty(p); got != want {
+				t.Errorf("%s: Format() changed the program:\n%s", path, formatted)
+			}
+			if again := Format(reparsed, layout); again != formatted {
+				t.Errorf("%s: Format() is not idempotent:\n%s\n%s", path, formatted, again)
+			}
+		}
+	}
+}
+
+func TestFormatLazyData(t *testing.T) {
+	lazy, err := data.DecodeLazy([]byte{0xd8, 0x79, 0x9f, 0x18, 0x07, 0xff})
+	if err != nil {
+		t.Fatalf("DecodeLazy() failed: %v", err)
+	}
+	term := &Constant{Con: &Data{Inner: lazy}}
+	const want = "(con data (Constr 0 [I 7]))"
+	if got := FormatTerm[Name](term, DefaultLayout); got != want {
+		t.Errorf("FormatTerm() = %s, want %s", got, want)
+	}
+	eager := &Constant{Con: &Data{Inner: data.NewConstr(0, data.NewInteger(big.NewInt(7)))}}
+	if got, want := PrettyTerm[Name](term), PrettyTerm[Name](eager); got != want {
+		t.Errorf("PrettyTerm() = %s, want %s", got, want)
+	}
+}
@@ -9,10 +9,22 @@ import (
 )
 
 type Lexer struct {
-	input   []rune
-	pos     int
-	readPos int
-	ch      rune
+	input    []rune
+	pos      int
+	readPos  int
+	ch       rune
+	comments []Comment
+}
+
+// Comment is a "--" comment, from its dashes to the end of its line.
+type Comment struct {
+	Text     string
+	Position int
+}
+
+// Comments returns the comments the lexer has skipped so far.
+func (l *Lexer) Comments() []Comment {
+	return l.comments
 }
 
 func NewLexer(input string) *Lexer {
@@ -52,6 +64,8 @@ func (l *Lexer) skipWhitespace() {
 
 		// Check for comment start
 		if l.ch == '-' && l.peekChar() == '-' {
+			start := l.pos
+
 			// Skip the '--'
 			l.readChar() // Consume first '-'
 			l.readChar() // Consume second '-'
@@ -61,6 +75,11 @@ func (l *Lexer) skipWhitespace() {
 				l.readChar()
 			}
 
+			l.comments = append(l.comments, Comment{
+				Text:     strings.TrimRightFunc(string(l.input[start:l.pos]), unicode.IsSpace),
+				Position: start,
+			})
+
 			// If we hit a newline, continue to check for more whitespace or comments
 			if l.ch == '\n' {
 				l.readChar()
@@ -27,6 +27,9 @@ type Parser struct {
 	uniqueCounter Unique
 	version       lang.LanguageVersion
 	depth         int
+	// terms records where each term starts when parsing with comments.
+	terms      []termStart
+	trackTerms bool
 }
 
 // enter records descent into a nested construct and fails if the nesting limit
@@ -98,6 +101,28 @@ func Parse(input string) (*Program[Name], error) {
 	return p.ParseProgram()
 }
 
+// ParseTerm parses input as a single term of a program at version, such as
+// a term entered at a prompt.
+func ParseTerm(input string, version lang.LanguageVersion) (Term[Name], error) {
+	p := NewParser(input)
+	p.version = version
+
+	term, err := p.ParseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.curToken.Type != lex.TokenEOF {
+		return nil, fmt.Errorf(
+			"unexpected token %v after term at position %d",
+			p.curToken.Type,
+			p.curToken.Position,
+		)
+	}
+
+	return term, nil
+}
+
 func (p *Parser) ParseProgram() (*Program[Name], error) {
 	if err := p.expect(lex.TokenLParen); err != nil {
 		return nil, err
@@ -167,6 +192,10 @@ func (p *Parser) ParseTerm() (Term[Name], error) {
 	}
 	defer p.leave()
 
+	if p.trackTerms {
+		p.terms = append(p.terms, termStart{position: p.curToken.Position, nodes: 1})
+	}
+
 	switch p.curToken.Type {
 	case lex.TokenIdentifier:
 		name := p.internName(p.curToken.Literal)
@@ -1314,6 +1343,8 @@ func (p *Parser) parseInnerTypeSpec() (Typ, error) {
 }
 
 func (p *Parser) parseApply() (Term[Name], error) {
+	start := len(p.terms) - 1
+
 	if err := p.expect(lex.TokenLBracket); err != nil {
 		return nil, err
 	}
@@ -1358,6 +1389,10 @@ func (p *Parser) parseApply() (Term[Name], error) {
 	}
 
 	// Build left-nested Apply structure
+	if p.trackTerms {
+		p.terms[start].nodes = len(terms) - 1
+	}
+
 	result := terms[0]
 
 	for i := 1; i < len(terms); i++ {
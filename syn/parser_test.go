@@ -1,7 +1,10 @@
 package syn
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/blinklabs-io/plutigo/lang"
 )
 
 func TestParsePrettyRoundTrip(t *testing.T) {
@@ -103,3 +106,29 @@ func fuzzProgramSeeds() []string {
 		"(program 1.3.0 (case (constr 0 (con integer 1)) (lam x x)))",
 	}
 }
+
+func TestParseTerm(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		version lang.LanguageVersion
+		error   string
+	}{
+		{"constant", `(con integer 1)`, lang.LanguageVersion{1, 0, 0}, ""},
+		{"free variable", `[f x]`, lang.LanguageVersion{1, 0, 0}, ""},
+		{"case at 1.1.0", `(case (constr 0) (con unit ()))`, lang.LanguageVersion{1, 1, 0}, ""},
+		{"case at 1.0.0", `(case (constr 0) (con unit ()))`, lang.LanguageVersion{1, 0, 0}, "before 1.1.0"},
+		{"trailing tokens", `x y`, lang.LanguageVersion{1, 0, 0}, "after term"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTerm(tt.input, tt.version)
+			if tt.error == "" && err != nil {
+				t.Errorf("ParseTerm() failed: %v", err)
+			}
+			if tt.error != "" && (err == nil || !strings.Contains(err.Error(), tt.error)) {
+				t.Errorf("ParseTerm() error = %v, want %q", err, tt.error)
+			}
+		})
+	}
+}
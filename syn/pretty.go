@@ -3,37 +3,40 @@ package syn
 import (
 	"fmt"
 	"strings"
-
-	"github.com/blinklabs-io/plutigo/data"
-	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
 )
 
-// Pretty Print a Program
+// Pretty prints a program with [PrettyLayout].
 func Pretty[T Binder](p *Program[T]) string {
 	pp := NewPrettyPrinter(2)
 
 	return prettyPrintProgram(pp, p)
 }
 
-// Pretty Print a Program
+// PrettyTerm prints a term with [PrettyLayout].
 func PrettyTerm[T Binder](t Term[T]) string {
 	pp := NewPrettyPrinter(2)
 
 	return prettyPrintTerm[T](pp, t)
 }
 
-// PrettyPrinter manages the state for pretty-printing AST nodes
+// PrettyPrinter manages the state for printing AST nodes with a [Layout]
 type PrettyPrinter struct {
-	builder    strings.Builder
-	indent     int
-	indentSize int
+	builder strings.Builder
+	indent  int
+	layout  Layout
+	// comments are printed before the terms they precede, which are
+	// counted by terms.
+	comments *Comments
+	terms    int
 }
 
-// NewPrettyPrinter creates a new PrettyPrinter with the specified indent size
+// NewPrettyPrinter creates a new PrettyPrinter with [PrettyLayout] and the
+// specified indent size
 func NewPrettyPrinter(indentSize int) *PrettyPrinter {
-	return &PrettyPrinter{
-		indentSize: indentSize,
-	}
+	layout := PrettyLayout
+	layout.IndentSize = indentSize
+
+	return &PrettyPrinter{layout: layout}
 }
 
 // Reset clears the accumulated output and indentation state so the
@@ -41,6 +44,7 @@ func NewPrettyPrinter(indentSize int) *PrettyPrinter {
 func (pp *PrettyPrinter) Reset() {
 	pp.builder.Reset()
 	pp.indent = 0
+	pp.terms = 0
 }
 
 // write writes a string to the builder
@@ -48,420 +52,47 @@ func (pp *PrettyPrinter) write(s string) {
 	pp.builder.WriteString(s)
 }
 
-// writeIndent writes the current indentation
-func (pp *PrettyPrinter) writeIndent() {
-	pp.write(strings.Repeat(" ", pp.indent*pp.indentSize))
-}
-
 // increaseIndent increases the indentation level
 func (pp *PrettyPrinter) increaseIndent() {
 	pp.indent++
 }
 
-// decreaseIndent decreases the indentation level
-func (pp *PrettyPrinter) decreaseIndent() {
-	if pp.indent > 0 {
-		pp.indent--
+// nest indents the lines of d after the first by the current indentation
+func (pp *PrettyPrinter) nest(d doc) doc {
+	for range pp.indent {
+		d = nest(d)
 	}
-}
 
-// PrettyPrintTerm formats a Term[Name] to a string
-func prettyPrintTerm[T Binder](pp *PrettyPrinter, term Term[T]) string {
-	printTerm[T](pp, term)
-	return pp.builder.String()
+	return d
 }
 
-func prettyPrintProgram[T Binder](pp *PrettyPrinter, prog *Program[T]) string {
-	printProgram(pp, prog)
+// prettyPrintTerm formats a term to a string
+func prettyPrintTerm[T Binder](pp *PrettyPrinter, term Term[T]) string {
+	pp.write(pp.layout.render(pp.nest(termDoc[T](pp, term))))
 
 	return pp.builder.String()
 }
 
-// printProgram formats the Program node
-func printProgram[T Binder](pp *PrettyPrinter, prog *Program[T]) {
-	pp.write("(program ")
-
-	pp.write(
-		fmt.Sprintf(
-			"%d.%d.%d",
-			prog.Version[0],
-			prog.Version[1],
-			prog.Version[2],
-		),
-	)
-
-	pp.write("\n")
-
-	pp.increaseIndent()
-	pp.writeIndent()
-
-	printTerm[T](pp, prog.Term)
-
-	pp.decreaseIndent()
-	pp.write("\n")
-
-	pp.write(")")
-
-	pp.write("\n")
-}
-
-// printTerm dispatches to the appropriate term printing method
-func printTerm[T Binder](pp *PrettyPrinter, term Term[T]) {
-	switch t := term.(type) {
-	case *Var[T]:
-		pp.write(t.Name.TextName())
-	case *Lambda[T]:
-		pp.write("(lam ")
-
-		pp.write(t.ParameterName.TextName())
-
-		pp.write("\n")
-		pp.increaseIndent()
-		pp.writeIndent()
-
-		printTerm[T](pp, t.Body)
-
-		pp.decreaseIndent()
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write(")")
-	case *Delay[T]:
-		pp.write("(delay")
-
-		pp.write("\n")
-		pp.increaseIndent()
-		pp.writeIndent()
-
-		printTerm[T](pp, t.Term)
-
-		pp.decreaseIndent()
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write(")")
-	case *Force[T]:
-		pp.write("(force")
-
-		pp.write("\n")
-		pp.increaseIndent()
-		pp.writeIndent()
-
-		printTerm[T](pp, t.Term)
-
-		pp.decreaseIndent()
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write(")")
-	case *Apply[T]:
-		pp.write("[")
-
-		pp.write("\n")
-		pp.increaseIndent()
-		pp.writeIndent()
-
-		printTerm[T](pp, t.Function)
-
-		pp.write("\n")
-		pp.writeIndent()
-
-		printTerm[T](pp, t.Argument)
-
-		pp.decreaseIndent()
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write("]")
-	case *Builtin:
-		pp.write("(builtin ")
-
-		pp.write(t.String()) // Assumes DefaultFunction has a String method
-
-		pp.write(")")
-	case *Constr[T]:
-		pp.write(fmt.Sprintf("(constr %d", t.Tag))
-
-		if len(t.Fields) > 0 {
-			pp.write("\n")
-			pp.increaseIndent()
-
-			for _, field := range t.Fields {
-				pp.writeIndent()
-
-				printTerm[T](pp, field)
-
-				pp.write("\n")
-			}
-
-			pp.decreaseIndent()
-			pp.writeIndent()
-		}
-
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write(")")
-	case *Case[T]:
-		pp.write("(case ")
-
-		printTerm[T](pp, t.Constr)
-
-		if len(t.Branches) > 0 {
-			pp.write("\n")
-			pp.increaseIndent()
-
-			for _, branch := range t.Branches {
-				pp.writeIndent()
-
-				printTerm[T](pp, branch)
-
-				pp.write("\n")
-			}
-
-			pp.decreaseIndent()
-			pp.writeIndent()
-		}
-
-		pp.write("\n")
-		pp.writeIndent()
-
-		pp.write(")")
-	case *Error:
-		pp.write("(error )")
-	case *Constant:
-		pp.printConstant(t, false)
-	default:
-		panic(fmt.Sprintf("unknown term: %T: %v", t, t))
-	}
-}
-
-// printConstant formats a Constant node
-func (pp *PrettyPrinter) printConstant(c *Constant, inCollection bool) {
-	if !inCollection {
-		pp.write("(con ")
+func prettyPrintProgram[T Binder](pp *PrettyPrinter, prog *Program[T]) string {
+	if pp.comments != nil {
+		pp.writeComments(pp.comments.leading)
 	}
 
-	switch con := c.Con.(type) {
-	case *Integer:
-		if !inCollection {
-			pp.write("integer ")
-		}
-
-		pp.write(con.Inner.String())
-	case *ByteString:
-		if !inCollection {
-			pp.write("bytestring #")
-		}
-
-		for _, b := range con.Inner {
-			fmt.Fprintf(&pp.builder, "%02x", b)
-		}
-	case *String:
-		if !inCollection {
-			pp.write("string ")
-		}
-
-		pp.write("\"")
-
-		pp.write(escapeString(con.Inner))
-
-		pp.write("\"")
-	case *Unit:
-		if !inCollection {
-			pp.write("unit ")
-		}
-
-		pp.write("()")
-	case *Bool:
-		if !inCollection {
-			pp.write("bool ")
-		}
-
-		if con.Inner {
-			pp.write("True")
-		} else {
-			pp.write("False")
-		}
-	case *ProtoList:
-		if !inCollection {
-			pp.write("(list ")
-			pp.printType(con.LTyp)
-			pp.write(") ")
-		}
-
-		if len(con.List) == 0 {
-			pp.write("[]")
-		} else {
-			pp.write("[\n")
-			pp.increaseIndent()
-			for i, item := range con.List {
-				pp.writeIndent()
-				pp.printConstant(&Constant{Con: item}, true)
-				if i < len(con.List)-1 {
-					pp.write(",")
-				}
-				pp.write("\n")
-			}
-			pp.decreaseIndent()
-			pp.writeIndent()
-			pp.write("]")
-		}
-	case *ProtoPair:
-		if !inCollection {
-			pp.write("(pair ")
-			pp.printType(con.FstType)
-			pp.write(" ")
-			pp.printType(con.SndType)
-			pp.write(") ")
-		}
-		pp.write("(")
-		pp.printConstant(&Constant{Con: con.First}, true)
-		pp.write(", ")
-		pp.printConstant(&Constant{Con: con.Second}, true)
-		pp.write(")")
-	case *Data:
-		if !inCollection {
-			pp.write("data ")
-			pp.write("(")
-		}
-
-		pp.printPlutusData(con.Inner)
-
-		if !inCollection {
-			pp.write(")")
-		}
-	case *Bls12_381G1Element:
-		if !inCollection {
-			pp.write("bls12_381_G1_element 0x")
-		}
-
-		affine := new(bls.G1Affine).FromJacobian(con.Inner)
-
-		for _, b := range affine.Bytes() {
-			fmt.Fprintf(&pp.builder, "%02x", b)
-		}
-	case *Bls12_381G2Element:
-		if !inCollection {
-			pp.write("bls12_381_G2_element 0x")
-		}
-
-		affine := new(bls.G2Affine).FromJacobian(con.Inner)
-
-		for _, b := range affine.Bytes() {
-			fmt.Fprintf(&pp.builder, "%02x", b)
-		}
-	default:
-		pp.write(fmt.Sprintf("unknown constant: %v", c))
-	}
+	pp.write(pp.layout.render(pp.nest(programDoc(pp, prog))))
+	pp.write("\n")
 
-	if !inCollection {
-		pp.write(")")
+	if pp.comments != nil {
+		pp.writeComments(pp.comments.trailing)
 	}
-}
 
-// printType formats a Typ interface
-func (pp *PrettyPrinter) printType(typ Typ) {
-	switch t := typ.(type) {
-	case *TInteger:
-		pp.write("integer")
-	case *TByteString:
-		pp.write("bytestring")
-	case *TString:
-		pp.write("string")
-	case *TUnit:
-		pp.write("unit")
-	case *TBool:
-		pp.write("bool")
-	case *TData:
-		pp.write("data")
-	case *TList:
-		pp.write("(list ")
-		pp.printType(t.Typ)
-		pp.write(")")
-	case *TPair:
-		pp.write("(pair ")
-		pp.printType(t.First)
-		pp.write(" ")
-		pp.printType(t.Second)
-		pp.write(")")
-	default:
-		pp.write(fmt.Sprintf("unknown type: %v", typ))
-	}
+	return pp.builder.String()
 }
 
-// printPlutusData formats a PlutusData node
-func (pp *PrettyPrinter) printPlutusData(pd data.PlutusData) {
-	switch d := pd.(type) {
-	case *data.Integer:
-		pp.write("I ")
-		pp.write(d.Inner.String())
-	case *data.ByteString:
-		pp.write("B #")
-		for _, b := range d.Inner {
-			fmt.Fprintf(&pp.builder, "%02x", b)
-		}
-	case *data.List:
-		if len(d.Items) == 0 {
-			pp.write("List []")
-		} else {
-			pp.write("List [\n")
-			pp.increaseIndent()
-			for i, item := range d.Items {
-				pp.writeIndent()
-				pp.printPlutusData(item)
-				if i < len(d.Items)-1 {
-					pp.write(",")
-				}
-				pp.write("\n")
-			}
-			pp.decreaseIndent()
-			pp.writeIndent()
-			pp.write("]")
-		}
-	case *data.Map:
-		if len(d.Pairs) == 0 {
-			pp.write("Map []")
-		} else {
-			pp.write("Map [\n")
-			pp.increaseIndent()
-			for i, pair := range d.Pairs {
-				pp.writeIndent()
-				pp.write("(")
-				pp.printPlutusData(pair[0])
-				pp.write(", ")
-				pp.printPlutusData(pair[1])
-				pp.write(")")
-				if i < len(d.Pairs)-1 {
-					pp.write(",")
-				}
-				pp.write("\n")
-			}
-			pp.decreaseIndent()
-			pp.writeIndent()
-			pp.write("]")
-		}
-	case *data.Constr:
-		pp.write(fmt.Sprintf("Constr %d ", d.Tag))
-		if len(d.Fields) == 0 {
-			pp.write("[]")
-		} else {
-			pp.write("[\n")
-			pp.increaseIndent()
-			for i, field := range d.Fields {
-				pp.writeIndent()
-				pp.printPlutusData(field)
-				if i < len(d.Fields)-1 {
-					pp.write(",")
-				}
-				pp.write("\n")
-			}
-			pp.decreaseIndent()
-			pp.writeIndent()
-			pp.write("]")
-		}
-	default:
-		pp.write(fmt.Sprintf("unknown PlutusData: %v", pd))
+// writeComments writes comments, each on its own line
+func (pp *PrettyPrinter) writeComments(comments []string) {
+	for _, comment := range comments {
+		pp.write(comment)
+		pp.write("\n")
 	}
 }
 
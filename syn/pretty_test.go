@@ -55,3 +55,33 @@ func TestPrettyPrinterReset(t *testing.T) {
 		t.Errorf("expected indent 0 after Reset, got %d", pp.indent)
 	}
 }
+
+func TestPrettyLayout(t *testing.T) {
+	p, err := Parse(`(program 1.1.0 (lam x [(con (list integer) [1, 2]) (con data (Constr 0 [I 1])) (error)]))`)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	const want = `(program 1.1.0
+  (lam x
+    [
+      [
+        (con (list integer) [
+          1,
+          2
+        ])
+        (con data (Constr 0 [
+          I 1
+        ]))
+      ]
+      (error)
+    ]
+  )
+)
+`
+	if got := Pretty(p); got != want {
+		t.Errorf("Pretty() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Format(p, PrettyLayout); got != want {
+		t.Errorf("Format(PrettyLayout) =\n%s\nwant\n%s", got, want)
+	}
+}